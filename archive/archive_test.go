@@ -0,0 +1,153 @@
+package archive
+
+import (
+	"bytes"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+func init() {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}})
+}
+
+func newTestItem(height uint64) *Item {
+	now := time.Unix(1546275661+int64(height), 0)
+	addr, _, _ := types.CreateAddress()
+
+	send := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		Height:         height,
+		AccountAddress: addr,
+		ToAddress:      addr,
+		Amount:         big.NewInt(int64(height)),
+		Fee:            big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		Timestamp:      &now,
+	}
+	send.Hash = send.ComputeHash()
+
+	snapshotBlock := &ledger.SnapshotBlock{
+		Height:    height,
+		Timestamp: &now,
+		SnapshotContent: ledger.SnapshotContent{
+			addr: {Hash: send.Hash, Height: send.Height},
+		},
+	}
+	snapshotBlock.Hash = snapshotBlock.ComputeHash()
+
+	return &Item{
+		SnapshotBlock: snapshotBlock,
+		AccountBlocks: []*ledger.AccountBlock{send},
+	}
+}
+
+func writeTestArchive(t *testing.T, items []*Item) []byte {
+	buf := new(bytes.Buffer)
+	writer, err := NewWriter(buf, items[0].SnapshotBlock.Height, items[len(items)-1].SnapshotBlock.Height)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range items {
+		if err := writer.WriteItem(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriterReader(t *testing.T) {
+	items := []*Item{newTestItem(2), newTestItem(3), newTestItem(4)}
+	data := writeTestArchive(t, items)
+
+	reader, err := NewReader(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header := reader.Header(); header.Version != VERSION || header.FromHeight != 2 || header.ToHeight != 4 {
+		t.Fatalf("unexpected header %+v", header)
+	}
+
+	for _, expected := range items {
+		item, err := reader.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item.SnapshotBlock.Hash != expected.SnapshotBlock.Hash || item.SnapshotBlock.ComputeHash() != expected.SnapshotBlock.Hash {
+			t.Fatalf("snapshot block %d is not matched", expected.SnapshotBlock.Height)
+		}
+		if len(item.AccountBlocks) != 1 || item.AccountBlocks[0].ComputeHash() != expected.AccountBlocks[0].Hash {
+			t.Fatalf("account blocks of snapshot block %d are not matched", expected.SnapshotBlock.Height)
+		}
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Fatalf("archive should be finished, error is %v", err)
+	}
+
+	if _, count, err := Check(bytes.NewReader(data)); err != nil || count != 3 {
+		t.Fatalf("check failed, count is %d, error is %v", count, err)
+	}
+}
+
+func TestReaderCorrupted(t *testing.T) {
+	data := writeTestArchive(t, []*Item{newTestItem(2), newTestItem(3)})
+
+	tampered := append([]byte{}, data...)
+	tampered[len(tampered)-1] ^= 0xff
+	if _, _, err := Check(bytes.NewReader(tampered)); err != ErrChecksumMismatch {
+		t.Fatalf("tampered checksum should be detected, error is %v", err)
+	}
+
+	if _, _, err := Check(bytes.NewReader(data[:len(data)-40])); err != io.ErrUnexpectedEOF {
+		t.Fatalf("truncated archive should be detected, error is %v", err)
+	}
+
+	wrongMagic := append([]byte{}, data...)
+	wrongMagic[0] = 'X'
+	if _, _, err := Check(bytes.NewReader(wrongMagic)); err != ErrInvalidMagic {
+		t.Fatalf("wrong magic should be detected, error is %v", err)
+	}
+}
+
+func TestSortAccountBlocks(t *testing.T) {
+	addr1 := types.Address{1}
+	addr2 := types.Address{2}
+
+	send1 := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, AccountAddress: addr2, Height: 1, Hash: types.Hash{1}}
+	receive1 := &ledger.AccountBlock{BlockType: ledger.BlockTypeReceive, AccountAddress: addr1, Height: 5, Hash: types.Hash{2}, FromBlockHash: send1.Hash}
+	send2 := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, AccountAddress: addr1, Height: 6, Hash: types.Hash{3}}
+	receive2 := &ledger.AccountBlock{BlockType: ledger.BlockTypeReceive, AccountAddress: addr2, Height: 2, Hash: types.Hash{4}, FromBlockHash: send2.Hash}
+
+	result, err := sortAccountBlocks(map[types.Address][]*ledger.AccountBlock{
+		addr1: {send2, receive1},
+		addr2: {receive2, send1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*ledger.AccountBlock{send1, receive1, send2, receive2}
+	for i, block := range expected {
+		if result[i] != block {
+			t.Fatalf("block %d is %s, expected %s", i, result[i].Hash, block.Hash)
+		}
+	}
+
+	receive3 := &ledger.AccountBlock{BlockType: ledger.BlockTypeReceive, AccountAddress: addr1, Height: 1, Hash: types.Hash{5}, FromBlockHash: types.Hash{6}}
+	receive4 := &ledger.AccountBlock{BlockType: ledger.BlockTypeReceive, AccountAddress: addr2, Height: 1, Hash: types.Hash{6}, FromBlockHash: receive3.Hash}
+	if _, err := sortAccountBlocks(map[types.Address][]*ledger.AccountBlock{
+		addr1: {receive3},
+		addr2: {receive4},
+	}); err == nil {
+		t.Fatal("circular dependencies should be detected")
+	}
+}
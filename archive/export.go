@@ -0,0 +1,138 @@
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+type ExportChain interface {
+	GetLatestSnapshotBlock() *ledger.SnapshotBlock
+	GetConfirmSubLedger(fromHeight uint64, toHeight uint64) ([]*ledger.SnapshotBlock, map[types.Address][]*ledger.AccountBlock, error)
+	PrunedHeight() uint64
+}
+
+// Export writes the snapshot blocks in [fromHeight, toHeight] and the account blocks they confirm to w.
+func Export(chain ExportChain, w io.Writer, fromHeight uint64, toHeight uint64, progress func(height uint64)) error {
+	if fromHeight == 0 || fromHeight > toHeight {
+		return errors.New(fmt.Sprintf("invalid height range [%d, %d]", fromHeight, toHeight))
+	}
+	if latestHeight := chain.GetLatestSnapshotBlock().Height; toHeight > latestHeight {
+		return errors.New(fmt.Sprintf("toHeight %d is higher than the latest snapshot height %d", toHeight, latestHeight))
+	}
+	if prunedHeight := chain.PrunedHeight(); fromHeight <= prunedHeight {
+		return errors.New(fmt.Sprintf("the account blocks confirmed by the snapshot blocks up to %d are pruned", prunedHeight))
+	}
+
+	writer, err := NewWriter(w, fromHeight, toHeight)
+	if err != nil {
+		return err
+	}
+
+	for height := fromHeight; height <= toHeight; height++ {
+		snapshotBlocks, subLedger, err := chain.GetConfirmSubLedger(height, height)
+		if err != nil {
+			return errors.New(fmt.Sprintf("GetConfirmSubLedger failed, height is %d, error is %s", height, err.Error()))
+		}
+		if len(snapshotBlocks) != 1 {
+			return errors.New(fmt.Sprintf("snapshot block %d is not existed", height))
+		}
+
+		snapshotBlock := snapshotBlocks[0]
+		for addr, blocks := range subLedger {
+			// only keep the blocks confirmed by this snapshot block
+			hashHeight, ok := snapshotBlock.SnapshotContent[addr]
+			if !ok {
+				delete(subLedger, addr)
+				continue
+			}
+			confirmed := make([]*ledger.AccountBlock, 0, len(blocks))
+			for _, block := range blocks {
+				if block.Height <= hashHeight.Height {
+					confirmed = append(confirmed, block)
+				}
+			}
+			subLedger[addr] = confirmed
+		}
+
+		accountBlocks, err := sortAccountBlocks(subLedger)
+		if err != nil {
+			return errors.New(fmt.Sprintf("sort account blocks of snapshot block %d failed, error is %s", height, err.Error()))
+		}
+
+		if err := writer.WriteItem(&Item{
+			SnapshotBlock: snapshotBlock,
+			AccountBlocks: accountBlocks,
+		}); err != nil {
+			return err
+		}
+
+		if progress != nil {
+			progress(height)
+		}
+	}
+
+	return writer.Close()
+}
+
+// sortAccountBlocks flattens the sub ledger so that each block follows its previous block and the send
+// block it receives. Accounts are visited in address order, which makes the output reproducible.
+func sortAccountBlocks(subLedger map[types.Address][]*ledger.AccountBlock) ([]*ledger.AccountBlock, error) {
+	addrList := make([]types.Address, 0, len(subLedger))
+	chains := make(map[types.Address][]*ledger.AccountBlock, len(subLedger))
+	pending := make(map[types.Hash]struct{})
+
+	for addr, blocks := range subLedger {
+		addrList = append(addrList, addr)
+
+		chain := make([]*ledger.AccountBlock, len(blocks))
+		copy(chain, blocks)
+		sort.Slice(chain, func(i, j int) bool {
+			return chain[i].Height < chain[j].Height
+		})
+		chains[addr] = chain
+
+		for _, block := range chain {
+			pending[block.Hash] = struct{}{}
+		}
+	}
+	sort.Slice(addrList, func(i, j int) bool {
+		return bytes.Compare(addrList[i].Bytes(), addrList[j].Bytes()) < 0
+	})
+
+	result := make([]*ledger.AccountBlock, 0, len(pending))
+	for len(pending) > 0 {
+		progressed := false
+		for _, addr := range addrList {
+			for len(chains[addr]) > 0 {
+				block := chains[addr][0]
+				if _, ok := pending[block.Hash]; !ok {
+					// duplicated
+					chains[addr] = chains[addr][1:]
+					progressed = true
+					continue
+				}
+				if block.IsReceiveBlock() {
+					if _, ok := pending[block.FromBlockHash]; ok {
+						break
+					}
+				}
+
+				result = append(result, block)
+				delete(pending, block.Hash)
+				chains[addr] = chains[addr][1:]
+				progressed = true
+			}
+		}
+
+		if !progressed {
+			return nil, errors.New("account blocks have circular dependencies")
+		}
+	}
+	return result, nil
+}
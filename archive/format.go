@@ -0,0 +1,286 @@
+/*
+Package archive reads and writes portable ledger archives.
+
+An archive holds the snapshot blocks of a height range together with the account blocks
+each of them confirms. All integers are big endian.
+
+	header   magic "VITELDGR" (8 bytes) | version uint32 | fromHeight uint64 | toHeight uint64
+	item     SECTION_ITEM (1 byte) | accountBlockCount uint32 |
+	         accountBlockCount * (size uint32 | AccountBlock.Serialize()) |
+	         size uint32 | SnapshotBlock.Serialize()
+	trailer  SECTION_END (1 byte) | itemCount uint64 | sha256 (32 bytes)
+
+Items are ordered by snapshot height, the account blocks of an item are ordered so that
+every block comes after its previous block and after the send block it receives, if that
+send block is in the same item. The sha256 is computed over all bytes before it.
+*/
+package archive
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+const (
+	VERSION = uint32(1)
+
+	SECTION_ITEM = byte(1)
+	SECTION_END  = byte(2)
+
+	// a single block never comes close to this, it only guards against corrupted sizes
+	MAX_BLOCK_SIZE = 16 * 1024 * 1024
+)
+
+var (
+	MAGIC = []byte("VITELDGR")
+
+	ErrInvalidMagic     = errors.New("not a ledger archive")
+	ErrChecksumMismatch = errors.New("archive checksum is not matched")
+)
+
+type Header struct {
+	Version    uint32
+	FromHeight uint64
+	ToHeight   uint64
+}
+
+type Item struct {
+	SnapshotBlock *ledger.SnapshotBlock
+	AccountBlocks []*ledger.AccountBlock
+}
+
+type Writer struct {
+	w         *bufio.Writer
+	hash      hash.Hash
+	itemCount uint64
+}
+
+func NewWriter(w io.Writer, fromHeight uint64, toHeight uint64) (*Writer, error) {
+	writer := &Writer{
+		w:    bufio.NewWriter(w),
+		hash: sha256.New(),
+	}
+
+	buf := make([]byte, 0, len(MAGIC)+20)
+	buf = append(buf, MAGIC...)
+	buf = appendUint32(buf, VERSION)
+	buf = appendUint64(buf, fromHeight)
+	buf = appendUint64(buf, toHeight)
+	if err := writer.write(buf); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (writer *Writer) WriteItem(item *Item) error {
+	buf := []byte{SECTION_ITEM}
+	buf = appendUint32(buf, uint32(len(item.AccountBlocks)))
+	for _, block := range item.AccountBlocks {
+		blockBytes, err := block.Serialize()
+		if err != nil {
+			return errors.New(fmt.Sprintf("Serialize account block %s failed, error is %s", block.Hash, err.Error()))
+		}
+		buf = appendUint32(buf, uint32(len(blockBytes)))
+		buf = append(buf, blockBytes...)
+	}
+
+	blockBytes, err := item.SnapshotBlock.Serialize()
+	if err != nil {
+		return errors.New(fmt.Sprintf("Serialize snapshot block %s failed, error is %s", item.SnapshotBlock.Hash, err.Error()))
+	}
+	buf = appendUint32(buf, uint32(len(blockBytes)))
+	buf = append(buf, blockBytes...)
+
+	if err := writer.write(buf); err != nil {
+		return err
+	}
+	writer.itemCount++
+	return nil
+}
+
+// Close writes the trailer and flushes, it does not close the underlying writer.
+func (writer *Writer) Close() error {
+	buf := []byte{SECTION_END}
+	buf = appendUint64(buf, writer.itemCount)
+	if err := writer.write(buf); err != nil {
+		return err
+	}
+
+	if _, err := writer.w.Write(writer.hash.Sum(nil)); err != nil {
+		return err
+	}
+	return writer.w.Flush()
+}
+
+func (writer *Writer) write(buf []byte) error {
+	writer.hash.Write(buf)
+	_, err := writer.w.Write(buf)
+	return err
+}
+
+type Reader struct {
+	r         io.Reader
+	hash      hash.Hash
+	header    Header
+	itemCount uint64
+	finished  bool
+}
+
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{
+		r:    bufio.NewReader(r),
+		hash: sha256.New(),
+	}
+
+	magic, err := reader.read(len(MAGIC))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(magic, MAGIC) {
+		return nil, ErrInvalidMagic
+	}
+
+	buf, err := reader.read(20)
+	if err != nil {
+		return nil, err
+	}
+	reader.header = Header{
+		Version:    binary.BigEndian.Uint32(buf[:4]),
+		FromHeight: binary.BigEndian.Uint64(buf[4:12]),
+		ToHeight:   binary.BigEndian.Uint64(buf[12:20]),
+	}
+	if reader.header.Version != VERSION {
+		return nil, errors.New(fmt.Sprintf("archive version %d is not supported, supported version is %d", reader.header.Version, VERSION))
+	}
+	return reader, nil
+}
+
+func (reader *Reader) Header() Header {
+	return reader.header
+}
+
+// Next returns the next item, io.EOF is returned after the trailer has been read and the checksum is matched.
+func (reader *Reader) Next() (*Item, error) {
+	if reader.finished {
+		return nil, io.EOF
+	}
+
+	section, err := reader.read(1)
+	if err != nil {
+		return nil, err
+	}
+
+	switch section[0] {
+	case SECTION_ITEM:
+		return reader.readItem()
+	case SECTION_END:
+		return nil, reader.readTrailer()
+	default:
+		return nil, errors.New(fmt.Sprintf("unknown section %d", section[0]))
+	}
+}
+
+func (reader *Reader) readItem() (*Item, error) {
+	count, err := reader.readUint32()
+	if err != nil {
+		return nil, err
+	}
+
+	item := &Item{}
+	for i := uint32(0); i < count; i++ {
+		buf, err := reader.readBlock()
+		if err != nil {
+			return nil, err
+		}
+		block := &ledger.AccountBlock{}
+		if err := block.Deserialize(buf); err != nil {
+			return nil, errors.New("Deserialize account block failed, error is " + err.Error())
+		}
+		item.AccountBlocks = append(item.AccountBlocks, block)
+	}
+
+	buf, err := reader.readBlock()
+	if err != nil {
+		return nil, err
+	}
+	item.SnapshotBlock = &ledger.SnapshotBlock{}
+	if err := item.SnapshotBlock.Deserialize(buf); err != nil {
+		return nil, errors.New("Deserialize snapshot block failed, error is " + err.Error())
+	}
+
+	reader.itemCount++
+	return item, nil
+}
+
+func (reader *Reader) readTrailer() error {
+	buf, err := reader.read(8)
+	if err != nil {
+		return err
+	}
+	if itemCount := binary.BigEndian.Uint64(buf); itemCount != reader.itemCount {
+		return errors.New(fmt.Sprintf("archive has %d items, trailer says %d", reader.itemCount, itemCount))
+	}
+
+	sum := reader.hash.Sum(nil)
+	checksum := make([]byte, len(sum))
+	if _, err := io.ReadFull(reader.r, checksum); err != nil {
+		return err
+	}
+	if !bytes.Equal(checksum, sum) {
+		return ErrChecksumMismatch
+	}
+
+	reader.finished = true
+	return io.EOF
+}
+
+func (reader *Reader) readBlock() ([]byte, error) {
+	size, err := reader.readUint32()
+	if err != nil {
+		return nil, err
+	}
+	if size > MAX_BLOCK_SIZE {
+		return nil, errors.New(fmt.Sprintf("block size %d is too large", size))
+	}
+	return reader.read(int(size))
+}
+
+func (reader *Reader) readUint32() (uint32, error) {
+	buf, err := reader.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(buf), nil
+}
+
+func (reader *Reader) read(size int) ([]byte, error) {
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(reader.r, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	reader.hash.Write(buf)
+	return buf, nil
+}
+
+func appendUint32(buf []byte, n uint32) []byte {
+	nBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(nBytes, n)
+	return append(buf, nBytes...)
+}
+
+func appendUint64(buf []byte, n uint64) []byte {
+	nBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(nBytes, n)
+	return append(buf, nBytes...)
+}
@@ -0,0 +1,166 @@
+package archive
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/verifier"
+	"github.com/vitelabs/go-vite/vm_context"
+)
+
+type ImportChain interface {
+	GetLatestSnapshotBlock() *ledger.SnapshotBlock
+	GetSnapshotBlockHeadByHeight(height uint64) (*ledger.SnapshotBlock, error)
+	IsAccountBlockExisted(hash types.Hash) (bool, error)
+	InsertAccountBlocks(vmAccountBlocks []*vm_context.VmAccountBlock) error
+	InsertSnapshotBlock(snapshotBlock *ledger.SnapshotBlock) error
+}
+
+type AccountVerifier interface {
+	VerifyNetAb(block *ledger.AccountBlock) error
+	VerifyReferred(block *ledger.AccountBlock) (verifier.VerifyResult, *verifier.AccountBlockVerifyStat)
+	VerifyforVM(block *ledger.AccountBlock) ([]*vm_context.VmAccountBlock, error)
+}
+
+type SnapshotVerifier interface {
+	VerifyNetSb(block *ledger.SnapshotBlock) error
+	VerifyReferred(block *ledger.SnapshotBlock) *verifier.SnapshotBlockVerifyStat
+}
+
+// Check reads the whole archive and verifies its structure and checksum without touching the chain.
+func Check(r io.Reader) (Header, uint64, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return Header{}, 0, err
+	}
+
+	count := uint64(0)
+	for {
+		if _, err := reader.Next(); err != nil {
+			if err == io.EOF {
+				return reader.Header(), count, nil
+			}
+			return reader.Header(), count, err
+		}
+		count++
+	}
+}
+
+// Importer inserts archive items through the verifiers and the normal chain insert path.
+type Importer struct {
+	chain            ImportChain
+	accountVerifier  AccountVerifier
+	snapshotVerifier SnapshotVerifier
+}
+
+func NewImporter(chain ImportChain, accountVerifier AccountVerifier, snapshotVerifier SnapshotVerifier) *Importer {
+	return &Importer{
+		chain:            chain,
+		accountVerifier:  accountVerifier,
+		snapshotVerifier: snapshotVerifier,
+	}
+}
+
+// Import inserts all items of the archive, items already in the chain are skipped.
+func (importer *Importer) Import(r io.Reader, progress func(height uint64, skipped bool)) error {
+	reader, err := NewReader(r)
+	if err != nil {
+		return err
+	}
+
+	for {
+		item, err := reader.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		skipped, err := importer.ImportItem(item)
+		if err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(item.SnapshotBlock.Height, skipped)
+		}
+	}
+}
+
+func (importer *Importer) ImportItem(item *Item) (bool, error) {
+	snapshotBlock := item.SnapshotBlock
+
+	existed, err := importer.chain.GetSnapshotBlockHeadByHeight(snapshotBlock.Height)
+	if err != nil {
+		return false, err
+	}
+	if existed != nil {
+		if existed.Hash != snapshotBlock.Hash {
+			return false, errors.New(fmt.Sprintf("snapshot block %d in the chain is %s, but %s in the archive, the archive is from another chain",
+				snapshotBlock.Height, existed.Hash, snapshotBlock.Hash))
+		}
+		return true, nil
+	}
+
+	if latestHeight := importer.chain.GetLatestSnapshotBlock().Height; snapshotBlock.Height != latestHeight+1 {
+		return false, errors.New(fmt.Sprintf("snapshot block %d can't be imported, the latest snapshot height is %d", snapshotBlock.Height, latestHeight))
+	}
+
+	archived := make(map[types.Hash]struct{}, len(item.AccountBlocks))
+	for _, block := range item.AccountBlocks {
+		archived[block.Hash] = struct{}{}
+	}
+
+	for _, block := range item.AccountBlocks {
+		if err := importer.importAccountBlock(block, archived); err != nil {
+			return false, errors.New(fmt.Sprintf("import account block %s of %s failed, error is %s", block.Hash, block.AccountAddress, err.Error()))
+		}
+	}
+
+	if err := importer.snapshotVerifier.VerifyNetSb(snapshotBlock); err != nil {
+		return false, errors.New(fmt.Sprintf("verify snapshot block %d failed, error is %s", snapshotBlock.Height, err.Error()))
+	}
+	if stat := importer.snapshotVerifier.VerifyReferred(snapshotBlock); stat.VerifyResult() != verifier.SUCCESS {
+		return false, errors.New(fmt.Sprintf("verify snapshot block %d failed, error is %s", snapshotBlock.Height, stat.ErrMsg()))
+	}
+
+	if err := importer.chain.InsertSnapshotBlock(snapshotBlock); err != nil {
+		return false, errors.New(fmt.Sprintf("insert snapshot block %d failed, error is %s", snapshotBlock.Height, err.Error()))
+	}
+	return false, nil
+}
+
+func (importer *Importer) importAccountBlock(block *ledger.AccountBlock, archived map[types.Hash]struct{}) error {
+	// the send blocks generated by a contract receive are inserted together with the receive block
+	if existed, err := importer.chain.IsAccountBlockExisted(block.Hash); err != nil {
+		return err
+	} else if existed {
+		return nil
+	}
+
+	if err := importer.accountVerifier.VerifyNetAb(block); err != nil {
+		return err
+	}
+
+	if result, stat := importer.accountVerifier.VerifyReferred(block); result != verifier.SUCCESS {
+		if stat.ErrMsg() != "" {
+			return errors.New(stat.ErrMsg())
+		}
+		return errors.New("verify referred block failed")
+	}
+
+	vmBlocks, err := importer.accountVerifier.VerifyforVM(block)
+	if err != nil {
+		return err
+	}
+	for _, vmBlock := range vmBlocks[1:] {
+		if _, ok := archived[vmBlock.AccountBlock.Hash]; !ok {
+			return errors.New(fmt.Sprintf("generated block %s is not in the archive", vmBlock.AccountBlock.Hash))
+		}
+	}
+
+	return importer.chain.InsertAccountBlocks(vmBlocks)
+}
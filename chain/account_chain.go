@@ -9,6 +9,7 @@ import (
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/monitor"
+	"github.com/vitelabs/go-vite/trie"
 	"github.com/vitelabs/go-vite/vm_context"
 	"time"
 )
@@ -342,7 +343,17 @@ func (c *chain) GetAccountBalance(addr *types.Address) (map[types.TokenTypeId]*b
 	if trie == nil {
 		return nil, nil
 	}
-	storageIterator := trie.NewIterator(vm_context.STORAGE_KEY_BALANCE)
+
+	balanceMap, err := getBalanceMap(trie)
+	if err != nil {
+		c.log.Error("getBalanceMap failed, error is "+err.Error(), "method", "GetAccountBalance")
+		return nil, err
+	}
+	return balanceMap, nil
+}
+
+func getBalanceMap(stateTrie *trie.Trie) (map[types.TokenTypeId]*big.Int, error) {
+	storageIterator := stateTrie.NewIterator(vm_context.STORAGE_KEY_BALANCE)
 	balanceMap := make(map[types.TokenTypeId]*big.Int)
 	prefixKeyLen := len(vm_context.STORAGE_KEY_BALANCE)
 	for {
@@ -354,7 +365,6 @@ func (c *chain) GetAccountBalance(addr *types.Address) (map[types.TokenTypeId]*b
 		tokenIdBytes := key[prefixKeyLen:]
 		tokenId, err := types.BytesToTokenTypeId(tokenIdBytes)
 		if err != nil {
-			c.log.Error("types.BytesToTokenTypeId failed, error is "+err.Error(), "method", "GetAccountBalance")
 			return nil, err
 		}
 
@@ -0,0 +1,48 @@
+package chain
+
+import (
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/chain/block_pruner"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+var ErrPruned = errors.New("the account block has been pruned")
+
+func (c *chain) BlockPruner() block_pruner.Pruner {
+	return c.blockPruner
+}
+
+func (c *chain) PrunedHeight() uint64 {
+	return c.blockPruner.PrunedHeight()
+}
+
+// IsAccountBlockPruned returns true if the block is confirmed by a pruned snapshot block and is not the head of its
+// account chain. Such a block may be kept in full for on road and rollback, but it is not served any more.
+func (c *chain) IsAccountBlockPruned(block *ledger.AccountBlock) (bool, error) {
+	prunedHeight := c.PrunedHeight()
+	if prunedHeight <= 0 {
+		return false, nil
+	}
+
+	meta := block.Meta
+	if meta == nil {
+		var err error
+		if meta, err = c.chainDb.Ac.GetBlockMeta(&block.Hash); err != nil {
+			c.log.Error("GetBlockMeta failed, error is "+err.Error(), "method", "IsAccountBlockPruned")
+			return false, err
+		}
+		if meta == nil {
+			return false, nil
+		}
+	}
+	if meta.SnapshotHeight <= 0 || meta.SnapshotHeight > prunedHeight {
+		return false, nil
+	}
+
+	latestBlock, err := c.chainDb.Ac.GetLatestBlock(meta.AccountId)
+	if err != nil {
+		c.log.Error("GetLatestBlock failed, error is "+err.Error(), "method", "IsAccountBlockPruned")
+		return false, err
+	}
+	return latestBlock == nil || latestBlock.Hash != block.Hash, nil
+}
@@ -0,0 +1,28 @@
+package block_pruner
+
+import (
+	"encoding/binary"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/chain_db/database"
+)
+
+// GetPrunedHeight reads the pruned height written by the pruner, it is 0 if the ledger has never been pruned.
+func GetPrunedHeight(db *leveldb.DB) (uint64, error) {
+	key, _ := database.EncodeKey(database.DBKP_PRUNED_HEIGHT)
+	value, err := db.Get(key, nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(value), nil
+}
+
+func writePrunedHeight(batch *leveldb.Batch, height uint64) {
+	key, _ := database.EncodeKey(database.DBKP_PRUNED_HEIGHT)
+	value := make([]byte, 8)
+	binary.BigEndian.PutUint64(value, height)
+	batch.Put(key, value)
+}
@@ -0,0 +1,23 @@
+package block_pruner
+
+import (
+	"github.com/vitelabs/go-vite/chain_db"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+type Pruner interface {
+	Start()
+	Stop()
+	Status() uint8
+
+	// PrunedHeight returns the height of the highest snapshot block whose confirmed account blocks have been pruned,
+	// 0 means nothing is pruned
+	PrunedHeight() uint64
+	Prune(terminal <-chan struct{}) error
+}
+
+type Chain interface {
+	ChainDb() *chain_db.ChainDb
+	GetLatestSnapshotBlock() *ledger.SnapshotBlock
+	IsGenesisAccountBlock(block *ledger.AccountBlock) bool
+}
@@ -0,0 +1,320 @@
+package block_pruner
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+)
+
+const (
+	STATUS_STOPPED = 1
+	STATUS_STARTED = 2
+	STATUS_PRUNING = 3
+)
+
+const (
+	DEFAULT_RETAIN_HEIGHT = 86400
+
+	// a rollback deeper than the retained snapshot blocks is refused, so they can't be too few
+	MIN_RETAIN_HEIGHT = 3600
+)
+
+var errTerminated = errors.New("pruning is terminated")
+
+type pruner struct {
+	terminal     chan struct{}
+	taskTerminal chan struct{}
+
+	statusLock sync.Mutex
+	status     uint8
+
+	wg sync.WaitGroup
+
+	checkInterval        time.Duration
+	heightsPerRound      uint64
+	sleepPerRound        time.Duration
+	retainSnapshotHeight uint64
+
+	chain Chain
+
+	log log15.Logger
+}
+
+// NewPruner creates a pruner which deletes the bodies of the account blocks confirmed by the snapshot blocks
+// lower than the latest retainSnapshotHeight ones, their hashes, headers and metas are kept.
+func NewPruner(chain Chain, retainSnapshotHeight uint64) Pruner {
+	if retainSnapshotHeight <= 0 {
+		retainSnapshotHeight = DEFAULT_RETAIN_HEIGHT
+	} else if retainSnapshotHeight < MIN_RETAIN_HEIGHT {
+		retainSnapshotHeight = MIN_RETAIN_HEIGHT
+	}
+
+	return &pruner{
+		status: STATUS_STOPPED,
+
+		checkInterval:        10 * time.Minute,
+		heightsPerRound:      100,
+		sleepPerRound:        5 * time.Millisecond,
+		retainSnapshotHeight: retainSnapshotHeight,
+
+		chain: chain,
+		log:   log15.New("module", "block_pruner"),
+	}
+}
+
+func (p *pruner) Start() {
+	p.statusLock.Lock()
+	if p.status >= STATUS_STARTED {
+		p.log.Error("pruner is started, don't start again")
+		p.statusLock.Unlock()
+		return
+	}
+
+	p.status = STATUS_STARTED
+
+	p.taskTerminal = make(chan struct{}, 1)
+	p.terminal = make(chan struct{}, 1)
+
+	p.wg.Add(1)
+	p.statusLock.Unlock()
+
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.checkInterval)
+		defer ticker.Stop()
+
+		p.runTask()
+		for {
+			select {
+			case <-ticker.C:
+				p.runTask()
+			case <-p.terminal:
+				return
+			}
+		}
+	}()
+	p.log.Info("pruner started.")
+}
+
+func (p *pruner) Stop() {
+	p.statusLock.Lock()
+	if p.status < STATUS_STARTED {
+		p.statusLock.Unlock()
+		return
+	}
+	p.status = STATUS_STOPPED
+
+	p.log.Info("pruner stopping.")
+
+	close(p.taskTerminal)
+	close(p.terminal)
+
+	p.statusLock.Unlock()
+
+	p.wg.Wait()
+	p.log.Info("pruner stopped.")
+}
+
+func (p *pruner) Status() uint8 {
+	return p.status
+}
+
+func (p *pruner) PrunedHeight() uint64 {
+	prunedHeight, err := GetPrunedHeight(p.chain.ChainDb().Db())
+	if err != nil {
+		p.log.Error("GetPrunedHeight failed, error is "+err.Error(), "method", "PrunedHeight")
+		return 0
+	}
+	return prunedHeight
+}
+
+func (p *pruner) runTask() {
+	p.statusLock.Lock()
+	if p.status > STATUS_STARTED {
+		p.log.Error("One task is already running, can't run multiple tasks in parallel.", "method", "runTask")
+		p.statusLock.Unlock()
+		return
+	}
+	p.status = STATUS_PRUNING
+	p.statusLock.Unlock()
+
+	defer func() {
+		p.statusLock.Lock()
+		if p.status == STATUS_PRUNING {
+			p.status = STATUS_STARTED
+		}
+		p.statusLock.Unlock()
+	}()
+
+	if err := p.Prune(p.taskTerminal); err != nil && err != errTerminated {
+		p.log.Error("Prune failed, error is "+err.Error(), "method", "runTask")
+	}
+}
+
+// Prune prunes the snapshot blocks from the pruned height to the latest retained one, the pruned height is written
+// with every round so that an interrupted task is continued by the next one.
+func (p *pruner) Prune(terminal <-chan struct{}) error {
+	latestSnapshotBlock := p.chain.GetLatestSnapshotBlock()
+	if latestSnapshotBlock.Height <= p.retainSnapshotHeight {
+		return nil
+	}
+	targetHeight := latestSnapshotBlock.Height - p.retainSnapshotHeight
+
+	prunedHeight, err := GetPrunedHeight(p.chain.ChainDb().Db())
+	if err != nil {
+		return err
+	}
+
+	for prunedHeight < targetHeight {
+		select {
+		case <-terminal:
+			return errTerminated
+		default:
+		}
+
+		roundHeight := prunedHeight + p.heightsPerRound
+		if roundHeight > targetHeight {
+			roundHeight = targetHeight
+		}
+
+		batch := new(leveldb.Batch)
+		for height := prunedHeight + 1; height <= roundHeight; height++ {
+			if err := p.pruneSnapshotHeight(batch, height, targetHeight); err != nil {
+				return errors.New(fmt.Sprintf("prune snapshot block %d failed, error is %s", height, err.Error()))
+			}
+		}
+		writePrunedHeight(batch, roundHeight)
+
+		if err := p.chain.ChainDb().Commit(batch); err != nil {
+			return err
+		}
+		prunedHeight = roundHeight
+
+		time.Sleep(p.sleepPerRound)
+	}
+
+	p.log.Info(fmt.Sprintf("pruned to snapshot block %d", prunedHeight), "method", "Prune")
+	return nil
+}
+
+// pruneSnapshotHeight prunes the account blocks confirmed by the snapshot block at snapshotHeight.
+func (p *pruner) pruneSnapshotHeight(batch *leveldb.Batch, snapshotHeight uint64, targetHeight uint64) error {
+	chainDb := p.chain.ChainDb()
+
+	content, err := chainDb.Sc.GetSnapshotContent(snapshotHeight)
+	if err != nil {
+		return err
+	}
+
+	for addr, hashHeight := range content {
+		account, err := chainDb.Account.GetAccountByAddress(&addr)
+		if err != nil {
+			return err
+		}
+		if account == nil {
+			continue
+		}
+
+		// the blocks confirmed by the lower snapshot blocks have been pruned
+		for height := hashHeight.Height; height > 0; height-- {
+			block, err := chainDb.Ac.GetBlockByHeight(account.AccountId, height)
+			if err != nil {
+				return err
+			}
+			if block == nil {
+				break
+			}
+			meta, err := chainDb.Ac.GetBlockMeta(&block.Hash)
+			if err != nil {
+				return err
+			}
+			if meta == nil || meta.SnapshotHeight != snapshotHeight {
+				break
+			}
+			block.AccountAddress = addr
+			block.Meta = meta
+
+			ok, err := p.canPrune(account.AccountId, block, targetHeight)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+
+			if block.LogHash != nil {
+				chainDb.Ac.DeleteVmLogList(batch, block.LogHash)
+			}
+
+			block.Data = nil
+			block.Signature = nil
+			block.PublicKey = nil
+			block.Nonce = nil
+			block.Difficulty = nil
+			if err := chainDb.Ac.WriteBlock(batch, account.AccountId, block); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// canPrune keeps the blocks which are still needed: the genesis blocks, the send create blocks which carry the
+// contract code, the head of each account chain and the send blocks which can be received again after a rollback.
+func (p *pruner) canPrune(accountId uint64, block *ledger.AccountBlock, targetHeight uint64) (bool, error) {
+	if p.chain.IsGenesisAccountBlock(block) || block.BlockType == ledger.BlockTypeSendCreate {
+		return false, nil
+	}
+
+	chainDb := p.chain.ChainDb()
+
+	// the next block can't be rolled back, so the block is not the head any more
+	nextBlock, err := chainDb.Ac.GetBlockByHeight(accountId, block.Height+1)
+	if err != nil {
+		return false, err
+	}
+	if nextBlock == nil {
+		return false, nil
+	}
+	if ok, err := p.isConfirmedBefore(&nextBlock.Hash, targetHeight); err != nil || !ok {
+		return false, err
+	}
+
+	if !block.IsSendBlock() {
+		return true, nil
+	}
+
+	// all the receive blocks can't be rolled back
+	if len(block.Meta.ReceiveBlockHeights) <= 0 {
+		return false, nil
+	}
+	toAccount, err := chainDb.Account.GetAccountByAddress(&block.ToAddress)
+	if err != nil || toAccount == nil {
+		return false, err
+	}
+	for _, receiveHeight := range block.Meta.ReceiveBlockHeights {
+		receiveHash, err := chainDb.Ac.GetHashByHeight(toAccount.AccountId, receiveHeight)
+		if err != nil || receiveHash == nil {
+			return false, err
+		}
+		if ok, err := p.isConfirmedBefore(receiveHash, targetHeight); err != nil || !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (p *pruner) isConfirmedBefore(blockHash *types.Hash, targetHeight uint64) (bool, error) {
+	confirmHeight, err := p.chain.ChainDb().Ac.GetBeSnapshot(blockHash)
+	if err != nil {
+		return false, err
+	}
+	return confirmHeight > 0 && confirmHeight <= targetHeight, nil
+}
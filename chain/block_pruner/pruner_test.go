@@ -0,0 +1,132 @@
+package block_pruner
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/chain_db"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+type testChain struct {
+	chainDb             *chain_db.ChainDb
+	latestSnapshotBlock *ledger.SnapshotBlock
+}
+
+func (c *testChain) ChainDb() *chain_db.ChainDb                            { return c.chainDb }
+func (c *testChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock         { return c.latestSnapshotBlock }
+func (c *testChain) IsGenesisAccountBlock(block *ledger.AccountBlock) bool { return false }
+
+type testBlock struct {
+	accountId     uint64
+	blockType     byte
+	height        uint64
+	to            uint64
+	receiveHeight uint64
+	confirmHeight uint64
+}
+
+func TestPrune(t *testing.T) {
+	dir, err := ioutil.TempDir("", "block_pruner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &testChain{
+		chainDb:             chain_db.NewChainDb(dir),
+		latestSnapshotBlock: &ledger.SnapshotBlock{Height: 6},
+	}
+	batch := new(leveldb.Batch)
+	now := time.Unix(1546275661, 0)
+
+	addrList := make([]types.Address, 3)
+	for i := uint64(1); i <= 2; i++ {
+		addrList[i], _, _ = types.CreateAddress()
+		c.chainDb.Account.WriteAccountIndex(batch, i, &addrList[i])
+		c.chainDb.Account.WriteAccount(batch, &ledger.Account{AccountAddress: addrList[i], AccountId: i})
+	}
+
+	logList := ledger.VmLogList{{Data: []byte("log")}}
+	c.chainDb.Ac.WriteVmLogList(batch, logList)
+
+	// the second account receives the first and the third send blocks of the first account
+	testBlocks := []*testBlock{
+		{accountId: 1, blockType: ledger.BlockTypeSendCall, height: 1, to: 2, receiveHeight: 1, confirmHeight: 2},
+		{accountId: 1, blockType: ledger.BlockTypeSendCall, height: 2, to: 2, confirmHeight: 3},
+		{accountId: 1, blockType: ledger.BlockTypeSendCall, height: 3, to: 2, receiveHeight: 2, confirmHeight: 4},
+		{accountId: 1, blockType: ledger.BlockTypeSendCall, height: 4, to: 2, confirmHeight: 5},
+		{accountId: 2, blockType: ledger.BlockTypeReceive, height: 1, confirmHeight: 3},
+		{accountId: 2, blockType: ledger.BlockTypeReceive, height: 2, confirmHeight: 4},
+	}
+	contents := make(map[uint64]ledger.SnapshotContent)
+	hashList := make([]types.Hash, len(testBlocks))
+	for i, item := range testBlocks {
+		hashList[i] = types.DataHash([]byte{byte(i)})
+		block := &ledger.AccountBlock{
+			BlockType:      item.blockType,
+			Hash:           hashList[i],
+			Height:         item.height,
+			AccountAddress: addrList[item.accountId],
+			ToAddress:      addrList[item.to],
+			Amount:         big.NewInt(1),
+			Timestamp:      &now,
+			Data:           []byte("data"),
+			Signature:      []byte("signature"),
+			LogHash:        logList.Hash(),
+		}
+		meta := &ledger.AccountBlockMeta{AccountId: item.accountId, Height: item.height}
+		if item.receiveHeight > 0 {
+			meta.ReceiveBlockHeights = []uint64{item.receiveHeight}
+		}
+		c.chainDb.Ac.WriteBlock(batch, item.accountId, block)
+		c.chainDb.Ac.WriteBlockMeta(batch, &block.Hash, meta)
+		c.chainDb.Ac.WriteBeSnapshot(batch, &block.Hash, item.confirmHeight)
+
+		if contents[item.confirmHeight] == nil {
+			contents[item.confirmHeight] = make(ledger.SnapshotContent)
+		}
+		contents[item.confirmHeight][block.AccountAddress] = &ledger.HashHeight{Hash: block.Hash, Height: block.Height}
+	}
+	for height := uint64(1); height <= 6; height++ {
+		c.chainDb.Sc.WriteSnapshotContent(batch, height, contents[height])
+	}
+	if err := c.chainDb.Commit(batch); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewPruner(c, 0).(*pruner)
+	p.retainSnapshotHeight = 2
+	if err := p.Prune(make(chan struct{})); err != nil {
+		t.Fatal(err)
+	}
+	if prunedHeight := p.PrunedHeight(); prunedHeight != 4 {
+		t.Fatalf("pruned height is %d", prunedHeight)
+	}
+
+	// the unreceived send block, the blocks which may be the head after a rollback and the blocks after the pruned
+	// height are kept
+	expected := []bool{true, false, false, false, true, false}
+	for i, item := range testBlocks {
+		block, err := c.chainDb.Ac.GetBlock(&hashList[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if block == nil || block.Height != item.height || block.Hash != hashList[i] {
+			t.Fatalf("the header of block %d is lost", i)
+		}
+		if pruned := block.Data == nil && block.Signature == nil; pruned != expected[i] {
+			t.Fatalf("block %d is pruned: %v", i, pruned)
+		}
+	}
+
+	// the log list is shared by the blocks in this test
+	if vmLogList, err := c.chainDb.Ac.GetVmLogList(logList.Hash()); err != nil || vmLogList != nil {
+		t.Fatalf("the log list is not deleted, %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/chain/block_pruner"
 	"github.com/vitelabs/go-vite/chain/cache"
 	"github.com/vitelabs/go-vite/chain/index"
 	"github.com/vitelabs/go-vite/chain/sender"
@@ -46,6 +47,7 @@ type chain struct {
 	globalCfg   *config.Config
 	kafkaSender *sender.KafkaSender
 	trieGc      trie_gc.Collector
+	blockPruner block_pruner.Pruner
 
 	saveTrieLock sync.RWMutex
 
@@ -54,6 +56,8 @@ type chain struct {
 
 	saList *chain_cache.AdditionList
 	fti    *chain_index.FilterTokenIndex
+
+	indexer *chain_index.Indexer
 }
 
 func NewChain(cfg *config.Config) Chain {
@@ -79,6 +83,15 @@ func NewChain(cfg *config.Config) Chain {
 		}
 	}
 
+	if chain.cfg.OpenSecondaryIndex {
+		var err error
+		chain.indexer, err = chain_index.NewIndexer(cfg, chain, chain_index.NewDefaultSecondaryIndexes(chain)...)
+		if err != nil {
+			chain.log.Crit("NewIndexer failed, error is "+err.Error(), "method", "NewChain")
+			return nil
+		}
+	}
+
 	chain.needSnapshotCache = chain_cache.NewNeedSnapshotCache(chain)
 	chain.blackBlock = NewBlackBlock(chain, chain.cfg.OpenBlackBlock)
 
@@ -119,12 +132,15 @@ func (c *chain) Init() {
 	// trie gc
 	c.trieGc = trie_gc.NewCollector(c, c.cfg.LedgerGcRetain)
 
+	// block pruner
+	c.blockPruner = block_pruner.NewPruner(c, c.cfg.LedgerPruneRetain)
+
 	// compressor
 	compressor := compress.NewCompressor(c, c.dataDir)
 	c.compressor = compressor
 
 	// kafka sender
-	if len(c.cfg.KafkaProducers) > 0 {
+	if len(c.cfg.KafkaProducers) > 0 || len(c.cfg.EventSinks) > 0 {
 		var newKafkaErr error
 		c.kafkaSender, newKafkaErr = sender.NewKafkaSender(c, filepath.Join(c.dataDir, "ledger_mq"))
 		if newKafkaErr != nil {
@@ -173,6 +189,10 @@ func (c *chain) checkForkPoints() (bool, *config.ForkPoint, error) {
 
 	for k := 0; k < t.NumField(); k++ {
 		forkPoint := v.Field(k).Interface().(*config.ForkPoint)
+		// a fork point not scheduled yet is nil
+		if forkPoint == nil {
+			continue
+		}
 		if forkPoint.Height > 0 && forkPoint.Hash != nil && forkPoint.Height <= latestSnapshotHeight {
 			blockPoint, err := c.GetSnapshotBlockByHash(forkPoint.Hash)
 			if err != nil {
@@ -314,6 +334,10 @@ func (c *chain) Fti() *chain_index.FilterTokenIndex {
 	return c.fti
 }
 
+func (c *chain) Indexer() *chain_index.Indexer {
+	return c.indexer
+}
+
 func (c *chain) Start() {
 	// saList start
 	c.saList.Start()
@@ -324,8 +348,14 @@ func (c *chain) Start() {
 	// check
 	c.checkAndInitData()
 
-	// start compressor
-	c.compressor.Start()
+	// start compressor, the compressed files are made of full blocks, so they are not kept by a pruned node
+	if c.cfg.LedgerPrune {
+		if err := c.compressor.ClearData(); err != nil {
+			c.log.Error("Compressor clear data failed, error is "+err.Error(), "method", "Start")
+		}
+	} else {
+		c.compressor.Start()
+	}
 
 	// start kafka sender
 	if c.kafkaSender != nil {
@@ -335,6 +365,13 @@ func (c *chain) Start() {
 				c.log.Crit("Start kafka sender failed, error is " + startErr.Error())
 			}
 		}
+
+		for _, eventSink := range c.cfg.EventSinks {
+			startErr := c.kafkaSender.StartSink(eventSink.Kind, eventSink.Target)
+			if startErr != nil {
+				c.log.Crit("Start event sink failed, error is " + startErr.Error())
+			}
+		}
 	}
 
 	// check trie
@@ -349,6 +386,11 @@ func (c *chain) Start() {
 		c.TrieGc().Start()
 	}
 
+	// block pruner
+	if c.cfg.LedgerPrune {
+		c.BlockPruner().Start()
+	}
+
 	// start build filter token index
 	if c.fti != nil {
 		fmt.Printf("FilterTokenIndex is being initialized...\n")
@@ -356,6 +398,13 @@ func (c *chain) Start() {
 		fmt.Printf("FilterTokenIndex initialization complete\n")
 	}
 
+	// start build secondary indexes
+	if c.indexer != nil {
+		fmt.Printf("Secondary indexes are being initialized...\n")
+		c.indexer.Start()
+		fmt.Printf("Secondary indexes initialization complete\n")
+	}
+
 	c.log.Info("Chain module started")
 }
 
@@ -365,6 +414,11 @@ func (c *chain) Stop() {
 		c.fti.Stop()
 	}
 
+	// stop build secondary indexes
+	if c.indexer != nil {
+		c.indexer.Stop()
+	}
+
 	// saList top
 	c.saList.Stop()
 
@@ -372,6 +426,11 @@ func (c *chain) Stop() {
 	if c.cfg.LedgerGc {
 		c.TrieGc().Stop()
 	}
+
+	// block pruner
+	if c.cfg.LedgerPrune {
+		c.BlockPruner().Stop()
+	}
 	// Stop compress
 	c.log.Info("Stop chain module")
 
@@ -1,15 +1,19 @@
 package chain
 
 import (
+	"io/ioutil"
 	"path/filepath"
+	"testing"
 
 	"encoding/json"
 	"fmt"
 	"github.com/vitelabs/go-vite/common"
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/config"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/node/unittest"
 	"math/big"
 	"os"
 )
@@ -158,3 +162,27 @@ func getChainInstance() Chain {
 
 	return innerChainInstance
 }
+
+// newTempChain starts a chain with the mainnet fork points in a temp dir, the dir is removed by the returned func
+func newTempChain(t *testing.T) (Chain, func()) {
+	dataDir, err := ioutil.TempDir("", "chain")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genesisConfig := makeChainConfig("")
+	genesisConfig.ForkPoints = node_unittest.MakeMainNetForkPointsConfig()
+	fork.SetForkPoints(genesisConfig.ForkPoints)
+
+	chainInstance := NewChain(&config.Config{
+		DataDir: dataDir,
+		Genesis: genesisConfig,
+	})
+	chainInstance.Init()
+	chainInstance.Start()
+	return chainInstance, func() {
+		chainInstance.Stop()
+		chainInstance.Destroy()
+		os.RemoveAll(dataDir)
+	}
+}
@@ -21,7 +21,7 @@ func (c *chain) GetContractGidByAccountBlock(block *ledger.AccountBlock) (*types
 	}
 
 	if block.Height == 1 {
-		if types.IsPrecompiledContractAddress(block.AccountAddress) {
+		if types.IsPrecompiledContractAddress(block.AccountAddress, c.GetLatestSnapshotBlock().Height) {
 			return &types.DELEGATE_GID, nil
 		}
 
@@ -44,7 +44,7 @@ func (c *chain) GetContractGid(addr *types.Address) (*types.Gid, error) {
 		return nil, nil
 	}
 
-	if types.IsPrecompiledContractAddress(*addr) {
+	if types.IsPrecompiledContractAddress(*addr, c.GetLatestSnapshotBlock().Height) {
 		return &types.DELEGATE_GID, nil
 	}
 
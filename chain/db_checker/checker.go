@@ -0,0 +1,637 @@
+package db_checker
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/vitelabs/go-vite/chain/block_pruner"
+	"github.com/vitelabs/go-vite/chain_db"
+	"github.com/vitelabs/go-vite/chain_db/database"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/trie"
+)
+
+const (
+	STAGE_SNAPSHOT_BLOCKS = "snapshot blocks"
+	STAGE_ACCOUNTS        = "accounts"
+	STAGE_ON_ROAD         = "on road blocks"
+	STAGE_STATE_TRIE      = "state trie nodes"
+)
+
+type Chain interface {
+	ChainDb() *chain_db.ChainDb
+	GetLatestSnapshotBlock() *ledger.SnapshotBlock
+	IsGenesisSnapshotBlock(block *ledger.SnapshotBlock) bool
+	IsGenesisAccountBlock(block *ledger.AccountBlock) bool
+	AccountType(address *types.Address) (uint64, error)
+}
+
+type checker struct {
+	chain   Chain
+	chainDb *chain_db.ChainDb
+	db      *leveldb.DB
+
+	// state tries of lower snapshot blocks may have been cleared by trie gc
+	retainMinHeight uint64
+	progress        func(stage string, count uint64)
+
+	report *Report
+
+	visitedNodes map[types.Hash]struct{}
+}
+
+// pendingProblem is a problem of an account block, its recover height is known after the whole account chain is walked.
+type pendingProblem struct {
+	problem *Problem
+	index   int
+}
+
+// Check walks the whole ledger and reports the problems found. It returns an error only if the ledger can't be read,
+// the chain must not be written during the check.
+func Check(chain Chain, retainMinHeight uint64, progress func(stage string, count uint64)) (*Report, error) {
+	c := &checker{
+		chain:           chain,
+		chainDb:         chain.ChainDb(),
+		db:              chain.ChainDb().Db(),
+		retainMinHeight: retainMinHeight,
+		progress:        progress,
+
+		report: &Report{
+			LatestSnapshotHeight: chain.GetLatestSnapshotBlock().Height,
+		},
+		visitedNodes: make(map[types.Hash]struct{}),
+	}
+
+	prunedHeight, err := block_pruner.GetPrunedHeight(c.db)
+	if err != nil {
+		return nil, err
+	}
+	c.report.PrunedHeight = prunedHeight
+
+	if err := c.checkSnapshotChain(); err != nil {
+		return nil, err
+	}
+	if err := c.checkAccounts(); err != nil {
+		return nil, err
+	}
+	if err := c.checkOnRoadMeta(); err != nil {
+		return nil, err
+	}
+	if err := c.checkAccountBlockCounter(); err != nil {
+		return nil, err
+	}
+	if err := c.checkLatestState(); err != nil {
+		return nil, err
+	}
+	return c.report, nil
+}
+
+func (c *checker) onProgress(stage string, count uint64) {
+	if c.progress != nil {
+		c.progress(stage, count)
+	}
+}
+
+func (c *checker) checkSnapshotChain() error {
+	key, _ := database.EncodeKey(database.DBKP_SNAPSHOTBLOCK)
+	iter := c.db.NewIterator(util.BytesPrefix(key), nil)
+	defer iter.Release()
+
+	var prevBlock *ledger.SnapshotBlock
+	for iter.Next() {
+		dbKey := iter.Key()
+		if len(dbKey) != 1+8+types.HashSize {
+			c.report.addProblem(&Problem{
+				Kind:    PROBLEM_SNAPSHOT_BLOCK,
+				Message: fmt.Sprintf("db key %x of snapshot block is invalid", dbKey),
+			})
+			continue
+		}
+		height := binary.BigEndian.Uint64(dbKey[1:9])
+		hash, _ := types.BytesToHash(dbKey[9:])
+
+		c.report.SnapshotBlockCount++
+		c.onProgress(STAGE_SNAPSHOT_BLOCKS, c.report.SnapshotBlockCount)
+
+		block := &ledger.SnapshotBlock{}
+		if err := block.Deserialize(iter.Value()); err != nil {
+			c.addSnapshotProblem(PROBLEM_SNAPSHOT_BLOCK, height, hash, "deserialize failed, error is %s", err.Error())
+			prevBlock = &ledger.SnapshotBlock{Height: height, Hash: hash}
+			continue
+		}
+		block.Hash = hash
+
+		content, err := c.chainDb.Sc.GetSnapshotContent(height)
+		if err != nil {
+			return err
+		}
+		block.SnapshotContent = content
+
+		if err := c.checkSnapshotBlock(prevBlock, block); err != nil {
+			return err
+		}
+		prevBlock = block
+	}
+
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+func (c *checker) addSnapshotProblem(kind string, height uint64, hash types.Hash, format string, args ...interface{}) {
+	c.report.addProblem(&Problem{
+		Kind:          kind,
+		Hash:          &hash,
+		Height:        height,
+		Message:       fmt.Sprintf(format, args...),
+		RecoverHeight: height,
+	})
+}
+
+func (c *checker) checkSnapshotBlock(prevBlock *ledger.SnapshotBlock, block *ledger.SnapshotBlock) error {
+	height := block.Height
+	if c.chain.IsGenesisSnapshotBlock(block) {
+		c.report.genesisHeight = height
+	}
+
+	if computedHash := block.ComputeHash(); computedHash != block.Hash {
+		c.addSnapshotProblem(PROBLEM_SNAPSHOT_BLOCK, height, block.Hash, "the computed hash is %s", computedHash)
+	}
+
+	switch {
+	case prevBlock == nil:
+		if height != 1 {
+			c.addSnapshotProblem(PROBLEM_SNAPSHOT_BLOCK, height, block.Hash, "the first snapshot block is not at height 1")
+		}
+	case height == prevBlock.Height:
+		c.addSnapshotProblem(PROBLEM_SNAPSHOT_BLOCK, height, block.Hash, "snapshot block %s is at the same height", prevBlock.Hash)
+	case height != prevBlock.Height+1:
+		// bootstrap-state skips the snapshot blocks between genesis and the bootstrap snapshot block
+		if c.report.BootstrapHeight == 0 && prevBlock.Height == c.report.genesisHeight {
+			c.report.BootstrapHeight = height
+		} else {
+			c.addSnapshotProblem(PROBLEM_SNAPSHOT_BLOCK, prevBlock.Height+1, block.Hash,
+				"snapshot blocks from %d to %d are missing", prevBlock.Height+1, height-1)
+		}
+	case block.PrevHash != prevBlock.Hash:
+		c.addSnapshotProblem(PROBLEM_SNAPSHOT_BLOCK, height, block.Hash,
+			"prev hash is %s, but the snapshot block at height %d is %s", block.PrevHash, prevBlock.Height, prevBlock.Hash)
+	}
+
+	indexHeight, err := c.chainDb.Sc.GetSnapshotBlockHeight(&block.Hash)
+	if err != nil {
+		return err
+	}
+	if indexHeight != height {
+		c.addSnapshotProblem(PROBLEM_SNAPSHOT_BLOCK, height, block.Hash, "height in the hash index is %d", indexHeight)
+	}
+
+	// the blocks in the content of the bootstrap snapshot block are not written
+	if height > c.report.genesisHeight && height != c.report.BootstrapHeight {
+		for addr, hashHeight := range block.SnapshotContent {
+			meta, err := c.chainDb.Ac.GetBlockMeta(&hashHeight.Hash)
+			if err != nil {
+				return err
+			}
+
+			var message string
+			if meta == nil {
+				message = fmt.Sprintf("account block %s of %s is missing", hashHeight.Hash, addr)
+			} else if meta.Height != hashHeight.Height {
+				message = fmt.Sprintf("account block %s of %s is at height %d, but %d in the content", hashHeight.Hash, addr, meta.Height, hashHeight.Height)
+			} else if meta.SnapshotHeight != height {
+				message = fmt.Sprintf("account block %s of %s is confirmed by snapshot block %d", hashHeight.Hash, addr, meta.SnapshotHeight)
+			} else {
+				continue
+			}
+			c.addSnapshotProblem(PROBLEM_SNAPSHOT_CONTENT, height, block.Hash, "%s", message)
+		}
+	}
+
+	if height >= c.retainMinHeight && block.StateHash != (types.Hash{}) {
+		ok, err := trie.ShallowCheck(c.db, &block.StateHash)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			c.addSnapshotProblem(PROBLEM_STATE_TRIE, height, block.Hash, "state trie %s is missing", block.StateHash)
+		}
+	}
+	return nil
+}
+
+// hasSnapshotBlock reports whether the snapshot block of height should be in the ledger.
+func (c *checker) hasSnapshotBlock(height uint64) bool {
+	if height <= 0 || height > c.report.LatestSnapshotHeight {
+		return false
+	}
+	return c.report.BootstrapHeight == 0 || height <= c.report.genesisHeight || height >= c.report.BootstrapHeight
+}
+
+// isBootstrapBlock reports whether the account block is written by bootstrap-state, the history before it is missing.
+func (c *checker) isBootstrapBlock(meta *ledger.AccountBlockMeta) bool {
+	return c.report.BootstrapHeight > 0 &&
+		meta.RefSnapshotHeight == c.report.BootstrapHeight &&
+		meta.SnapshotHeight == c.report.BootstrapHeight
+}
+
+func (c *checker) checkAccounts() error {
+	lastAccountId, err := c.chainDb.Account.GetLastAccountId()
+	if err != nil {
+		return err
+	}
+
+	for accountId := uint64(1); accountId <= lastAccountId; accountId++ {
+		addr, err := c.chainDb.Account.GetAddressById(accountId)
+		if err != nil {
+			if err != leveldb.ErrNotFound {
+				return err
+			}
+			c.report.addProblem(&Problem{
+				Kind:    PROBLEM_ACCOUNT,
+				Message: fmt.Sprintf("address of account %d is missing", accountId),
+			})
+			continue
+		}
+
+		account, err := c.chainDb.Account.GetAccountByAddress(addr)
+		if err != nil {
+			return err
+		}
+		if account == nil || account.AccountId != accountId {
+			message := fmt.Sprintf("account %d is missing", accountId)
+			if account != nil {
+				message = fmt.Sprintf("account id is %d, but %d in the account index", account.AccountId, accountId)
+			}
+			c.report.addProblem(&Problem{
+				Kind:    PROBLEM_ACCOUNT,
+				Address: addr,
+				Message: message,
+			})
+			continue
+		}
+
+		c.report.AccountCount++
+		c.onProgress(STAGE_ACCOUNTS, c.report.AccountCount)
+
+		if err := c.checkAccountChain(account); err != nil {
+			return err
+		}
+	}
+
+	// every account must be in the account index
+	key, _ := database.EncodeKey(database.DBKP_ACCOUNT)
+	iter := c.db.NewIterator(util.BytesPrefix(key), nil)
+	defer iter.Release()
+
+	accountCount := uint64(0)
+	for iter.Next() {
+		accountCount++
+	}
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return err
+	}
+	if accountCount != lastAccountId {
+		c.report.addProblem(&Problem{
+			Kind:    PROBLEM_ACCOUNT,
+			Message: fmt.Sprintf("%d accounts are stored, but the last account id is %d", accountCount, lastAccountId),
+		})
+	}
+	return nil
+}
+
+func (c *checker) checkAccountChain(account *ledger.Account) error {
+	key, _ := database.EncodeKey(database.DBKP_ACCOUNTBLOCK, account.AccountId)
+	iter := c.db.NewIterator(util.BytesPrefix(key), nil)
+	defer iter.Release()
+
+	var (
+		refHeights []uint64
+		pending    []pendingProblem
+
+		prevBlock *ledger.AccountBlock
+	)
+
+	for iter.Next() {
+		dbKey := iter.Key()
+		if len(dbKey) != 1+8+8+types.HashSize {
+			c.report.addProblem(&Problem{
+				Kind:    PROBLEM_ACCOUNT_BLOCK,
+				Address: &account.AccountAddress,
+				Message: fmt.Sprintf("db key %x of account block is invalid", dbKey),
+			})
+			continue
+		}
+		height := binary.BigEndian.Uint64(dbKey[9:17])
+		hash, _ := types.BytesToHash(dbKey[17:])
+		c.report.AccountBlockCount++
+
+		meta, err := c.chainDb.Ac.GetBlockMeta(&hash)
+		if err != nil {
+			return err
+		}
+		// recover stops at the block without meta
+		refHeight := uint64(0)
+		if meta != nil {
+			refHeight = meta.RefSnapshotHeight
+		}
+		refHeights = append(refHeights, refHeight)
+
+		block := &ledger.AccountBlock{}
+		var problems []*Problem
+		if err := block.DbDeserialize(iter.Value()); err != nil {
+			problems = append(problems, newBlockProblem(PROBLEM_ACCOUNT_BLOCK, account, height, hash, "deserialize failed, error is %s", err.Error()))
+			block = &ledger.AccountBlock{Height: height, Hash: hash}
+		} else {
+			block.AccountAddress = account.AccountAddress
+			block.Hash = hash
+			block.Meta = meta
+			if block.Height != height {
+				problems = append(problems, newBlockProblem(PROBLEM_ACCOUNT_BLOCK, account, height, hash, "height of the block is %d", block.Height))
+			}
+
+			if problems, err = c.checkAccountBlock(account, prevBlock, block, problems); err != nil {
+				return err
+			}
+		}
+
+		for _, problem := range problems {
+			c.report.addProblem(problem)
+			pending = append(pending, pendingProblem{problem: problem, index: len(refHeights) - 1})
+		}
+		prevBlock = block
+	}
+
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return err
+	}
+
+	// recover deletes the blocks from the tail whose ref snapshot height is not lower than the target height
+	minRefHeights := make([]uint64, len(refHeights))
+	for i := len(refHeights) - 1; i >= 0; i-- {
+		minRefHeights[i] = refHeights[i]
+		if i+1 < len(refHeights) && minRefHeights[i+1] < minRefHeights[i] {
+			minRefHeights[i] = minRefHeights[i+1]
+		}
+	}
+	for _, item := range pending {
+		item.problem.RecoverHeight = minRefHeights[item.index]
+	}
+	return nil
+}
+
+func newBlockProblem(kind string, account *ledger.Account, height uint64, hash types.Hash, format string, args ...interface{}) *Problem {
+	return &Problem{
+		Kind:    kind,
+		Address: &account.AccountAddress,
+		Hash:    &hash,
+		Height:  height,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+func (c *checker) checkAccountBlock(account *ledger.Account, prevBlock *ledger.AccountBlock, block *ledger.AccountBlock,
+	problems []*Problem) ([]*Problem, error) {
+	addProblem := func(kind string, format string, args ...interface{}) {
+		problems = append(problems, newBlockProblem(kind, account, block.Height, block.Hash, format, args...))
+	}
+
+	// the hash of a pruned block can't be computed
+	if meta := block.Meta; meta == nil || meta.SnapshotHeight <= 0 || meta.SnapshotHeight > c.report.PrunedHeight {
+		if computedHash := block.ComputeHash(); computedHash != block.Hash {
+			addProblem(PROBLEM_ACCOUNT_BLOCK, "the computed hash is %s", computedHash)
+		}
+	}
+
+	meta := block.Meta
+	if meta == nil {
+		addProblem(PROBLEM_ACCOUNT_BLOCK_META, "block meta is missing")
+		return problems, nil
+	}
+	if meta.AccountId != account.AccountId || meta.Height != block.Height {
+		addProblem(PROBLEM_ACCOUNT_BLOCK_META, "block meta is of account %d at height %d", meta.AccountId, meta.Height)
+	}
+
+	isBootstrap := c.isBootstrapBlock(meta)
+
+	// hash linkage, bootstrap-state only writes some blocks of an account
+	switch {
+	case prevBlock == nil:
+		if block.Height != 1 && !isBootstrap {
+			addProblem(PROBLEM_ACCOUNT_BLOCK, "the first block is not at height 1")
+		}
+	case block.Height == prevBlock.Height:
+		addProblem(PROBLEM_ACCOUNT_BLOCK, "block %s is at the same height", prevBlock.Hash)
+	case block.Height == prevBlock.Height+1:
+		if block.PrevHash != prevBlock.Hash {
+			addProblem(PROBLEM_ACCOUNT_BLOCK, "prev hash is %s, but the block at height %d is %s", block.PrevHash, prevBlock.Height, prevBlock.Hash)
+		}
+	case !isBootstrap:
+		addProblem(PROBLEM_ACCOUNT_BLOCK, "blocks from %d to %d are missing", prevBlock.Height+1, block.Height-1)
+	}
+
+	if !isBootstrap {
+		refHeight, err := c.chainDb.Sc.GetSnapshotBlockHeight(&block.SnapshotHash)
+		if err != nil {
+			return nil, err
+		}
+		if refHeight <= 0 {
+			addProblem(PROBLEM_ACCOUNT_BLOCK, "referred snapshot block %s is missing", block.SnapshotHash)
+		} else if meta.RefSnapshotHeight != refHeight {
+			addProblem(PROBLEM_ACCOUNT_BLOCK_META, "ref snapshot height is %d, but snapshot block %s is at %d", meta.RefSnapshotHeight, block.SnapshotHash, refHeight)
+		}
+	}
+
+	// the confirmed blocks are before the unconfirmed ones
+	confirmHeight := meta.SnapshotHeight
+	if confirmHeight > 0 {
+		if !c.hasSnapshotBlock(confirmHeight) {
+			addProblem(PROBLEM_ACCOUNT_BLOCK_META, "confirmed by snapshot block %d, which is missing", confirmHeight)
+		} else if confirmHeight < meta.RefSnapshotHeight {
+			addProblem(PROBLEM_ACCOUNT_BLOCK_META, "confirmed by snapshot block %d, lower than the ref snapshot height %d", confirmHeight, meta.RefSnapshotHeight)
+		}
+
+		if prevBlock != nil && prevBlock.Meta != nil {
+			if prevConfirmHeight := prevBlock.Meta.SnapshotHeight; prevConfirmHeight <= 0 {
+				addProblem(PROBLEM_ACCOUNT_BLOCK_META, "confirmed by snapshot block %d, but the previous block is unconfirmed", confirmHeight)
+			} else if confirmHeight < prevConfirmHeight {
+				addProblem(PROBLEM_ACCOUNT_BLOCK_META, "confirmed by snapshot block %d, but the previous block is confirmed by %d", confirmHeight, prevConfirmHeight)
+			}
+		}
+	}
+
+	if !isBootstrap {
+		switch {
+		case c.chain.IsGenesisAccountBlock(block):
+			// the genesis blocks are inserted before the on road listener, the genesis receive block has no send block
+		case block.IsReceiveBlock():
+			sendMeta, err := c.chainDb.Ac.GetBlockMeta(&block.FromBlockHash)
+			if err != nil {
+				return nil, err
+			}
+			if sendMeta == nil {
+				addProblem(PROBLEM_ACCOUNT_BLOCK, "send block %s is missing", block.FromBlockHash)
+			} else if !containsHeight(sendMeta.ReceiveBlockHeights, block.Height) {
+				addProblem(PROBLEM_ACCOUNT_BLOCK_META, "receive heights of send block %s don't contain the block", block.FromBlockHash)
+			}
+		default:
+			message, err := c.checkOnRoad(block)
+			if err != nil {
+				return nil, err
+			}
+			if message != "" {
+				addProblem(PROBLEM_ON_ROAD, "%s", message)
+			}
+		}
+
+		// the state tries of the blocks written by bootstrap-state are not saved except the latest ones
+		if (confirmHeight <= 0 || confirmHeight >= c.retainMinHeight) && block.StateHash != (types.Hash{}) {
+			ok, err := trie.ShallowCheck(c.db, &block.StateHash)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				addProblem(PROBLEM_STATE_TRIE, "state trie %s is missing", block.StateHash)
+			}
+		}
+	}
+	return problems, nil
+}
+
+// checkOnRoad checks that the send block is on road if and only if it is not received successfully.
+func (c *checker) checkOnRoad(block *ledger.AccountBlock) (string, error) {
+	received := false
+	if len(block.Meta.ReceiveBlockHeights) > 0 {
+		toAccount, err := c.chainDb.Account.GetAccountByAddress(&block.ToAddress)
+		if err != nil {
+			return "", err
+		}
+		if toAccount == nil {
+			return fmt.Sprintf("account %s which receives the block is missing", block.ToAddress), nil
+		}
+
+		for _, height := range block.Meta.ReceiveBlockHeights {
+			receiveBlock, err := c.chainDb.Ac.GetBlockByHeight(toAccount.AccountId, height)
+			if err != nil {
+				return "", err
+			}
+			if receiveBlock == nil || receiveBlock.FromBlockHash != block.Hash {
+				return fmt.Sprintf("block at height %d of %s doesn't receive the block", height, block.ToAddress), nil
+			}
+			if receiveBlock.BlockType == ledger.BlockTypeReceive {
+				received = true
+			}
+		}
+	}
+
+	onRoadMeta, err := c.chainDb.OnRoad.GetMeta(&block.ToAddress, &block.Hash)
+	if err != nil {
+		return "", err
+	}
+	if received && onRoadMeta != nil {
+		return "the block is received, but still on road", nil
+	}
+	if !received && onRoadMeta == nil {
+		// contracts drop the send blocks they can't receive
+		if block.BlockType == ledger.BlockTypeSendCreate {
+			return "", nil
+		}
+		accountType, err := c.chain.AccountType(&block.ToAddress)
+		if err != nil {
+			return "", err
+		}
+		if accountType != ledger.AccountTypeContract {
+			return "the block is not received, but not on road", nil
+		}
+	}
+	return "", nil
+}
+
+func containsHeight(heights []uint64, height uint64) bool {
+	for _, item := range heights {
+		if item == height {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *checker) checkOnRoadMeta() error {
+	key, _ := database.EncodeKey(database.DBKP_ONROADMETA)
+	iter := c.db.NewIterator(util.BytesPrefix(key), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		dbKey := iter.Key()
+		if len(dbKey) != 1+types.AddressSize+types.HashSize {
+			c.report.addProblem(&Problem{
+				Kind:    PROBLEM_ON_ROAD,
+				Message: fmt.Sprintf("db key %x of on road meta is invalid", dbKey),
+			})
+			continue
+		}
+		addr, _ := types.BytesToAddress(dbKey[1 : 1+types.AddressSize])
+		hash, _ := types.BytesToHash(dbKey[1+types.AddressSize:])
+
+		c.report.OnRoadCount++
+		c.onProgress(STAGE_ON_ROAD, c.report.OnRoadCount)
+
+		sendBlock, err := c.chainDb.Ac.GetBlock(&hash)
+		if err != nil {
+			return err
+		}
+
+		var message string
+		if sendBlock == nil {
+			message = "send block is missing"
+		} else if !sendBlock.IsSendBlock() {
+			message = "the block is not a send block"
+		} else if sendBlock.ToAddress != addr {
+			message = fmt.Sprintf("the block is sent to %s", sendBlock.ToAddress)
+		} else {
+			continue
+		}
+		c.report.addProblem(&Problem{
+			Kind:    PROBLEM_ON_ROAD,
+			Address: &addr,
+			Hash:    &hash,
+			Message: message,
+		})
+	}
+
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return err
+	}
+	return nil
+}
+
+// checkAccountBlockCounter checks the account block counter written by old versions, nothing writes it now.
+func (c *checker) checkAccountBlockCounter() error {
+	key, _ := database.EncodeKey(database.DBKP_ACCOUNTBLOCK_COUNTER)
+	iter := c.db.NewIterator(util.BytesPrefix(key), nil)
+	defer iter.Release()
+
+	for iter.Next() {
+		var message string
+		if value := iter.Value(); len(iter.Key()) != 1 || len(value) != 8 {
+			message = fmt.Sprintf("counter %x = %x is invalid", iter.Key(), value)
+		} else if counter := binary.BigEndian.Uint64(value); counter != c.report.AccountBlockCount {
+			message = fmt.Sprintf("counter is %d, but %d account blocks are stored", counter, c.report.AccountBlockCount)
+		} else {
+			continue
+		}
+		c.report.addProblem(&Problem{
+			Kind:    PROBLEM_ACCOUNT_BLOCK_COUNTER,
+			Message: message,
+		})
+	}
+
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package db_checker
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/chain_db"
+	"github.com/vitelabs/go-vite/chain_db/database"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/trie"
+)
+
+func init() {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}})
+}
+
+type testChain struct {
+	dir            string
+	chainDb        *chain_db.ChainDb
+	snapshotBlocks []*ledger.SnapshotBlock
+}
+
+func (c *testChain) ChainDb() *chain_db.ChainDb { return c.chainDb }
+func (c *testChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
+	return c.snapshotBlocks[len(c.snapshotBlocks)-1]
+}
+func (c *testChain) IsGenesisSnapshotBlock(block *ledger.SnapshotBlock) bool {
+	return block.Hash == c.snapshotBlocks[0].Hash || block.Hash == c.snapshotBlocks[1].Hash
+}
+func (c *testChain) IsGenesisAccountBlock(block *ledger.AccountBlock) bool { return false }
+func (c *testChain) AccountType(address *types.Address) (uint64, error) {
+	return ledger.AccountTypeGeneral, nil
+}
+
+// newTestChain writes 4 snapshot blocks, the third one is referred by a send block and its receive block,
+// both are confirmed by the latest one.
+func newTestChain(t *testing.T) (*testChain, *ledger.AccountBlock) {
+	dir, err := ioutil.TempDir("", "db_checker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &testChain{dir: dir, chainDb: chain_db.NewChainDb(dir)}
+	db := c.chainDb.Db()
+	batch := new(leveldb.Batch)
+	now := time.Unix(1546275661, 0)
+
+	var addrList []types.Address
+	for i := uint64(1); i <= 2; i++ {
+		addr, _, _ := types.CreateAddress()
+		addrList = append(addrList, addr)
+		c.chainDb.Account.WriteAccountIndex(batch, i, &addr)
+		c.chainDb.Account.WriteAccount(batch, &ledger.Account{AccountAddress: addr, AccountId: i})
+	}
+
+	accountTrie := trie.NewTrie(db, nil, nil)
+	accountTrie.SetValue([]byte("key"), []byte("value"))
+	stateTrie := trie.NewTrie(db, nil, nil)
+	stateTrie.SetValue(addrList[0].Bytes(), accountTrie.Hash().Bytes())
+	for _, item := range []*trie.Trie{accountTrie, stateTrie} {
+		if _, err := item.Save(batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	prevHash := types.Hash{}
+	for height := uint64(1); height <= 4; height++ {
+		block := &ledger.SnapshotBlock{
+			PrevHash:        prevHash,
+			Height:          height,
+			Timestamp:       &now,
+			SnapshotContent: ledger.SnapshotContent{},
+		}
+		if height == 4 {
+			block.StateHash = *stateTrie.Hash()
+		}
+		block.Hash = block.ComputeHash()
+		c.snapshotBlocks = append(c.snapshotBlocks, block)
+		prevHash = block.Hash
+	}
+
+	sendBlock := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		Height:         1,
+		AccountAddress: addrList[0],
+		ToAddress:      addrList[1],
+		Amount:         big.NewInt(1),
+		TokenId:        ledger.ViteTokenId,
+		Timestamp:      &now,
+	}
+	receiveBlock := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeReceive,
+		Height:         1,
+		AccountAddress: addrList[1],
+		Timestamp:      &now,
+	}
+	for i, block := range []*ledger.AccountBlock{sendBlock, receiveBlock} {
+		block.SnapshotHash = c.snapshotBlocks[2].Hash
+		if i == 1 {
+			block.FromBlockHash = sendBlock.Hash
+		}
+		block.Hash = block.ComputeHash()
+
+		meta := &ledger.AccountBlockMeta{AccountId: uint64(i + 1), Height: 1, RefSnapshotHeight: 3}
+		if i == 0 {
+			meta.ReceiveBlockHeights = []uint64{1}
+		}
+		c.chainDb.Ac.WriteBlock(batch, uint64(i+1), block)
+		c.chainDb.Ac.WriteBlockMeta(batch, &block.Hash, meta)
+		c.chainDb.Ac.WriteBeSnapshot(batch, &block.Hash, 4)
+		c.snapshotBlocks[3].SnapshotContent[block.AccountAddress] = &ledger.HashHeight{Height: 1, Hash: block.Hash}
+	}
+
+	// the content of the latest snapshot block is changed
+	c.snapshotBlocks[3].Hash = c.snapshotBlocks[3].ComputeHash()
+	for _, block := range c.snapshotBlocks {
+		c.chainDb.Sc.WriteSnapshotBlock(batch, block)
+		c.chainDb.Sc.WriteSnapshotContent(batch, block.Height, block.SnapshotContent)
+		c.chainDb.Sc.WriteSnapshotHash(batch, &block.Hash, block.Height)
+	}
+
+	if err := c.chainDb.Commit(batch); err != nil {
+		t.Fatal(err)
+	}
+	return c, sendBlock
+}
+
+func TestCheck(t *testing.T) {
+	c, sendBlock := newTestChain(t)
+	defer os.RemoveAll(c.dir)
+
+	report, err := Check(c, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Problems) > 0 {
+		t.Fatalf("the ledger is consistent, but %d problems are found, the first one is %s", len(report.Problems), report.Problems[0])
+	}
+	if report.SnapshotBlockCount != 4 || report.AccountCount != 2 || report.AccountBlockCount != 2 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if _, ok := report.SafeHeight(); ok {
+		t.Fatal("no safe height is needed")
+	}
+
+	// the received send block is still on road and the snapshot trie is missing
+	onRoadKey, _ := database.EncodeKey(database.DBKP_ONROADMETA, sendBlock.ToAddress.Bytes(), sendBlock.Hash.Bytes())
+	trieNodeKey, _ := database.EncodeKey(database.DBKP_TRIE_NODE, c.GetLatestSnapshotBlock().StateHash.Bytes())
+	batch := new(leveldb.Batch)
+	batch.Put(onRoadKey, []byte{0})
+	batch.Delete(trieNodeKey)
+	c.chainDb.Commit(batch)
+
+	if report, err = Check(c, 1, nil); err != nil {
+		t.Fatal(err)
+	}
+	kinds := make(map[string]int)
+	for _, problem := range report.Problems {
+		kinds[problem.Kind]++
+	}
+	if kinds[PROBLEM_ON_ROAD] != 1 || kinds[PROBLEM_STATE_TRIE] != 2 || len(report.Problems) != 3 {
+		t.Fatalf("unexpected problems %v", report.Problems)
+	}
+	if safeHeight, ok := report.SafeHeight(); !ok || safeHeight != 3 {
+		t.Fatalf("safe height is %d, %v", safeHeight, ok)
+	}
+}
@@ -0,0 +1,82 @@
+package db_checker
+
+import (
+	"fmt"
+
+	"github.com/vitelabs/go-vite/common/types"
+)
+
+const (
+	PROBLEM_SNAPSHOT_BLOCK        = "snapshotBlock"
+	PROBLEM_SNAPSHOT_CONTENT      = "snapshotContent"
+	PROBLEM_STATE_TRIE            = "stateTrie"
+	PROBLEM_ACCOUNT               = "account"
+	PROBLEM_ACCOUNT_BLOCK         = "accountBlock"
+	PROBLEM_ACCOUNT_BLOCK_META    = "accountBlockMeta"
+	PROBLEM_ON_ROAD               = "onRoad"
+	PROBLEM_ACCOUNT_BLOCK_COUNTER = "accountBlockCounter"
+)
+
+// Problem is an inconsistency found in the ledger. Height is the snapshot block height for the problems of
+// snapshot blocks and the account block height for the problems of account blocks.
+type Problem struct {
+	Kind    string         `json:"kind"`
+	Address *types.Address `json:"address,omitempty"`
+	Hash    *types.Hash    `json:"hash,omitempty"`
+	Height  uint64         `json:"height"`
+	Message string         `json:"message"`
+
+	// The highest height which `recover --del` deletes the problem by, 0 means recover can't fix it
+	RecoverHeight uint64 `json:"recoverHeight"`
+}
+
+func (p *Problem) String() string {
+	str := fmt.Sprintf("[%s] height %d", p.Kind, p.Height)
+	if p.Address != nil {
+		str += fmt.Sprintf(", address %s", p.Address)
+	}
+	if p.Hash != nil {
+		str += fmt.Sprintf(", hash %s", p.Hash)
+	}
+	return str + ": " + p.Message
+}
+
+type Report struct {
+	LatestSnapshotHeight uint64 `json:"latestSnapshotHeight"`
+
+	// Height of the snapshot block written by bootstrap-state, 0 means the ledger is synced from genesis
+	BootstrapHeight uint64 `json:"bootstrapHeight"`
+
+	// The bodies of the account blocks confirmed by the snapshot blocks up to PrunedHeight may have been pruned
+	PrunedHeight uint64 `json:"prunedHeight"`
+
+	SnapshotBlockCount uint64 `json:"snapshotBlockCount"`
+	AccountCount       uint64 `json:"accountCount"`
+	AccountBlockCount  uint64 `json:"accountBlockCount"`
+	OnRoadCount        uint64 `json:"onRoadCount"`
+
+	Problems []*Problem `json:"problems"`
+
+	genesisHeight uint64
+}
+
+func (report *Report) addProblem(problem *Problem) *Problem {
+	report.Problems = append(report.Problems, problem)
+	return problem
+}
+
+// SafeHeight returns the height to recover to, it is false if there is no problem or some problems can't be
+// fixed by recover.
+func (report *Report) SafeHeight() (uint64, bool) {
+	safeHeight := uint64(0)
+	for _, problem := range report.Problems {
+		// recover can't delete the genesis and the pruned snapshot blocks
+		if problem.RecoverHeight <= report.genesisHeight || problem.RecoverHeight <= report.PrunedHeight {
+			return 0, false
+		}
+		if safeHeight == 0 || problem.RecoverHeight < safeHeight {
+			safeHeight = problem.RecoverHeight
+		}
+	}
+	return safeHeight, safeHeight > 0
+}
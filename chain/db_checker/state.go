@@ -0,0 +1,122 @@
+package db_checker
+
+import (
+	"fmt"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/chain_db/database"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/trie"
+)
+
+// checkLatestState walks the snapshot trie of the latest snapshot block and the state trie of every account in it,
+// the other state roots are only checked shallowly.
+func (c *checker) checkLatestState() error {
+	latestSnapshotBlock := c.chain.GetLatestSnapshotBlock()
+	if latestSnapshotBlock.StateHash == (types.Hash{}) {
+		return nil
+	}
+
+	var accountStateHashList []types.Hash
+	if err := c.checkStateTrie(latestSnapshotBlock.Height, latestSnapshotBlock.StateHash, func(value []byte) {
+		if stateHash, err := types.BytesToHash(value); err == nil {
+			accountStateHashList = append(accountStateHashList, stateHash)
+		}
+	}); err != nil {
+		return err
+	}
+
+	for _, stateHash := range accountStateHashList {
+		if err := c.checkStateTrie(latestSnapshotBlock.Height, stateHash, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStateTrie checks that every node and ref value of the trie is in the db, sub tries which have been checked are skipped.
+func (c *checker) checkStateTrie(snapshotHeight uint64, stateHash types.Hash, onValue func(value []byte)) error {
+	var missingCount uint64
+	var firstMissing string
+
+	pending := []types.Hash{stateHash}
+	for len(pending) > 0 {
+		hash := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+
+		if _, ok := c.visitedNodes[hash]; ok {
+			continue
+		}
+		c.visitedNodes[hash] = struct{}{}
+		c.onProgress(STAGE_STATE_TRIE, uint64(len(c.visitedNodes)))
+
+		node, err := c.getTrieNode(&hash)
+		if err != nil {
+			return err
+		}
+		if node == nil {
+			missingCount++
+			if firstMissing == "" {
+				firstMissing = fmt.Sprintf("trie node %s", hash)
+			}
+			continue
+		}
+
+		switch node.NodeType() {
+		case trie.TRIE_FULL_NODE:
+			for _, child := range node.Children() {
+				pending = append(pending, *child.Hash())
+			}
+			if node.Child() != nil {
+				pending = append(pending, *node.Child().Hash())
+			}
+		case trie.TRIE_SHORT_NODE:
+			pending = append(pending, *node.Child().Hash())
+		case trie.TRIE_VALUE_NODE:
+			if onValue != nil {
+				onValue(node.Value())
+			}
+		case trie.TRIE_HASH_NODE:
+			dbKey, _ := database.EncodeKey(database.DBKP_TRIE_REF_VALUE, node.Value())
+			ok, err := c.db.Has(dbKey, nil)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				missingCount++
+				if firstMissing == "" {
+					firstMissing = fmt.Sprintf("ref value %x", node.Value())
+				}
+			}
+		}
+	}
+
+	if missingCount > 0 {
+		c.report.addProblem(&Problem{
+			Kind:          PROBLEM_STATE_TRIE,
+			Hash:          &stateHash,
+			Height:        snapshotHeight,
+			Message:       fmt.Sprintf("state trie is incomplete, %d nodes are missing, the first one is %s", missingCount, firstMissing),
+			RecoverHeight: snapshotHeight,
+		})
+	}
+	return nil
+}
+
+func (c *checker) getTrieNode(hash *types.Hash) (*trie.TrieNode, error) {
+	dbKey, _ := database.EncodeKey(database.DBKP_TRIE_NODE, hash.Bytes())
+	data, err := c.db.Get(dbKey, nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	node := &trie.TrieNode{}
+	if err := node.DbDeserialize(data); err != nil {
+		// a broken node is as bad as a missing one
+		return nil, nil
+	}
+	return node, nil
+}
@@ -0,0 +1,17 @@
+package chain
+
+import "testing"
+
+// BatchSend, Escrow and MultiSig are nil in the mainnet fork points
+func TestChain_StartWithMainNetForkPoints(t *testing.T) {
+	chainInstance, clean := newTempChain(t)
+	defer clean()
+
+	ok, forkPoint, err := chainInstance.(*chain).checkForkPoints()
+	if err != nil || !ok || forkPoint != nil {
+		t.Fatalf("check fork points failed, ok %v, fork point %v, error %v", ok, forkPoint, err)
+	}
+	if chainInstance.GetLatestSnapshotBlock() == nil {
+		t.Fatal("latest snapshot block is nil")
+	}
+}
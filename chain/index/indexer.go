@@ -0,0 +1,446 @@
+package chain_index
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	errors2 "github.com/syndtr/goleveldb/leveldb/errors"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/vitelabs/go-vite/chain_db/database"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+)
+
+const (
+	DBKP_INDEX_ENTRY = byte(1)
+
+	DBKP_INDEXED_HASH = byte(2)
+
+	DBKP_INDEXER_CONSUME_ID = byte(3)
+)
+
+// entry key: DBKP_INDEX_ENTRY + index id + index key + event id + position in event + block hash
+const entrySuffixLen = 8 + 8 + types.HashSize
+
+// SecondaryIndex maps an account block to the index keys it should be found by. All keys of
+// one index must have the same length, entries sharing a key are listed in event order.
+type SecondaryIndex interface {
+	Id() byte
+	Name() string
+	Keys(block *ledger.AccountBlock) ([][]byte, error)
+}
+
+// Indexer builds secondary indexes by consuming the block event log. Every written entry is
+// recorded under the block hash, so the delete events can roll the entries back without the block.
+type Indexer struct {
+	db *leveldb.DB
+
+	dataDirName      string
+	log              log15.Logger
+	chainInstance    Chain
+	EventNumPerBatch uint64
+
+	indexes map[byte]SecondaryIndex
+
+	status     int
+	statusLock sync.Mutex
+	ticker     *time.Ticker
+	terminal   chan struct{}
+	wg         sync.WaitGroup
+
+	buildLock sync.Mutex
+}
+
+func NewIndexer(cfg *config.Config, chainInstance Chain, indexes ...SecondaryIndex) (*Indexer, error) {
+	indexer := &Indexer{
+		log:         log15.New("module", "indexer"),
+		dataDirName: filepath.Join(cfg.DataDir, "ledger_secondary_index"),
+
+		chainInstance:    chainInstance,
+		EventNumPerBatch: 1000,
+
+		indexes: make(map[byte]SecondaryIndex, len(indexes)),
+
+		status: STOP,
+	}
+
+	for _, index := range indexes {
+		if _, ok := indexer.indexes[index.Id()]; ok {
+			return nil, errors.New(fmt.Sprintf("id of index %s is duplicated", index.Name()))
+		}
+		indexer.indexes[index.Id()] = index
+	}
+
+	if err := indexer.initDb(); err != nil {
+		err := errors.New("initDb failed, error is " + err.Error())
+		indexer.log.Error(err.Error(), "method", "NewIndexer")
+
+		return nil, err
+	}
+
+	return indexer, nil
+}
+
+func (indexer *Indexer) Start() {
+	indexer.statusLock.Lock()
+	defer indexer.statusLock.Unlock()
+	if indexer.status == START {
+		return
+	}
+
+	if err := indexer.checkAndInitData(); err != nil {
+		indexer.log.Crit("Indexer start failed, error is "+err.Error(), "method", "Start")
+	}
+	if err := indexer.build(); err != nil {
+		indexer.log.Error("indexer build failed, error is "+err.Error(), "method", "Start")
+	}
+
+	indexer.ticker = time.NewTicker(time.Second * 3)
+	indexer.terminal = make(chan struct{})
+	indexer.wg.Add(1)
+	go func() {
+		defer indexer.wg.Done()
+		for {
+			select {
+			case <-indexer.ticker.C:
+				if err := indexer.build(); err != nil {
+					indexer.log.Error("indexer build failed, error is "+err.Error(), "method", "Start")
+				}
+			case <-indexer.terminal:
+				return
+			}
+		}
+	}()
+
+	indexer.status = START
+}
+
+func (indexer *Indexer) Stop() {
+	indexer.statusLock.Lock()
+	defer indexer.statusLock.Unlock()
+
+	if indexer.status == STOP {
+		return
+	}
+
+	indexer.ticker.Stop()
+	close(indexer.terminal)
+	indexer.wg.Wait()
+	indexer.status = STOP
+}
+
+func (indexer *Indexer) initDb() error {
+	db, err := database.NewLevelDb(indexer.dataDirName)
+	if err != nil {
+		switch err.(type) {
+		case *errors2.ErrCorrupted:
+			// clear
+			return indexer.clearAndInitDb()
+		default:
+			return err
+		}
+	}
+
+	indexer.db = db
+	return nil
+}
+
+func (indexer *Indexer) checkAndInitData() error {
+	latestBlockEventId, err := indexer.chainInstance.GetLatestBlockEventId()
+	if err != nil {
+		return err
+	}
+
+	consumeId, err := indexer.getConsumeId()
+	if err != nil {
+		return err
+	}
+
+	// the event log is behind the index, the ledger has been rebuilt
+	if consumeId > latestBlockEventId {
+		return indexer.clearAndInitDb()
+	}
+	return nil
+}
+
+func (indexer *Indexer) clearAndInitDb() error {
+	if indexer.db != nil {
+		if closeErr := indexer.db.Close(); closeErr != nil {
+			return errors.New("Close db failed, error is " + closeErr.Error())
+		}
+	}
+
+	if err := os.RemoveAll(indexer.dataDirName); err != nil && err != os.ErrNotExist {
+		return errors.New("Remove " + indexer.dataDirName + " failed, error is " + err.Error())
+	}
+
+	indexer.db = nil
+	return indexer.initDb()
+}
+
+func (indexer *Indexer) saveConsumeId(batch *leveldb.Batch, eventId uint64) {
+	key, _ := database.EncodeKey(DBKP_INDEXER_CONSUME_ID)
+
+	eventIdBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(eventIdBytes, eventId)
+
+	batch.Put(key, eventIdBytes)
+}
+
+// getConsumeId returns the id of the latest consumed event, 0 means nothing consumed.
+func (indexer *Indexer) getConsumeId() (uint64, error) {
+	key, _ := database.EncodeKey(DBKP_INDEXER_CONSUME_ID)
+	value, err := indexer.db.Get(key, nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	if len(value) <= 0 {
+		return 0, nil
+	}
+
+	return binary.BigEndian.Uint64(value), nil
+}
+
+func (indexer *Indexer) build() error {
+	indexer.buildLock.Lock()
+	defer indexer.buildLock.Unlock()
+
+	consumeId, err := indexer.getConsumeId()
+	if err != nil {
+		return err
+	}
+
+	latestBeId, err := indexer.chainInstance.GetLatestBlockEventId()
+	if err != nil {
+		return err
+	}
+
+	batch := new(leveldb.Batch)
+	// entries of the batch, deleting the blocks of the same batch must see them
+	unsavedEntries := make(map[types.Hash][][]byte)
+
+	eventNum := uint64(0)
+	for eventId := consumeId + 1; eventId <= latestBeId; eventId++ {
+		eventType, blockHashList, err := indexer.chainInstance.GetEvent(eventId)
+		if err != nil {
+			return err
+		}
+
+		switch eventType {
+		// AddAccountBlocksEvent = byte(1)
+		case byte(1):
+			for position, blockHash := range blockHashList {
+				block, err := indexer.chainInstance.GetAccountBlockByHash(&blockHash)
+				if err != nil {
+					return err
+				}
+
+				// has been deleted, the delete event comes later
+				if block == nil {
+					continue
+				}
+
+				entryKeys, err := indexer.addBlock(batch, eventId, uint64(position), block)
+				if err != nil {
+					return err
+				}
+				unsavedEntries[block.Hash] = entryKeys
+			}
+
+		// DeleteAccountBlocksEvent = byte(2)
+		case byte(2):
+			for _, blockHash := range blockHashList {
+				entryKeys, ok := unsavedEntries[blockHash]
+				if ok {
+					delete(unsavedEntries, blockHash)
+				} else {
+					var err error
+					if entryKeys, err = indexer.getEntryKeys(blockHash); err != nil {
+						return err
+					}
+				}
+
+				indexer.deleteBlock(batch, blockHash, entryKeys)
+			}
+		}
+
+		eventNum++
+		if eventId >= latestBeId || eventNum >= indexer.EventNumPerBatch {
+			indexer.saveConsumeId(batch, eventId)
+			if err := indexer.db.Write(batch, nil); err != nil {
+				return err
+			}
+
+			batch = new(leveldb.Batch)
+			unsavedEntries = make(map[types.Hash][][]byte)
+			eventNum = 0
+		}
+	}
+
+	return nil
+}
+
+func (indexer *Indexer) addBlock(batch *leveldb.Batch, eventId uint64, position uint64, block *ledger.AccountBlock) ([][]byte, error) {
+	var entryKeys [][]byte
+	for id, index := range indexer.indexes {
+		keys, err := index.Keys(block)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Keys of index %s failed, error is %s", index.Name(), err.Error()))
+		}
+
+		for _, key := range keys {
+			entryKey, _ := database.EncodeKey(DBKP_INDEX_ENTRY, []byte{id}, key, eventId, position, block.Hash.Bytes())
+			batch.Put(entryKey, nil)
+			entryKeys = append(entryKeys, entryKey)
+		}
+	}
+
+	if len(entryKeys) > 0 {
+		hashKey, _ := database.EncodeKey(DBKP_INDEXED_HASH, block.Hash.Bytes())
+		batch.Put(hashKey, encodeEntryKeys(entryKeys))
+	}
+	return entryKeys, nil
+}
+
+func (indexer *Indexer) deleteBlock(batch *leveldb.Batch, blockHash types.Hash, entryKeys [][]byte) {
+	for _, entryKey := range entryKeys {
+		batch.Delete(entryKey)
+	}
+
+	hashKey, _ := database.EncodeKey(DBKP_INDEXED_HASH, blockHash.Bytes())
+	batch.Delete(hashKey)
+}
+
+func (indexer *Indexer) getEntryKeys(blockHash types.Hash) ([][]byte, error) {
+	hashKey, _ := database.EncodeKey(DBKP_INDEXED_HASH, blockHash.Bytes())
+	value, err := indexer.db.Get(hashKey, nil)
+	if err != nil {
+		if err == leveldb.ErrNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return decodeEntryKeys(value)
+}
+
+func (indexer *Indexer) getIndex(name string) (SecondaryIndex, error) {
+	for _, index := range indexer.indexes {
+		if index.Name() == name {
+			return index, nil
+		}
+	}
+	return nil, errors.New(fmt.Sprintf("index %s is not opened", name))
+}
+
+// GetBlockHashList returns the block hashes indexed by key, from the newest to the oldest. If originBlockHash
+// is not nil, the list starts from it.
+func (indexer *Indexer) GetBlockHashList(indexName string, key []byte, originBlockHash *types.Hash, count uint64) ([]types.Hash, error) {
+	index, err := indexer.getIndex(indexName)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, _ := database.EncodeKey(DBKP_INDEX_ENTRY, []byte{index.Id()}, key)
+
+	iter := indexer.db.NewIterator(util.BytesPrefix(prefix), nil)
+	defer iter.Release()
+
+	var ok bool
+	if originBlockHash == nil {
+		ok = iter.Last()
+	} else {
+		entryKeys, err := indexer.getEntryKeys(*originBlockHash)
+		if err != nil {
+			return nil, err
+		}
+
+		var originKey []byte
+		for _, entryKey := range entryKeys {
+			if len(entryKey) == len(prefix)+entrySuffixLen && string(entryKey[:len(prefix)]) == string(prefix) {
+				originKey = entryKey
+				break
+			}
+		}
+		if originKey == nil {
+			return nil, errors.New(fmt.Sprintf("block %s is not in the index", originBlockHash))
+		}
+		ok = iter.Seek(originKey)
+	}
+
+	var hashList []types.Hash
+	for ; ok && uint64(len(hashList)) < count; ok = iter.Prev() {
+		hash, err := types.BytesToHash(iter.Key()[len(iter.Key())-types.HashSize:])
+		if err != nil {
+			return nil, err
+		}
+		hashList = append(hashList, hash)
+	}
+
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return nil, err
+	}
+	return hashList, nil
+}
+
+// GetBlockHashListByRange returns at most count block hashes whose keys are in [startKey, endKey), in key order.
+func (indexer *Indexer) GetBlockHashListByRange(indexName string, startKey []byte, endKey []byte, count uint64) ([]types.Hash, error) {
+	index, err := indexer.getIndex(indexName)
+	if err != nil {
+		return nil, err
+	}
+
+	start, _ := database.EncodeKey(DBKP_INDEX_ENTRY, []byte{index.Id()}, startKey)
+	limit, _ := database.EncodeKey(DBKP_INDEX_ENTRY, []byte{index.Id()}, endKey)
+
+	iter := indexer.db.NewIterator(&util.Range{Start: start, Limit: limit}, nil)
+	defer iter.Release()
+
+	var hashList []types.Hash
+	for uint64(len(hashList)) < count && iter.Next() {
+		hash, err := types.BytesToHash(iter.Key()[len(iter.Key())-types.HashSize:])
+		if err != nil {
+			return nil, err
+		}
+		hashList = append(hashList, hash)
+	}
+
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return nil, err
+	}
+	return hashList, nil
+}
+
+func encodeEntryKeys(entryKeys [][]byte) []byte {
+	var buf []byte
+	for _, entryKey := range entryKeys {
+		buf = append(buf, byte(len(entryKey)))
+		buf = append(buf, entryKey...)
+	}
+	return buf
+}
+
+func decodeEntryKeys(buf []byte) ([][]byte, error) {
+	var entryKeys [][]byte
+	for len(buf) > 0 {
+		keyLen := int(buf[0])
+		if len(buf) < keyLen+1 {
+			return nil, errors.New("entry keys are broken")
+		}
+
+		entryKeys = append(entryKeys, buf[1:keyLen+1])
+		buf = buf[keyLen+1:]
+	}
+	return entryKeys, nil
+}
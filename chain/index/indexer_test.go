@@ -0,0 +1,190 @@
+package chain_index
+
+import (
+	"io/ioutil"
+	"math"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/chain_db"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+type testEvent struct {
+	eventType byte
+	hashList  []types.Hash
+}
+
+type testChain struct {
+	events    []testEvent
+	blocks    map[types.Hash]*ledger.AccountBlock
+	contracts map[types.Address]bool
+}
+
+func (c *testChain) GetLatestBlockEventId() (uint64, error) {
+	return uint64(len(c.events)), nil
+}
+
+func (c *testChain) GetEvent(eventId uint64) (byte, []types.Hash, error) {
+	event := c.events[eventId-1]
+	return event.eventType, event.hashList, nil
+}
+
+func (c *testChain) GetAccountBlockByHash(blockHash *types.Hash) (*ledger.AccountBlock, error) {
+	return c.blocks[*blockHash], nil
+}
+
+func (c *testChain) GetAccount(address *types.Address) (*ledger.Account, error) { return nil, nil }
+func (c *testChain) IsAccountBlockExisted(hash types.Hash) (bool, error) {
+	_, ok := c.blocks[hash]
+	return ok, nil
+}
+func (c *testChain) ChainDb() *chain_db.ChainDb                            { return nil }
+func (c *testChain) IsGenesisAccountBlock(block *ledger.AccountBlock) bool { return false }
+func (c *testChain) AccountType(address *types.Address) (uint64, error) {
+	if c.contracts[*address] {
+		return ledger.AccountTypeContract, nil
+	}
+	return ledger.AccountTypeGeneral, nil
+}
+
+func (c *testChain) insert(blocks ...*ledger.AccountBlock) {
+	var hashList []types.Hash
+	for _, block := range blocks {
+		c.blocks[block.Hash] = block
+		hashList = append(hashList, block.Hash)
+	}
+	c.events = append(c.events, testEvent{eventType: byte(1), hashList: hashList})
+}
+
+func (c *testChain) delete(blocks ...*ledger.AccountBlock) {
+	var hashList []types.Hash
+	for _, block := range blocks {
+		delete(c.blocks, block.Hash)
+		hashList = append(hashList, block.Hash)
+	}
+	c.events = append(c.events, testEvent{eventType: byte(2), hashList: hashList})
+}
+
+func newTestBlock(blockType byte, from types.Address, to types.Address, data []byte, timestamp int64) *ledger.AccountBlock {
+	t := time.Unix(timestamp, 0)
+	block := &ledger.AccountBlock{
+		BlockType:      blockType,
+		AccountAddress: from,
+		ToAddress:      to,
+		Data:           data,
+		Timestamp:      &t,
+	}
+	block.Hash = types.DataHash(append(append(from.Bytes(), to.Bytes()...), append(data, byte(timestamp))...))
+	return block
+}
+
+// newTestIndexer opens an indexer in a temp dir, the dir is removed by the returned func
+func newTestIndexer(t *testing.T, chainInstance *testChain) (*Indexer, func()) {
+	dataDir, err := ioutil.TempDir("", "indexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	indexer, err := NewIndexer(&config.Config{DataDir: dataDir}, chainInstance, NewDefaultSecondaryIndexes(chainInstance)...)
+	if err != nil {
+		os.RemoveAll(dataDir)
+		t.Fatal(err)
+	}
+	return indexer, func() {
+		if indexer.db != nil {
+			indexer.db.Close()
+		}
+		os.RemoveAll(dataDir)
+	}
+}
+
+func checkHashList(t *testing.T, hashList []types.Hash, blocks ...*ledger.AccountBlock) {
+	if len(hashList) != len(blocks) {
+		t.Fatalf("hash list has %d items, expected %d", len(hashList), len(blocks))
+	}
+	for i, block := range blocks {
+		if hashList[i] != block.Hash {
+			t.Fatalf("hash %d is %s, expected %s", i, hashList[i], block.Hash)
+		}
+	}
+}
+
+func TestIndexer(t *testing.T) {
+	addr1, _, _ := types.CreateAddress()
+	addr2, _, _ := types.CreateAddress()
+	contract, _, _ := types.CreateAddress()
+
+	chainInstance := &testChain{
+		blocks:    make(map[types.Hash]*ledger.AccountBlock),
+		contracts: map[types.Address]bool{contract: true},
+	}
+	indexer, clean := newTestIndexer(t, chainInstance)
+	defer clean()
+
+	send1 := newTestBlock(ledger.BlockTypeSendCall, addr1, addr2, nil, 100)
+	send2 := newTestBlock(ledger.BlockTypeSendCall, addr1, addr2, []byte{1}, 200)
+	call1 := newTestBlock(ledger.BlockTypeSendCall, addr1, contract, []byte{1, 2, 3, 4, 5}, 300)
+	call2 := newTestBlock(ledger.BlockTypeSendCall, addr2, contract, []byte{1, 2, 3, 4, 6}, 400)
+	receive := newTestBlock(ledger.BlockTypeReceive, addr2, types.Address{}, send1.Hash.Bytes(), 500)
+	receive.FromBlockHash = send1.Hash
+
+	chainInstance.insert(send1, send2)
+	chainInstance.insert(call1)
+	if err := indexer.build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// rolled back before being indexed
+	chainInstance.insert(call2)
+	chainInstance.insert(receive)
+	chainInstance.delete(call2)
+	if err := indexer.build(); err != nil {
+		t.Fatal(err)
+	}
+
+	hashList, _ := indexer.GetBlockHashList(INDEX_SEND_FROM_TO, append(addr1.Bytes(), addr2.Bytes()...), nil, 10)
+	checkHashList(t, hashList, send2, send1)
+
+	hashList, _ = indexer.GetBlockHashList(INDEX_SEND_FROM_TO, append(addr1.Bytes(), addr2.Bytes()...), &send1.Hash, 10)
+	checkHashList(t, hashList, send1)
+
+	hashList, _ = indexer.GetBlockHashList(INDEX_FROM_BLOCK_HASH, send1.Hash.Bytes(), nil, 10)
+	checkHashList(t, hashList, receive)
+
+	hashList, _ = indexer.GetBlockHashList(INDEX_METHOD_SELECTOR, append(contract.Bytes(), 1, 2, 3, 4), nil, 10)
+	checkHashList(t, hashList, call1)
+
+	hashList, _ = indexer.GetBlockHashListByRange(INDEX_BLOCK_TIMESTAMP, TimestampKey(200), TimestampKey(501), 10)
+	checkHashList(t, hashList, send2, call1, receive)
+
+	hashList, _ = indexer.GetBlockHashListByRange(INDEX_BLOCK_TIMESTAMP, TimestampKey(200), TimestampEndKey(500), 10)
+	checkHashList(t, hashList, send2, call1, receive)
+
+	// math.MaxInt64 means no upper bound
+	hashList, _ = indexer.GetBlockHashListByRange(INDEX_BLOCK_TIMESTAMP, TimestampKey(200), TimestampEndKey(math.MaxInt64), 10)
+	checkHashList(t, hashList, send2, call1, receive)
+
+	// rollback
+	chainInstance.delete(receive, call1)
+	if err := indexer.build(); err != nil {
+		t.Fatal(err)
+	}
+
+	hashList, _ = indexer.GetBlockHashList(INDEX_FROM_BLOCK_HASH, send1.Hash.Bytes(), nil, 10)
+	checkHashList(t, hashList)
+
+	hashList, _ = indexer.GetBlockHashList(INDEX_METHOD_SELECTOR, append(contract.Bytes(), 1, 2, 3, 4), nil, 10)
+	checkHashList(t, hashList)
+
+	hashList, _ = indexer.GetBlockHashListByRange(INDEX_BLOCK_TIMESTAMP, TimestampKey(0), TimestampKey(1000), 10)
+	checkHashList(t, hashList, send1, send2)
+
+	consumeId, _ := indexer.getConsumeId()
+	if consumeId != uint64(len(chainInstance.events)) {
+		t.Fatalf("consume id is %d, expected %d", consumeId, len(chainInstance.events))
+	}
+}
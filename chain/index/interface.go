@@ -16,4 +16,5 @@ type Chain interface {
 	IsAccountBlockExisted(hash types.Hash) (bool, error)
 	ChainDb() *chain_db.ChainDb
 	IsGenesisAccountBlock(block *ledger.AccountBlock) bool
+	AccountType(address *types.Address) (uint64, error)
 }
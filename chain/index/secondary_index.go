@@ -0,0 +1,106 @@
+package chain_index
+
+import (
+	"encoding/binary"
+
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+const (
+	INDEX_SEND_FROM_TO     = "sendFromTo"
+	INDEX_FROM_BLOCK_HASH  = "fromBlockHash"
+	INDEX_METHOD_SELECTOR  = "methodSelector"
+	INDEX_BLOCK_TIMESTAMP  = "timestamp"
+	METHOD_SELECTOR_LENGTH = 4
+)
+
+// NewDefaultSecondaryIndexes returns the built-in indexes, their ids must never change.
+func NewDefaultSecondaryIndexes(chainInstance Chain) []SecondaryIndex {
+	return []SecondaryIndex{
+		&SendFromToIndex{},
+		&FromBlockHashIndex{},
+		&MethodSelectorIndex{chainInstance: chainInstance},
+		&TimestampIndex{},
+	}
+}
+
+// SendFromToIndex indexes send blocks by sender address + receiver address.
+type SendFromToIndex struct{}
+
+func (index *SendFromToIndex) Id() byte     { return 1 }
+func (index *SendFromToIndex) Name() string { return INDEX_SEND_FROM_TO }
+
+func (index *SendFromToIndex) Keys(block *ledger.AccountBlock) ([][]byte, error) {
+	if !block.IsSendBlock() {
+		return nil, nil
+	}
+	return [][]byte{append(block.AccountAddress.Bytes(), block.ToAddress.Bytes()...)}, nil
+}
+
+// FromBlockHashIndex indexes receive blocks by the hash of the send block they receive.
+type FromBlockHashIndex struct{}
+
+func (index *FromBlockHashIndex) Id() byte     { return 2 }
+func (index *FromBlockHashIndex) Name() string { return INDEX_FROM_BLOCK_HASH }
+
+func (index *FromBlockHashIndex) Keys(block *ledger.AccountBlock) ([][]byte, error) {
+	if !block.IsReceiveBlock() {
+		return nil, nil
+	}
+	return [][]byte{block.FromBlockHash.Bytes()}, nil
+}
+
+// MethodSelectorIndex indexes contract calls by contract address + the first 4 bytes of the call data.
+type MethodSelectorIndex struct {
+	chainInstance Chain
+}
+
+func (index *MethodSelectorIndex) Id() byte     { return 3 }
+func (index *MethodSelectorIndex) Name() string { return INDEX_METHOD_SELECTOR }
+
+func (index *MethodSelectorIndex) Keys(block *ledger.AccountBlock) ([][]byte, error) {
+	if block.BlockType != ledger.BlockTypeSendCall || len(block.Data) < METHOD_SELECTOR_LENGTH {
+		return nil, nil
+	}
+
+	accountType, err := index.chainInstance.AccountType(&block.ToAddress)
+	if err != nil {
+		return nil, err
+	}
+	if accountType != ledger.AccountTypeContract {
+		return nil, nil
+	}
+	return [][]byte{append(block.ToAddress.Bytes(), block.Data[:METHOD_SELECTOR_LENGTH]...)}, nil
+}
+
+// TimestampIndex indexes all account blocks by unix timestamp, it is queried by range.
+type TimestampIndex struct{}
+
+func (index *TimestampIndex) Id() byte     { return 4 }
+func (index *TimestampIndex) Name() string { return INDEX_BLOCK_TIMESTAMP }
+
+func (index *TimestampIndex) Keys(block *ledger.AccountBlock) ([][]byte, error) {
+	if block.Timestamp == nil {
+		return nil, nil
+	}
+	return [][]byte{TimestampKey(block.Timestamp.Unix())}, nil
+}
+
+func TimestampKey(timestamp int64) []byte {
+	if timestamp < 0 {
+		timestamp = 0
+	}
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(timestamp))
+	return key
+}
+
+// TimestampEndKey is the exclusive end key of a range which includes timestamp, it doesn't overflow at math.MaxInt64.
+func TimestampEndKey(timestamp int64) []byte {
+	if timestamp < 0 {
+		return TimestampKey(0)
+	}
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(timestamp)+1)
+	return key
+}
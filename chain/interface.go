@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/chain/block_pruner"
 	"github.com/vitelabs/go-vite/chain/cache"
 	"github.com/vitelabs/go-vite/chain/index"
 	"github.com/vitelabs/go-vite/chain/sender"
@@ -50,6 +51,9 @@ type Chain interface {
 	Init()
 	Compressor() *compress.Compressor
 	TrieGc() trie_gc.Collector
+	BlockPruner() block_pruner.Pruner
+	PrunedHeight() uint64
+	IsAccountBlockPruned(block *ledger.AccountBlock) (bool, error)
 
 	StopSaveTrie()
 	StartSaveTrie()
@@ -124,7 +128,11 @@ type Chain interface {
 	GetConfirmSubLedgerBySnapshotBlocks(snapshotBlocks []*ledger.SnapshotBlock) (map[types.Address][]*ledger.AccountBlock, error)
 
 	GetStateTrie(stateHash *types.Hash) *trie.Trie
+	GetConfirmedStateTrie(addr *types.Address, snapshotHeight uint64) (*ledger.AccountBlock, *trie.Trie, error)
+	GetAccountBalanceAt(addr *types.Address, snapshotHeight uint64) (*ledger.AccountBlock, map[types.TokenTypeId]*big.Int, error)
 	ShallowCheckStateTrie(stateHash *types.Hash) (bool, error)
+	InsertStateSnapshot(snapshotBlock *ledger.SnapshotBlock, trieNodes []*trie.TrieNode, refValueMap map[types.Hash][]byte,
+		accountList []*ledger.Account, blocks []*ledger.AccountBlock, onRoadBlocks []*ledger.AccountBlock) error
 	GenStateTrieFromDb(prevStateHash types.Hash, snapshotContent ledger.SnapshotContent) (*trie.Trie, error)
 	NewStateTrie() *trie.Trie
 
@@ -146,6 +154,7 @@ type Chain interface {
 	// get receive block heights
 	GetReceiveBlockHeights(hash *types.Hash) ([]uint64, error)
 	Fti() *chain_index.FilterTokenIndex
+	Indexer() *chain_index.Indexer
 
 	// get on road blocks in a snapshot
 	GetOnRoadBlocksBySendAccount(sendAccountAddress *types.Address, snapshotBlockHeight uint64) ([]*ledger.AccountBlock, error)
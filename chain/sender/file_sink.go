@@ -0,0 +1,53 @@
+package sender
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+)
+
+// fileSink appends every message as one line of a local NDJSON file.
+type fileSink struct {
+	fileName string
+	file     *os.File
+}
+
+func newFileSink(fileName string) *fileSink {
+	return &fileSink{
+		fileName: fileName,
+	}
+}
+
+func (sink *fileSink) Open() error {
+	if err := os.MkdirAll(filepath.Dir(sink.fileName), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(sink.fileName, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	sink.file = file
+	return nil
+}
+
+func (sink *fileSink) Send(msgList [][]byte) error {
+	var buf bytes.Buffer
+	for _, msg := range msgList {
+		buf.Write(msg)
+		buf.WriteByte('\n')
+	}
+
+	if _, err := sink.file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return sink.file.Sync()
+}
+
+func (sink *fileSink) Close() error {
+	if err := sink.file.Close(); err != nil {
+		return err
+	}
+	sink.file = nil
+	return nil
+}
@@ -79,6 +79,32 @@ func (sender *KafkaSender) Start(brokerList []string, topic string) error {
 	return nil
 }
 
+// StartSink starts a producer which sends the block events to a non-kafka sink, the progress is kept per sink.
+func (sender *KafkaSender) StartSink(kind string, target string) error {
+	sender.lock.Lock()
+	defer sender.lock.Unlock()
+
+	producer, err := sender.getSinkProducer(kind, target)
+	if err != nil {
+		return err
+	}
+
+	for _, runProducer := range sender.runProducers {
+		if runProducer.IsSameSink(kind, target) {
+			// has run
+			return nil
+		}
+	}
+
+	if startErr := producer.Start(); startErr != nil {
+		return startErr
+	}
+
+	sender.runProducers = append(sender.runProducers, producer)
+
+	return nil
+}
+
 func (sender *KafkaSender) StopById(producerId uint8) {
 	sender.lock.Lock()
 	defer sender.lock.Unlock()
@@ -151,6 +177,30 @@ func (sender *KafkaSender) getProducer(brokerList []string, topic string) (*Prod
 	return newProducer, nil
 }
 
+func (sender *KafkaSender) getSinkProducer(kind string, target string) (*Producer, error) {
+	for _, producer := range sender.producers {
+		if producer.IsSameSink(kind, target) {
+			return producer, nil
+		}
+	}
+
+	if _, err := newSink(kind, nil, "", target); err != nil {
+		return nil, err
+	}
+
+	newProducer, newErr := NewSinkProducer(byte(len(sender.producers)+1), kind, target, sender.chain, sender.db)
+	if newErr != nil {
+		return nil, newErr
+	}
+
+	if writeErr := sender.writeProducerToDb(newProducer); writeErr != nil {
+		return nil, writeErr
+	}
+
+	sender.producers = append(sender.producers, newProducer)
+	return newProducer, nil
+}
+
 func (sender *KafkaSender) writeProducerToDb(producer *Producer) error {
 	key := append([]byte{DBKP_PRODUCER}, producer.producerId)
 	buf, sErr := producer.Serialize()
@@ -0,0 +1,73 @@
+package sender
+
+import (
+	"sync"
+
+	"github.com/Shopify/sarama"
+	"github.com/vitelabs/go-vite/log15"
+)
+
+type kafkaSink struct {
+	brokerList []string
+	topic      string
+
+	kafkaProducer sarama.AsyncProducer
+	sendWg        sync.WaitGroup
+
+	log log15.Logger
+}
+
+func newKafkaSink(brokerList []string, topic string) *kafkaSink {
+	return &kafkaSink{
+		brokerList: brokerList,
+		topic:      topic,
+		log:        log15.New("module", "sender/kafka_sink"),
+	}
+}
+
+func (sink *kafkaSink) Open() error {
+	config := sarama.NewConfig()
+	config.Producer.Return.Successes = true
+	config.Producer.Return.Errors = true
+
+	kafkaProducer, err := sarama.NewAsyncProducer(sink.brokerList, config)
+	if err != nil {
+		return err
+	}
+
+	sink.kafkaProducer = kafkaProducer
+	return nil
+}
+
+func (sink *kafkaSink) Send(msgList [][]byte) (err error) {
+	for i := 0; i < len(msgList); i++ {
+		sMsg := &sarama.ProducerMessage{Topic: sink.topic, Value: sarama.StringEncoder(msgList[i])}
+
+		sink.sendWg.Add(1)
+		// Simple implementation, may be fix
+		go func() {
+			defer sink.sendWg.Done()
+			sink.kafkaProducer.Input() <- sMsg
+			select {
+			// success
+			case <-sink.kafkaProducer.Successes():
+				break
+
+			// error
+			case sendError := <-sink.kafkaProducer.Errors():
+				sink.log.Error("kafka send failed, error is "+sendError.Error(), "method", "Send")
+				err = sendError
+			}
+		}()
+	}
+	sink.sendWg.Wait()
+	return
+}
+
+func (sink *kafkaSink) Close() error {
+	if err := sink.kafkaProducer.Close(); err != nil {
+		return err
+	}
+	sink.kafkaProducer = nil
+	return nil
+}
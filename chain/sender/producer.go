@@ -8,7 +8,6 @@ import (
 	"sync"
 	"time"
 
-	"github.com/Shopify/sarama"
 	"github.com/golang/protobuf/proto"
 	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/vitelabs/go-vite/common"
@@ -59,8 +58,10 @@ type Producer struct {
 	producerId uint8
 	db         *leveldb.DB
 
+	kind       string
 	brokerList []string
 	topic      string
+	target     string
 
 	hasSendLock      sync.RWMutex
 	hasSend          uint64
@@ -75,11 +76,9 @@ type Producer struct {
 
 	wg sync.WaitGroup
 
-	kafkaProducer sarama.AsyncProducer
-	chain         Chain
-	concurrency   uint64
-
-	sendWg sync.WaitGroup
+	sink        Sink
+	chain       Chain
+	concurrency uint64
 }
 
 func NewProducerFromDb(producerId uint8, buf []byte, chain Chain, db *leveldb.DB) (*Producer, error) {
@@ -97,6 +96,7 @@ func NewProducerFromDb(producerId uint8, buf []byte, chain Chain, db *leveldb.DB
 
 func NewProducer(producerId uint8, brokerList []string, topic string, chain Chain, db *leveldb.DB) (*Producer, error) {
 	producer := &Producer{
+		kind:       SINK_KAFKA,
 		brokerList: brokerList,
 		topic:      topic,
 	}
@@ -108,6 +108,19 @@ func NewProducer(producerId uint8, brokerList []string, topic string, chain Chai
 	return producer, nil
 }
 
+func NewSinkProducer(producerId uint8, kind string, target string, chain Chain, db *leveldb.DB) (*Producer, error) {
+	producer := &Producer{
+		kind:   kind,
+		target: target,
+	}
+
+	if err := producer.init(producerId, chain, db); err != nil {
+		return nil, err
+	}
+	producer.log = log15.New("module", "sender/producer")
+	return producer, nil
+}
+
 func (producer *Producer) init(producerId uint8, chain Chain, db *leveldb.DB) error {
 	producer.producerId = producerId
 	producer.concurrency = 100
@@ -143,6 +156,14 @@ func (producer *Producer) ProducerId() uint8 {
 	return producer.producerId
 }
 
+func (producer *Producer) Kind() string {
+	return producer.kind
+}
+
+func (producer *Producer) Target() string {
+	return producer.target
+}
+
 func (producer *Producer) BrokerList() []string {
 	return producer.brokerList
 }
@@ -159,8 +180,13 @@ func (producer *Producer) Status() int {
 	return producer.status
 }
 
+func (producer *Producer) IsSameSink(kind string, target string) bool {
+	return producer.kind == kind && producer.target == target
+}
+
 func (producer *Producer) IsSame(brokerList []string, topic string) bool {
-	if producer.topic != topic ||
+	if producer.kind != SINK_KAFKA ||
+		producer.topic != topic ||
 		len(brokerList) != len(producer.brokerList) {
 		return false
 	}
@@ -191,8 +217,14 @@ func (producer *Producer) Deserialize(buffer []byte) error {
 		return err
 	}
 
+	producer.kind = pb.Kind
+	if producer.kind == "" {
+		// producers saved before sinks were introduced are all kafka producers
+		producer.kind = SINK_KAFKA
+	}
 	producer.topic = pb.Topic
 	producer.brokerList = pb.BrokerList
+	producer.target = pb.Target
 	return nil
 }
 
@@ -200,6 +232,8 @@ func (producer *Producer) Serialize() ([]byte, error) {
 	pb := &vitepb.Producer{}
 	pb.BrokerList = producer.brokerList
 	pb.Topic = producer.topic
+	pb.Kind = producer.kind
+	pb.Target = producer.target
 
 	return proto.Marshal(pb)
 }
@@ -211,16 +245,16 @@ func (producer *Producer) Start() error {
 		return nil
 	}
 
-	config := sarama.NewConfig()
-	config.Producer.Return.Successes = true
-	config.Producer.Return.Errors = true
-
-	kafkaProducer, err := sarama.NewAsyncProducer(producer.brokerList, config)
+	sink, err := newSink(producer.kind, producer.brokerList, producer.topic, producer.target)
 	if err != nil {
 		return err
 	}
 
-	producer.kafkaProducer = kafkaProducer
+	if err := sink.Open(); err != nil {
+		return err
+	}
+
+	producer.sink = sink
 	producer.status = RUNNING
 	producer.termination = make(chan int)
 
@@ -234,18 +268,18 @@ func (producer *Producer) Start() error {
 				closeCount := 0
 
 				for ; closeCount < tryCloseCount; closeCount++ {
-					closeErr := producer.kafkaProducer.Close()
+					closeErr := producer.sink.Close()
 
 					if closeErr != nil {
-						producer.log.Error("kafkaProducer close failed, error is "+closeErr.Error(), "method", "Start")
+						producer.log.Error("sink close failed, error is "+closeErr.Error(), "method", "Start")
 					} else {
-						producer.kafkaProducer = nil
+						producer.sink = nil
 						return
 					}
 				}
 
 				if closeCount == tryCloseCount {
-					producer.log.Crit("kafkaProducer close failed", "method", "Start")
+					producer.log.Crit("sink close failed", "method", "Start")
 				}
 			default:
 				producer.send()
@@ -534,32 +568,19 @@ func (producer *Producer) getHasSend() (uint64, error) {
 	return binary.BigEndian.Uint64(value), nil
 }
 
-func (producer *Producer) sendMessage(msgList []*message) (err error) {
-	//var err error
-	for i := 0; i < len(msgList); i++ {
-		buf, jsonErr := json.Marshal(msgList[i])
+func (producer *Producer) sendMessage(msgList []*message) error {
+	if len(msgList) <= 0 {
+		return nil
+	}
+
+	bufList := make([][]byte, 0, len(msgList))
+	for _, m := range msgList {
+		buf, jsonErr := json.Marshal(m)
 		if jsonErr != nil {
 			return jsonErr
 		}
-		sMsg := &sarama.ProducerMessage{Topic: producer.topic, Value: sarama.StringEncoder(buf)}
-
-		producer.sendWg.Add(1)
-		// Simple implementation, may be fix
-		go func() {
-			defer producer.sendWg.Done()
-			producer.kafkaProducer.Input() <- sMsg
-			select {
-			// success
-			case <-producer.kafkaProducer.Successes():
-				break
-
-			// error
-			case sendError := <-producer.kafkaProducer.Errors():
-				producer.log.Error("kafka send failed, error is "+sendError.Error(), "method", "sendMessage")
-				err = sendError
-			}
-		}()
+		bufList = append(bufList, buf)
 	}
-	producer.sendWg.Wait()
-	return
+
+	return producer.sink.Send(bufList)
 }
@@ -0,0 +1,37 @@
+package sender
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	SINK_KAFKA   = "kafka"
+	SINK_FILE    = "file"
+	SINK_UNIX    = "unix"
+	SINK_WEBHOOK = "webhook"
+)
+
+// Sink is the transport a producer delivers block events through. Messages are json encoded and
+// handed over in event id order, a message is regarded as delivered once Send returns nil, so a
+// sink may see the same event again after an unclean shutdown.
+type Sink interface {
+	Open() error
+	Send(msgList [][]byte) error
+	Close() error
+}
+
+func newSink(kind string, brokerList []string, topic string, target string) (Sink, error) {
+	switch kind {
+	case SINK_KAFKA:
+		return newKafkaSink(brokerList, topic), nil
+	case SINK_FILE:
+		return newFileSink(target), nil
+	case SINK_UNIX:
+		return newUnixSink(target), nil
+	case SINK_WEBHOOK:
+		return newWebhookSink(target), nil
+	}
+	return nil, errors.New(fmt.Sprintf("unknown sink kind %s", kind))
+}
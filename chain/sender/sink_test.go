@@ -0,0 +1,161 @@
+package sender
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testMsgList() [][]byte {
+	return [][]byte{
+		[]byte(`{"type":"InsertAccountBlocks","data":"[]","eventId":1}`),
+		[]byte(`{"type":"DeleteAccountBlocks","data":"[]","eventId":2}`),
+	}
+}
+
+func TestFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sender")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	fileName := filepath.Join(dir, "events.ndjson")
+
+	for i := 0; i < 2; i++ {
+		sink, err := newSink(SINK_FILE, nil, "", fileName)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.Open(); err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.Send(testMsgList()); err != nil {
+			t.Fatal(err)
+		}
+		if err := sink.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	buf, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(buf), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("file has %d lines, expected 4", len(lines))
+	}
+	for _, line := range lines {
+		m := &message{}
+		if err := json.Unmarshal([]byte(line), m); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestUnixSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sender")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socketPath := filepath.Join(dir, "sender.sock")
+
+	sink, err := newSink(SINK_UNIX, nil, "", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	// the consumer is not listening yet
+	if err := sink.Send(testMsgList()); err == nil {
+		t.Fatal("send should fail without a listener")
+	}
+
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan []string)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			close(received)
+			return
+		}
+		defer conn.Close()
+
+		var lines []string
+		scanner := bufio.NewScanner(conn)
+		for len(lines) < 2 && scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		received <- lines
+	}()
+
+	if err := sink.Send(testMsgList()); err != nil {
+		t.Fatal(err)
+	}
+	lines := <-received
+	if len(lines) != 2 || lines[1] != string(testMsgList()[1]) {
+		t.Fatalf("received %v", lines)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received []*message
+	fail := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	if err := newWebhookSink("ftp://127.0.0.1").Open(); err == nil {
+		t.Fatal("open should fail with a non http url")
+	}
+
+	sink, err := newSink(SINK_WEBHOOK, nil, "", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Open(); err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+
+	if err := sink.Send(testMsgList()); err == nil {
+		t.Fatal("send should fail when the endpoint responds an error")
+	}
+
+	fail = false
+	if err := sink.Send(testMsgList()); err != nil {
+		t.Fatal(err)
+	}
+	if len(received) != 2 || received[0].EventId != 1 || received[1].MsgType != "DeleteAccountBlocks" {
+		t.Fatalf("received %v", received)
+	}
+}
+
+func TestUnknownSink(t *testing.T) {
+	if _, err := newSink("ftp", nil, "", "127.0.0.1"); err == nil {
+		t.Fatal("unknown sink kind should fail")
+	}
+}
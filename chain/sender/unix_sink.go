@@ -0,0 +1,59 @@
+package sender
+
+import (
+	"bytes"
+	"net"
+	"time"
+)
+
+const unixSinkTimeout = 10 * time.Second
+
+// unixSink streams NDJSON lines to a consumer listening on a unix socket. The connection is
+// dialed lazily and redialed after a failure, so the consumer may start after the node.
+type unixSink struct {
+	socketPath string
+	conn       net.Conn
+}
+
+func newUnixSink(socketPath string) *unixSink {
+	return &unixSink{
+		socketPath: socketPath,
+	}
+}
+
+func (sink *unixSink) Open() error {
+	return nil
+}
+
+func (sink *unixSink) Send(msgList [][]byte) error {
+	if sink.conn == nil {
+		conn, err := net.DialTimeout("unix", sink.socketPath, unixSinkTimeout)
+		if err != nil {
+			return err
+		}
+		sink.conn = conn
+	}
+
+	var buf bytes.Buffer
+	for _, msg := range msgList {
+		buf.Write(msg)
+		buf.WriteByte('\n')
+	}
+
+	sink.conn.SetWriteDeadline(time.Now().Add(unixSinkTimeout))
+	if _, err := sink.conn.Write(buf.Bytes()); err != nil {
+		sink.Close()
+		return err
+	}
+	return nil
+}
+
+func (sink *unixSink) Close() error {
+	if sink.conn == nil {
+		return nil
+	}
+
+	err := sink.conn.Close()
+	sink.conn = nil
+	return err
+}
@@ -0,0 +1,61 @@
+package sender
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// webhookSink posts every batch of messages to an http endpoint as a json array,
+// the batch is delivered when the endpoint answers with a 2xx status.
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func newWebhookSink(url string) *webhookSink {
+	return &webhookSink{
+		url: url,
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+func (sink *webhookSink) Open() error {
+	u, err := url.Parse(sink.url)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New(fmt.Sprintf("webhook url %s is not http or https", sink.url))
+	}
+	return nil
+}
+
+func (sink *webhookSink) Send(msgList [][]byte) error {
+	body := append([]byte{'['}, bytes.Join(msgList, []byte{','})...)
+	body = append(body, ']')
+
+	resp, err := sink.client.Post(sink.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.New(fmt.Sprintf("webhook %s responded %s", sink.url, resp.Status))
+	}
+	return nil
+}
+
+func (sink *webhookSink) Close() error {
+	return nil
+}
@@ -526,6 +526,13 @@ func (c *chain) DeleteSnapshotBlocksToHeight(toHeight uint64) ([]*ledger.Snapsho
 		return nil, nil, nil
 	}
 
+	// the bodies of the account blocks are needed to insert them again
+	if prunedHeight := c.PrunedHeight(); toHeight <= prunedHeight {
+		err := errors.New(fmt.Sprintf("can't delete to snapshot block %d, the snapshot blocks up to %d are pruned", toHeight, prunedHeight))
+		c.log.Error(err.Error(), "method", "DeleteSnapshotBlocksToHeight")
+		return nil, nil, err
+	}
+
 	batch := new(leveldb.Batch)
 	snapshotBlocks, accountBlocksMap, err := c.deleteSnapshotBlocksByHeight(batch, toHeight)
 	if err != nil {
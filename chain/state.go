@@ -1,10 +1,29 @@
 package chain
 
 import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/pkg/errors"
 	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/monitor"
 	"github.com/vitelabs/go-vite/trie"
 )
 
+// ErrStateTriePruned means the state at the snapshot height has been cleared by trie gc,
+// only the state at RetainMinHeight or higher is guaranteed to be kept.
+type ErrStateTriePruned struct {
+	SnapshotHeight  uint64
+	RetainMinHeight uint64
+}
+
+func (e *ErrStateTriePruned) Error() string {
+	return fmt.Sprintf("state at snapshot height %d has been pruned by trie gc, the lowest retained height is %d",
+		e.SnapshotHeight, e.RetainMinHeight)
+}
+
 const (
 	SAVE_TRIE_STATUS_STOPPED = 1
 	SAVE_TRIE_STATUS_STARTED = 2
@@ -46,3 +65,58 @@ func (c *chain) GetStateTrie(stateHash *types.Hash) *trie.Trie {
 func (c *chain) NewStateTrie() *trie.Trie {
 	return trie.NewTrie(c.TrieDb(), nil, c.trieNodePool)
 }
+
+// GetConfirmedStateTrie returns the latest block of addr confirmed at snapshotHeight and its state trie,
+// both are nil if the account has no confirmed block at that height.
+func (c *chain) GetConfirmedStateTrie(addr *types.Address, snapshotHeight uint64) (*ledger.AccountBlock, *trie.Trie, error) {
+	monitorTags := []string{"chain", "GetConfirmedStateTrie"}
+	defer monitor.LogTimerConsuming(monitorTags, time.Now())
+
+	latestSnapshotBlock := c.GetLatestSnapshotBlock()
+	if snapshotHeight > latestSnapshotBlock.Height {
+		return nil, nil, errors.New(fmt.Sprintf("snapshot height %d is higher than the latest snapshot height %d",
+			snapshotHeight, latestSnapshotBlock.Height))
+	}
+
+	block, err := c.GetConfirmAccountBlock(snapshotHeight, addr)
+	if err != nil {
+		c.log.Error("GetConfirmAccountBlock failed, error is "+err.Error(), "method", "GetConfirmedStateTrie")
+		return nil, nil, err
+	}
+	if block == nil {
+		return nil, nil, nil
+	}
+
+	if ok, err := c.ShallowCheckStateTrie(&block.StateHash); err != nil {
+		c.log.Error("ShallowCheckStateTrie failed, error is "+err.Error(), "method", "GetConfirmedStateTrie")
+		return nil, nil, err
+	} else if !ok {
+		if retainMinHeight := c.TrieGc().RetainMinHeight(); snapshotHeight < retainMinHeight {
+			return nil, nil, &ErrStateTriePruned{
+				SnapshotHeight:  snapshotHeight,
+				RetainMinHeight: retainMinHeight,
+			}
+		}
+		return nil, nil, errors.New(fmt.Sprintf("state trie %s of block %s is not existed", block.StateHash, block.Hash))
+	}
+
+	return block, c.GetStateTrie(&block.StateHash), nil
+}
+
+// GetAccountBalanceAt returns the latest block of addr confirmed at snapshotHeight and the balances after it.
+func (c *chain) GetAccountBalanceAt(addr *types.Address, snapshotHeight uint64) (*ledger.AccountBlock, map[types.TokenTypeId]*big.Int, error) {
+	block, stateTrie, err := c.GetConfirmedStateTrie(addr, snapshotHeight)
+	if err != nil {
+		return nil, nil, err
+	}
+	if stateTrie == nil {
+		return nil, nil, nil
+	}
+
+	balanceMap, err := getBalanceMap(stateTrie)
+	if err != nil {
+		c.log.Error("getBalanceMap failed, error is "+err.Error(), "method", "GetAccountBalanceAt")
+		return nil, nil, err
+	}
+	return block, balanceMap, nil
+}
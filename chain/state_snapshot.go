@@ -0,0 +1,163 @@
+package chain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/monitor"
+	"github.com/vitelabs/go-vite/trie"
+	"github.com/vitelabs/go-vite/vm_context"
+)
+
+// InsertStateSnapshot makes snapshotBlock the latest snapshot block of a chain which only has the genesis blocks.
+// The tries must be complete and verified by the caller. Only the given account blocks are written, the history
+// before them is missing, so all of them are regarded as confirmed by snapshotBlock. onRoadBlocks are the send
+// blocks in blocks which are not received yet.
+func (c *chain) InsertStateSnapshot(snapshotBlock *ledger.SnapshotBlock, trieNodes []*trie.TrieNode, refValueMap map[types.Hash][]byte,
+	accountList []*ledger.Account, blocks []*ledger.AccountBlock, onRoadBlocks []*ledger.AccountBlock) error {
+	monitorTags := []string{"chain", "InsertStateSnapshot"}
+	defer monitor.LogTimerConsuming(monitorTags, time.Now())
+
+	if latestSnapshotBlock := c.GetLatestSnapshotBlock(); !c.IsGenesisSnapshotBlock(latestSnapshotBlock) {
+		return errors.New(fmt.Sprintf("the chain is not empty, the latest snapshot height is %d", latestSnapshotBlock.Height))
+	} else if snapshotBlock.Height <= latestSnapshotBlock.Height {
+		return errors.New(fmt.Sprintf("snapshot block %d is not higher than the latest snapshot block %d", snapshotBlock.Height, latestSnapshotBlock.Height))
+	}
+	if content := c.GetNeedSnapshotContent(); len(content) > 0 {
+		return errors.New(fmt.Sprintf("the chain has unconfirmed account blocks of %d accounts", len(content)))
+	}
+
+	c.createAccountLock.Lock()
+	defer c.createAccountLock.Unlock()
+
+	c.saveTrieLock.RLock()
+	defer c.saveTrieLock.RUnlock()
+
+	batch := new(leveldb.Batch)
+
+	// Save state tries
+	if err := trie.SaveNodes(batch, trieNodes, refValueMap); err != nil {
+		c.log.Error("SaveNodes failed, error is "+err.Error(), "method", "InsertStateSnapshot")
+		return err
+	}
+
+	// Check and create accounts, the producer account may have no blocks
+	producer := &ledger.Account{
+		AccountAddress: snapshotBlock.Producer(),
+		PublicKey:      snapshotBlock.PublicKey,
+	}
+	nextAccountId, err := c.newAccountId()
+	if err != nil {
+		c.log.Error("newAccountId failed, error is "+err.Error(), "method", "InsertStateSnapshot")
+		return err
+	}
+
+	accountMap := make(map[types.Address]*ledger.Account, len(accountList)+1)
+	for _, item := range append(accountList, producer) {
+		if _, ok := accountMap[item.AccountAddress]; ok {
+			continue
+		}
+
+		account, err := c.chainDb.Account.GetAccountByAddress(&item.AccountAddress)
+		if err != nil {
+			c.log.Error("GetAccountByAddress failed, error is "+err.Error(), "method", "InsertStateSnapshot")
+			return err
+		}
+		if account == nil {
+			if account, err = c.createAccount(batch, nextAccountId, &item.AccountAddress, item.PublicKey); err != nil {
+				c.log.Error("createAccount failed, error is "+err.Error(), "method", "InsertStateSnapshot")
+				return err
+			}
+			nextAccountId++
+		}
+		accountMap[item.AccountAddress] = account
+	}
+
+	// The receive heights of the send blocks which are received by the given blocks
+	receiveHeightsMap := make(map[types.Hash][]uint64)
+	for _, block := range blocks {
+		if block.IsReceiveBlock() {
+			receiveHeightsMap[block.FromBlockHash] = append(receiveHeightsMap[block.FromBlockHash], block.Height)
+		}
+	}
+
+	var addBlockHashList []types.Hash
+	for _, block := range blocks {
+		account, ok := accountMap[block.AccountAddress]
+		if !ok {
+			err := errors.New(fmt.Sprintf("account %s of block %s is not existed", block.AccountAddress, block.Hash))
+			c.log.Error(err.Error(), "method", "InsertStateSnapshot")
+			return err
+		}
+
+		if err := c.chainDb.Ac.WriteBlock(batch, account.AccountId, block); err != nil {
+			c.log.Error("WriteBlock failed, error is "+err.Error(), "method", "InsertStateSnapshot")
+			return err
+		}
+
+		blockMeta := &ledger.AccountBlockMeta{
+			AccountId:           account.AccountId,
+			Height:              block.Height,
+			ReceiveBlockHeights: receiveHeightsMap[block.Hash],
+			RefSnapshotHeight:   snapshotBlock.Height,
+		}
+		if err := c.chainDb.Ac.WriteBlockMeta(batch, &block.Hash, blockMeta); err != nil {
+			c.log.Error("WriteBlockMeta failed, error is "+err.Error(), "method", "InsertStateSnapshot")
+			return err
+		}
+
+		if err := c.chainDb.Ac.WriteBeSnapshot(batch, &block.Hash, snapshotBlock.Height); err != nil {
+			c.log.Error("WriteBeSnapshot failed, error is "+err.Error(), "method", "InsertStateSnapshot")
+			return err
+		}
+
+		addBlockHashList = append(addBlockHashList, block.Hash)
+	}
+
+	// Let the listeners record the send blocks which are not received yet
+	if len(onRoadBlocks) > 0 {
+		vmAccountBlocks := make([]*vm_context.VmAccountBlock, 0, len(onRoadBlocks))
+		for _, block := range onRoadBlocks {
+			vmAccountBlocks = append(vmAccountBlocks, &vm_context.VmAccountBlock{
+				AccountBlock: block,
+				VmContext:    vm_context.NewEmptyVmContextByTrie(nil),
+			})
+		}
+		if err := c.em.triggerInsertAccountBlocks(batch, vmAccountBlocks); err != nil {
+			c.log.Error("c.em.trigger, error is "+err.Error(), "method", "InsertStateSnapshot")
+			return err
+		}
+	}
+	c.chainDb.Be.AddAccountBlocks(batch, addBlockHashList)
+
+	// Save snapshot block
+	if err := c.chainDb.Sc.WriteSnapshotBlock(batch, snapshotBlock); err != nil {
+		c.log.Error("WriteSnapshotBlock failed, error is "+err.Error(), "method", "InsertStateSnapshot")
+		return err
+	}
+	if err := c.chainDb.Sc.WriteSnapshotContent(batch, snapshotBlock.Height, snapshotBlock.SnapshotContent); err != nil {
+		c.log.Error("WriteSnapshotContent failed, error is "+err.Error(), "method", "InsertStateSnapshot")
+		return err
+	}
+	c.chainDb.Sc.WriteSnapshotHash(batch, &snapshotBlock.Hash, snapshotBlock.Height)
+	c.chainDb.Be.AddSnapshotBlocks(batch, []types.Hash{snapshotBlock.Hash})
+
+	// Write db
+	if err := c.chainDb.Commit(batch); err != nil {
+		c.log.Crit("c.chainDb.Commit(batch) failed, error is "+err.Error(), "method", "InsertStateSnapshot")
+		return err
+	}
+
+	snapshotBlock.StateTrie = c.GetStateTrie(&snapshotBlock.StateHash)
+
+	// The quota of the skipped snapshot blocks is unknown
+	c.saList.Add(snapshotBlock, 0)
+
+	// Set cache
+	c.latestSnapshotBlock = snapshotBlock
+	return nil
+}
@@ -0,0 +1,260 @@
+package state_snapshot
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/chain_db/database"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/trie"
+)
+
+type DumpChain interface {
+	GetLatestSnapshotBlock() *ledger.SnapshotBlock
+	GetSnapshotBlockByHeight(height uint64) (*ledger.SnapshotBlock, error)
+	ShallowCheckStateTrie(stateHash *types.Hash) (bool, error)
+	GetStateTrie(stateHash *types.Hash) *trie.Trie
+	TrieDb() *leveldb.DB
+
+	GetAccount(address *types.Address) (*ledger.Account, error)
+	GetConfirmAccountBlock(snapshotHeight uint64, address *types.Address) (*ledger.AccountBlock, error)
+	GetAccountBlockByHeight(addr *types.Address, height uint64) (*ledger.AccountBlock, error)
+	GetAccountBlockByHash(blockHash *types.Hash) (*ledger.AccountBlock, error)
+	GetOnRoadBlocksBySendAccount(sendAccountAddress *types.Address, snapshotBlockHeight uint64) ([]*ledger.AccountBlock, error)
+}
+
+type dumper struct {
+	chain  DumpChain
+	writer *Writer
+
+	// nodes already written, shared sub tries are written once
+	writtenNodes map[types.Hash]struct{}
+}
+
+// Dump writes the whole state at the snapshot block of height to w.
+func Dump(chain DumpChain, w io.Writer, height uint64, progress func(accountCount uint64)) error {
+	if latestHeight := chain.GetLatestSnapshotBlock().Height; height > latestHeight {
+		return errors.New(fmt.Sprintf("height %d is higher than the latest snapshot height %d", height, latestHeight))
+	}
+
+	snapshotBlock, err := chain.GetSnapshotBlockByHeight(height)
+	if err != nil {
+		return errors.New(fmt.Sprintf("GetSnapshotBlockByHeight failed, height is %d, error is %s", height, err.Error()))
+	}
+	if snapshotBlock == nil {
+		return errors.New(fmt.Sprintf("snapshot block %d is not existed", height))
+	}
+
+	writer, err := NewWriter(w, snapshotBlock)
+	if err != nil {
+		return err
+	}
+
+	d := &dumper{
+		chain:        chain,
+		writer:       writer,
+		writtenNodes: make(map[types.Hash]struct{}),
+	}
+
+	stateTrie, err := d.dumpTrie(&snapshotBlock.StateHash)
+	if err != nil {
+		return err
+	}
+
+	accountCount := uint64(0)
+	iter := stateTrie.NewIterator(nil)
+	for {
+		key, value, ok := iter.Next()
+		if !ok {
+			break
+		}
+
+		addr, err := types.BytesToAddress(key)
+		if err != nil {
+			return errors.New(fmt.Sprintf("key %x of the snapshot trie is not an address", key))
+		}
+		stateHash, err := types.BytesToHash(value)
+		if err != nil {
+			return errors.New(fmt.Sprintf("state hash %x of %s is invalid", value, addr))
+		}
+
+		if err := d.dumpAccount(snapshotBlock, addr, stateHash); err != nil {
+			return errors.New(fmt.Sprintf("dump account %s failed, error is %s", addr, err.Error()))
+		}
+
+		accountCount++
+		if progress != nil {
+			progress(accountCount)
+		}
+	}
+
+	return writer.Close()
+}
+
+func (d *dumper) dumpAccount(snapshotBlock *ledger.SnapshotBlock, addr types.Address, stateHash types.Hash) error {
+	account, err := d.chain.GetAccount(&addr)
+	if err != nil {
+		return err
+	}
+	if account == nil {
+		return errors.New("account is not existed")
+	}
+
+	latestBlock, err := d.chain.GetConfirmAccountBlock(snapshotBlock.Height, &addr)
+	if err != nil {
+		return err
+	}
+	if latestBlock == nil {
+		return errors.New("confirmed block is not existed")
+	}
+	if latestBlock.StateHash != stateHash {
+		return errors.New(fmt.Sprintf("state hash of the confirmed block %s is %s, but %s in the snapshot trie",
+			latestBlock.Hash, latestBlock.StateHash, stateHash))
+	}
+
+	if _, err := d.dumpTrie(&stateHash); err != nil {
+		return err
+	}
+
+	blocks, err := d.contractCreateBlocks(latestBlock)
+	if err != nil {
+		return err
+	}
+
+	onRoadBlocks, err := d.chain.GetOnRoadBlocksBySendAccount(&addr, snapshotBlock.Height)
+	if err != nil {
+		return err
+	}
+	chainBlocks, err := d.onRoadChainBlocks(latestBlock, onRoadBlocks)
+	if err != nil {
+		return err
+	}
+	blocks = append(blocks, chainBlocks...)
+
+	return d.writer.WriteAccount(&AccountState{
+		Address:      addr,
+		PublicKey:    account.PublicKey,
+		LatestBlock:  latestBlock,
+		Blocks:       blocks,
+		OnRoadBlocks: onRoadBlocks,
+	})
+}
+
+// contractCreateBlocks returns the first block of a contract and the send create block it receives,
+// the gid of a contract is read from them.
+func (d *dumper) contractCreateBlocks(latestBlock *ledger.AccountBlock) ([]*ledger.AccountBlock, error) {
+	firstBlock := latestBlock
+	if latestBlock.Height > 1 {
+		var err error
+		if firstBlock, err = d.chain.GetAccountBlockByHeight(&latestBlock.AccountAddress, 1); err != nil {
+			return nil, err
+		}
+	}
+	if firstBlock == nil || !firstBlock.IsReceiveBlock() {
+		return nil, nil
+	}
+
+	fromBlock, err := d.chain.GetAccountBlockByHash(&firstBlock.FromBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if fromBlock == nil || fromBlock.BlockType != ledger.BlockTypeSendCreate {
+		return nil, nil
+	}
+
+	if firstBlock == latestBlock {
+		return []*ledger.AccountBlock{fromBlock}, nil
+	}
+	return []*ledger.AccountBlock{firstBlock, fromBlock}, nil
+}
+
+// onRoadChainBlocks returns the blocks between the lowest on road block and the latest block, the loader links
+// every on road block to the latest block by them.
+func (d *dumper) onRoadChainBlocks(latestBlock *ledger.AccountBlock, onRoadBlocks []*ledger.AccountBlock) ([]*ledger.AccountBlock, error) {
+	if len(onRoadBlocks) == 0 {
+		return nil, nil
+	}
+
+	lowestHeight := latestBlock.Height
+	onRoadSet := make(map[uint64]struct{}, len(onRoadBlocks))
+	for _, block := range onRoadBlocks {
+		onRoadSet[block.Height] = struct{}{}
+		if block.Height < lowestHeight {
+			lowestHeight = block.Height
+		}
+	}
+
+	var blocks []*ledger.AccountBlock
+	for height := lowestHeight + 1; height < latestBlock.Height; height++ {
+		if _, ok := onRoadSet[height]; ok {
+			continue
+		}
+		block, err := d.chain.GetAccountBlockByHeight(&latestBlock.AccountAddress, height)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, errors.New(fmt.Sprintf("block %d of %s is not existed", height, latestBlock.AccountAddress))
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+// dumpTrie writes the nodes of the trie which are not written yet, it walks like the marker of trie gc.
+func (d *dumper) dumpTrie(stateHash *types.Hash) (*trie.Trie, error) {
+	if ok, err := d.chain.ShallowCheckStateTrie(stateHash); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errors.New(fmt.Sprintf("state trie %s is not existed, it may have been pruned by trie gc", stateHash))
+	}
+
+	stateTrie := d.chain.GetStateTrie(stateHash)
+
+	missing := false
+	notWritten := func(node *trie.TrieNode) bool {
+		if node == nil {
+			missing = true
+			return false
+		}
+		_, ok := d.writtenNodes[*node.Hash()]
+		return !ok
+	}
+
+	ni := stateTrie.NewNodeIterator()
+	for ni.Next(notWritten) {
+		if missing {
+			return nil, errors.New(fmt.Sprintf("state trie %s is not complete", stateHash))
+		}
+
+		node := ni.Node()
+		nodeHash := node.Hash()
+		if _, ok := d.writtenNodes[*nodeHash]; ok {
+			continue
+		}
+
+		if err := d.writer.WriteTrieNode(node); err != nil {
+			return nil, err
+		}
+		d.writtenNodes[*nodeHash] = struct{}{}
+
+		if node.NodeType() == trie.TRIE_HASH_NODE {
+			if err := d.dumpRefValue(node.Value()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return stateTrie, nil
+}
+
+func (d *dumper) dumpRefValue(refHashBytes []byte) error {
+	dbKey, _ := database.EncodeKey(database.DBKP_TRIE_REF_VALUE, refHashBytes)
+	value, err := d.chain.TrieDb().Get(dbKey, nil)
+	if err != nil {
+		return errors.New(fmt.Sprintf("get ref value %x failed, error is %s", refHashBytes, err.Error()))
+	}
+	return d.writer.WriteRefValue(value)
+}
@@ -0,0 +1,454 @@
+/*
+Package state_snapshot dumps the whole state at a snapshot block and bootstraps a new node from it.
+
+A state snapshot file holds the snapshot block, every trie node reachable from its StateHash (the
+snapshot trie and all account tries), the ref values of hash nodes and the accounts. All integers
+are big endian.
+
+	header   magic "VITESTAT" (8 bytes) | version uint32 | size uint32 | SnapshotBlock.Serialize()
+	node     SECTION_TRIE_NODE (1 byte) | size uint32 | TrieNode.DbSerialize()
+	ref      SECTION_REF_VALUE (1 byte) | size uint32 | value
+	account  SECTION_ACCOUNT (1 byte) | address (20 bytes) | size uint32 | public key |
+	         block of the latest confirmed one | blockCount uint32 | blockCount * block |
+	         onRoadCount uint32 | onRoadCount * block
+	block    size uint32 | AccountBlock.Serialize() | StateHash (32 bytes)
+	trailer  SECTION_END (1 byte) | recordCount uint64 | sha256 (32 bytes)
+
+Nodes and ref values are identified by their hashes, which are computed again when they are read.
+The blocks of an account record are the other blocks a node needs to go on syncing, e.g. the first block
+of a contract and the send create block it receives, and the blocks between the lowest on road block and the
+latest block. The on road blocks are the send blocks which are not received at the snapshot block, the latest
+block may be one of them. They are linked to the latest block by prev hashes when loaded.
+The sha256 is computed over all bytes before it.
+*/
+package state_snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/crypto"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/trie"
+)
+
+const (
+	VERSION = uint32(1)
+
+	SECTION_TRIE_NODE = byte(1)
+	SECTION_REF_VALUE = byte(2)
+	SECTION_ACCOUNT   = byte(3)
+	SECTION_END       = byte(4)
+
+	// it only guards against corrupted sizes
+	MAX_RECORD_SIZE = 16 * 1024 * 1024
+)
+
+var (
+	MAGIC = []byte("VITESTAT")
+
+	ErrInvalidMagic     = errors.New("not a state snapshot")
+	ErrChecksumMismatch = errors.New("state snapshot checksum is not matched")
+)
+
+const (
+	filenamePrefix = "state_"
+	filenameSuffix = ".snapshot"
+)
+
+// Filename is the name of the state snapshot at the snapshot block of hash, nodes serve state snapshots by it.
+func Filename(hash types.Hash) string {
+	return filenamePrefix + hash.String() + filenameSuffix
+}
+
+// ParseFilename returns the snapshot block hash of a state snapshot name, false if name is not one.
+func ParseFilename(name string) (types.Hash, bool) {
+	if !strings.HasPrefix(name, filenamePrefix) || !strings.HasSuffix(name, filenameSuffix) {
+		return types.Hash{}, false
+	}
+	hash, err := types.HexToHash(strings.TrimSuffix(strings.TrimPrefix(name, filenamePrefix), filenameSuffix))
+	if err != nil || Filename(hash) != name {
+		return types.Hash{}, false
+	}
+	return hash, true
+}
+
+type AccountState struct {
+	Address     types.Address
+	PublicKey   []byte
+	LatestBlock *ledger.AccountBlock
+
+	Blocks       []*ledger.AccountBlock
+	OnRoadBlocks []*ledger.AccountBlock
+}
+
+// Record is one of TrieNode, RefValue or Account, depending on Section.
+type Record struct {
+	Section byte
+
+	TrieNode *trie.TrieNode
+
+	RefHash  types.Hash
+	RefValue []byte
+
+	Account *AccountState
+}
+
+type Writer struct {
+	w           *bufio.Writer
+	hash        hash.Hash
+	recordCount uint64
+}
+
+func NewWriter(w io.Writer, snapshotBlock *ledger.SnapshotBlock) (*Writer, error) {
+	writer := &Writer{
+		w:    bufio.NewWriter(w),
+		hash: sha256.New(),
+	}
+
+	blockBytes, err := snapshotBlock.Serialize()
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Serialize snapshot block %s failed, error is %s", snapshotBlock.Hash, err.Error()))
+	}
+
+	buf := make([]byte, 0, len(MAGIC)+8+len(blockBytes))
+	buf = append(buf, MAGIC...)
+	buf = appendUint32(buf, VERSION)
+	buf = appendBytes(buf, blockBytes)
+	if err := writer.write(buf); err != nil {
+		return nil, err
+	}
+	return writer, nil
+}
+
+func (writer *Writer) WriteTrieNode(node *trie.TrieNode) error {
+	nodeBytes, err := node.DbSerialize()
+	if err != nil {
+		return errors.New(fmt.Sprintf("DbSerialize trie node %s failed, error is %s", node.Hash(), err.Error()))
+	}
+	return writer.writeRecord(appendBytes([]byte{SECTION_TRIE_NODE}, nodeBytes))
+}
+
+func (writer *Writer) WriteRefValue(value []byte) error {
+	return writer.writeRecord(appendBytes([]byte{SECTION_REF_VALUE}, value))
+}
+
+func (writer *Writer) WriteAccount(account *AccountState) error {
+	buf := []byte{SECTION_ACCOUNT}
+	buf = append(buf, account.Address.Bytes()...)
+	buf = appendBytes(buf, account.PublicKey)
+
+	var err error
+	if buf, err = appendAccountBlock(buf, account.LatestBlock); err != nil {
+		return err
+	}
+
+	for _, blocks := range [][]*ledger.AccountBlock{account.Blocks, account.OnRoadBlocks} {
+		buf = appendUint32(buf, uint32(len(blocks)))
+		for _, block := range blocks {
+			if buf, err = appendAccountBlock(buf, block); err != nil {
+				return err
+			}
+		}
+	}
+	return writer.writeRecord(buf)
+}
+
+// Close writes the trailer and flushes, it does not close the underlying writer.
+func (writer *Writer) Close() error {
+	buf := []byte{SECTION_END}
+	buf = appendUint64(buf, writer.recordCount)
+	if err := writer.write(buf); err != nil {
+		return err
+	}
+
+	if _, err := writer.w.Write(writer.hash.Sum(nil)); err != nil {
+		return err
+	}
+	return writer.w.Flush()
+}
+
+func (writer *Writer) writeRecord(buf []byte) error {
+	if err := writer.write(buf); err != nil {
+		return err
+	}
+	writer.recordCount++
+	return nil
+}
+
+func (writer *Writer) write(buf []byte) error {
+	writer.hash.Write(buf)
+	_, err := writer.w.Write(buf)
+	return err
+}
+
+type Reader struct {
+	r             io.Reader
+	hash          hash.Hash
+	version       uint32
+	snapshotBlock *ledger.SnapshotBlock
+	recordCount   uint64
+	finished      bool
+}
+
+func NewReader(r io.Reader) (*Reader, error) {
+	reader := &Reader{
+		r:    bufio.NewReader(r),
+		hash: sha256.New(),
+	}
+
+	magic, err := reader.read(len(MAGIC))
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(magic, MAGIC) {
+		return nil, ErrInvalidMagic
+	}
+
+	if reader.version, err = reader.readUint32(); err != nil {
+		return nil, err
+	}
+	if reader.version != VERSION {
+		return nil, errors.New(fmt.Sprintf("state snapshot version %d is not supported, supported version is %d", reader.version, VERSION))
+	}
+
+	buf, err := reader.readBytes()
+	if err != nil {
+		return nil, err
+	}
+	reader.snapshotBlock = &ledger.SnapshotBlock{}
+	if err := reader.snapshotBlock.Deserialize(buf); err != nil {
+		return nil, errors.New("Deserialize snapshot block failed, error is " + err.Error())
+	}
+	return reader, nil
+}
+
+func (reader *Reader) Version() uint32 {
+	return reader.version
+}
+
+func (reader *Reader) SnapshotBlock() *ledger.SnapshotBlock {
+	return reader.snapshotBlock
+}
+
+// Next returns the next record, io.EOF is returned after the trailer has been read and the checksum is matched.
+func (reader *Reader) Next() (*Record, error) {
+	if reader.finished {
+		return nil, io.EOF
+	}
+
+	section, err := reader.read(1)
+	if err != nil {
+		return nil, err
+	}
+
+	var record *Record
+	switch section[0] {
+	case SECTION_TRIE_NODE:
+		record, err = reader.readTrieNode()
+	case SECTION_REF_VALUE:
+		record, err = reader.readRefValue()
+	case SECTION_ACCOUNT:
+		record, err = reader.readAccount()
+	case SECTION_END:
+		return nil, reader.readTrailer()
+	default:
+		return nil, errors.New(fmt.Sprintf("unknown section %d", section[0]))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reader.recordCount++
+	return record, nil
+}
+
+func (reader *Reader) readTrieNode() (*Record, error) {
+	buf, err := reader.readBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	node := &trie.TrieNode{}
+	if err := node.DbDeserialize(buf); err != nil {
+		return nil, errors.New("DbDeserialize trie node failed, error is " + err.Error())
+	}
+	return &Record{
+		Section:  SECTION_TRIE_NODE,
+		TrieNode: node,
+	}, nil
+}
+
+func (reader *Reader) readRefValue() (*Record, error) {
+	value, err := reader.readBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	refHash, _ := types.BytesToHash(crypto.Hash256(value))
+	return &Record{
+		Section:  SECTION_REF_VALUE,
+		RefHash:  refHash,
+		RefValue: value,
+	}, nil
+}
+
+func (reader *Reader) readAccount() (*Record, error) {
+	addrBytes, err := reader.read(types.AddressSize)
+	if err != nil {
+		return nil, err
+	}
+
+	account := &AccountState{}
+	account.Address, _ = types.BytesToAddress(addrBytes)
+
+	if account.PublicKey, err = reader.readBytes(); err != nil {
+		return nil, err
+	}
+	if len(account.PublicKey) == 0 {
+		account.PublicKey = nil
+	}
+
+	if account.LatestBlock, err = reader.readAccountBlock(); err != nil {
+		return nil, err
+	}
+
+	if account.Blocks, err = reader.readAccountBlocks(); err != nil {
+		return nil, err
+	}
+	if account.OnRoadBlocks, err = reader.readAccountBlocks(); err != nil {
+		return nil, err
+	}
+
+	return &Record{
+		Section: SECTION_ACCOUNT,
+		Account: account,
+	}, nil
+}
+
+func (reader *Reader) readAccountBlocks() ([]*ledger.AccountBlock, error) {
+	count, err := reader.readUint32()
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []*ledger.AccountBlock
+	for i := uint32(0); i < count; i++ {
+		block, err := reader.readAccountBlock()
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
+
+func (reader *Reader) readAccountBlock() (*ledger.AccountBlock, error) {
+	buf, err := reader.readBytes()
+	if err != nil {
+		return nil, err
+	}
+
+	block := &ledger.AccountBlock{}
+	if err := block.Deserialize(buf); err != nil {
+		return nil, errors.New("Deserialize account block failed, error is " + err.Error())
+	}
+
+	// the state hash is not serialized for the network
+	stateHashBytes, err := reader.read(types.HashSize)
+	if err != nil {
+		return nil, err
+	}
+	block.StateHash, _ = types.BytesToHash(stateHashBytes)
+	return block, nil
+}
+
+func (reader *Reader) readTrailer() error {
+	buf, err := reader.read(8)
+	if err != nil {
+		return err
+	}
+	if recordCount := binary.BigEndian.Uint64(buf); recordCount != reader.recordCount {
+		return errors.New(fmt.Sprintf("state snapshot has %d records, trailer says %d", reader.recordCount, recordCount))
+	}
+
+	sum := reader.hash.Sum(nil)
+	checksum := make([]byte, len(sum))
+	if _, err := io.ReadFull(reader.r, checksum); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if !bytes.Equal(checksum, sum) {
+		return ErrChecksumMismatch
+	}
+
+	reader.finished = true
+	return io.EOF
+}
+
+func (reader *Reader) readBytes() ([]byte, error) {
+	size, err := reader.readUint32()
+	if err != nil {
+		return nil, err
+	}
+	if size > MAX_RECORD_SIZE {
+		return nil, errors.New(fmt.Sprintf("record size %d is too large", size))
+	}
+	return reader.read(int(size))
+}
+
+func (reader *Reader) readUint32() (uint32, error) {
+	buf, err := reader.read(4)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(buf), nil
+}
+
+func (reader *Reader) read(size int) ([]byte, error) {
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(reader.r, buf); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	reader.hash.Write(buf)
+	return buf, nil
+}
+
+func appendAccountBlock(buf []byte, block *ledger.AccountBlock) ([]byte, error) {
+	blockBytes, err := block.Serialize()
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Serialize account block %s failed, error is %s", block.Hash, err.Error()))
+	}
+	buf = appendBytes(buf, blockBytes)
+	return append(buf, block.StateHash.Bytes()...), nil
+}
+
+func appendBytes(buf []byte, data []byte) []byte {
+	buf = appendUint32(buf, uint32(len(data)))
+	return append(buf, data...)
+}
+
+func appendUint32(buf []byte, n uint32) []byte {
+	nBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(nBytes, n)
+	return append(buf, nBytes...)
+}
+
+func appendUint64(buf []byte, n uint64) []byte {
+	nBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(nBytes, n)
+	return append(buf, nBytes...)
+}
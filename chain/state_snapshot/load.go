@@ -0,0 +1,456 @@
+package state_snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/trie"
+	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
+)
+
+type BootstrapChain interface {
+	InsertStateSnapshot(snapshotBlock *ledger.SnapshotBlock, trieNodes []*trie.TrieNode, refValueMap map[types.Hash][]byte,
+		accountList []*ledger.Account, blocks []*ledger.AccountBlock, onRoadBlocks []*ledger.AccountBlock) error
+}
+
+// StateSnapshot is a verified state snapshot, only the nodes reachable from the state hash are kept.
+type StateSnapshot struct {
+	SnapshotBlock *ledger.SnapshotBlock
+	TrieNodes     []*trie.TrieNode
+	RefValueMap   map[types.Hash][]byte
+	Accounts      []*AccountState
+}
+
+type loader struct {
+	nodeMap     map[types.Hash]*trie.TrieNode
+	refValueMap map[types.Hash][]byte
+
+	reachedNodes []*trie.TrieNode
+	reachedRefs  map[types.Hash][]byte
+	visited      map[types.Hash]struct{}
+
+	// set after all tries are verified
+	snapshotBlock *ledger.SnapshotBlock
+	stateHashMap  map[types.Address]types.Hash
+	producerSet   map[types.Address]struct{}
+}
+
+// Load reads the whole state snapshot and verifies it. The snapshot block must be the block of trustedHash,
+// all tries must be complete and every account must match its state hash in the snapshot trie.
+func Load(r io.Reader, trustedHash types.Hash) (*StateSnapshot, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotBlock := reader.SnapshotBlock()
+	if snapshotBlock.Hash != trustedHash {
+		return nil, errors.New(fmt.Sprintf("snapshot block of the state snapshot is %s, but the trusted hash is %s", snapshotBlock.Hash, trustedHash))
+	}
+	if computedHash := snapshotBlock.ComputeHash(); computedHash != snapshotBlock.Hash {
+		return nil, errors.New(fmt.Sprintf("snapshot block hash is %s, but the computed hash is %s", snapshotBlock.Hash, computedHash))
+	}
+	if !snapshotBlock.VerifySignature() {
+		return nil, errors.New(fmt.Sprintf("signature of snapshot block %s is invalid", snapshotBlock.Hash))
+	}
+
+	l := &loader{
+		nodeMap:     make(map[types.Hash]*trie.TrieNode),
+		refValueMap: make(map[types.Hash][]byte),
+		reachedRefs: make(map[types.Hash][]byte),
+		visited:     make(map[types.Hash]struct{}),
+	}
+
+	var accounts []*AccountState
+	for {
+		record, err := reader.Next()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+
+		switch record.Section {
+		case SECTION_TRIE_NODE:
+			l.nodeMap[*record.TrieNode.Hash()] = record.TrieNode
+		case SECTION_REF_VALUE:
+			l.refValueMap[record.RefHash] = record.RefValue
+		case SECTION_ACCOUNT:
+			accounts = append(accounts, record.Account)
+		}
+	}
+
+	// the snapshot trie is walked without skipping, every address is needed
+	stateHashMap := make(map[types.Address]types.Hash)
+	if err := l.walk(snapshotBlock.StateHash, nil, trie.TRIE_UNKNOW_NODE, false, false, func(key []byte, value []byte) error {
+		addr, err := types.BytesToAddress(key)
+		if err != nil {
+			return errors.New(fmt.Sprintf("key %x of the snapshot trie is not an address", key))
+		}
+		if stateHashMap[addr], err = types.BytesToHash(value); err != nil {
+			return errors.New(fmt.Sprintf("state hash %x of %s is invalid", value, addr))
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	if len(accounts) != len(stateHashMap) {
+		return nil, errors.New(fmt.Sprintf("state snapshot has %d accounts, but the snapshot trie has %d", len(accounts), len(stateHashMap)))
+	}
+	// all tries are verified before the accounts, the producers of blocks are checked against the storage of contracts
+	for _, account := range accounts {
+		stateHash, ok := stateHashMap[account.Address]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("account %s is not in the snapshot trie", account.Address))
+		}
+		if err := l.walk(stateHash, nil, trie.TRIE_UNKNOW_NODE, false, true, nil); err != nil {
+			return nil, errors.New(fmt.Sprintf("verify state trie of %s failed, error is %s", account.Address, err.Error()))
+		}
+	}
+
+	l.snapshotBlock = snapshotBlock
+	l.stateHashMap = stateHashMap
+	for _, account := range accounts {
+		if err := l.verifyAccount(account); err != nil {
+			return nil, errors.New(fmt.Sprintf("verify account %s failed, error is %s", account.Address, err.Error()))
+		}
+	}
+
+	return &StateSnapshot{
+		SnapshotBlock: snapshotBlock,
+		TrieNodes:     l.reachedNodes,
+		RefValueMap:   l.reachedRefs,
+		Accounts:      accounts,
+	}, nil
+}
+
+// verifyAccount checks the blocks of account against the verified state. Every signed block must be signed by a
+// legal producer. An on road block can be received and credited to others, so it must be on the chain of the
+// account below the latest block, and a signed block or the snapshot block must confirm that part of the chain.
+func (l *loader) verifyAccount(account *AccountState) error {
+	stateHash := l.stateHashMap[account.Address]
+	latestBlock := account.LatestBlock
+	if latestBlock.AccountAddress != account.Address {
+		return errors.New(fmt.Sprintf("latest block %s belongs to %s", latestBlock.Hash, latestBlock.AccountAddress))
+	}
+	if latestBlock.StateHash != stateHash {
+		return errors.New(fmt.Sprintf("state hash of the latest block is %s, but %s in the snapshot trie", latestBlock.StateHash, stateHash))
+	}
+	if hashHeight, ok := l.snapshotBlock.SnapshotContent[account.Address]; ok &&
+		(hashHeight.Hash != latestBlock.Hash || hashHeight.Height != latestBlock.Height) {
+		return errors.New(fmt.Sprintf("latest block %s is not the block %s in the snapshot block", latestBlock.Hash, hashHeight.Hash))
+	}
+	// the public key of a multisig account is the keys of the signers of its first block
+	if len(account.PublicKey) > 0 && !ledger.IsMultiSigPublicKey(account.PublicKey) &&
+		types.PubkeyToAddress(account.PublicKey) != account.Address {
+		return errors.New("public key is not matched")
+	}
+
+	for _, block := range account.OnRoadBlocks {
+		if !block.IsSendBlock() || block.AccountAddress != account.Address {
+			return errors.New(fmt.Sprintf("on road block %s is not a send block of the account", block.Hash))
+		}
+	}
+
+	for _, block := range allBlocks(account) {
+		if computedHash := block.ComputeHash(); computedHash != block.Hash {
+			return errors.New(fmt.Sprintf("block hash is %s, but the computed hash is %s", block.Hash, computedHash))
+		}
+		if _, ok := l.stateHashMap[block.AccountAddress]; !ok {
+			return errors.New(fmt.Sprintf("block %s belongs to %s, which is not in the snapshot trie", block.Hash, block.AccountAddress))
+		}
+		if block.AccountAddress == account.Address && block.Height > latestBlock.Height {
+			return errors.New(fmt.Sprintf("block %s is higher than the latest block", block.Hash))
+		}
+		// only the send blocks made by vm are not signed
+		if len(block.Signature) == 0 {
+			continue
+		}
+		if !block.VerifySignature() {
+			return errors.New(fmt.Sprintf("signature of block %s is invalid", block.Hash))
+		}
+		if err := l.verifyProducer(block); err != nil {
+			return errors.New(fmt.Sprintf("producer of block %s is illegal, error is %s", block.Hash, err.Error()))
+		}
+	}
+
+	return l.verifyOnRoadBlocks(account)
+}
+
+// verifyProducer checks the signer of a block like the verifier does, but the key set of a multisig address and
+// the producers of contracts are read from the verified state.
+func (l *loader) verifyProducer(block *ledger.AccountBlock) error {
+	if !block.IsMultiSigned() && types.PubkeyToAddress(block.PublicKey) == block.AccountAddress {
+		return nil
+	}
+
+	if info := cabi.GetMultiSigInfo(l, block.AccountAddress); info != nil {
+		pubkeys, err := block.SignerPublicKeys()
+		if err != nil {
+			return err
+		}
+		if len(pubkeys) < int(info.Threshold) {
+			return errors.New("signer count is less than the threshold of the multisig address")
+		}
+		for _, pubkey := range pubkeys {
+			if !info.IsMultiSigKey(pubkey) {
+				return errors.New("signer is not in the key set of the multisig address")
+			}
+		}
+		return nil
+	}
+
+	if block.IsReceiveBlock() && !block.IsMultiSigned() {
+		producers, err := l.producers()
+		if err != nil {
+			return err
+		}
+		if _, ok := producers[types.PubkeyToAddress(block.PublicKey)]; ok {
+			return nil
+		}
+	}
+	return errors.New("publicKey doesn't match with the accountAddress")
+}
+
+// producers returns the node addresses which are or were registered in any consensus group, the receive blocks
+// of contracts are signed by them.
+func (l *loader) producers() (map[types.Address]struct{}, error) {
+	if l.producerSet != nil {
+		return l.producerSet, nil
+	}
+	l.producerSet = make(map[types.Address]struct{})
+	stateHash, ok := l.stateHashMap[types.AddressRegister]
+	if !ok {
+		return l.producerSet, nil
+	}
+	err := l.walk(stateHash, nil, trie.TRIE_UNKNOW_NODE, false, false, func(key []byte, value []byte) error {
+		if !cabi.IsRegisterKey(key) {
+			return nil
+		}
+		registration := new(types.Registration)
+		if err := cabi.ABIRegister.UnpackVariable(registration, cabi.VariableNameRegistration, value); err != nil {
+			return nil
+		}
+		l.producerSet[registration.NodeAddr] = struct{}{}
+		for _, addr := range registration.HisAddrList {
+			l.producerSet[addr] = struct{}{}
+		}
+		return nil
+	})
+	return l.producerSet, err
+}
+
+// verifyOnRoadBlocks follows the prev hashes from the latest block, every on road block must be reached, and a
+// signed block or the snapshot block must confirm it, the send blocks made by vm are confirmed by the blocks above.
+func (l *loader) verifyOnRoadBlocks(account *AccountState) error {
+	if len(account.OnRoadBlocks) == 0 {
+		return nil
+	}
+
+	blockMap := make(map[types.Hash]*ledger.AccountBlock)
+	for _, block := range allBlocks(account) {
+		if block.AccountAddress == account.Address {
+			blockMap[block.Hash] = block
+		}
+	}
+
+	latestBlock := account.LatestBlock
+	_, confirmed := l.snapshotBlock.SnapshotContent[account.Address]
+	confirmedMap := make(map[types.Hash]bool)
+	for block := latestBlock; block != nil; block = blockMap[block.PrevHash] {
+		if len(block.Signature) > 0 {
+			confirmed = true
+		}
+		confirmedMap[block.Hash] = confirmed
+		if block.Height <= 1 {
+			break
+		}
+	}
+
+	for _, block := range account.OnRoadBlocks {
+		confirmed, ok := confirmedMap[block.Hash]
+		if !ok {
+			return errors.New(fmt.Sprintf("on road block %s is not on the chain below the latest block", block.Hash))
+		}
+		if !confirmed {
+			return errors.New(fmt.Sprintf("on road block %s is not confirmed by a signed block or the snapshot block", block.Hash))
+		}
+	}
+	return nil
+}
+
+// GetStorageBySnapshotHash reads the verified state at the snapshot block, snapshotHash is ignored.
+func (l *loader) GetStorageBySnapshotHash(addr *types.Address, key []byte, snapshotHash *types.Hash) []byte {
+	stateHash, ok := l.stateHashMap[*addr]
+	if !ok {
+		return nil
+	}
+	return l.getValue(stateHash, key)
+}
+
+// NewStorageIteratorBySnapshotHash iterates the verified state at the snapshot block, snapshotHash is ignored.
+func (l *loader) NewStorageIteratorBySnapshotHash(addr *types.Address, prefix []byte, snapshotHash *types.Hash) vmctxt_interface.StorageIterator {
+	stateHash, ok := l.stateHashMap[*addr]
+	if !ok {
+		return nil
+	}
+	iter := &storageIterator{}
+	l.walk(stateHash, nil, trie.TRIE_UNKNOW_NODE, false, false, func(key []byte, value []byte) error {
+		if bytes.HasPrefix(key, prefix) {
+			iter.keys = append(iter.keys, key)
+			iter.values = append(iter.values, value)
+		}
+		return nil
+	})
+	return iter
+}
+
+type storageIterator struct {
+	keys   [][]byte
+	values [][]byte
+}
+
+func (iter *storageIterator) Next() (key, value []byte, ok bool) {
+	if len(iter.keys) == 0 {
+		return nil, nil, false
+	}
+	key, value = iter.keys[0], iter.values[0]
+	iter.keys, iter.values = iter.keys[1:], iter.values[1:]
+	return key, value, true
+}
+
+// getValue looks up key in the verified trie of hash like trie.GetValue.
+func (l *loader) getValue(hash types.Hash, key []byte) []byte {
+	node, ok := l.nodeMap[hash]
+	if !ok {
+		return nil
+	}
+	switch node.NodeType() {
+	case trie.TRIE_FULL_NODE:
+		if len(key) == 0 {
+			if node.Child() == nil {
+				return nil
+			}
+			return l.getValue(*node.Child().Hash(), nil)
+		}
+		child, ok := node.Children()[key[0]]
+		if !ok || child == nil {
+			return nil
+		}
+		return l.getValue(*child.Hash(), key[1:])
+	case trie.TRIE_SHORT_NODE:
+		if !bytes.HasPrefix(key, node.Key()) {
+			return nil
+		}
+		return l.getValue(*node.Child().Hash(), key[len(node.Key()):])
+	case trie.TRIE_VALUE_NODE:
+		if len(key) == 0 {
+			return node.Value()
+		}
+	case trie.TRIE_HASH_NODE:
+		if len(key) == 0 {
+			if refHash, err := types.BytesToHash(node.Value()); err == nil {
+				return l.refValueMap[refHash]
+			}
+		}
+	}
+	return nil
+}
+
+// walk checks that every node reachable from hash is in the state snapshot and calls onLeaf with the key
+// and value of each leaf. Sub tries which have been walked are skipped if skipVisited is true. Every node is
+// checked against its position, see trie.CheckNodeType, a node of the same hash is checked at each position.
+func (l *loader) walk(hash types.Hash, key []byte, parentType byte, isValueChild bool, skipVisited bool, onLeaf func(key []byte, value []byte) error) error {
+	node, ok := l.nodeMap[hash]
+	if !ok {
+		return errors.New(fmt.Sprintf("trie node %s is missing", hash))
+	}
+	if err := trie.CheckNodeType(node, parentType, isValueChild); err != nil {
+		return errors.New(fmt.Sprintf("trie node %s: %s", hash, err.Error()))
+	}
+
+	if _, ok := l.visited[hash]; ok {
+		if skipVisited {
+			return nil
+		}
+	} else {
+		l.visited[hash] = struct{}{}
+		l.reachedNodes = append(l.reachedNodes, node)
+	}
+
+	switch node.NodeType() {
+	case trie.TRIE_FULL_NODE:
+		if node.Child() != nil {
+			if err := l.walk(*node.Child().Hash(), key, trie.TRIE_FULL_NODE, true, skipVisited, onLeaf); err != nil {
+				return err
+			}
+		}
+		for k, child := range node.Children() {
+			if err := l.walk(*child.Hash(), append(append([]byte{}, key...), k), trie.TRIE_FULL_NODE, false, skipVisited, onLeaf); err != nil {
+				return err
+			}
+		}
+	case trie.TRIE_SHORT_NODE:
+		return l.walk(*node.Child().Hash(), append(append([]byte{}, key...), node.Key()...), trie.TRIE_SHORT_NODE, false, skipVisited, onLeaf)
+	case trie.TRIE_VALUE_NODE:
+		if onLeaf != nil {
+			return onLeaf(key, node.Value())
+		}
+	case trie.TRIE_HASH_NODE:
+		refHash, err := types.BytesToHash(node.Value())
+		if err != nil {
+			return err
+		}
+		value, ok := l.refValueMap[refHash]
+		if !ok {
+			return errors.New(fmt.Sprintf("ref value %s is missing", refHash))
+		}
+		l.reachedRefs[refHash] = value
+		if onLeaf != nil {
+			return onLeaf(key, value)
+		}
+	default:
+		return errors.New(fmt.Sprintf("trie node %s has unknown type %d", hash, node.NodeType()))
+	}
+	return nil
+}
+
+// Bootstrap writes the verified state snapshot into a chain which only has the genesis blocks.
+func Bootstrap(chain BootstrapChain, snapshot *StateSnapshot) error {
+	accountList := make([]*ledger.Account, 0, len(snapshot.Accounts))
+	blocks := make([]*ledger.AccountBlock, 0, len(snapshot.Accounts))
+	var onRoadBlocks []*ledger.AccountBlock
+	blockSet := make(map[types.Hash]struct{})
+
+	for _, account := range snapshot.Accounts {
+		accountList = append(accountList, &ledger.Account{
+			AccountAddress: account.Address,
+			PublicKey:      account.PublicKey,
+		})
+
+		for _, block := range allBlocks(account) {
+			if _, ok := blockSet[block.Hash]; ok {
+				continue
+			}
+			blockSet[block.Hash] = struct{}{}
+			blocks = append(blocks, block)
+		}
+		onRoadBlocks = append(onRoadBlocks, account.OnRoadBlocks...)
+	}
+
+	return chain.InsertStateSnapshot(snapshot.SnapshotBlock, snapshot.TrieNodes, snapshot.RefValueMap, accountList, blocks, onRoadBlocks)
+}
+
+func allBlocks(account *AccountState) []*ledger.AccountBlock {
+	blocks := make([]*ledger.AccountBlock, 0, 1+len(account.Blocks)+len(account.OnRoadBlocks))
+	blocks = append(blocks, account.LatestBlock)
+	blocks = append(blocks, account.Blocks...)
+	return append(blocks, account.OnRoadBlocks...)
+}
@@ -0,0 +1,374 @@
+package state_snapshot
+
+import (
+	"bytes"
+	"crypto/rand"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/trie"
+)
+
+func init() {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}})
+}
+
+type testChain struct {
+	db            *leveldb.DB
+	snapshotBlock *ledger.SnapshotBlock
+	accounts      map[types.Address]*ledger.Account
+	latestBlocks  map[types.Address]*ledger.AccountBlock
+	keys          map[types.Address]ed25519.PrivateKey
+	blocks        map[types.Address]map[uint64]*ledger.AccountBlock
+	onRoadBlocks  map[types.Address][]*ledger.AccountBlock
+	producerKey   ed25519.PrivateKey
+}
+
+func (c *testChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock { return c.snapshotBlock }
+func (c *testChain) GetSnapshotBlockByHeight(height uint64) (*ledger.SnapshotBlock, error) {
+	if height == c.snapshotBlock.Height {
+		return c.snapshotBlock, nil
+	}
+	return nil, nil
+}
+func (c *testChain) ShallowCheckStateTrie(stateHash *types.Hash) (bool, error) {
+	return trie.ShallowCheck(c.db, stateHash)
+}
+func (c *testChain) GetStateTrie(stateHash *types.Hash) *trie.Trie {
+	return trie.NewTrie(c.db, stateHash, nil)
+}
+func (c *testChain) TrieDb() *leveldb.DB { return c.db }
+func (c *testChain) GetAccount(address *types.Address) (*ledger.Account, error) {
+	return c.accounts[*address], nil
+}
+func (c *testChain) GetConfirmAccountBlock(snapshotHeight uint64, address *types.Address) (*ledger.AccountBlock, error) {
+	return c.latestBlocks[*address], nil
+}
+func (c *testChain) GetAccountBlockByHeight(addr *types.Address, height uint64) (*ledger.AccountBlock, error) {
+	return c.blocks[*addr][height], nil
+}
+func (c *testChain) GetAccountBlockByHash(blockHash *types.Hash) (*ledger.AccountBlock, error) {
+	return nil, nil
+}
+func (c *testChain) GetOnRoadBlocksBySendAccount(sendAccountAddress *types.Address, snapshotBlockHeight uint64) ([]*ledger.AccountBlock, error) {
+	return c.onRoadBlocks[*sendAccountAddress], nil
+}
+
+func signBlock(block *ledger.AccountBlock, key ed25519.PrivateKey) {
+	block.PublicKey = key.PubByte()
+	block.Hash = block.ComputeHash()
+	block.Signature = ed25519.Sign(key, block.Hash.Bytes())
+}
+
+// sendBlocks returns the chain of send blocks of addr from height 1 to the latest block, they are signed if key
+// isn't nil.
+func (c *testChain) sendBlocks(addr types.Address, key ed25519.PrivateKey) []*ledger.AccountBlock {
+	latestBlock := c.latestBlocks[addr]
+	blocks := make([]*ledger.AccountBlock, latestBlock.Height)
+	var prevHash types.Hash
+	for i := range blocks {
+		block := &ledger.AccountBlock{
+			BlockType:      ledger.BlockTypeSendCall,
+			Height:         uint64(i + 1),
+			PrevHash:       prevHash,
+			AccountAddress: addr,
+			ToAddress:      addr,
+			Amount:         big.NewInt(int64(i + 1)),
+			Fee:            big.NewInt(0),
+			TokenId:        ledger.ViteTokenId,
+			Timestamp:      latestBlock.Timestamp,
+			StateHash:      latestBlock.StateHash,
+		}
+		if key != nil {
+			signBlock(block, key)
+		} else {
+			block.Hash = block.ComputeHash()
+		}
+		blocks[i] = block
+		prevHash = block.Hash
+	}
+	return blocks
+}
+
+// setChain makes blocks the chain of their account, the lowest one is on road.
+func (c *testChain) setChain(blocks []*ledger.AccountBlock) {
+	addr := blocks[0].AccountAddress
+	c.blocks[addr] = make(map[uint64]*ledger.AccountBlock)
+	for _, block := range blocks {
+		c.blocks[addr][block.Height] = block
+	}
+	c.latestBlocks[addr] = blocks[len(blocks)-1]
+	c.onRoadBlocks[addr] = blocks[:1]
+}
+
+func newTestChain(t *testing.T) *testChain {
+	db, err := leveldb.Open(storage.NewMemStorage(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &testChain{
+		db:           db,
+		accounts:     make(map[types.Address]*ledger.Account),
+		latestBlocks: make(map[types.Address]*ledger.AccountBlock),
+		keys:         make(map[types.Address]ed25519.PrivateKey),
+		blocks:       make(map[types.Address]map[uint64]*ledger.AccountBlock),
+		onRoadBlocks: make(map[types.Address][]*ledger.AccountBlock),
+	}
+
+	batch := new(leveldb.Batch)
+	snapshotTrie := trie.NewTrie(db, nil, nil)
+	now := time.Unix(1546275661, 0)
+	for i := 0; i < 3; i++ {
+		addr, key, _ := types.CreateAddress()
+		c.keys[addr] = key
+
+		accountTrie := trie.NewTrie(db, nil, nil)
+		accountTrie.SetValue([]byte("small"), []byte{byte(i)})
+		// more than 32 bytes, saved as a hash node and a ref value
+		accountTrie.SetValue([]byte("large"), bytes.Repeat([]byte{byte(i)}, 64))
+		if _, err := accountTrie.Save(batch); err != nil {
+			t.Fatal(err)
+		}
+
+		block := &ledger.AccountBlock{
+			BlockType:      ledger.BlockTypeSendCall,
+			Height:         uint64(i + 1),
+			AccountAddress: addr,
+			ToAddress:      addr,
+			Amount:         big.NewInt(int64(i)),
+			Fee:            big.NewInt(0),
+			TokenId:        ledger.ViteTokenId,
+			Timestamp:      &now,
+			StateHash:      *accountTrie.Hash(),
+		}
+		signBlock(block, key)
+
+		c.accounts[addr] = &ledger.Account{AccountAddress: addr}
+		c.latestBlocks[addr] = block
+		snapshotTrie.SetValue(addr.Bytes(), accountTrie.Hash().Bytes())
+	}
+	if _, err := snapshotTrie.Save(batch); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Write(batch, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	publicKey, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	c.snapshotBlock = &ledger.SnapshotBlock{
+		Height:    10,
+		Timestamp: &now,
+		StateHash: *snapshotTrie.Hash(),
+		PublicKey: publicKey,
+	}
+	c.snapshotBlock.Hash = c.snapshotBlock.ComputeHash()
+	c.snapshotBlock.Signature = ed25519.Sign(privateKey, c.snapshotBlock.Hash.Bytes())
+	c.producerKey = privateKey
+	return c
+}
+
+type testBootstrapChain struct {
+	trieNodes   []*trie.TrieNode
+	accountList []*ledger.Account
+	blocks      []*ledger.AccountBlock
+}
+
+func (c *testBootstrapChain) InsertStateSnapshot(snapshotBlock *ledger.SnapshotBlock, trieNodes []*trie.TrieNode, refValueMap map[types.Hash][]byte,
+	accountList []*ledger.Account, blocks []*ledger.AccountBlock, onRoadBlocks []*ledger.AccountBlock) error {
+	c.trieNodes = trieNodes
+	c.accountList = accountList
+	c.blocks = blocks
+	return nil
+}
+
+func TestDumpAndLoad(t *testing.T) {
+	c := newTestChain(t)
+
+	buf := new(bytes.Buffer)
+	if err := Dump(c, buf, c.snapshotBlock.Height, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := Load(bytes.NewReader(buf.Bytes()), c.snapshotBlock.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Accounts) != 3 || len(snapshot.RefValueMap) != 3 {
+		t.Fatalf("%d accounts and %d ref values are loaded", len(snapshot.Accounts), len(snapshot.RefValueMap))
+	}
+
+	bootstrapChain := &testBootstrapChain{}
+	if err := Bootstrap(bootstrapChain, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if len(bootstrapChain.accountList) != 3 || len(bootstrapChain.blocks) != 3 {
+		t.Fatalf("%d accounts and %d blocks are bootstrapped", len(bootstrapChain.accountList), len(bootstrapChain.blocks))
+	}
+
+	// the loaded nodes rebuild the same tries
+	db, _ := leveldb.Open(storage.NewMemStorage(), nil)
+	batch := new(leveldb.Batch)
+	if err := trie.SaveNodes(batch, snapshot.TrieNodes, snapshot.RefValueMap); err != nil {
+		t.Fatal(err)
+	}
+	db.Write(batch, nil)
+	for addr, block := range c.latestBlocks {
+		stateTrie := trie.NewTrie(db, &block.StateHash, nil)
+		if !bytes.Equal(stateTrie.GetValue([]byte("large")), c.GetStateTrie(&block.StateHash).GetValue([]byte("large"))) {
+			t.Fatalf("state of %s is not matched", addr)
+		}
+	}
+
+	if _, err := Load(bytes.NewReader(buf.Bytes()), types.Hash{1}); err == nil {
+		t.Fatal("untrusted snapshot block should be rejected")
+	}
+}
+
+func TestLoadIncomplete(t *testing.T) {
+	c := newTestChain(t)
+
+	// drop the first account trie node
+	buf := new(bytes.Buffer)
+	writer, _ := NewWriter(buf, c.snapshotBlock)
+	d := &dumper{chain: c, writer: writer, writtenNodes: make(map[types.Hash]struct{})}
+	for _, block := range c.latestBlocks {
+		root := c.GetStateTrie(&block.StateHash).Root
+		d.writtenNodes[*root.Hash()] = struct{}{}
+		break
+	}
+	if _, err := d.dumpTrie(&c.snapshotBlock.StateHash); err != nil {
+		t.Fatal(err)
+	}
+	for addr, block := range c.latestBlocks {
+		if _, err := d.dumpTrie(&block.StateHash); err != nil {
+			t.Fatal(err)
+		}
+		writer.WriteAccount(&AccountState{Address: addr, LatestBlock: block})
+	}
+	writer.Close()
+
+	if _, err := Load(bytes.NewReader(buf.Bytes()), c.snapshotBlock.Hash); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("incomplete state trie should be rejected, error is %v", err)
+	}
+}
+
+func TestLoadForgedSubtree(t *testing.T) {
+	c := newTestChain(t)
+
+	// replace the first account trie with a value node holding the hash source of its root, which has the same hash
+	buf := new(bytes.Buffer)
+	writer, _ := NewWriter(buf, c.snapshotBlock)
+	d := &dumper{chain: c, writer: writer, writtenNodes: make(map[types.Hash]struct{})}
+	for _, block := range c.latestBlocks {
+		root := c.GetStateTrie(&block.StateHash).Root
+		if root.NodeType() != trie.TRIE_FULL_NODE {
+			t.Fatalf("root of the account trie is of type %d", root.NodeType())
+		}
+		source := []byte{trie.TRIE_FULL_NODE}
+		for _, k := range []byte("ls") {
+			source = append(append(source, k), root.Children()[k].Hash().Bytes()...)
+		}
+		forged := trie.NewValueNode(source)
+		if *forged.Hash() != *root.Hash() {
+			t.Fatalf("hash of the forged node is %s, expected %s", forged.Hash(), root.Hash())
+		}
+		writer.WriteTrieNode(forged)
+		d.writtenNodes[*root.Hash()] = struct{}{}
+		break
+	}
+	if _, err := d.dumpTrie(&c.snapshotBlock.StateHash); err != nil {
+		t.Fatal(err)
+	}
+	for addr, block := range c.latestBlocks {
+		if _, err := d.dumpTrie(&block.StateHash); err != nil {
+			t.Fatal(err)
+		}
+		writer.WriteAccount(&AccountState{Address: addr, LatestBlock: block})
+	}
+	writer.Close()
+
+	if _, err := Load(bytes.NewReader(buf.Bytes()), c.snapshotBlock.Hash); err == nil || !strings.Contains(err.Error(), trie.ErrNodeType.Error()) {
+		t.Fatalf("forged state trie should be rejected, error is %v", err)
+	}
+}
+
+func dumpAndLoad(t *testing.T, c *testChain) error {
+	buf := new(bytes.Buffer)
+	if err := Dump(c, buf, c.snapshotBlock.Height, nil); err != nil {
+		t.Fatal(err)
+	}
+	_, err := Load(bytes.NewReader(buf.Bytes()), c.snapshotBlock.Hash)
+	return err
+}
+
+func TestLoadOnRoadBlocks(t *testing.T) {
+	c := newTestChain(t)
+	var addr types.Address
+	for addr = range c.latestBlocks {
+		if c.latestBlocks[addr].Height == 3 {
+			break
+		}
+	}
+
+	// the blocks between the on road block and the latest block are dumped
+	c.setChain(c.sendBlocks(addr, c.keys[addr]))
+	if err := dumpAndLoad(t, c); err != nil {
+		t.Fatal(err)
+	}
+
+	// blocks signed by others
+	_, otherKey, _ := types.CreateAddress()
+	c.setChain(c.sendBlocks(addr, otherKey))
+	if err := dumpAndLoad(t, c); err == nil || !strings.Contains(err.Error(), "producer") {
+		t.Fatalf("blocks signed by others should be rejected, error is %v", err)
+	}
+
+	// an unsigned on road block which isn't on the chain of the account
+	blocks := c.sendBlocks(addr, c.keys[addr])
+	c.setChain(blocks)
+	forged := c.sendBlocks(addr, nil)[0]
+	forged.Amount = big.NewInt(1e18)
+	forged.Hash = forged.ComputeHash()
+	c.onRoadBlocks[addr] = []*ledger.AccountBlock{forged}
+	if err := dumpAndLoad(t, c); err == nil || !strings.Contains(err.Error(), "not on the chain") {
+		t.Fatalf("on road block out of the chain should be rejected, error is %v", err)
+	}
+
+	// unsigned blocks which no signed block confirms
+	c.setChain(c.sendBlocks(addr, nil))
+	if err := dumpAndLoad(t, c); err == nil || !strings.Contains(err.Error(), "not confirmed") {
+		t.Fatalf("unconfirmed on road block should be rejected, error is %v", err)
+	}
+
+	// the snapshot block confirms the latest block
+	latestBlock := c.latestBlocks[addr]
+	c.snapshotBlock.SnapshotContent = ledger.SnapshotContent{
+		addr: &ledger.HashHeight{Hash: latestBlock.Hash, Height: latestBlock.Height},
+	}
+	c.snapshotBlock.Hash = c.snapshotBlock.ComputeHash()
+	c.snapshotBlock.Signature = ed25519.Sign(c.producerKey, c.snapshotBlock.Hash.Bytes())
+	if err := dumpAndLoad(t, c); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFilename(t *testing.T) {
+	hash := types.Hash{1, 2, 3}
+	if parsed, ok := ParseFilename(Filename(hash)); !ok || parsed != hash {
+		t.Fatalf("parse %s failed", Filename(hash))
+	}
+	for _, name := range []string{"state_.snapshot", "../" + Filename(hash), strings.ToUpper(Filename(hash)), "ledger_1"} {
+		if _, ok := ParseFilename(name); ok {
+			t.Fatalf("%s is not a state snapshot name", name)
+		}
+	}
+}
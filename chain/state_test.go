@@ -1,8 +1,17 @@
 package chain
 
 import (
+	"bytes"
 	"fmt"
+	"math/big"
 	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/chain/trie_gc"
+	"github.com/vitelabs/go-vite/chain_db/database"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm_context"
 )
 
 func TestNewStateTrie(t *testing.T) {
@@ -10,3 +19,139 @@ func TestNewStateTrie(t *testing.T) {
 	newTrie := chainInstance.NewStateTrie()
 	fmt.Printf("%+v\n", newTrie)
 }
+
+// insertStateBlock inserts a block of addr which sets its balance and storage, then snapshots it.
+func insertStateBlock(t *testing.T, chainInstance Chain, addr types.Address, balance int64, storage []byte) *ledger.SnapshotBlock {
+	vmContext, err := vm_context.NewVmContext(chainInstance, nil, nil, &addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latestBlock, _ := chainInstance.GetLatestAccountBlock(&addr)
+	nextHeight := uint64(1)
+	var prevHash types.Hash
+	if latestBlock != nil {
+		nextHeight = latestBlock.Height + 1
+		prevHash = latestBlock.Hash
+	}
+
+	vmContext.SubBalance(&ledger.ViteTokenId, vmContext.GetBalance(&addr, &ledger.ViteTokenId))
+	vmContext.AddBalance(&ledger.ViteTokenId, big.NewInt(balance))
+	vmContext.SetStorage([]byte("key"), storage)
+
+	now := time.Now()
+	block := &ledger.AccountBlock{
+		PrevHash:       prevHash,
+		BlockType:      ledger.BlockTypeSendCall,
+		AccountAddress: addr,
+		ToAddress:      addr,
+		Amount:         big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		Height:         nextHeight,
+		Fee:            big.NewInt(0),
+		SnapshotHash:   chainInstance.GetLatestSnapshotBlock().Hash,
+		Timestamp:      &now,
+		StateHash:      *vmContext.GetStorageHash(),
+	}
+	block.Hash = block.ComputeHash()
+	if err := chainInstance.InsertAccountBlocks([]*vm_context.VmAccountBlock{{AccountBlock: block, VmContext: vmContext}}); err != nil {
+		t.Fatal(err)
+	}
+
+	return insertEmptySnapshotBlock(t, chainInstance)
+}
+
+func insertEmptySnapshotBlock(t *testing.T, chainInstance Chain) *ledger.SnapshotBlock {
+	latestBlock := chainInstance.GetLatestSnapshotBlock()
+	now := time.Now()
+	snapshotBlock := &ledger.SnapshotBlock{
+		Height:          latestBlock.Height + 1,
+		PrevHash:        latestBlock.Hash,
+		Timestamp:       &now,
+		SnapshotContent: chainInstance.GetNeedSnapshotContent(),
+	}
+	stateTrie, err := chainInstance.GenStateTrie(latestBlock.StateHash, snapshotBlock.SnapshotContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	snapshotBlock.StateTrie = stateTrie
+	snapshotBlock.StateHash = *stateTrie.Hash()
+	snapshotBlock.Hash = snapshotBlock.ComputeHash()
+	if err := chainInstance.InsertSnapshotBlock(snapshotBlock); err != nil {
+		t.Fatal(err)
+	}
+	return snapshotBlock
+}
+
+func TestGetAccountBalanceAt(t *testing.T) {
+	chainInstance, clean := newTempChain(t)
+	defer clean()
+
+	addr, _, _ := types.CreateAddress()
+	beforeHeight := chainInstance.GetLatestSnapshotBlock().Height
+	first := insertStateBlock(t, chainInstance, addr, 100, []byte{1})
+	second := insertStateBlock(t, chainInstance, addr, 50, []byte{2})
+
+	if block, balanceMap, err := chainInstance.GetAccountBalanceAt(&addr, beforeHeight); err != nil || block != nil || balanceMap != nil {
+		t.Fatalf("account has no block at %d, block %v, balance %v, error %v", beforeHeight, block, balanceMap, err)
+	}
+	for _, c := range []struct {
+		height  uint64
+		balance int64
+		storage []byte
+	}{{first.Height, 100, []byte{1}}, {second.Height, 50, []byte{2}}} {
+		block, balanceMap, err := chainInstance.GetAccountBalanceAt(&addr, c.height)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if balanceMap[ledger.ViteTokenId].Int64() != c.balance {
+			t.Fatalf("balance at %d is %s, want %d", c.height, balanceMap[ledger.ViteTokenId], c.balance)
+		}
+
+		_, stateTrie, err := chainInstance.GetConfirmedStateTrie(&addr, c.height)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *stateTrie.Hash() != block.StateHash || !bytes.Equal(stateTrie.GetValue([]byte("key")), c.storage) {
+			t.Fatalf("storage at %d is %v, want %v", c.height, stateTrie.GetValue([]byte("key")), c.storage)
+		}
+	}
+
+	if _, _, err := chainInstance.GetAccountBalanceAt(&addr, second.Height+1); err == nil {
+		t.Fatal("height higher than the latest snapshot block should fail")
+	}
+}
+
+func TestGetConfirmedStateTrie_Pruned(t *testing.T) {
+	chainInstance, clean := newTempChain(t)
+	defer clean()
+	c := chainInstance.(*chain)
+	// only the state of the latest 2 snapshot blocks is retained
+	c.trieGc = trie_gc.NewCollector(c, 2)
+
+	addr, _, _ := types.CreateAddress()
+	first := insertStateBlock(t, chainInstance, addr, 100, []byte{1})
+	block, _ := chainInstance.GetLatestAccountBlock(&addr)
+	insertStateBlock(t, chainInstance, addr, 50, []byte{2})
+	insertEmptySnapshotBlock(t, chainInstance)
+
+	// clear the state trie of the first block like trie gc does
+	dbKey, _ := database.EncodeKey(database.DBKP_TRIE_NODE, block.StateHash.Bytes())
+	if err := c.TrieDb().Delete(dbKey, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, err := chainInstance.GetAccountBalanceAt(&addr, first.Height)
+	pruned, ok := err.(*ErrStateTriePruned)
+	if !ok {
+		t.Fatalf("expect ErrStateTriePruned, got %v", err)
+	}
+	if pruned.SnapshotHeight != first.Height || pruned.RetainMinHeight != c.TrieGc().RetainMinHeight() {
+		t.Fatalf("unexpected error %v", pruned)
+	}
+
+	// the state of the latest blocks is still readable
+	if _, balanceMap, err := chainInstance.GetAccountBalanceAt(&addr, chainInstance.GetLatestSnapshotBlock().Height); err != nil ||
+		balanceMap[ledger.ViteTokenId].Int64() != 50 {
+		t.Fatalf("balance %v, error %v", balanceMap, err)
+	}
+}
@@ -278,6 +278,10 @@ func (ac *AccountChain) getConfirmHeight(accountBlockHash *types.Hash) (uint64,
 	if err != nil {
 		return 0, nil, err
 	}
+	// the block isn't in the chain, such as a simulated block
+	if accountBlockMeta == nil {
+		return 0, nil, nil
+	}
 	if accountBlockMeta.SnapshotHeight > 0 {
 		return accountBlockMeta.SnapshotHeight, accountBlockMeta, nil
 	}
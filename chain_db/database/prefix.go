@@ -36,4 +36,6 @@ const (
 	DBKP_BE_SNAPSHOT = byte(17)
 
 	DBKP_ADDITIONAL_LIST = byte(18)
+
+	DBKP_PRUNED_HEIGHT = byte(19)
 )
@@ -0,0 +1,19 @@
+package client
+
+import (
+	"context"
+
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/rpcapi/api"
+)
+
+// BatchSendClient calls the batchSend namespace.
+type BatchSendClient struct {
+	c *rpc.Client
+}
+
+func (b *BatchSendClient) GetBatchSendData(ctx context.Context, transfers []api.BatchTransferParam) ([]byte, error) {
+	var data []byte
+	err := b.c.CallContext(ctx, &data, "batchSend_getBatchSendData", transfers)
+	return data, err
+}
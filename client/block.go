@@ -0,0 +1,164 @@
+package client
+
+import (
+	"context"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/pow"
+	"github.com/vitelabs/go-vite/rpcapi/api"
+)
+
+// NewSendBlock returns a send block calling toAddr, the chain fields are filled by FillBlock.
+func NewSendBlock(addr types.Address, toAddr types.Address, tokenId types.TokenTypeId, amount *big.Int, data []byte) *ledger.AccountBlock {
+	return &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		AccountAddress: addr,
+		ToAddress:      toAddr,
+		TokenId:        tokenId,
+		Amount:         amount,
+		Fee:            big.NewInt(0),
+		Data:           data,
+	}
+}
+
+// NewReceiveBlock returns a block receiving the send block fromBlockHash, the chain fields are filled by FillBlock.
+func NewReceiveBlock(addr types.Address, fromBlockHash types.Hash) *ledger.AccountBlock {
+	return &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeReceive,
+		AccountAddress: addr,
+		FromBlockHash:  fromBlockHash,
+		Amount:         big.NewInt(0),
+		Fee:            big.NewInt(0),
+	}
+}
+
+// FillBlock sets the height and previous hash after the latest block of the account, the fittest snapshot hash and
+// the timestamp of the block.
+func (c *Client) FillBlock(ctx context.Context, block *ledger.AccountBlock) error {
+	latest, err := c.Ledger.GetLatestBlock(ctx, block.AccountAddress, false)
+	if err != nil {
+		return err
+	}
+	if latest == nil || latest.AccountBlock == nil {
+		block.Height = 1
+		block.PrevHash = types.Hash{}
+	} else {
+		height, err := strconv.ParseUint(latest.Height, 10, 64)
+		if err != nil {
+			return err
+		}
+		block.Height = height + 1
+		block.PrevHash = latest.Hash
+	}
+
+	var sendBlockHash *types.Hash
+	if block.IsReceiveBlock() {
+		sendBlockHash = &block.FromBlockHash
+	}
+	snapshotHash, err := c.Ledger.GetFittestSnapshotHash(ctx, block.AccountAddress, sendBlockHash)
+	if err != nil {
+		return err
+	}
+	if snapshotHash == nil {
+		return errors.New("fittest snapshot hash is nil")
+	}
+	block.SnapshotHash = *snapshotHash
+
+	now := time.Unix(time.Now().Unix(), 0)
+	block.Timestamp = &now
+	return nil
+}
+
+// PowBlock calculates the nonce of the block locally, the hash and signature must be computed after it.
+func PowBlock(block *ledger.AccountBlock, difficulty *big.Int) error {
+	nonce, err := pow.GetPowNonce(difficulty, types.DataHash(append(block.AccountAddress.Bytes(), block.PrevHash.Bytes()...)))
+	if err != nil {
+		return err
+	}
+	block.Nonce = nonce
+	block.Difficulty = difficulty
+	return nil
+}
+
+// SignBlock computes the hash of the block and signs it with the private key of the account.
+func SignBlock(block *ledger.AccountBlock, priv ed25519.PrivateKey) error {
+	if types.PubkeyToAddress(priv.PubByte()) != block.AccountAddress {
+		return errors.New("the private key doesn't belong to the account")
+	}
+	block.PublicKey = priv.PubByte()
+	block.Hash = block.ComputeHash()
+	block.Signature = ed25519.Sign(priv, block.Hash.Bytes())
+	return nil
+}
+
+// SignMultiSigBlock computes the hash of the block of a multisig account and signs it with the private key of a
+// signer, the signatures of the signers are combined by AddSignature of the block or multisig_combineSignatures.
+func SignMultiSigBlock(block *ledger.AccountBlock, priv ed25519.PrivateKey) *api.MultiSignature {
+	block.Hash = block.ComputeHash()
+	return &api.MultiSignature{
+		PublicKey: priv.PubByte(),
+		Signature: ed25519.Sign(priv, block.Hash.Bytes()),
+	}
+}
+
+// ToRpcBlock converts a ledger block to the block accepted by tx_sendRawTx.
+func ToRpcBlock(block *ledger.AccountBlock) *api.AccountBlock {
+	rpcBlock := &api.AccountBlock{
+		AccountBlock: block,
+		Height:       strconv.FormatUint(block.Height, 10),
+	}
+	if block.Amount != nil {
+		amount := block.Amount.String()
+		rpcBlock.Amount = &amount
+	}
+	if block.Fee != nil {
+		fee := block.Fee.String()
+		rpcBlock.Fee = &fee
+	}
+	if block.Difficulty != nil {
+		difficulty := block.Difficulty.String()
+		rpcBlock.Difficulty = &difficulty
+	}
+	if block.Timestamp != nil {
+		rpcBlock.Timestamp = block.Timestamp.Unix()
+	}
+	return rpcBlock
+}
+
+// SendBlock fills the block, calculates the PoW if the pledge quota of the account is not enough, signs and sends it
+// to the node.
+func (c *Client) SendBlock(ctx context.Context, block *ledger.AccountBlock, priv ed25519.PrivateKey) error {
+	if err := c.FillBlock(ctx, block); err != nil {
+		return err
+	}
+	param := api.CalcPoWDifficultyParam{
+		SelfAddr:       block.AccountAddress,
+		PrevHash:       block.PrevHash,
+		SnapshotHash:   block.SnapshotHash,
+		BlockType:      block.BlockType,
+		Data:           block.Data,
+		UsePledgeQuota: true,
+	}
+	if block.IsSendBlock() {
+		param.ToAddr = &block.ToAddress
+	}
+	difficulty, err := c.Tx.CalcPoWDifficulty(ctx, param)
+	if err != nil {
+		return err
+	}
+	if difficulty.Sign() > 0 {
+		if err := PowBlock(block, difficulty); err != nil {
+			return err
+		}
+	}
+	if err := SignBlock(block, priv); err != nil {
+		return err
+	}
+	return c.Tx.SendRawTx(ctx, ToRpcBlock(block))
+}
@@ -0,0 +1,71 @@
+// Package client provides typed methods for the public rpc apis of a node, and helpers to build, sign and send
+// account blocks.
+package client
+
+import (
+	"context"
+
+	"github.com/vitelabs/go-vite/rpc"
+)
+
+// Client calls the apis of a node, each namespace has its own typed client.
+type Client struct {
+	c *rpc.Client
+
+	Ledger    *LedgerClient
+	Onroad    *OnroadClient
+	Contract  *ContractClient
+	Register  *RegisterClient
+	Vote      *VoteClient
+	Pledge    *PledgeClient
+	Mintage   *MintageClient
+	BatchSend *BatchSendClient
+	Escrow    *EscrowClient
+	MultiSig  *MultiSigClient
+	Net       *NetClient
+	Tx        *TxClient
+	Pow       *PowClient
+	Subscribe *SubscribeClient
+}
+
+func Dial(rawurl string) (*Client, error) {
+	return DialContext(context.Background(), rawurl)
+}
+
+func DialContext(ctx context.Context, rawurl string) (*Client, error) {
+	c, err := rpc.DialContext(ctx, rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c), nil
+}
+
+// NewClient creates a client on a connected rpc client, like the one returned by rpc.DialInProc.
+func NewClient(c *rpc.Client) *Client {
+	return &Client{
+		c:         c,
+		Ledger:    &LedgerClient{c},
+		Onroad:    &OnroadClient{c},
+		Contract:  &ContractClient{c},
+		Register:  &RegisterClient{c},
+		Vote:      &VoteClient{c},
+		Pledge:    &PledgeClient{c},
+		Mintage:   &MintageClient{c},
+		BatchSend: &BatchSendClient{c},
+		Escrow:    &EscrowClient{c},
+		MultiSig:  &MultiSigClient{c},
+		Net:       &NetClient{c},
+		Tx:        &TxClient{c},
+		Pow:       &PowClient{c},
+		Subscribe: &SubscribeClient{c},
+	}
+}
+
+// RpcClient returns the underlying rpc client for the apis without typed methods.
+func (c *Client) RpcClient() *rpc.Client {
+	return c.c
+}
+
+func (c *Client) Close() {
+	c.c.Close()
+}
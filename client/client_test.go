@@ -0,0 +1,325 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/chain/unittest"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/rpcapi"
+	"github.com/vitelabs/go-vite/rpcapi/api/filters"
+	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/wallet"
+)
+
+// the modules of the namespaces called by the client, the private contract apis are served by in-process connections
+var clientModules = []string{"ledger", "public_onroad", "net", "contract", "private_contract", "pledge", "register",
+	"vote", "mintage", "batchSend", "escrow", "multisig", "tx", "pow", "subscribe"}
+
+// newTestVite creates a node on a temp chain whose genesis account is genesisAddr, the chain, the onroad blocks, the
+// consensus and the pool are started for the apis, the net is not.
+func newTestVite(t *testing.T, genesisAddr types.Address) (*vite.Vite, func()) {
+	dir, err := ioutil.TempDir("", "client")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genesis := chain_unittest.MakeChainConfig("")
+	genesis.GenesisAccountAddress = genesisAddr
+	v, err := vite.New(&config.Config{
+		DataDir:  dir,
+		Genesis:  genesis,
+		Producer: &config.Producer{},
+		Net:      &config.Net{Single: true},
+		// the test quota params need a small PoW difficulty
+		Vm: &config.Vm{IsUseVmTestParam: true},
+	}, wallet.New(&wallet.Config{DataDir: dir}))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	// started like Vite.Start, the onroad blocks of the genesis blocks are written when the chain starts
+	if err := v.Init(); err != nil {
+		t.Fatal(err)
+	}
+	v.OnRoad().Start()
+	v.Chain().Start()
+	if err := v.Consensus().Init(); err != nil {
+		t.Fatal(err)
+	}
+	v.Pool().Init(v.Net(), v.WalletManager(), v.SnapshotVerifier(), v.AccountVerifier())
+	v.Consensus().Start()
+	v.Pool().Start()
+
+	filters.Es = filters.NewEventSystem(v)
+	filters.Es.Start()
+
+	return v, func() {
+		filters.Es.Stop()
+		filters.Es = nil
+		v.Pool().Stop()
+		v.Consensus().Stop()
+		v.Chain().Stop()
+		v.OnRoad().Stop()
+		v.Chain().Destroy()
+		os.RemoveAll(dir)
+	}
+}
+
+// newTestClient registers the apis of the node like the in-process apis of a node.
+func newTestClient(t *testing.T, v *vite.Vite) *Client {
+	server := rpc.NewServer()
+	for _, api := range rpcapi.GetApis(v, clientModules...) {
+		if err := server.RegisterName(api.Namespace, api.Service); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewClient(rpc.DialInProc(server))
+}
+
+func TestClient_SendBlock(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := types.PubkeyToAddress(priv.PubByte())
+	v, closeVite := newTestVite(t, addr)
+	defer closeVite()
+	c := newTestClient(t, v)
+	defer c.Close()
+	ctx := context.Background()
+
+	ch := make(chan []*filters.AccountBlock, 10)
+	sub, err := c.Subscribe.SubscribeAccountBlocks(ctx, ch, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Unsubscribe()
+
+	height, err := c.Ledger.GetSnapshotChainHeight(ctx)
+	if err != nil || height != v.Chain().GetLatestSnapshotBlock().Height {
+		t.Fatalf("snapshot chain height %v, err %v", height, err)
+	}
+
+	// the genesis account receives the vite token minted for it
+	onroadBlocks, err := c.Onroad.GetOnroadBlocksByAddress(ctx, addr, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(onroadBlocks) != 1 || onroadBlocks[0].AccountAddress != types.AddressMintage {
+		t.Fatalf("onroad blocks are %+v", onroadBlocks)
+	}
+
+	// the first block of an account needs PoW
+	block := NewReceiveBlock(addr, onroadBlocks[0].Hash)
+	if err := c.SendBlock(ctx, block, priv); err != nil {
+		t.Fatal(err)
+	}
+	if block.Height != 1 || block.PrevHash != (types.Hash{}) || block.SnapshotHash.IsZero() ||
+		len(block.Nonce) == 0 || block.Difficulty.Sign() <= 0 {
+		t.Fatalf("unexpected block sent %+v", block)
+	}
+
+	latest, err := c.Ledger.GetLatestBlock(ctx, addr, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest == nil || latest.Hash != block.Hash || latest.FromBlockHash != onroadBlocks[0].Hash {
+		t.Fatalf("latest block is %+v", latest)
+	}
+
+	select {
+	case blocks := <-ch:
+		if len(blocks) != 1 || blocks[0].Hash != block.Hash || blocks[0].Removed {
+			t.Fatalf("unexpected blocks %+v", blocks)
+		}
+	case err := <-sub.Err():
+		t.Fatal(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no account blocks received")
+	}
+
+	_, other, _ := ed25519.GenerateKey(nil)
+	toAddr := types.PubkeyToAddress(other.PubByte())
+	// the block must be signed by the account
+	block = NewSendBlock(addr, toAddr, ledger.ViteTokenId, big.NewInt(1), nil)
+	if err := c.SendBlock(ctx, block, other); err == nil {
+		t.Fatal("block signed by another account is sent")
+	}
+}
+
+// signatureServer answers the calls of the client over http, it checks the method and the arguments against the
+// signature of the service registered by the node, and replies a value of the result type of the service.
+type signatureServer struct {
+	services map[string][]reflect.Type
+}
+
+type signatureRequest struct {
+	Id     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+func (s *signatureServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req signatureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.Id}
+	if result, err := s.call(req.Method, req.Params); err != nil {
+		resp["error"] = map[string]interface{}{"code": -32602, "message": err.Error()}
+	} else {
+		resp["result"] = result
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+func (s *signatureServer) call(name string, params []json.RawMessage) (interface{}, error) {
+	method, err := s.method(name)
+	if err != nil {
+		return nil, err
+	}
+
+	// the receiver and the context are not sent
+	var argTypes []reflect.Type
+	for i := 1; i < method.Type.NumIn(); i++ {
+		if argType := method.Type.In(i); argType != reflect.TypeOf((*context.Context)(nil)).Elem() {
+			argTypes = append(argTypes, argType)
+		}
+	}
+	if len(params) > len(argTypes) {
+		return nil, fmt.Errorf("%s has %d arguments, %d sent", name, len(argTypes), len(params))
+	}
+	for i, argType := range argTypes {
+		if i >= len(params) {
+			if argType.Kind() != reflect.Ptr {
+				return nil, fmt.Errorf("missing argument %d of %s", i, name)
+			}
+			continue
+		}
+		if err := json.Unmarshal(params[i], reflect.New(argType).Interface()); err != nil {
+			return nil, fmt.Errorf("invalid argument %d of %s: %v", i, name, err)
+		}
+	}
+
+	if method.Type.NumOut() == 0 || method.Type.Out(0) == reflect.TypeOf((*error)(nil)).Elem() {
+		return nil, nil
+	}
+	return sampleValue(method.Type.Out(0)).Interface(), nil
+}
+
+func (s *signatureServer) method(name string) (reflect.Method, error) {
+	for namespace, services := range s.services {
+		for _, service := range services {
+			for i := 0; i < service.NumMethod(); i++ {
+				method := service.Method(i)
+				if namespace+"_"+formatName(method.Name) == name {
+					return method, nil
+				}
+			}
+		}
+	}
+	return reflect.Method{}, fmt.Errorf("%s doesn't exist", name)
+}
+
+func formatName(name string) string {
+	return string(name[0]+'a'-'A') + name[1:]
+}
+
+// sampleValue returns a value which isn't null in json, so that the client decodes it into its result. The numbers
+// are sent as strings by the apis.
+func sampleValue(t reflect.Type) reflect.Value {
+	switch t.Kind() {
+	case reflect.String:
+		return reflect.ValueOf("0").Convert(t)
+	case reflect.Ptr:
+		return reflect.New(t.Elem())
+	case reflect.Slice:
+		value := reflect.MakeSlice(t, 1, 1)
+		value.Index(0).Set(sampleValue(t.Elem()))
+		return value
+	case reflect.Map:
+		return reflect.MakeMap(t)
+	default:
+		return reflect.Zero(t)
+	}
+}
+
+// TestClient_Signatures calls all methods of the typed clients, except the subscriptions which need a connection
+// supporting notifications, to check them against the services of the node.
+func TestClient_Signatures(t *testing.T) {
+	v, closeVite := newTestVite(t, types.Address{})
+	defer closeVite()
+
+	server := &signatureServer{services: make(map[string][]reflect.Type)}
+	for _, api := range rpcapi.GetApis(v, clientModules...) {
+		server.services[api.Namespace] = append(server.services[api.Namespace], reflect.TypeOf(api.Service))
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	rpcClient, err := rpc.DialHTTP(httpServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewClient(rpcClient)
+	defer c.Close()
+
+	count := 0
+	clients := reflect.ValueOf(c).Elem()
+	for i := 0; i < clients.NumField(); i++ {
+		field := clients.Field(i)
+		if !field.CanInterface() || field.Kind() != reflect.Ptr || field.Type() == reflect.TypeOf(rpcClient) {
+			continue
+		}
+		for j := 0; j < field.NumMethod(); j++ {
+			method := field.Type().Method(j)
+			args, ok := sampleArgs(method.Type)
+			if !ok {
+				continue
+			}
+			out := field.Method(j).Call(args)
+			if err, _ := out[len(out)-1].Interface().(error); err != nil {
+				t.Errorf("%s.%s: %v", clients.Type().Field(i).Name, method.Name, err)
+			}
+			count++
+		}
+	}
+	if count == 0 {
+		t.Fatal("no client methods are checked")
+	}
+}
+
+// sampleArgs returns the arguments of a client method, the methods taking a channel are not supported.
+func sampleArgs(methodType reflect.Type) ([]reflect.Value, bool) {
+	var args []reflect.Value
+	for i := 1; i < methodType.NumIn(); i++ {
+		argType := methodType.In(i)
+		switch {
+		case argType == reflect.TypeOf((*context.Context)(nil)).Elem():
+			args = append(args, reflect.ValueOf(context.Background()))
+		case argType.Kind() == reflect.Chan:
+			return nil, false
+		default:
+			args = append(args, sampleValue(argType))
+		}
+	}
+	return args, true
+}
@@ -0,0 +1,74 @@
+package client
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/rpcapi/api"
+)
+
+// ContractClient calls the contract namespace.
+type ContractClient struct {
+	c *rpc.Client
+}
+
+func (c *ContractClient) GetCreateContractToAddress(ctx context.Context, selfAddr types.Address, height uint64, prevHash types.Hash, snapshotHash types.Hash) (*types.Address, error) {
+	var addr *types.Address
+	err := c.c.CallContext(ctx, &addr, "contract_getCreateContractToAddress", selfAddr, strconv.FormatUint(height, 10), prevHash, snapshotHash)
+	return addr, err
+}
+
+func (c *ContractClient) GetCreateContractData(ctx context.Context, gid types.Gid, hexCode string, abiStr string, params []string) ([]byte, error) {
+	var data []byte
+	err := c.c.CallContext(ctx, &data, "contract_getCreateContractData", gid, hexCode, abiStr, params)
+	return data, err
+}
+
+func (c *ContractClient) GetCallContractData(ctx context.Context, abiStr string, methodName string, params []string) ([]byte, error) {
+	var data []byte
+	err := c.c.CallContext(ctx, &data, "contract_getCallContractData", abiStr, methodName, params)
+	return data, err
+}
+
+func (c *ContractClient) GetCallOffChainData(ctx context.Context, abiStr string, offChainName string, params []string) ([]byte, error) {
+	var data []byte
+	err := c.c.CallContext(ctx, &data, "contract_getCallOffChainData", abiStr, offChainName, params)
+	return data, err
+}
+
+func (c *ContractClient) CallOffChainMethod(ctx context.Context, param api.CallOffChainMethodParam) ([]byte, error) {
+	var data []byte
+	err := c.c.CallContext(ctx, &data, "contract_callOffChainMethod", param)
+	return data, err
+}
+
+func (c *ContractClient) CallOffChainMethodAt(ctx context.Context, param api.CallOffChainMethodParam, snapshotHeight uint64) ([]byte, error) {
+	var data []byte
+	err := c.c.CallContext(ctx, &data, "contract_callOffChainMethodAt", param, snapshotHeight)
+	return data, err
+}
+
+// GetStorageAt returns the hex value of the hex key in the contract storage confirmed at the snapshot height.
+func (c *ContractClient) GetStorageAt(ctx context.Context, addr types.Address, key string, snapshotHeight uint64) (string, error) {
+	var value string
+	err := c.c.CallContext(ctx, &value, "contract_getStorageAt", addr, key, snapshotHeight)
+	return value, err
+}
+
+// RegisterAbi registers the abi used to decode the logs of the contract, it is only served by ipc and in-process
+// connections.
+func (c *ContractClient) RegisterAbi(ctx context.Context, addr types.Address, abiStr string) error {
+	return c.c.CallContext(ctx, nil, "contract_registerAbi", addr, abiStr)
+}
+
+func (c *ContractClient) UnregisterAbi(ctx context.Context, addr types.Address) error {
+	return c.c.CallContext(ctx, nil, "contract_unregisterAbi", addr)
+}
+
+func (c *ContractClient) DecodeCallData(ctx context.Context, to types.Address, data []byte) (*api.DecodedData, error) {
+	var decoded *api.DecodedData
+	err := c.c.CallContext(ctx, &decoded, "contract_decodeCallData", to, data)
+	return decoded, err
+}
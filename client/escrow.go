@@ -0,0 +1,55 @@
+package client
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/rpcapi/api"
+)
+
+// EscrowClient calls the escrow namespace.
+type EscrowClient struct {
+	c *rpc.Client
+}
+
+func (e *EscrowClient) GetDepositData(ctx context.Context, beneficialAddr types.Address, unlockHeight uint64) ([]byte, error) {
+	var data []byte
+	err := e.c.CallContext(ctx, &data, "escrow_getDepositData", beneficialAddr, strconv.FormatUint(unlockHeight, 10))
+	return data, err
+}
+
+// GetDepositByTimeData returns the data of a deposit unlocked at unlockTime, in unix seconds.
+func (e *EscrowClient) GetDepositByTimeData(ctx context.Context, beneficialAddr types.Address, unlockTime int64) ([]byte, error) {
+	var data []byte
+	err := e.c.CallContext(ctx, &data, "escrow_getDepositByTimeData", beneficialAddr, unlockTime)
+	return data, err
+}
+
+func (e *EscrowClient) GetWithdrawData(ctx context.Context, id types.Hash) ([]byte, error) {
+	var data []byte
+	err := e.c.CallContext(ctx, &data, "escrow_getWithdrawData", id)
+	return data, err
+}
+
+// GetUnlockHeight returns the estimated snapshot height at unlockTime, in unix seconds.
+func (e *EscrowClient) GetUnlockHeight(ctx context.Context, unlockTime int64) (uint64, error) {
+	var height string
+	if err := e.c.CallContext(ctx, &height, "escrow_getUnlockHeight", unlockTime); err != nil {
+		return 0, err
+	}
+	return api.StringToUint64(height)
+}
+
+func (e *EscrowClient) GetEscrowList(ctx context.Context, beneficialAddr types.Address, index int, count int) (*api.EscrowInfoList, error) {
+	var list *api.EscrowInfoList
+	err := e.c.CallContext(ctx, &list, "escrow_getEscrowList", beneficialAddr, index, count)
+	return list, err
+}
+
+func (e *EscrowClient) GetEscrowListByDepositor(ctx context.Context, depositorAddr types.Address, index int, count int) (*api.EscrowInfoList, error) {
+	var list *api.EscrowInfoList
+	err := e.c.CallContext(ctx, &list, "escrow_getEscrowListByDepositor", depositorAddr, index, count)
+	return list, err
+}
@@ -0,0 +1,182 @@
+package client
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/rpcapi/api"
+)
+
+// LedgerClient calls the ledger namespace. The blocks returned by the block queries include the decoded call data of
+// the built-in contracts and the contracts with registered abis if decode is true.
+type LedgerClient struct {
+	c *rpc.Client
+}
+
+func (l *LedgerClient) GetBlockByHash(ctx context.Context, blockHash types.Hash, decode bool) (*api.AccountBlock, error) {
+	var block *api.AccountBlock
+	err := l.c.CallContext(ctx, &block, "ledger_getBlockByHash", blockHash, decode)
+	return block, err
+}
+
+// GetBlocksByHash returns count blocks of the account before originBlockHash, from the latest block if
+// originBlockHash is nil.
+func (l *LedgerClient) GetBlocksByHash(ctx context.Context, addr types.Address, originBlockHash *types.Hash, count uint64, decode bool) ([]*api.AccountBlock, error) {
+	var blocks []*api.AccountBlock
+	err := l.c.CallContext(ctx, &blocks, "ledger_getBlocksByHash", addr, originBlockHash, count, decode)
+	return blocks, err
+}
+
+func (l *LedgerClient) GetBlocksByHashInToken(ctx context.Context, addr types.Address, originBlockHash *types.Hash, tokenTypeId types.TokenTypeId, count uint64, decode bool) ([]*api.AccountBlock, error) {
+	var blocks []*api.AccountBlock
+	err := l.c.CallContext(ctx, &blocks, "ledger_getBlocksByHashInToken", addr, originBlockHash, tokenTypeId, count, decode)
+	return blocks, err
+}
+
+func (l *LedgerClient) GetBlocksBySendFromTo(ctx context.Context, fromAddr types.Address, toAddr types.Address, originBlockHash *types.Hash, count uint64, decode bool) ([]*api.AccountBlock, error) {
+	var blocks []*api.AccountBlock
+	err := l.c.CallContext(ctx, &blocks, "ledger_getBlocksBySendFromTo", fromAddr, toAddr, originBlockHash, count, decode)
+	return blocks, err
+}
+
+func (l *LedgerClient) GetBlocksByFromBlockHash(ctx context.Context, fromBlockHash types.Hash, count uint64, decode bool) ([]*api.AccountBlock, error) {
+	var blocks []*api.AccountBlock
+	err := l.c.CallContext(ctx, &blocks, "ledger_getBlocksByFromBlockHash", fromBlockHash, count, decode)
+	return blocks, err
+}
+
+func (l *LedgerClient) GetBlocksByMethodSelector(ctx context.Context, contractAddr types.Address, methodSelector string, originBlockHash *types.Hash, count uint64, decode bool) ([]*api.AccountBlock, error) {
+	var blocks []*api.AccountBlock
+	err := l.c.CallContext(ctx, &blocks, "ledger_getBlocksByMethodSelector", contractAddr, methodSelector, originBlockHash, count, decode)
+	return blocks, err
+}
+
+func (l *LedgerClient) GetBlocksByTimestampRange(ctx context.Context, startTime int64, endTime int64, count uint64, decode bool) ([]*api.AccountBlock, error) {
+	var blocks []*api.AccountBlock
+	err := l.c.CallContext(ctx, &blocks, "ledger_getBlocksByTimestampRange", startTime, endTime, count, decode)
+	return blocks, err
+}
+
+func (l *LedgerClient) GetBlocksByHeight(ctx context.Context, addr types.Address, height uint64, count uint64, forward bool, decode bool) ([]*api.AccountBlock, error) {
+	var blocks []*api.AccountBlock
+	err := l.c.CallContext(ctx, &blocks, "ledger_getBlocksByHeight", addr, height, count, forward, decode)
+	return blocks, err
+}
+
+func (l *LedgerClient) GetBlockByHeight(ctx context.Context, addr types.Address, height uint64, decode bool) (*api.AccountBlock, error) {
+	var block *api.AccountBlock
+	err := l.c.CallContext(ctx, &block, "ledger_getBlockByHeight", addr, strconv.FormatUint(height, 10), decode)
+	return block, err
+}
+
+func (l *LedgerClient) GetBlocksByAccAddr(ctx context.Context, addr types.Address, index int, count int, decode bool) ([]*api.AccountBlock, error) {
+	var blocks []*api.AccountBlock
+	err := l.c.CallContext(ctx, &blocks, "ledger_getBlocksByAccAddr", addr, index, count, decode)
+	return blocks, err
+}
+
+func (l *LedgerClient) GetLatestBlock(ctx context.Context, addr types.Address, decode bool) (*api.AccountBlock, error) {
+	var block *api.AccountBlock
+	err := l.c.CallContext(ctx, &block, "ledger_getLatestBlock", addr, decode)
+	return block, err
+}
+
+func (l *LedgerClient) GetBlockMeta(ctx context.Context, hash types.Hash) (*ledger.AccountBlockMeta, error) {
+	var meta *ledger.AccountBlockMeta
+	err := l.c.CallContext(ctx, &meta, "ledger_getBlockMeta", hash)
+	return meta, err
+}
+
+func (l *LedgerClient) GetAccountByAccAddr(ctx context.Context, addr types.Address) (*api.RpcAccountInfo, error) {
+	var info *api.RpcAccountInfo
+	err := l.c.CallContext(ctx, &info, "ledger_getAccountByAccAddr", addr)
+	return info, err
+}
+
+func (l *LedgerClient) GetBalanceAt(ctx context.Context, addr types.Address, snapshotHeight uint64) (*api.RpcAccountInfo, error) {
+	var info *api.RpcAccountInfo
+	err := l.c.CallContext(ctx, &info, "ledger_getBalanceAt", addr, snapshotHeight)
+	return info, err
+}
+
+func (l *LedgerClient) AccountType(ctx context.Context, addr types.Address) (uint64, error) {
+	var accountType uint64
+	err := l.c.CallContext(ctx, &accountType, "ledger_accountType", addr)
+	return accountType, err
+}
+
+// GetAccountStateProof proves the account state of addr, and the balances and storage keys in keys if it isn't nil.
+func (l *LedgerClient) GetAccountStateProof(ctx context.Context, addr types.Address, snapshotHash types.Hash, keys *api.AccountStateProofKeys) (*api.AccountStateProof, error) {
+	var proof *api.AccountStateProof
+	err := l.c.CallContext(ctx, &proof, "ledger_getAccountStateProof", addr, snapshotHash, keys)
+	return proof, err
+}
+
+func (l *LedgerClient) GetSnapshotBlockByHash(ctx context.Context, hash types.Hash) (*ledger.SnapshotBlock, error) {
+	var block *ledger.SnapshotBlock
+	err := l.c.CallContext(ctx, &block, "ledger_getSnapshotBlockByHash", hash)
+	return block, err
+}
+
+func (l *LedgerClient) GetSnapshotBlockByHeight(ctx context.Context, height uint64) (*ledger.SnapshotBlock, error) {
+	var block *ledger.SnapshotBlock
+	err := l.c.CallContext(ctx, &block, "ledger_getSnapshotBlockByHeight", height)
+	return block, err
+}
+
+func (l *LedgerClient) GetSnapshotChainHeight(ctx context.Context) (uint64, error) {
+	var height string
+	if err := l.c.CallContext(ctx, &height, "ledger_getSnapshotChainHeight"); err != nil {
+		return 0, err
+	}
+	return api.StringToUint64(height)
+}
+
+func (l *LedgerClient) GetLatestSnapshotChainHash(ctx context.Context) (*types.Hash, error) {
+	var hash *types.Hash
+	err := l.c.CallContext(ctx, &hash, "ledger_getLatestSnapshotChainHash")
+	return hash, err
+}
+
+// GetFittestSnapshotHash returns the snapshot hash a new block of the account should refer to, sendBlockHash is the
+// send block to be received, and nil for a send block.
+func (l *LedgerClient) GetFittestSnapshotHash(ctx context.Context, addr types.Address, sendBlockHash *types.Hash) (*types.Hash, error) {
+	var hash *types.Hash
+	err := l.c.CallContext(ctx, &hash, "ledger_getFittestSnapshotHash", addr, sendBlockHash)
+	return hash, err
+}
+
+func (l *LedgerClient) GetTokenMintage(ctx context.Context, tti types.TokenTypeId) (*api.RpcTokenInfo, error) {
+	var info *api.RpcTokenInfo
+	err := l.c.CallContext(ctx, &info, "ledger_getTokenMintage", tti)
+	return info, err
+}
+
+// GetVmLogList returns the logs of the block, the events are decoded by the abis registered in the node if decode is
+// true.
+func (l *LedgerClient) GetVmLogList(ctx context.Context, blockHash types.Hash, decode bool) ([]*api.VmLog, error) {
+	var logs []*api.VmLog
+	err := l.c.CallContext(ctx, &logs, "ledger_getVmLogList", blockHash, decode)
+	return logs, err
+}
+
+func (l *LedgerClient) GetVmLogListByHash(ctx context.Context, logHash types.Hash) (ledger.VmLogList, error) {
+	var logs ledger.VmLogList
+	err := l.c.CallContext(ctx, &logs, "ledger_getVmLogListByHash", logHash)
+	return logs, err
+}
+
+func (l *LedgerClient) GetStatistics(ctx context.Context) (*api.Statistics, error) {
+	var statistics *api.Statistics
+	err := l.c.CallContext(ctx, &statistics, "ledger_getStatistics")
+	return statistics, err
+}
+
+func (l *LedgerClient) GetGcStatus(ctx context.Context) (*api.GcStatus, error) {
+	var status *api.GcStatus
+	err := l.c.CallContext(ctx, &status, "ledger_getGcStatus")
+	return status, err
+}
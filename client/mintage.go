@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/rpcapi/api"
+)
+
+// MintageClient calls the mintage namespace.
+type MintageClient struct {
+	c *rpc.Client
+}
+
+func (m *MintageClient) NewTokenId(ctx context.Context, param api.NewTokenIdParams) (*types.TokenTypeId, error) {
+	var tokenId *types.TokenTypeId
+	err := m.c.CallContext(ctx, &tokenId, "mintage_newTokenId", param)
+	return tokenId, err
+}
+
+func (m *MintageClient) GetMintageData(ctx context.Context, param api.MintageParams) ([]byte, error) {
+	var data []byte
+	err := m.c.CallContext(ctx, &data, "mintage_getMintageData", param)
+	return data, err
+}
+
+func (m *MintageClient) GetMintageCancelPledgeData(ctx context.Context, tokenId types.TokenTypeId) ([]byte, error) {
+	var data []byte
+	err := m.c.CallContext(ctx, &data, "mintage_getMintageCancelPledgeData", tokenId)
+	return data, err
+}
+
+func (m *MintageClient) GetMintData(ctx context.Context, param api.MintageParams) ([]byte, error) {
+	var data []byte
+	err := m.c.CallContext(ctx, &data, "mintage_getMintData", param)
+	return data, err
+}
+
+func (m *MintageClient) GetIssueData(ctx context.Context, param api.IssueParams) ([]byte, error) {
+	var data []byte
+	err := m.c.CallContext(ctx, &data, "mintage_getIssueData", param)
+	return data, err
+}
+
+func (m *MintageClient) GetBurnData(ctx context.Context) ([]byte, error) {
+	var data []byte
+	err := m.c.CallContext(ctx, &data, "mintage_getBurnData")
+	return data, err
+}
+
+func (m *MintageClient) GetTransferOwnerData(ctx context.Context, param api.TransferOwnerParams) ([]byte, error) {
+	var data []byte
+	err := m.c.CallContext(ctx, &data, "mintage_getTransferOwnerData", param)
+	return data, err
+}
+
+func (m *MintageClient) GetChangeTokenTypeData(ctx context.Context, tokenId types.TokenTypeId) ([]byte, error) {
+	var data []byte
+	err := m.c.CallContext(ctx, &data, "mintage_getChangeTokenTypeData", tokenId)
+	return data, err
+}
+
+func (m *MintageClient) GetTokenInfoList(ctx context.Context, index int, count int) (*api.TokenInfoList, error) {
+	var list *api.TokenInfoList
+	err := m.c.CallContext(ctx, &list, "mintage_getTokenInfoList", index, count)
+	return list, err
+}
+
+func (m *MintageClient) GetTokenInfoById(ctx context.Context, tokenId types.TokenTypeId) (*api.RpcTokenInfo, error) {
+	var info *api.RpcTokenInfo
+	err := m.c.CallContext(ctx, &info, "mintage_getTokenInfoById", tokenId)
+	return info, err
+}
+
+func (m *MintageClient) GetTokenInfoListByOwner(ctx context.Context, owner types.Address) ([]*api.RpcTokenInfo, error) {
+	var list []*api.RpcTokenInfo
+	err := m.c.CallContext(ctx, &list, "mintage_getTokenInfoListByOwner", owner)
+	return list, err
+}
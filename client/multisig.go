@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/rpcapi/api"
+)
+
+// MultiSigClient calls the multisig namespace.
+type MultiSigClient struct {
+	c *rpc.Client
+}
+
+func (m *MultiSigClient) GetMultiSigAddress(ctx context.Context, threshold uint8, keys []ed25519.PublicKey) (types.Address, error) {
+	var addr types.Address
+	err := m.c.CallContext(ctx, &addr, "multisig_getMultiSigAddress", threshold, keys)
+	return addr, err
+}
+
+func (m *MultiSigClient) GetRegisterData(ctx context.Context, threshold uint8, keys []ed25519.PublicKey) ([]byte, error) {
+	var data []byte
+	err := m.c.CallContext(ctx, &data, "multisig_getRegisterData", threshold, keys)
+	return data, err
+}
+
+// GetMultiSigInfo returns the key set registered for the address, nil if the address is not registered.
+func (m *MultiSigClient) GetMultiSigInfo(ctx context.Context, addr types.Address) (*api.MultiSigInfo, error) {
+	var info *api.MultiSigInfo
+	err := m.c.CallContext(ctx, &info, "multisig_getMultiSigInfo", addr)
+	return info, err
+}
+
+// CombineSignatures lets the node add the signatures to the block, the signatures can also be added offline by
+// AddSignature of the block.
+func (m *MultiSigClient) CombineSignatures(ctx context.Context, block *ledger.AccountBlock, signatures []*api.MultiSignature) (*api.AccountBlock, error) {
+	list := make([]api.MultiSignature, len(signatures))
+	for i, s := range signatures {
+		list[i] = *s
+	}
+	var result *api.AccountBlock
+	err := m.c.CallContext(ctx, &result, "multisig_combineSignatures", ToRpcBlock(block), list)
+	return result, err
+}
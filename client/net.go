@@ -0,0 +1,51 @@
+package client
+
+import (
+	"context"
+
+	"github.com/vitelabs/go-vite/p2p"
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/rpcapi/api"
+	"github.com/vitelabs/go-vite/vite/net"
+)
+
+// NetClient calls the net namespace.
+type NetClient struct {
+	c *rpc.Client
+}
+
+func (n *NetClient) SyncInfo(ctx context.Context) (*api.SyncInfo, error) {
+	var info *api.SyncInfo
+	err := n.c.CallContext(ctx, &info, "net_syncInfo")
+	return info, err
+}
+
+func (n *NetClient) SyncDetail(ctx context.Context) (*net.SyncDetail, error) {
+	var detail *net.SyncDetail
+	err := n.c.CallContext(ctx, &detail, "net_syncDetail")
+	return detail, err
+}
+
+func (n *NetClient) Peers(ctx context.Context) (*net.NodeInfo, error) {
+	var info *net.NodeInfo
+	err := n.c.CallContext(ctx, &info, "net_peers")
+	return info, err
+}
+
+func (n *NetClient) PeersCount(ctx context.Context) (uint, error) {
+	var count uint
+	err := n.c.CallContext(ctx, &count, "net_peersCount")
+	return count, err
+}
+
+func (n *NetClient) Nodes(ctx context.Context) ([]string, error) {
+	var nodes []string
+	err := n.c.CallContext(ctx, &nodes, "net_nodes")
+	return nodes, err
+}
+
+func (n *NetClient) NodeInfo(ctx context.Context) (*p2p.NodeInfo, error) {
+	var info *p2p.NodeInfo
+	err := n.c.CallContext(ctx, &info, "net_nodeInfo")
+	return info, err
+}
@@ -0,0 +1,26 @@
+package client
+
+import (
+	"context"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/rpcapi/api"
+)
+
+// OnroadClient calls the public onroad namespace.
+type OnroadClient struct {
+	c *rpc.Client
+}
+
+func (o *OnroadClient) GetOnroadBlocksByAddress(ctx context.Context, addr types.Address, index int, count int) ([]*api.AccountBlock, error) {
+	var blocks []*api.AccountBlock
+	err := o.c.CallContext(ctx, &blocks, "onroad_getOnroadBlocksByAddress", addr, index, count)
+	return blocks, err
+}
+
+func (o *OnroadClient) GetAccountOnroadInfo(ctx context.Context, addr types.Address) (*api.RpcAccountInfo, error) {
+	var info *api.RpcAccountInfo
+	err := o.c.CallContext(ctx, &info, "onroad_getAccountOnroadInfo", addr)
+	return info, err
+}
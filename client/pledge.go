@@ -0,0 +1,39 @@
+package client
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/rpcapi/api"
+)
+
+// PledgeClient calls the pledge namespace.
+type PledgeClient struct {
+	c *rpc.Client
+}
+
+func (p *PledgeClient) GetPledgeData(ctx context.Context, beneficialAddr types.Address) ([]byte, error) {
+	var data []byte
+	err := p.c.CallContext(ctx, &data, "pledge_getPledgeData", beneficialAddr)
+	return data, err
+}
+
+func (p *PledgeClient) GetCancelPledgeData(ctx context.Context, beneficialAddr types.Address, amount *big.Int) ([]byte, error) {
+	var data []byte
+	err := p.c.CallContext(ctx, &data, "pledge_getCancelPledgeData", beneficialAddr, amount.String())
+	return data, err
+}
+
+func (p *PledgeClient) GetPledgeQuota(ctx context.Context, addr types.Address) (*api.QuotaAndTxNum, error) {
+	var quota *api.QuotaAndTxNum
+	err := p.c.CallContext(ctx, &quota, "pledge_getPledgeQuota", addr)
+	return quota, err
+}
+
+func (p *PledgeClient) GetPledgeList(ctx context.Context, addr types.Address, index int, count int) (*api.PledgeInfoList, error) {
+	var list *api.PledgeInfoList
+	err := p.c.CallContext(ctx, &list, "pledge_getPledgeList", addr, index, count)
+	return list, err
+}
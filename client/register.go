@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/rpcapi/api"
+)
+
+// RegisterClient calls the register namespace.
+type RegisterClient struct {
+	c *rpc.Client
+}
+
+func (r *RegisterClient) GetRegisterData(ctx context.Context, gid types.Gid, name string, nodeAddr types.Address) ([]byte, error) {
+	var data []byte
+	err := r.c.CallContext(ctx, &data, "register_getRegisterData", gid, name, nodeAddr)
+	return data, err
+}
+
+func (r *RegisterClient) GetCancelRegisterData(ctx context.Context, gid types.Gid, name string) ([]byte, error) {
+	var data []byte
+	err := r.c.CallContext(ctx, &data, "register_getCancelRegisterData", gid, name)
+	return data, err
+}
+
+func (r *RegisterClient) GetRewardData(ctx context.Context, gid types.Gid, name string, beneficialAddr types.Address) ([]byte, error) {
+	var data []byte
+	err := r.c.CallContext(ctx, &data, "register_getRewardData", gid, name, beneficialAddr)
+	return data, err
+}
+
+func (r *RegisterClient) GetUpdateRegistrationData(ctx context.Context, gid types.Gid, name string, nodeAddr types.Address) ([]byte, error) {
+	var data []byte
+	err := r.c.CallContext(ctx, &data, "register_getUpdateRegistrationData", gid, name, nodeAddr)
+	return data, err
+}
+
+func (r *RegisterClient) GetRegistrationList(ctx context.Context, gid types.Gid, pledgeAddr types.Address) ([]*api.RegistrationInfo, error) {
+	var list []*api.RegistrationInfo
+	err := r.c.CallContext(ctx, &list, "register_getRegistrationList", gid, pledgeAddr)
+	return list, err
+}
+
+func (r *RegisterClient) GetRegistration(ctx context.Context, name string, gid types.Gid) (*types.Registration, error) {
+	var registration *types.Registration
+	err := r.c.CallContext(ctx, &registration, "register_getRegistration", name, gid)
+	return registration, err
+}
+
+func (r *RegisterClient) GetRegisterPledgeAddr(ctx context.Context, name string, gid *types.Gid) (*types.Address, error) {
+	var addr *types.Address
+	err := r.c.CallContext(ctx, &addr, "register_getRegisterPledgeAddr", name, gid)
+	return addr, err
+}
+
+func (r *RegisterClient) GetRegisterPledgeAddrList(ctx context.Context, paramList []*api.RegistParam) ([]*types.Address, error) {
+	var list []*types.Address
+	err := r.c.CallContext(ctx, &list, "register_getRegisterPledgeAddrList", paramList)
+	return list, err
+}
+
+func (r *RegisterClient) GetCandidateList(ctx context.Context, gid types.Gid) ([]*api.CandidateInfo, error) {
+	var list []*api.CandidateInfo
+	err := r.c.CallContext(ctx, &list, "register_getCandidateList", gid)
+	return list, err
+}
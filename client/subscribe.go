@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/rpcapi/api/filters"
+)
+
+// SubscribeClient calls the subscribe namespace, either by polling filters or by the subscriptions which need a
+// websocket, ipc or in-process connection.
+type SubscribeClient struct {
+	c *rpc.Client
+}
+
+func (s *SubscribeClient) NewAccountBlocksFilter(ctx context.Context) (rpc.ID, error) {
+	var id rpc.ID
+	err := s.c.CallContext(ctx, &id, "subscribe_newAccountBlocksFilter")
+	return id, err
+}
+
+func (s *SubscribeClient) NewLogsFilter(ctx context.Context, param filters.RpcFilterParam) (rpc.ID, error) {
+	var id rpc.ID
+	err := s.c.CallContext(ctx, &id, "subscribe_newLogsFilter", param)
+	return id, err
+}
+
+func (s *SubscribeClient) NewSnapshotBlocksFilter(ctx context.Context) (rpc.ID, error) {
+	var id rpc.ID
+	err := s.c.CallContext(ctx, &id, "subscribe_newSnapshotBlocksFilter")
+	return id, err
+}
+
+func (s *SubscribeClient) UninstallFilter(ctx context.Context, id rpc.ID) (bool, error) {
+	var found bool
+	err := s.c.CallContext(ctx, &found, "subscribe_uninstallFilter", id)
+	return found, err
+}
+
+// GetAccountBlocksFilterChanges returns the account blocks since the last poll of a filter created by
+// NewAccountBlocksFilter.
+func (s *SubscribeClient) GetAccountBlocksFilterChanges(ctx context.Context, id rpc.ID) (*filters.AccountBlocksMsg, error) {
+	var msg *filters.AccountBlocksMsg
+	err := s.c.CallContext(ctx, &msg, "subscribe_getFilterChanges", id)
+	return msg, err
+}
+
+// GetLogsFilterChanges returns the logs since the last poll of a filter created by NewLogsFilter.
+func (s *SubscribeClient) GetLogsFilterChanges(ctx context.Context, id rpc.ID) (*filters.LogsMsg, error) {
+	var msg *filters.LogsMsg
+	err := s.c.CallContext(ctx, &msg, "subscribe_getFilterChanges", id)
+	return msg, err
+}
+
+// GetSnapshotBlocksFilterChanges returns the snapshot blocks since the last poll of a filter created by
+// NewSnapshotBlocksFilter.
+func (s *SubscribeClient) GetSnapshotBlocksFilterChanges(ctx context.Context, id rpc.ID) (*filters.SnapshotBlocksMsg, error) {
+	var msg *filters.SnapshotBlocksMsg
+	err := s.c.CallContext(ctx, &msg, "subscribe_getFilterChanges", id)
+	return msg, err
+}
+
+func (s *SubscribeClient) GetLogs(ctx context.Context, param filters.RpcFilterParam) ([]*filters.Logs, error) {
+	var logs []*filters.Logs
+	err := s.c.CallContext(ctx, &logs, "subscribe_getLogs", param)
+	return logs, err
+}
+
+// SubscribeAccountBlocks sends the inserted and deleted account blocks to ch. startEventId is the event id of the
+// latest block received before reconnecting, or nil to start from now.
+func (s *SubscribeClient) SubscribeAccountBlocks(ctx context.Context, ch chan<- []*filters.AccountBlock, startEventId *string) (*rpc.ClientSubscription, error) {
+	return s.c.Subscribe(ctx, "subscribe", ch, "newAccountBlocks", startEventId)
+}
+
+func (s *SubscribeClient) SubscribeLogs(ctx context.Context, ch chan<- []*filters.Logs, param filters.RpcFilterParam, startEventId *string) (*rpc.ClientSubscription, error) {
+	return s.c.Subscribe(ctx, "subscribe", ch, "newLogs", param, startEventId)
+}
+
+func (s *SubscribeClient) SubscribeSnapshotBlocks(ctx context.Context, ch chan<- []*filters.SnapshotBlock, startEventId *string) (*rpc.ClientSubscription, error) {
+	return s.c.Subscribe(ctx, "subscribe", ch, "newSnapshotBlocks", startEventId)
+}
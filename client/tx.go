@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/rpcapi/api"
+)
+
+// TxClient calls the tx namespace.
+type TxClient struct {
+	c *rpc.Client
+}
+
+func (t *TxClient) SendRawTx(ctx context.Context, block *api.AccountBlock) error {
+	return t.c.CallContext(ctx, nil, "tx_sendRawTx", block)
+}
+
+// CalcPoWDifficulty returns the difficulty of the PoW the block needs, 0 if the pledge quota is enough and
+// param.UsePledgeQuota is set.
+func (t *TxClient) CalcPoWDifficulty(ctx context.Context, param api.CalcPoWDifficultyParam) (*big.Int, error) {
+	var difficulty string
+	if err := t.c.CallContext(ctx, &difficulty, "tx_calcPoWDifficulty", param); err != nil {
+		return nil, err
+	}
+	d, ok := new(big.Int).SetString(difficulty, 10)
+	if !ok {
+		return nil, errors.New("invalid difficulty " + difficulty)
+	}
+	return d, nil
+}
+
+func (t *TxClient) Simulate(ctx context.Context, block *api.AccountBlock) (*api.SimulateResult, error) {
+	var result *api.SimulateResult
+	err := t.c.CallContext(ctx, &result, "tx_simulate", block)
+	return result, err
+}
+
+func (t *TxClient) EstimateQuota(ctx context.Context, block *api.AccountBlock) (*api.QuotaEstimate, error) {
+	var estimate *api.QuotaEstimate
+	err := t.c.CallContext(ctx, &estimate, "tx_estimateQuota", block)
+	return estimate, err
+}
+
+// CreateUnsignedTx lets the node build the block, the payload of the result is signed offline by `gvite wallet sign`
+// or offline.SignBlock. If only the difficulty is returned, the nonce is calculated on the PoW hash, e.g. by
+// Pow.GetPowNonce, and the block is created again with the difficulty and the nonce.
+func (t *TxClient) CreateUnsignedTx(ctx context.Context, block *api.AccountBlock) (*api.UnsignedTx, error) {
+	var unsignedTx *api.UnsignedTx
+	err := t.c.CallContext(ctx, &unsignedTx, "tx_createUnsignedTx", block)
+	return unsignedTx, err
+}
+
+func (t *TxClient) SendSignedTx(ctx context.Context, payload string) error {
+	return t.c.CallContext(ctx, nil, "tx_sendSignedTx", payload)
+}
+
+// PowClient calls the pow namespace, the nonce is calculated by the node.
+type PowClient struct {
+	c *rpc.Client
+}
+
+func (p *PowClient) GetPowNonce(ctx context.Context, difficulty *big.Int, data types.Hash) ([]byte, error) {
+	var nonce []byte
+	err := p.c.CallContext(ctx, &nonce, "pow_getPowNonce", difficulty.String(), data)
+	return nonce, err
+}
@@ -0,0 +1,32 @@
+package client
+
+import (
+	"context"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/rpcapi/api"
+)
+
+// VoteClient calls the vote namespace.
+type VoteClient struct {
+	c *rpc.Client
+}
+
+func (v *VoteClient) GetVoteData(ctx context.Context, gid types.Gid, name string) ([]byte, error) {
+	var data []byte
+	err := v.c.CallContext(ctx, &data, "vote_getVoteData", gid, name)
+	return data, err
+}
+
+func (v *VoteClient) GetCancelVoteData(ctx context.Context, gid types.Gid) ([]byte, error) {
+	var data []byte
+	err := v.c.CallContext(ctx, &data, "vote_getCancelVoteData", gid)
+	return data, err
+}
+
+func (v *VoteClient) GetVoteInfo(ctx context.Context, gid types.Gid, addr types.Address) (*api.VoteInfo, error) {
+	var info *api.VoteInfo
+	err := v.c.CallContext(ctx, &info, "vote_getVoteInfo", gid, addr)
+	return info, err
+}
@@ -0,0 +1,68 @@
+package gvite_plugins
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/vitelabs/go-vite/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/cmd/utils"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	exportChainCommand = cli.Command{
+		Action:   utils.MigrateFlags(exportChainAction),
+		Name:     "export-chain",
+		Usage:    "export-chain --from=1 --to=5000000 --file=ledger.archive",
+		Flags:    append(archiveFlags, configFlags...),
+		Category: "ARCHIVE COMMANDS",
+		Description: `
+Export snapshot blocks and the account blocks they confirm to a ledger archive.
+`,
+	}
+
+	importChainCommand = cli.Command{
+		Action:   utils.MigrateFlags(importChainAction),
+		Name:     "import-chain",
+		Usage:    "import-chain --file=ledger.archive",
+		Flags:    append(archiveFlags, configFlags...),
+		Category: "ARCHIVE COMMANDS",
+		Description: `
+Verify and import a ledger archive.
+`,
+	}
+)
+
+func exportChainAction(ctx *cli.Context) error {
+	nodeManager, err := nodemanager.NewArchiveNodeManager(ctx, nodemanager.FullNodeMaker{}, false)
+	if err != nil {
+		log.Error(fmt.Sprintf("new Node error, %+v", err))
+		return err
+	}
+
+	if err := nodeManager.Start(); err != nil {
+		log.Error(err.Error())
+		fmt.Println(err.Error())
+		return err
+	}
+
+	os.Exit(0)
+	return nil
+}
+
+func importChainAction(ctx *cli.Context) error {
+	nodeManager, err := nodemanager.NewArchiveNodeManager(ctx, nodemanager.FullNodeMaker{}, true)
+	if err != nil {
+		log.Error(fmt.Sprintf("new Node error, %+v", err))
+		return err
+	}
+
+	if err := nodeManager.Start(); err != nil {
+		log.Error(err.Error())
+		fmt.Println(err.Error())
+		return err
+	}
+
+	os.Exit(0)
+	return nil
+}
@@ -127,6 +127,30 @@ var (
 	exportFlags = []cli.Flag{
 		utils.ExportSbHeightFlags,
 	}
+
+	// Ledger archive
+	archiveFlags = []cli.Flag{
+		utils.ArchiveFromHeightFlag,
+		utils.ArchiveToHeightFlag,
+		utils.ArchiveFileFlag,
+	}
+
+	// State snapshot, the file flag is shared with the ledger archive
+	stateSnapshotFlags = []cli.Flag{
+		utils.StateSnapshotHeightFlag,
+		utils.StateSnapshotHashFlag,
+	}
+
+	// Verify db
+	verifyDbFlags = []cli.Flag{
+		utils.VerifyDbJsonFlag,
+	}
+
+	// Offline signing
+	walletFlags = []cli.Flag{
+		utils.EntropyStoreFileFlag,
+		utils.SignAddressFlag,
+	}
 )
 
 func init() {
@@ -153,13 +177,19 @@ func init() {
 		attachCommand,
 		ledgerRecoverCommand,
 		exportCommand,
+		exportChainCommand,
+		importChainCommand,
+		dumpStateCommand,
+		bootstrapStateCommand,
+		verifyDbCommand,
+		walletCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
 	//Import: Please add the New Flags here
 	app.Flags = utils.MergeFlags(configFlags, generalFlags, p2pFlags,
 		ipcFlags, httpFlags, wsFlags, consoleFlags, producerFlags, logFlags,
-		vmFlags, netFlags, statFlags, metricsFlags, ledgerFlags, exportFlags)
+		vmFlags, netFlags, statFlags, metricsFlags, ledgerFlags, exportFlags, archiveFlags, stateSnapshotFlags, verifyDbFlags, walletFlags)
 
 	app.Before = beforeAction
 	app.Action = action
@@ -0,0 +1,61 @@
+package gvite_plugins
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/vitelabs/go-vite/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/cmd/utils"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	dumpStateCommand = cli.Command{
+		Action:   utils.MigrateFlags(dumpStateAction),
+		Name:     "dump-state",
+		Usage:    "dump-state --height=5000000 --file=state.snapshot",
+		Flags:    append([]cli.Flag{utils.StateSnapshotHeightFlag, utils.ArchiveFileFlag}, configFlags...),
+		Category: "STATE SNAPSHOT COMMANDS",
+		Description: `
+Dump the whole state at a snapshot block to a state snapshot.
+`,
+	}
+
+	bootstrapStateCommand = cli.Command{
+		Action:   utils.MigrateFlags(bootstrapStateAction),
+		Name:     "bootstrap-state",
+		Usage:    "bootstrap-state --snapshotHash=<hash> [--file=state.snapshot]",
+		Flags:    append([]cli.Flag{utils.StateSnapshotHashFlag, utils.ArchiveFileFlag}, configFlags...),
+		Category: "STATE SNAPSHOT COMMANDS",
+		Description: `
+Verify a state snapshot against the trusted snapshot block hash and write it into an empty ledger,
+the node syncs from the snapshot block after restarting. The state snapshot is downloaded from peers
+if no file is given, peers serve the state snapshots in their StateSnapshotDir.
+`,
+	}
+)
+
+func dumpStateAction(ctx *cli.Context) error {
+	return runStateSnapshot(ctx, false)
+}
+
+func bootstrapStateAction(ctx *cli.Context) error {
+	return runStateSnapshot(ctx, true)
+}
+
+func runStateSnapshot(ctx *cli.Context, isBootstrap bool) error {
+	nodeManager, err := nodemanager.NewStateSnapshotNodeManager(ctx, nodemanager.FullNodeMaker{}, isBootstrap)
+	if err != nil {
+		log.Error(fmt.Sprintf("new Node error, %+v", err))
+		return err
+	}
+
+	if err := nodeManager.Start(); err != nil {
+		log.Error(err.Error())
+		fmt.Println(err.Error())
+		return err
+	}
+
+	os.Exit(0)
+	return nil
+}
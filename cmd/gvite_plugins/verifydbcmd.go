@@ -0,0 +1,40 @@
+package gvite_plugins
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/vitelabs/go-vite/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/cmd/utils"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	verifyDbCommand = cli.Command{
+		Action:   utils.MigrateFlags(verifyDbAction),
+		Name:     "verify-db",
+		Usage:    "verify-db [--json]",
+		Flags:    append(verifyDbFlags, configFlags...),
+		Category: "RECOVER COMMANDS",
+		Description: `
+Walk the whole ledger and report the problems found, and the height to recover to if recover can fix them.
+`,
+	}
+)
+
+func verifyDbAction(ctx *cli.Context) error {
+	nodeManager, err := nodemanager.NewVerifyDbNodeManager(ctx, nodemanager.FullNodeMaker{})
+	if err != nil {
+		log.Error(fmt.Sprintf("new Node error, %+v", err))
+		return err
+	}
+
+	if err := nodeManager.Start(); err != nil {
+		log.Error(err.Error())
+		fmt.Println(err.Error())
+		os.Exit(1)
+	}
+
+	os.Exit(0)
+	return nil
+}
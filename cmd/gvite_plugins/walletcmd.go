@@ -0,0 +1,105 @@
+package gvite_plugins
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/cmd/console"
+	"github.com/vitelabs/go-vite/cmd/utils"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/wallet/entropystore"
+	"github.com/vitelabs/go-vite/wallet/offline"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	walletCommand = cli.Command{
+		Name:     "wallet",
+		Usage:    "Manage the keys offline",
+		Category: "WALLET COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Action:    utils.MigrateFlags(walletSignAction),
+				Name:      "sign",
+				Usage:     "sign --entropystore=<file> [--address=<address>] [payload]",
+				ArgsUsage: "[payload]",
+				Flags:     walletFlags,
+				Description: `
+Sign the payload of tx_createUnsignedTx without a node, the payload is read from stdin if it is not given.
+The signed payload is sent by tx_sendSignedTx on an online node.
+`,
+			},
+		},
+	}
+)
+
+func walletSignAction(ctx *cli.Context) error {
+	file := ctx.GlobalString(utils.EntropyStoreFileFlag.Name)
+	if file == "" {
+		return errors.New("entropy store file is required")
+	}
+	ok, primaryAddr, err := entropystore.IsMayValidEntropystoreFile(file)
+	if err != nil {
+		return err
+	}
+	if !ok || primaryAddr == nil {
+		return errors.New("invalid entropy store file")
+	}
+	addr := *primaryAddr
+	if s := ctx.GlobalString(utils.SignAddressFlag.Name); s != "" {
+		if addr, err = types.HexToAddress(s); err != nil {
+			return err
+		}
+	}
+
+	payload := ctx.Args().First()
+	if payload == "" {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return err
+		}
+		payload = line
+	}
+	block, err := offline.DecodeBlock(strings.TrimSpace(payload))
+	if err != nil {
+		return err
+	}
+	if block.AccountAddress != addr {
+		return errors.New(fmt.Sprintf("the block belongs to %s, not %s", block.AccountAddress, addr))
+	}
+	printOfflineBlock(block)
+
+	passphrase, err := console.Stdin.PromptPassword("Passphrase: ")
+	if err != nil {
+		return err
+	}
+	manager := entropystore.NewManager(file, *primaryAddr, entropystore.DefaultMaxIndex)
+	if err := offline.SignBlock(block, func(addr types.Address, data []byte) (signedData, pubkey []byte, err error) {
+		return manager.SignDataWithPassphrase(addr, passphrase, data)
+	}); err != nil {
+		return err
+	}
+
+	signedPayload, err := offline.EncodeBlock(block)
+	if err != nil {
+		return err
+	}
+	fmt.Println(signedPayload)
+	return nil
+}
+
+func printOfflineBlock(block *ledger.AccountBlock) {
+	fmt.Printf("Account: %s\nHeight: %d\nHash: %s\n", block.AccountAddress, block.Height, block.Hash)
+	if block.IsSendBlock() {
+		fmt.Printf("To: %s\nToken: %s\nAmount: %s\nFee: %s\n", block.ToAddress, block.TokenId, block.Amount, block.Fee)
+	} else {
+		fmt.Printf("From block: %s\n", block.FromBlockHash)
+	}
+	if len(block.Data) > 0 {
+		fmt.Printf("Data: %x\n", block.Data)
+	}
+}
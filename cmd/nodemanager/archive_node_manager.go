@@ -0,0 +1,171 @@
+package nodemanager
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/archive"
+	"github.com/vitelabs/go-vite/cmd/utils"
+	"github.com/vitelabs/go-vite/node"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const archiveProgressInterval = 10000
+
+type ArchiveNodeManager struct {
+	ctx      *cli.Context
+	node     *node.Node
+	isImport bool
+}
+
+func NewArchiveNodeManager(ctx *cli.Context, maker NodeMaker, isImport bool) (*ArchiveNodeManager, error) {
+	node, err := maker.MakeNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// single mode
+	node.Config().Single = true
+	node.ViteConfig().Net.Single = true
+
+	// no miner
+	node.Config().MinerEnabled = false
+	node.ViteConfig().Producer.Producer = false
+
+	// no ledger gc
+	ledgerGc := false
+	node.Config().LedgerGc = &ledgerGc
+	node.ViteConfig().Chain.LedgerGc = ledgerGc
+
+	// no ledger prune
+	node.Config().LedgerPrune = false
+	node.ViteConfig().Chain.LedgerPrune = false
+
+	return &ArchiveNodeManager{
+		ctx:      ctx,
+		node:     node,
+		isImport: isImport,
+	}, nil
+}
+
+func (nodeManager *ArchiveNodeManager) getFile() (string, error) {
+	if !nodeManager.ctx.GlobalIsSet(utils.ArchiveFileFlag.Name) {
+		return "", errors.New("`--file` is required")
+	}
+	return nodeManager.ctx.GlobalString(utils.ArchiveFileFlag.Name), nil
+}
+
+func (nodeManager *ArchiveNodeManager) Start() error {
+	file, err := nodeManager.getFile()
+	if err != nil {
+		return err
+	}
+
+	if nodeManager.isImport {
+		// check the whole archive before the node is touched
+		if err := nodeManager.check(file); err != nil {
+			return err
+		}
+	}
+
+	if err := StartNode(nodeManager.node); err != nil {
+		return err
+	}
+
+	if nodeManager.isImport {
+		err = nodeManager.importChain(file)
+		// flush the imported ledger
+		StopNode(nodeManager.node)
+		return err
+	}
+	return nodeManager.exportChain(file)
+}
+
+func (nodeManager *ArchiveNodeManager) exportChain(file string) error {
+	chainInstance := nodeManager.node.Vite().Chain()
+
+	fromHeight := uint64(1)
+	if nodeManager.ctx.GlobalIsSet(utils.ArchiveFromHeightFlag.Name) {
+		fromHeight = nodeManager.ctx.GlobalUint64(utils.ArchiveFromHeightFlag.Name)
+	}
+	toHeight := chainInstance.GetLatestSnapshotBlock().Height
+	if nodeManager.ctx.GlobalIsSet(utils.ArchiveToHeightFlag.Name) {
+		toHeight = nodeManager.ctx.GlobalUint64(utils.ArchiveToHeightFlag.Name)
+	}
+
+	fd, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Create archive file failed, error is %s", err.Error()))
+	}
+	defer fd.Close()
+
+	fmt.Printf("Export snapshot blocks from %d to %d into %s\n", fromHeight, toHeight, file)
+	if err := archive.Export(chainInstance, fd, fromHeight, toHeight, func(height uint64) {
+		if height%archiveProgressInterval == 0 {
+			fmt.Printf("Exported to %d\n", height)
+		}
+	}); err != nil {
+		return err
+	}
+
+	if err := fd.Sync(); err != nil {
+		return err
+	}
+	fmt.Printf("Export successed!\n")
+	return nil
+}
+
+func (nodeManager *ArchiveNodeManager) check(file string) error {
+	fd, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	header, count, err := archive.Check(fd)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Check archive failed, error is %s", err.Error()))
+	}
+	fmt.Printf("The archive is valid, version is %d, snapshot blocks are from %d to %d, %d items\n",
+		header.Version, header.FromHeight, header.ToHeight, count)
+	return nil
+}
+
+func (nodeManager *ArchiveNodeManager) importChain(file string) error {
+	fd, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+
+	vite := nodeManager.node.Vite()
+	importer := archive.NewImporter(vite.Chain(), vite.AccountVerifier(), vite.SnapshotVerifier())
+
+	skippedCount := 0
+	if err := importer.Import(fd, func(height uint64, skipped bool) {
+		if skipped {
+			skippedCount++
+		}
+		if height%archiveProgressInterval == 0 {
+			fmt.Printf("Imported to %d\n", height)
+		}
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("Import successed! %d snapshot blocks were already in the chain. Latest snapshot block height is %d\n",
+		skippedCount, vite.Chain().GetLatestSnapshotBlock().Height)
+	return nil
+}
+
+func (nodeManager *ArchiveNodeManager) Stop() error {
+
+	StopNode(nodeManager.node)
+
+	return nil
+}
+
+func (nodeManager *ArchiveNodeManager) Node() *node.Node {
+	return nodeManager.node
+}
@@ -42,6 +42,10 @@ func NewExportNodeManager(ctx *cli.Context, maker NodeMaker) (*ExportNodeManager
 	node.Config().LedgerGc = &ledgerGc
 	node.ViteConfig().Chain.LedgerGc = ledgerGc
 
+	// no ledger prune
+	node.Config().LedgerPrune = false
+	node.ViteConfig().Chain.LedgerPrune = false
+
 	return &ExportNodeManager{
 		ctx:  ctx,
 		node: node,
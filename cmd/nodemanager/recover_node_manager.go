@@ -35,6 +35,10 @@ func NewRecoverNodeManager(ctx *cli.Context, maker NodeMaker) (*RecoverNodeManag
 	node.Config().LedgerGc = &ledgerGc
 	node.ViteConfig().Chain.LedgerGc = ledgerGc
 
+	// no ledger prune
+	node.Config().LedgerPrune = false
+	node.ViteConfig().Chain.LedgerPrune = false
+
 	return &RecoverNodeManager{
 		ctx:  ctx,
 		node: node,
@@ -0,0 +1,214 @@
+package nodemanager
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/chain/state_snapshot"
+	"github.com/vitelabs/go-vite/cmd/utils"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/node"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const stateSnapshotProgressInterval = 100000
+
+// peers are asked for the state snapshot every stateSnapshotRetryInterval until stateSnapshotDownloadTimeout
+const stateSnapshotRetryInterval = 10 * time.Second
+const stateSnapshotDownloadTimeout = 10 * time.Minute
+
+type StateSnapshotNodeManager struct {
+	ctx         *cli.Context
+	node        *node.Node
+	isBootstrap bool
+}
+
+func NewStateSnapshotNodeManager(ctx *cli.Context, maker NodeMaker, isBootstrap bool) (*StateSnapshotNodeManager, error) {
+	node, err := maker.MakeNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if isBootstrap && !ctx.GlobalIsSet(utils.ArchiveFileFlag.Name) {
+		// the state snapshot is downloaded from peers, the ledger is not synced before it is bootstrapped
+		node.ViteConfig().Net.SyncDisabled = true
+	} else {
+		// single mode
+		node.Config().Single = true
+		node.ViteConfig().Net.Single = true
+	}
+
+	// no miner
+	node.Config().MinerEnabled = false
+	node.ViteConfig().Producer.Producer = false
+
+	// no ledger gc, the dumped tries must not be cleared
+	ledgerGc := false
+	node.Config().LedgerGc = &ledgerGc
+	node.ViteConfig().Chain.LedgerGc = ledgerGc
+
+	// no ledger prune
+	node.Config().LedgerPrune = false
+	node.ViteConfig().Chain.LedgerPrune = false
+
+	return &StateSnapshotNodeManager{
+		ctx:         ctx,
+		node:        node,
+		isBootstrap: isBootstrap,
+	}, nil
+}
+
+func (nodeManager *StateSnapshotNodeManager) Start() error {
+	if nodeManager.isBootstrap && !nodeManager.ctx.GlobalIsSet(utils.ArchiveFileFlag.Name) {
+		if err := StartNode(nodeManager.node); err != nil {
+			return err
+		}
+		snapshot, err := nodeManager.download()
+		if err == nil {
+			err = nodeManager.bootstrap(snapshot)
+		}
+		// flush the ledger
+		StopNode(nodeManager.node)
+		return err
+	}
+
+	if !nodeManager.ctx.GlobalIsSet(utils.ArchiveFileFlag.Name) {
+		return errors.New("`--file` is required")
+	}
+	file := nodeManager.ctx.GlobalString(utils.ArchiveFileFlag.Name)
+
+	if nodeManager.isBootstrap {
+		// load and verify the whole state snapshot before the node is touched
+		snapshot, err := nodeManager.load(file)
+		if err != nil {
+			return err
+		}
+
+		if err := StartNode(nodeManager.node); err != nil {
+			return err
+		}
+		err = nodeManager.bootstrap(snapshot)
+		// flush the ledger
+		StopNode(nodeManager.node)
+		return err
+	}
+
+	if err := StartNode(nodeManager.node); err != nil {
+		return err
+	}
+	return nodeManager.dump(file)
+}
+
+func (nodeManager *StateSnapshotNodeManager) dump(file string) error {
+	chainInstance := nodeManager.node.Vite().Chain()
+
+	height := chainInstance.GetLatestSnapshotBlock().Height
+	if nodeManager.ctx.GlobalIsSet(utils.StateSnapshotHeightFlag.Name) {
+		height = nodeManager.ctx.GlobalUint64(utils.StateSnapshotHeightFlag.Name)
+	}
+
+	fd, err := os.OpenFile(file, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.New(fmt.Sprintf("Create state snapshot file failed, error is %s", err.Error()))
+	}
+	defer fd.Close()
+
+	fmt.Printf("Dump the state at snapshot block %d into %s\n", height, file)
+	if err := state_snapshot.Dump(chainInstance, fd, height, func(accountCount uint64) {
+		if accountCount%stateSnapshotProgressInterval == 0 {
+			fmt.Printf("Dumped %d accounts\n", accountCount)
+		}
+	}); err != nil {
+		return err
+	}
+
+	if err := fd.Sync(); err != nil {
+		return err
+	}
+
+	snapshotBlock, err := chainInstance.GetSnapshotBlockByHeight(height)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Dump successed! The snapshot block hash is %s\n", snapshotBlock.Hash)
+	fmt.Printf("Put it into StateSnapshotDir as %s to serve it to peers\n", state_snapshot.Filename(snapshotBlock.Hash))
+	return nil
+}
+
+func (nodeManager *StateSnapshotNodeManager) trustedHash() (types.Hash, error) {
+	if !nodeManager.ctx.GlobalIsSet(utils.StateSnapshotHashFlag.Name) {
+		return types.Hash{}, errors.New("`--snapshotHash` is required")
+	}
+	trustedHash, err := types.HexToHash(nodeManager.ctx.GlobalString(utils.StateSnapshotHashFlag.Name))
+	if err != nil {
+		return types.Hash{}, errors.New(fmt.Sprintf("snapshotHash is invalid, error is %s", err.Error()))
+	}
+	return trustedHash, nil
+}
+
+// download asks the connected peers for the state snapshot until one of them sends a valid one.
+func (nodeManager *StateSnapshotNodeManager) download() (*state_snapshot.StateSnapshot, error) {
+	trustedHash, err := nodeManager.trustedHash()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Download the state snapshot %s from peers\n", trustedHash)
+	deadline := time.Now().Add(stateSnapshotDownloadTimeout)
+	for {
+		snapshot, err := nodeManager.node.Vite().Net().DownloadStateSnapshot(trustedHash)
+		if err == nil {
+			fmt.Printf("The state snapshot is valid, snapshot block is %d, %d accounts, %d trie nodes\n",
+				snapshot.SnapshotBlock.Height, len(snapshot.Accounts), len(snapshot.TrieNodes))
+			return snapshot, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New(fmt.Sprintf("Download state snapshot failed, error is %s", err.Error()))
+		}
+		time.Sleep(stateSnapshotRetryInterval)
+	}
+}
+
+func (nodeManager *StateSnapshotNodeManager) load(file string) (*state_snapshot.StateSnapshot, error) {
+	trustedHash, err := nodeManager.trustedHash()
+	if err != nil {
+		return nil, err
+	}
+
+	fd, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+
+	snapshot, err := state_snapshot.Load(fd, trustedHash)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Load state snapshot failed, error is %s", err.Error()))
+	}
+	fmt.Printf("The state snapshot is valid, snapshot block is %d, %d accounts, %d trie nodes\n",
+		snapshot.SnapshotBlock.Height, len(snapshot.Accounts), len(snapshot.TrieNodes))
+	return snapshot, nil
+}
+
+func (nodeManager *StateSnapshotNodeManager) bootstrap(snapshot *state_snapshot.StateSnapshot) error {
+	chainInstance := nodeManager.node.Vite().Chain()
+	if err := state_snapshot.Bootstrap(chainInstance, snapshot); err != nil {
+		return errors.New(fmt.Sprintf("Bootstrap failed, error is %s", err.Error()))
+	}
+
+	fmt.Printf("Bootstrap successed! Latest snapshot block height is %d\n", chainInstance.GetLatestSnapshotBlock().Height)
+	return nil
+}
+
+func (nodeManager *StateSnapshotNodeManager) Stop() error {
+
+	StopNode(nodeManager.node)
+
+	return nil
+}
+
+func (nodeManager *StateSnapshotNodeManager) Node() *node.Node {
+	return nodeManager.node
+}
@@ -0,0 +1,122 @@
+package nodemanager
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/chain/db_checker"
+	"github.com/vitelabs/go-vite/cmd/utils"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/node"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const verifyDbProgressInterval = 100000
+
+type VerifyDbNodeManager struct {
+	ctx  *cli.Context
+	node *node.Node
+}
+
+func NewVerifyDbNodeManager(ctx *cli.Context, maker NodeMaker) (*VerifyDbNodeManager, error) {
+	node, err := maker.MakeNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// the node is made for its config only, it isn't started
+	return &VerifyDbNodeManager{
+		ctx:  ctx,
+		node: node,
+	}, nil
+}
+
+// openChain opens the ledger which may be corrupt without starting the chain, so nothing is written to it before the
+// check, Start would init or roll back the data, and run the trie gc, the pruner and the indexes.
+func (nodeManager *VerifyDbNodeManager) openChain() chain.Chain {
+	cfg := *nodeManager.node.ViteConfig()
+	chainCfg := config.Chain{}
+	if cfg.Chain != nil {
+		chainCfg = *cfg.Chain
+	}
+	chainCfg.LedgerGc = false
+	chainCfg.LedgerPrune = false
+	chainCfg.OpenFilterTokenIndex = false
+	chainCfg.OpenSecondaryIndex = false
+	chainCfg.KafkaProducers = nil
+	chainCfg.EventSinks = nil
+	cfg.Chain = &chainCfg
+
+	fork.SetForkPoints(cfg.ForkPoints)
+
+	c := chain.NewChain(&cfg)
+	c.Init()
+	return c
+}
+
+func (nodeManager *VerifyDbNodeManager) Start() error {
+	c := nodeManager.openChain()
+	defer c.Destroy()
+
+	isJson := nodeManager.ctx.GlobalIsSet(utils.VerifyDbJsonFlag.Name)
+
+	report, err := db_checker.Check(c, c.TrieGc().RetainMinHeight(), func(stage string, count uint64) {
+		if !isJson && count%verifyDbProgressInterval == 0 {
+			fmt.Printf("Checked %d %s\n", count, stage)
+		}
+	})
+	if err != nil {
+		return errors.New(fmt.Sprintf("Verify db failed, error is %s", err.Error()))
+	}
+	safeHeight, ok := report.SafeHeight()
+
+	if isJson {
+		data, err := json.MarshalIndent(struct {
+			*db_checker.Report
+			SafeHeight uint64 `json:"safeHeight"`
+		}{report, safeHeight}, "", "\t")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	} else {
+		fmt.Printf("Latest snapshot block height is %d, %d snapshot blocks, %d accounts, %d account blocks, %d on road blocks\n",
+			report.LatestSnapshotHeight, report.SnapshotBlockCount, report.AccountCount, report.AccountBlockCount, report.OnRoadCount)
+		if report.BootstrapHeight > 0 {
+			fmt.Printf("The ledger is bootstrapped from the state at snapshot block %d\n", report.BootstrapHeight)
+		}
+		if report.PrunedHeight > 0 {
+			fmt.Printf("The account blocks confirmed by the snapshot blocks up to %d are pruned\n", report.PrunedHeight)
+		}
+		for _, problem := range report.Problems {
+			fmt.Println(problem.String())
+		}
+	}
+
+	if len(report.Problems) <= 0 {
+		if !isJson {
+			fmt.Printf("No problem found!\n")
+		}
+		return nil
+	}
+
+	if !isJson {
+		if ok {
+			fmt.Printf("Run `recover --del=%d` to fix the problems\n", safeHeight)
+		} else {
+			fmt.Printf("Some problems can't be fixed by recover, resync the ledger\n")
+		}
+	}
+	return errors.New(fmt.Sprintf("%d problems found", len(report.Problems)))
+}
+
+func (nodeManager *VerifyDbNodeManager) Stop() error {
+	return nil
+}
+
+func (nodeManager *VerifyDbNodeManager) Node() *node.Node {
+	return nodeManager.node
+}
@@ -186,6 +186,46 @@ var (
 		Usage: "The snapshot block height",
 	}
 
+	// Ledger archive
+	ArchiveFromHeightFlag = cli.Uint64Flag{
+		Name:  "from",
+		Usage: "The first snapshot block height to export",
+	}
+	ArchiveToHeightFlag = cli.Uint64Flag{
+		Name:  "to",
+		Usage: "The last snapshot block height to export, default is the latest height",
+	}
+	ArchiveFileFlag = cli.StringFlag{
+		Name:  "file",
+		Usage: "The ledger archive or state snapshot file",
+	}
+
+	// State snapshot
+	StateSnapshotHeightFlag = cli.Uint64Flag{
+		Name:  "height",
+		Usage: "The snapshot block height of the state to dump, default is the latest height",
+	}
+	StateSnapshotHashFlag = cli.StringFlag{
+		Name:  "snapshotHash",
+		Usage: "The trusted hash of the snapshot block in the state snapshot",
+	}
+
+	// Verify db
+	VerifyDbJsonFlag = cli.BoolFlag{
+		Name:  "json",
+		Usage: "Print the report of verify-db in json",
+	}
+
+	// Offline signing
+	EntropyStoreFileFlag = cli.StringFlag{
+		Name:  "entropystore",
+		Usage: "The entropy store file holding the key to sign with",
+	}
+	SignAddressFlag = cli.StringFlag{
+		Name:  "address",
+		Usage: "The address to sign with, default is the primary address of the entropy store",
+	}
+
 	//Net
 	SingleFlag = cli.BoolFlag{
 		Name:  "single",
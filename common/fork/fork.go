@@ -1,6 +1,7 @@
 package fork
 
 import (
+	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/config"
 	"reflect"
 	"sort"
@@ -21,6 +22,13 @@ func (a ForkPointList) Len() int           { return len(a) }
 func (a ForkPointList) Swap(i, j int)      { a[i], a[j] = a[j], a[i] }
 func (a ForkPointList) Less(i, j int) bool { return a[i].Height < a[j].Height }
 
+func init() {
+	// the addresses of the precompiled contracts added by forks are user accounts before the fork
+	types.SetPrecompiledContractFork(types.AddressBatchSend, IsBatchSendFork)
+	types.SetPrecompiledContractFork(types.AddressEscrow, IsEscrowFork)
+	types.SetPrecompiledContractFork(types.AddressMultiSig, IsMultiSigFork)
+}
+
 func SetForkPoints(points *config.ForkPoints) {
 	forkPoints = *points
 
@@ -29,6 +37,11 @@ func SetForkPoints(points *config.ForkPoints) {
 
 	for k := 0; k < t.NumField(); k++ {
 		forkPoint := v.Field(k).Interface().(*config.ForkPoint)
+		// a fork point not scheduled yet is nil and doesn't take part in the snapshot block hash,
+		// a fork point at height 0 does, the same as before the fork points which may be nil were added
+		if forkPoint == nil {
+			continue
+		}
 		forkPointList = append(forkPointList, &ForkPointItem{
 			ForkPoint: *forkPoint,
 			forkName:  t.Field(k).Name,
@@ -46,6 +59,18 @@ func IsMintFork(blockHeight uint64) bool {
 	return forkPoints.Mint.Height > 0 && blockHeight >= forkPoints.Mint.Height
 }
 
+func IsBatchSendFork(blockHeight uint64) bool {
+	return forkPoints.BatchSend != nil && forkPoints.BatchSend.Height > 0 && blockHeight >= forkPoints.BatchSend.Height
+}
+
+func IsEscrowFork(blockHeight uint64) bool {
+	return forkPoints.Escrow != nil && forkPoints.Escrow.Height > 0 && blockHeight >= forkPoints.Escrow.Height
+}
+
+func IsMultiSigFork(blockHeight uint64) bool {
+	return forkPoints.MultiSig != nil && forkPoints.MultiSig.Height > 0 && blockHeight >= forkPoints.MultiSig.Height
+}
+
 func GetForkPoints() config.ForkPoints {
 	return forkPoints
 }
@@ -26,24 +26,52 @@ var (
 	AddressPledge, _         = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 3})
 	AddressConsensusGroup, _ = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 4})
 	AddressMintage, _        = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 5})
+	AddressBatchSend, _      = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 6})
+	AddressEscrow, _         = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 7})
+	AddressMultiSig, _       = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 8})
 
-	PrecompiledContractAddressList             = []Address{AddressRegister, AddressVote, AddressPledge, AddressConsensusGroup, AddressMintage}
-	PrecompiledContractWithoutQuotaAddressList = []Address{AddressRegister, AddressVote, AddressPledge, AddressConsensusGroup, AddressMintage}
+	PrecompiledContractAddressList             = []Address{AddressRegister, AddressVote, AddressPledge, AddressConsensusGroup, AddressMintage, AddressBatchSend, AddressEscrow, AddressMultiSig}
+	PrecompiledContractWithoutQuotaAddressList = []Address{AddressRegister, AddressVote, AddressPledge, AddressConsensusGroup, AddressMintage, AddressBatchSend, AddressEscrow, AddressMultiSig}
 )
 
-func IsPrecompiledContractAddress(addr Address) bool {
+// precompiledContractForks holds the checks of the forks which add precompiled contracts, the address of such a
+// contract is a user account below its fork. The checks are set by package fork, which can't be imported here.
+var precompiledContractForks = make(map[Address]func(sbHeight uint64) bool)
+
+// SetPrecompiledContractFork makes addr a precompiled contract only at the snapshot heights where isFork is true
+func SetPrecompiledContractFork(addr Address, isFork func(sbHeight uint64) bool) {
+	precompiledContractForks[addr] = isFork
+}
+
+func isPrecompiledContractForked(addr Address, sbHeight uint64) bool {
+	isFork, ok := precompiledContractForks[addr]
+	return !ok || isFork(sbHeight)
+}
+
+// GetPrecompiledContractAddressList returns the precompiled contracts at snapshot height sbHeight
+func GetPrecompiledContractAddressList(sbHeight uint64) []Address {
+	addrList := make([]Address, 0, len(PrecompiledContractAddressList))
+	for _, cAddr := range PrecompiledContractAddressList {
+		if isPrecompiledContractForked(cAddr, sbHeight) {
+			addrList = append(addrList, cAddr)
+		}
+	}
+	return addrList
+}
+
+func IsPrecompiledContractAddress(addr Address, sbHeight uint64) bool {
 	for _, cAddr := range PrecompiledContractAddressList {
 		if cAddr == addr {
-			return true
+			return isPrecompiledContractForked(addr, sbHeight)
 		}
 	}
 	return false
 }
 
-func IsPrecompiledContractWithoutQuotaAddress(addr Address) bool {
+func IsPrecompiledContractWithoutQuotaAddress(addr Address, sbHeight uint64) bool {
 	for _, cAddr := range PrecompiledContractWithoutQuotaAddressList {
 		if cAddr == addr {
-			return true
+			return isPrecompiledContractForked(addr, sbHeight)
 		}
 	}
 	return false
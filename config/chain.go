@@ -5,11 +5,22 @@ type KafkaProducer struct {
 	Topic      string
 }
 
+// EventSink is a non-kafka destination of block events, Kind is one of "file", "unix" and "webhook",
+// Target is the file path, the socket path or the url.
+type EventSink struct {
+	Kind   string
+	Target string
+}
+
 type Chain struct {
 	KafkaProducers       []*KafkaProducer
+	EventSinks           []*EventSink
 	OpenBlackBlock       bool
 	LedgerGcRetain       uint64
 	GenesisFile          string
 	LedgerGc             bool
+	LedgerPrune          bool
+	LedgerPruneRetain    uint64
 	OpenFilterTokenIndex bool
+	OpenSecondaryIndex   bool
 }
@@ -15,6 +15,7 @@ type Config struct {
 	*Vm         `json:"Vm"`
 	*Subscribe  `json:"Subscribe"`
 	*Net        `json:"Net"`
+	*Pool       `json:"Pool"`
 	*biz.Reward `json:"Reward"`
 	*Genesis    `json:"Genesis"`
 
@@ -28,6 +29,10 @@ func (c Config) RunLogDir() string {
 	return filepath.Join(c.DataDir, "runlog")
 }
 
+func (c Config) PoolJournalDir() string {
+	return filepath.Join(c.DataDir, "pool_journal")
+}
+
 // DefaultDataDir is the default data directory to use for the databases and other persistence requirements.
 func DefaultDataDir() string {
 	// Try to place the data folder in the user's home dir
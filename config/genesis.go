@@ -38,8 +38,11 @@ type ForkPoint struct {
 }
 
 type ForkPoints struct {
-	Smart *ForkPoint
-	Mint  *ForkPoint
+	Smart     *ForkPoint
+	Mint      *ForkPoint
+	BatchSend *ForkPoint
+	Escrow    *ForkPoint
+	MultiSig  *ForkPoint
 }
 
 type Genesis struct {
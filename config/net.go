@@ -1,6 +1,8 @@
 package config
 
 type Net struct {
-	Single      bool   `json:"Single"`
-	FileAddress string `json:"FileAddress"`
+	Single           bool   `json:"Single"`
+	FileAddress      string `json:"FileAddress"`
+	StateSnapshotDir string `json:"StateSnapshotDir"`
+	SyncDisabled     bool   `json:"SyncDisabled"`
 }
@@ -0,0 +1,14 @@
+package config
+
+type Pool struct {
+	// PoolJournal keeps the blocks received but not yet inserted into chain on disk, they are replayed after restart
+	PoolJournal bool `json:"PoolJournal"`
+
+	// PoolAccountLimit is the max number of pending blocks of an address, 0 means no limit
+	PoolAccountLimit int `json:"PoolAccountLimit"`
+	// PoolGlobalLimit is the max number of pending account blocks in pool, the blocks of the account with the lowest
+	// priority are evicted when it is reached, 0 means no limit
+	PoolGlobalLimit int `json:"PoolGlobalLimit"`
+	// PoolPriority orders the accounts to evict, "quota" by pledge quota or "difficulty" by PoW difficulty
+	PoolPriority string `json:"PoolPriority"`
+}
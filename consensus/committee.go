@@ -101,7 +101,8 @@ func (self *committee) VerifyAccountProducer(accountBlock *ledger.AccountBlock)
 	if err != nil {
 		return false, errors.Wrap(err, fmt.Sprintf(" account[%s][%d] ", accountBlock.Hash, accountBlock.Height))
 	}
-	return self.verifyProducer(*accountBlock.Timestamp, accountBlock.Producer(), electionResult), nil
+	// the receive block of a contract is signed by the SBP
+	return self.verifyProducer(*accountBlock.Timestamp, types.PubkeyToAddress(accountBlock.PublicKey), electionResult), nil
 }
 
 func (self *committee) verifyProducer(t time.Time, address types.Address, result *electionResult) bool {
@@ -35,6 +35,16 @@ type GenResult struct {
 }
 
 func NewGenerator(chain vm_context.Chain, snapshotBlockHash, prevBlockHash *types.Hash, addr *types.Address) (*Generator, error) {
+	vmContext, err := vm_context.NewVmContext(chain, snapshotBlockHash, prevBlockHash, addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewGeneratorWithVmDatabase(vmContext)
+}
+
+// NewGeneratorWithVmDatabase creates a generator which runs the vm on the given database, the caller keeps the
+// database to compare the state before the block with the state after the vm.
+func NewGeneratorWithVmDatabase(vmContext vmctxt_interface.VmDatabase) (*Generator, error) {
 	gen := &Generator{
 		log:      log15.New("module", "Generator"),
 		sbHeight: 2,
@@ -42,10 +52,6 @@ func NewGenerator(chain vm_context.Chain, snapshotBlockHash, prevBlockHash *type
 
 	gen.vm = *vm.NewVM()
 
-	vmContext, err := vm_context.NewVmContext(chain, snapshotBlockHash, prevBlockHash, addr)
-	if err != nil {
-		return nil, err
-	}
 	gen.vmContext = vmContext
 
 	if sb := gen.vmContext.CurrentSnapshotBlock(); sb != nil {
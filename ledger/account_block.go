@@ -3,6 +3,7 @@ package ledger
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"math/big"
 	"time"
 
@@ -86,8 +87,6 @@ const (
 type AccountBlock struct {
 	Meta *AccountBlockMeta `json:"-"`
 
-	producer *types.Address
-
 	BlockType byte       `json:"blockType"`
 	Hash      types.Hash `json:"hash"`
 	Height    uint64     `json:"height"`
@@ -125,11 +124,6 @@ func (ab *AccountBlock) Copy() *AccountBlock {
 		newAb.Meta = ab.Meta.Copy()
 	}
 
-	if ab.producer != nil {
-		producer := *ab.producer
-		newAb.producer = &producer
-	}
-
 	if ab.Amount != nil {
 		newAb.Amount = new(big.Int).Set(ab.Amount)
 	}
@@ -162,18 +156,12 @@ func (ab *AccountBlock) Copy() *AccountBlock {
 	return &newAb
 }
 
+// Producer is decided by the account, not by the keys which sign the block. A block of a multisig account is signed
+// by its signers and a receive block of a contract is signed by the SBP, the producer of both is the account address.
 func (ab *AccountBlock) Producer() types.Address {
-	if ab.producer == nil {
-		if len(ab.PublicKey) > 0 {
-			producer := types.PubkeyToAddress(ab.PublicKey)
-			ab.producer = &producer
-		} else {
-			ab.producer = &ab.AccountAddress
-		}
-
-	}
-	return *ab.producer
+	return ab.AccountAddress
 }
+
 func (ab *AccountBlock) proto() *vitepb.AccountBlock {
 	pb := &vitepb.AccountBlock{}
 	pb.BlockType = uint32(ab.BlockType)
@@ -220,7 +208,8 @@ func (ab *AccountBlock) proto() *vitepb.AccountBlock {
 func (ab *AccountBlock) DbProto() *vitepb.AccountBlock {
 	pb := ab.proto()
 	pb.StateHash = ab.StateHash.Bytes()
-	if len(ab.Producer()) > 0 && !bytes.Equal(ab.Producer().Bytes(), ab.AccountAddress.Bytes()) {
+	// the public key is completed by the account when read, unless it is the keys of others
+	if len(ab.PublicKey) > 0 && types.PubkeyToAddress(ab.PublicKey) != ab.AccountAddress {
 		pb.PublicKey = ab.PublicKey
 	}
 
@@ -342,12 +331,72 @@ func (ab *AccountBlock) ComputeHash() types.Hash {
 	return hash
 }
 
+// VerifySignature verifies the signatures of all the signers, a block of a multisig account carries the public keys
+// and the signatures of the signers one after another.
 func (ab *AccountBlock) VerifySignature() bool {
-	isVerified, verifyErr := crypto.VerifySig(ab.PublicKey, ab.Hash.Bytes(), ab.Signature)
-	if verifyErr != nil {
-		accountBlockLog.Error("crypto.VerifySig failed, error is "+verifyErr.Error(), "method", "VerifySignature")
+	pubkeys, err := ab.SignerPublicKeys()
+	if err != nil {
+		accountBlockLog.Error("get signer public keys failed, error is "+err.Error(), "method", "VerifySignature")
+		return false
 	}
-	return isVerified
+	for i, pubkey := range pubkeys {
+		isVerified, verifyErr := crypto.VerifySig(pubkey, ab.Hash.Bytes(), ab.Signature[i*ed25519.SignatureSize:(i+1)*ed25519.SignatureSize])
+		if verifyErr != nil {
+			accountBlockLog.Error("crypto.VerifySig failed, error is "+verifyErr.Error(), "method", "VerifySignature")
+		}
+		if !isVerified {
+			return false
+		}
+	}
+	return true
+}
+
+// IsMultiSigPublicKey reports whether pubkey is the keys of several signers of a multisig account.
+func IsMultiSigPublicKey(pubkey []byte) bool {
+	return len(pubkey) > ed25519.PublicKeySize
+}
+
+// IsMultiSigned reports whether the block is signed by several signers of a multisig account.
+func (ab *AccountBlock) IsMultiSigned() bool {
+	return IsMultiSigPublicKey(ab.PublicKey)
+}
+
+// SignerPublicKeys splits the public key of the block into the keys of the signers.
+func (ab *AccountBlock) SignerPublicKeys() ([]ed25519.PublicKey, error) {
+	count := len(ab.PublicKey) / ed25519.PublicKeySize
+	if count == 0 || len(ab.PublicKey) != count*ed25519.PublicKeySize || len(ab.Signature) != count*ed25519.SignatureSize {
+		return nil, errors.New("invalid length of public key or signature")
+	}
+	pubkeys := make([]ed25519.PublicKey, count)
+	for i := range pubkeys {
+		pubkeys[i] = ab.PublicKey[i*ed25519.PublicKeySize : (i+1)*ed25519.PublicKeySize]
+		for _, pubkey := range pubkeys[:i] {
+			if bytes.Equal(pubkey, pubkeys[i]) {
+				return nil, errors.New("duplicated signer public key")
+			}
+		}
+	}
+	return pubkeys, nil
+}
+
+// AddSignature appends the signature of a signer of a multisig account, which is verified against the hash of the
+// block.
+func (ab *AccountBlock) AddSignature(pubkey ed25519.PublicKey, signature []byte) error {
+	isVerified, err := crypto.VerifySig(pubkey, ab.Hash.Bytes(), signature)
+	if err != nil {
+		return err
+	}
+	if !isVerified {
+		return errors.New("invalid signature")
+	}
+	for i := 0; i+ed25519.PublicKeySize <= len(ab.PublicKey); i += ed25519.PublicKeySize {
+		if bytes.Equal(ab.PublicKey[i:i+ed25519.PublicKeySize], pubkey) {
+			return errors.New("duplicated signer public key")
+		}
+	}
+	ab.PublicKey = append(append(ed25519.PublicKey{}, ab.PublicKey...), pubkey...)
+	ab.Signature = append(append([]byte{}, ab.Signature...), signature...)
+	return nil
 }
 
 func (ab *AccountBlock) DbSerialize() ([]byte, error) {
@@ -1,12 +1,14 @@
 package ledger
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"github.com/vitelabs/go-vite/common/types"
 
 	"encoding/base64"
 	"github.com/vitelabs/go-vite/crypto"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
 	"math/big"
 	"testing"
 	"time"
@@ -115,3 +117,54 @@ func TestCreateAccountBlock(t *testing.T) {
 	result, _ := json.Marshal(rpcBlock)
 	fmt.Println(string(result))
 }
+
+func TestAccountBlock_AddSignature(t *testing.T) {
+	_, priv1, _ := ed25519.GenerateKey(nil)
+	_, priv2, _ := ed25519.GenerateKey(nil)
+	ts := time.Unix(1539604021, 0)
+	multiSigAddr, _, _ := types.CreateAddress()
+	block := &AccountBlock{
+		BlockType:      BlockTypeSendCall,
+		AccountAddress: multiSigAddr,
+		Height:         1,
+		Amount:         big.NewInt(0),
+		Fee:            big.NewInt(0),
+		Timestamp:      &ts,
+	}
+	block.Hash = block.ComputeHash()
+	if err := block.AddSignature(priv1.PubByte(), ed25519.Sign(priv2, block.Hash.Bytes())); err == nil {
+		t.Fatal("add a signature of another key")
+	}
+	if err := block.AddSignature(priv1.PubByte(), ed25519.Sign(priv1, block.Hash.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	// a block of a 1-of-N multisig account is signed by a single key
+	if block.IsMultiSigned() || block.Producer() != block.AccountAddress {
+		t.Fatalf("producer of a single signer block is %s, expected %s", block.Producer(), block.AccountAddress)
+	}
+	if !bytes.Equal(block.DbProto().PublicKey, priv1.PubByte()) {
+		t.Fatal("public key of the single signer isn't kept in db")
+	}
+	if err := block.AddSignature(priv1.PubByte(), ed25519.Sign(priv1, block.Hash.Bytes())); err == nil {
+		t.Fatal("add a duplicated signature")
+	}
+	if err := block.AddSignature(priv2.PubByte(), ed25519.Sign(priv2, block.Hash.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	if pubkeys, err := block.SignerPublicKeys(); err != nil || len(pubkeys) != 2 {
+		t.Fatal("get signer public keys failed")
+	}
+	if !block.IsMultiSigned() || block.Producer() != block.AccountAddress {
+		t.Fatalf("producer of a multisig block is %s, expected %s", block.Producer(), block.AccountAddress)
+	}
+	if !bytes.Equal(block.DbProto().PublicKey, block.PublicKey) {
+		t.Fatal("public keys of the signers aren't kept in db")
+	}
+	if !block.VerifySignature() {
+		t.Fatal("verify signature failed")
+	}
+	block.Signature[len(block.Signature)-1] ^= 1
+	if block.VerifySignature() {
+		t.Fatal("verify a broken signature")
+	}
+}
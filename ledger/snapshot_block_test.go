@@ -0,0 +1,29 @@
+package ledger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+)
+
+// the fork name of the Smart and Mint fork points at height 0 is in the hash of every snapshot block
+func TestSnapshotBlock_ComputeHashForkPointsAtZero(t *testing.T) {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 0}, Mint: &config.ForkPoint{Height: 0}})
+
+	prevHash, _ := types.HexToHash("3e3393b720679ff09dbc57f6e23570dbca3dc947cf28cdcbad3abc1cb6da2bee")
+	stateHash, _ := types.HexToHash("48290760a0249c28e92bfbcac31e1c0b61e74f666bddc1a2574b96a7bb533852")
+	ts := time.Unix(1539604021, 0)
+	for height, hexHash := range map[uint64]string{
+		1:   "0abd16cf56bf7ea1f024f0fbff8c680b5458fba95d4403e3ec69a504d3173c19",
+		2:   "bb866aba96274e732d606eb0d5eaff331d09daf333bf102a81ae85279b8f16be",
+		100: "b453fe1a7e2ddb39b62be3d06d1ae5b8a8c035add492e0cf640cd6cb143891f0",
+	} {
+		sb := &SnapshotBlock{PrevHash: prevHash, Height: height, Timestamp: &ts, StateHash: stateHash}
+		if hash := sb.ComputeHash(); hash.String() != hexHash {
+			t.Fatalf("hash of snapshot block %d is %s, expected %s", height, hash, hexHash)
+		}
+	}
+}
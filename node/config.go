@@ -35,11 +35,17 @@ type Config struct {
 	// template：["broker1,broker2,...|topic",""]
 	KafkaProducers []string `json:"KafkaProducers"`
 
+	// template：["kind|target",""], kind is file, unix or webhook
+	EventSinks []string `json:"EventSinks"`
+
 	// chain
 	OpenBlackBlock       bool   `json:"OpenBlackBlock"`
 	LedgerGcRetain       uint64 `json:"LedgerGcRetain"`
 	LedgerGc             *bool  `json:"LedgerGc"`
+	LedgerPrune          bool   `json:"LedgerPrune"`
+	LedgerPruneRetain    uint64 `json:"LedgerPruneRetain"`
 	OpenFilterTokenIndex *bool  `json:"OpenFilterTokenIndex"`
+	OpenSecondaryIndex   *bool  `json:"OpenSecondaryIndex"`
 
 	// genesis
 	GenesisFile string `json:"GenesisFile"`
@@ -86,6 +92,9 @@ type Config struct {
 
 	PowServerUrl string `json:"PowServerUrl”`
 
+	// template：["address|abiFilePath",""], the abis are used to decode the logs of the contracts
+	ContractAbis []string `json:"ContractAbis"`
+
 	//Log level
 	LogLevel    string `json:"LogLevel"`
 	ErrorLogDir string `json:"ErrorLogDir"`
@@ -98,6 +107,12 @@ type Config struct {
 	// subscribe
 	SubscribeEnabled bool `json:"SubscribeEnabled"`
 
+	// pool
+	PoolJournal      bool   `json:"PoolJournal"`
+	PoolAccountLimit int    `json:"PoolAccountLimit"`
+	PoolGlobalLimit  int    `json:"PoolGlobalLimit"`
+	PoolPriority     string `json:"PoolPriority"`
+
 	//Net TODO: cmd after ？
 	Single                 bool     `json:"Single"`
 	FilePort               int      `json:"FilePort"`
@@ -106,6 +121,7 @@ type Config struct {
 	TopologyReportInterval int      `json:"TopologyReportInterval"`
 	TopoEnabled            bool     `json:"TopoEnabled"`
 	DashboardTargetURL     string
+	StateSnapshotDir       string `json:"StateSnapshotDir"` // state snapshots in it are served to peers
 
 	// reward
 	RewardAddr string `json:"RewardAddr"`
@@ -132,6 +148,7 @@ func (c *Config) makeViteConfig() *config.Config {
 		Net:       c.makeNetConfig(),
 		Vm:        c.makeVmConfig(),
 		Subscribe: c.makeSubscribeConfig(),
+		Pool:      c.makePoolConfig(),
 		Reward:    c.makeRewardConfig(),
 		Genesis:   c.makeGenesisConfig(),
 		LogLevel:  c.LogLevel,
@@ -142,8 +159,9 @@ func (c *Config) makeNetConfig() *config.Net {
 	fileAddress := "0.0.0.0:" + strconv.Itoa(c.FilePort)
 
 	return &config.Net{
-		Single:      c.Single,
-		FileAddress: fileAddress,
+		Single:           c.Single,
+		FileAddress:      fileAddress,
+		StateSnapshotDir: c.StateSnapshotDir,
 	}
 }
 
@@ -168,6 +186,15 @@ func (c *Config) makeSubscribeConfig() *config.Subscribe {
 	}
 }
 
+func (c *Config) makePoolConfig() *config.Pool {
+	return &config.Pool{
+		PoolJournal:      c.PoolJournal,
+		PoolAccountLimit: c.PoolAccountLimit,
+		PoolGlobalLimit:  c.PoolGlobalLimit,
+		PoolPriority:     c.PoolPriority,
+	}
+}
+
 func (c *Config) makeMetricsConfig() *metrics.Config {
 	mc := &metrics.Config{
 		IsEnable:         false,
@@ -378,6 +405,21 @@ func (c *Config) makeChainConfig() *config.Chain {
 		}
 	}
 
+	// init eventSinks
+	var eventSinks []*config.EventSink
+	for _, eventSink := range c.EventSinks {
+		splitEventSink := strings.SplitN(eventSink, "|", 2)
+		if len(splitEventSink) != 2 || splitEventSink[1] == "" {
+			log.Warn(fmt.Sprintf("EventSinks is setting error，The program will skip here and continue processing"))
+			continue
+		}
+
+		eventSinks = append(eventSinks, &config.EventSink{
+			Kind:   splitEventSink[0],
+			Target: splitEventSink[1],
+		})
+	}
+
 	ledgerGc := true
 	if c.LedgerGc != nil {
 		ledgerGc = *c.LedgerGc
@@ -386,13 +428,21 @@ func (c *Config) makeChainConfig() *config.Chain {
 	if c.OpenFilterTokenIndex != nil {
 		openFilterTokenIndex = *c.OpenFilterTokenIndex
 	}
+	openSecondaryIndex := false
+	if c.OpenSecondaryIndex != nil {
+		openSecondaryIndex = *c.OpenSecondaryIndex
+	}
 
 	return &config.Chain{
 		KafkaProducers:       kafkaProducers,
+		EventSinks:           eventSinks,
 		OpenBlackBlock:       c.OpenBlackBlock,
 		LedgerGcRetain:       c.LedgerGcRetain,
 		LedgerGc:             ledgerGc,
+		LedgerPrune:          c.LedgerPrune,
+		LedgerPruneRetain:    c.LedgerPruneRetain,
 		OpenFilterTokenIndex: openFilterTokenIndex,
+		OpenSecondaryIndex:   openSecondaryIndex,
 	}
 }
 
@@ -360,6 +360,9 @@ func (node *Node) startRPC() error {
 
 	// Init rpc log
 	rpcapi.Init(node.config.DataDir, node.config.LogLevel, node.config.TestTokenHexPrivKey, node.config.TestTokenTti, node.config.NetID)
+	if err := rpcapi.InitContractAbis(node.config.ContractAbis); err != nil {
+		return err
+	}
 
 	// Start the various API endpoints, terminating all in case of errors
 	if err := node.startInProcess(node.GetInProcessApis()); err != nil {
@@ -10,17 +10,17 @@ import (
 
 //In-proc apis
 func (node *Node) GetInProcessApis() []rpc.API {
-	return rpcapi.GetApis(node.viteServer, "ledger", "wallet", "private_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "consensusGroup", "testapi", "pow", "tx")
+	return rpcapi.GetApis(node.viteServer, "ledger", "wallet", "private_onroad", "net", "contract", "private_contract", "pledge", "register", "vote", "mintage", "batchSend", "escrow", "multisig", "consensusGroup", "testapi", "pow", "tx", "private_debug")
 }
 
 //Ipc apis
 func (node *Node) GetIpcApis() []rpc.API {
-	return rpcapi.GetApis(node.viteServer, "ledger", "wallet", "private_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "consensusGroup", "testapi", "pow", "tx")
+	return rpcapi.GetApis(node.viteServer, "ledger", "wallet", "private_onroad", "net", "contract", "private_contract", "pledge", "register", "vote", "mintage", "batchSend", "escrow", "multisig", "consensusGroup", "testapi", "pow", "tx", "private_debug")
 }
 
 //Http apis
 func (node *Node) GetHttpApis() []rpc.API {
-	apiModules := []string{"ledger", "public_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "consensusGroup", "pow", "tx"}
+	apiModules := []string{"ledger", "public_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "batchSend", "escrow", "multisig", "consensusGroup", "pow", "tx"}
 	if node.Config().NetID > 1 {
 		apiModules = append(apiModules, "testapi")
 	}
@@ -29,7 +29,7 @@ func (node *Node) GetHttpApis() []rpc.API {
 
 //WS apis
 func (node *Node) GetWSApis() []rpc.API {
-	apiModules := []string{"ledger", "public_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "consensusGroup", "pow", "tx"}
+	apiModules := []string{"ledger", "public_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "batchSend", "escrow", "multisig", "consensusGroup", "pow", "tx"}
 	if node.Config().NetID > 1 {
 		apiModules = append(apiModules, "testapi")
 	}
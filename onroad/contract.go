@@ -18,10 +18,11 @@ type ContractWorker struct {
 
 	uBlocksPool *model.OnroadBlocksPool
 
-	gid                 types.Gid
-	address             types.Address
-	accEvent            producerevent.AccountStartEvent
-	currentSnapshotHash types.Hash
+	gid                   types.Gid
+	address               types.Address
+	accEvent              producerevent.AccountStartEvent
+	currentSnapshotHash   types.Hash
+	currentSnapshotHeight uint64
 
 	status      int
 	statusMutex sync.Mutex
@@ -74,9 +75,9 @@ func (w *ContractWorker) Start(accEvent producerevent.AccountStartEvent) {
 	w.address = accEvent.Address
 	w.accEvent = accEvent
 	if sb := w.manager.chain.GetLatestSnapshotBlock(); sb != nil {
-		w.currentSnapshotHash = sb.Hash
+		w.currentSnapshotHash, w.currentSnapshotHeight = sb.Hash, sb.Height
 	} else {
-		w.currentSnapshotHash = w.accEvent.SnapshotHash
+		w.currentSnapshotHash, w.currentSnapshotHeight = w.accEvent.SnapshotHash, w.accEvent.SnapshotHeight
 	}
 
 	w.log = slog.New("worker", "c", "addr", accEvent.Address, "gid", accEvent.Gid)
@@ -297,7 +298,7 @@ func (w ContractWorker) Status() int {
 }
 
 func (w *ContractWorker) GetPledgeQuota(addr types.Address) uint64 {
-	if types.IsPrecompiledContractWithoutQuotaAddress(addr) {
+	if types.IsPrecompiledContractWithoutQuotaAddress(addr, w.currentSnapshotHeight) {
 		return math.MaxUint64
 	}
 	quota, err := w.manager.Chain().GetPledgeQuota(w.currentSnapshotHash, addr)
@@ -312,7 +313,7 @@ func (w *ContractWorker) GetPledgeQuotas(beneficialList []types.Address) map[typ
 	if w.gid == types.DELEGATE_GID {
 		commonContractAddressList := make([]types.Address, 0, len(beneficialList))
 		for _, addr := range beneficialList {
-			if types.IsPrecompiledContractWithoutQuotaAddress(addr) {
+			if types.IsPrecompiledContractWithoutQuotaAddress(addr, w.currentSnapshotHeight) {
 				quotas[addr] = math.MaxUint64
 			} else {
 				commonContractAddressList = append(commonContractAddressList, addr)
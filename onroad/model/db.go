@@ -163,8 +163,9 @@ func (ucf *OnroadSet) GetContractAddrList(gid *types.Gid) ([]types.Address, erro
 	}
 	if *gid == types.DELEGATE_GID {
 		commonAddrList := make([]types.Address, 0, len(addrList))
+		sbHeight := ucf.chain.GetLatestSnapshotBlock().Height
 		for _, v := range addrList {
-			if !types.IsPrecompiledContractAddress(v) {
+			if !types.IsPrecompiledContractAddress(v, sbHeight) {
 				commonAddrList = append(commonAddrList, v)
 			}
 		}
@@ -3,6 +3,7 @@ package discovery
 import (
 	"bytes"
 	"encoding/binary"
+	"math"
 	"os"
 	"time"
 
@@ -26,6 +27,9 @@ var (
 	activeKey  = []byte(":active")
 	pingKey    = []byte(":ping")
 	findKey    = []byte(":find")
+
+	// scores are kept apart from nodes, so they survive deleteNode
+	scorePrefix = []byte("s")
 )
 
 func newDB(path string, version int, id NodeID) (db *nodeDB, err error) {
@@ -222,6 +226,28 @@ func (db *nodeDB) storeInt64(key []byte, n int64) error {
 	return db.db.Put(key, buf, nil)
 }
 
+// retrieveScore return the score of node and the time it updated, updated is zero if the score is not stored
+func (db *nodeDB) retrieveScore(ID NodeID) (score float64, updated time.Time) {
+	key := bytes.Join([][]byte{scorePrefix, ID[:]}, nil)
+	buf, err := db.db.Get(key, nil)
+	if err != nil || len(buf) <= 8 {
+		return
+	}
+
+	score = math.Float64frombits(binary.BigEndian.Uint64(buf))
+	updated = time.Unix(decodeVarint(buf[8:]), 0)
+	return
+}
+
+func (db *nodeDB) storeScore(ID NodeID, score float64, updated time.Time) error {
+	buf := make([]byte, 8+binary.MaxVarintLen64)
+	binary.BigEndian.PutUint64(buf, math.Float64bits(score))
+	buf = buf[:8+binary.PutVarint(buf[8:], updated.Unix())]
+
+	key := bytes.Join([][]byte{scorePrefix, ID[:]}, nil)
+	return db.db.Put(key, buf, nil)
+}
+
 func (db *nodeDB) cleanStaleNodes() {
 	now := time.Now()
 
@@ -242,6 +268,17 @@ func (db *nodeDB) cleanStaleNodes() {
 			db.deleteNode(id)
 		}
 	}
+
+	// scores of nodes which haven't been connected for a long time are meaningless
+	sitr := db.db.NewIterator(util.BytesPrefix(scorePrefix), nil)
+	defer sitr.Release()
+
+	for sitr.Next() {
+		copy(id[:], sitr.Key()[len(scorePrefix):])
+		if _, updated := db.retrieveScore(id); now.Sub(updated) > seedMaxAge {
+			db.db.Delete(sitr.Key(), nil)
+		}
+	}
 }
 
 func (db *nodeDB) close() {
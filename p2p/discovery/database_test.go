@@ -38,3 +38,34 @@ func TestDB_Store_Node(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestDB_Store_Score(t *testing.T) {
+	db, err := newDB("", 3, NodeID{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	node := mockNode(true)
+	if _, updated := db.retrieveScore(node.ID); !updated.IsZero() {
+		t.Fatal("score should not exist")
+	}
+
+	now := time.Now()
+	if err = db.storeScore(node.ID, -12.5, now); err != nil {
+		t.Fatal(err)
+	}
+
+	// score is kept after node deleted
+	db.deleteNode(node.ID)
+
+	score, updated := db.retrieveScore(node.ID)
+	if score != -12.5 || updated.Unix() != now.Unix() {
+		t.Fatalf("retrieve score %f at %s, should be -12.5 at %s", score, updated, now)
+	}
+
+	db.storeScore(node.ID, 1, now.Add(-2*seedMaxAge))
+	db.cleanStaleNodes()
+	if _, updated = db.retrieveScore(node.ID); !updated.IsZero() {
+		t.Fatal("stale score should be cleaned")
+	}
+}
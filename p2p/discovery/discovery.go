@@ -57,6 +57,8 @@ type Discovery interface {
 	More(ch chan<- *Node, n int)
 	Nodes() []string
 	Delete(id NodeID)
+	RetrieveScore(id NodeID) (score float64, updated time.Time)
+	StoreScore(id NodeID, score float64, updated time.Time)
 }
 
 type discovery struct {
@@ -106,6 +108,25 @@ func (d *discovery) Delete(id NodeID) {
 	d.db.deleteNode(id)
 }
 
+// RetrieveScore return the score of node stored by StoreScore, updated is zero if not found
+func (d *discovery) RetrieveScore(id NodeID) (score float64, updated time.Time) {
+	if d.db == nil {
+		return
+	}
+
+	return d.db.retrieveScore(id)
+}
+
+func (d *discovery) StoreScore(id NodeID, score float64, updated time.Time) {
+	if d.db == nil {
+		return
+	}
+
+	if err := d.db.storeScore(id, score, updated); err != nil {
+		d.log.Error(fmt.Sprintf("store score of %s error: %v", id, err))
+	}
+}
+
 // New create a Discovery implementation
 func New(cfg *Config) Discovery {
 	d := &discovery{
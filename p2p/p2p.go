@@ -21,6 +21,7 @@ import (
 )
 
 var errSvrStarted = errors.New("server has started")
+var errVersionTooLow = errors.New("P2P version too low")
 var blockMinExpired = time.Minute
 var blockMaxExpired = 5 * time.Minute
 
@@ -76,6 +77,8 @@ type Server interface {
 	URL() string
 	Config() *Config
 	Block(id discovery.NodeID, ip net.IP, err error)
+	RetrieveScore(id discovery.NodeID) (score float64, updated time.Time)
+	StoreScore(id discovery.NodeID, score float64, updated time.Time)
 }
 
 type server struct {
@@ -454,16 +457,33 @@ func (svr *server) listenLoop() {
 }
 
 func (svr *server) setupConn(c net.Conn, flag connFlag, id discovery.NodeID) {
-	var err error
-	if err = svr.checkHead(c); err != nil {
+	head, err := svr.checkHead(c)
+	if err == errVersionTooLow {
+		// not a misbehaviour, the peer may connect again after it upgrades
+		svr.log.Warn(fmt.Sprintf("HeadShake with %s error: %v, their version %d", c.RemoteAddr(), err, head.Version))
+		c.Close()
+		return
+	}
+	if err != nil {
 		svr.log.Warn(fmt.Sprintf("HeadShake with %s error: %v, block it", c.RemoteAddr(), err))
 		c.Close()
 		svr.Block(id, c.RemoteAddr().(*net.TCPAddr).IP, err)
 		return
 	}
 
+	// peers of version 1 can't set up the secure channel, see MinVersion
+	conn := c
+	if head.Secure {
+		if conn, err = SecureHandshake(c, svr.config.PeerKey, !flag.is(inbound)); err != nil {
+			svr.log.Warn(fmt.Sprintf("SecureHandshake with %s error: %v, block it", c.RemoteAddr(), err))
+			c.Close()
+			svr.Block(id, c.RemoteAddr().(*net.TCPAddr).IP, err)
+			return
+		}
+	}
+
 	ts := &transport{
-		Conn:  c,
+		Conn:  conn,
 		flags: flag,
 	}
 
@@ -495,6 +515,11 @@ func (svr *server) handleTS(ts *transport, id discovery.NodeID) error {
 		return fmt.Errorf("unmatched server ID, dial %s got %s", id, their.ID)
 	}
 
+	// the NodeID in handshake must be the one proved by the secure channel
+	if sc, ok := ts.Conn.(*SecureConn); ok && their.ID != sc.RemoteID() {
+		return fmt.Errorf("unmatched handshake ID, secure channel %s got %s", sc.RemoteID(), their.ID)
+	}
+
 	if err = svr.checkConn(id, ts.flags); err != nil {
 		return err
 	}
@@ -514,26 +539,27 @@ func (svr *server) handleTS(ts *transport, id discovery.NodeID) error {
 	return nil
 }
 
-func (svr *server) checkHead(c net.Conn) error {
+// checkHead exchanges the head message, the returned head of the peer is not nil if err is errVersionTooLow
+func (svr *server) checkHead(c net.Conn) (*headMsg, error) {
 	head, err := headShake(c, &headMsg{
 		Version: Version,
 		NetID:   svr.config.NetID,
+		Secure:  true,
 	})
 
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if svr.config.NetID != head.NetID {
-		return fmt.Errorf("different NetID: our %s, their %s", svr.config.NetID, head.NetID)
+		return nil, fmt.Errorf("different NetID: our %s, their %s", svr.config.NetID, head.NetID)
 	}
 
-	// todo compatibility
-	if head.Version < Version {
-		return fmt.Errorf("P2P version too low: our %d, their %d", Version, head.Version)
+	if head.Version < MinVersion {
+		return head, errVersionTooLow
 	}
 
-	return nil
+	return head, nil
 }
 
 func (svr *server) checkConn(id discovery.NodeID, flag connFlag) error {
@@ -723,6 +749,23 @@ func (svr *server) UnSubNodes(ch chan<- *discovery.Node) {
 	svr.discv.UnSubNodes(ch)
 }
 
+// RetrieveScore return the score of node persisted in the node database of discovery
+func (svr *server) RetrieveScore(id discovery.NodeID) (score float64, updated time.Time) {
+	if svr.discv == nil {
+		return
+	}
+
+	return svr.discv.RetrieveScore(id)
+}
+
+func (svr *server) StoreScore(id discovery.NodeID, score float64, updated time.Time) {
+	if svr.discv == nil {
+		return
+	}
+
+	svr.discv.StoreScore(id, score, updated)
+}
+
 // NodeInfo represent current p2p node
 type NodeInfo struct {
 	ID        string        `json:"id"`
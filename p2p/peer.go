@@ -425,6 +425,12 @@ func (p *Peer) IP() net.IP {
 	return p.RemoteAddr().IP
 }
 
+// Secure is whether the connection runs on the secure channel, it doesn't for peers of version 1
+func (p *Peer) Secure() bool {
+	_, ok := p.ts.Conn.(*SecureConn)
+	return ok
+}
+
 func (p *Peer) Info() *PeerInfo {
 	caps := make([]string, len(p.pfs))
 
@@ -0,0 +1,222 @@
+package p2p
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common"
+	"github.com/vitelabs/go-vite/crypto"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/p2p/discovery"
+)
+
+// secure hello is the first packet after head shake, the node ID and an ephemeral X25519 public key
+const secureHelloLen = 64 // NodeID[32] + ephemeralKey[32]
+const secureFrameHeadLen = 4
+const maxSecureFrameSize = 64 * 1024 // maximum plaintext size of a frame
+
+var secureContext = []byte("vite p2p secure channel")
+
+var errSecureSignature = errors.New("signature of secure hello verify failed")
+var errSecureKey = errors.New("invalid ephemeral key of secure hello")
+var errSecureFrameTooLarge = errors.New("secure frame is too large")
+
+// SecureConn is a net.Conn whose data is sealed by AES-GCM in frames, the session keys are derived from an X25519
+// exchange of ephemeral keys signed by the ed25519 keys of both nodes.
+type SecureConn struct {
+	net.Conn
+	remoteID discovery.NodeID
+
+	rmu    sync.Mutex
+	rAead  cipher.AEAD
+	rNonce uint64
+	rbuf   []byte
+
+	wmu    sync.Mutex
+	wAead  cipher.AEAD
+	wNonce uint64
+}
+
+// SecureHandshake sets up a secure channel on conn, the dialer is the initiator. The remote node proves it holds the
+// private key of its NodeID by signing the hellos of both sides, so the session can't be relayed by a third node.
+func SecureHandshake(conn net.Conn, key ed25519.PrivateKey, initiator bool) (*SecureConn, error) {
+	conn.SetDeadline(time.Now().Add(shakeTimeout))
+	defer conn.SetDeadline(time.Time{})
+
+	ephPub, ephPriv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return nil, err
+	}
+	defer ephPriv.Clear()
+
+	our := make([]byte, secureHelloLen)
+	copy(our[:32], key.PubByte())
+	copy(our[32:], ephPub.ToX25519Pk())
+
+	their, err := exchangeSecurePacket(conn, our)
+	if err != nil {
+		return nil, err
+	}
+	remoteID, _ := discovery.Bytes2NodeID(their[:32])
+
+	var transcript []byte
+	if initiator {
+		transcript = crypto.Hash256(secureContext, our, their)
+	} else {
+		transcript = crypto.Hash256(secureContext, their, our)
+	}
+
+	sig, err := exchangeSecurePacket(conn, ed25519.Sign(key, transcript))
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(remoteID[:], transcript, sig) {
+		return nil, errSecureSignature
+	}
+
+	secret, err := crypto.X25519ComputeSecret(ephPriv.ToX25519Sk(), their[32:])
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(secret, make([]byte, len(secret))) {
+		return nil, errSecureKey
+	}
+
+	initiatorAead, err := newSecureAead(crypto.Hash256(secret, transcript, []byte("initiator")))
+	if err != nil {
+		return nil, err
+	}
+	responderAead, err := newSecureAead(crypto.Hash256(secret, transcript, []byte("responder")))
+	if err != nil {
+		return nil, err
+	}
+
+	sc := &SecureConn{
+		Conn:     conn,
+		remoteID: remoteID,
+	}
+	if initiator {
+		sc.wAead, sc.rAead = initiatorAead, responderAead
+	} else {
+		sc.wAead, sc.rAead = responderAead, initiatorAead
+	}
+	return sc, nil
+}
+
+// exchangeSecurePacket writes our packet and reads a packet of the same length from the remote node.
+func exchangeSecurePacket(conn net.Conn, our []byte) (their []byte, err error) {
+	send := make(chan error, 1)
+	common.Go(func() {
+		if n, err := conn.Write(our); err != nil {
+			send <- err
+		} else if n != len(our) {
+			send <- fmt.Errorf("write incomplete secure packet %d/%d", n, len(our))
+		} else {
+			send <- nil
+		}
+	})
+
+	their = make([]byte, len(our))
+	if _, err = io.ReadFull(conn, their); err != nil {
+		return nil, err
+	}
+
+	if err = <-send; err != nil {
+		return nil, err
+	}
+	return their, nil
+}
+
+func newSecureAead(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func secureNonce(aead cipher.AEAD, counter uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], counter)
+	return nonce
+}
+
+// RemoteID is the NodeID authenticated in the handshake.
+func (sc *SecureConn) RemoteID() discovery.NodeID {
+	return sc.remoteID
+}
+
+// Read decrypts the frames from the connection, a frame which fails authentication breaks the connection.
+func (sc *SecureConn) Read(p []byte) (n int, err error) {
+	sc.rmu.Lock()
+	defer sc.rmu.Unlock()
+
+	if len(sc.rbuf) == 0 {
+		if sc.rbuf, err = sc.readFrame(); err != nil {
+			return 0, err
+		}
+	}
+
+	n = copy(p, sc.rbuf)
+	sc.rbuf = sc.rbuf[n:]
+	return n, nil
+}
+
+func (sc *SecureConn) readFrame() ([]byte, error) {
+	head := make([]byte, secureFrameHeadLen)
+	if _, err := io.ReadFull(sc.Conn, head); err != nil {
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(head)
+	if size > uint32(maxSecureFrameSize+sc.rAead.Overhead()) {
+		return nil, errSecureFrameTooLarge
+	}
+
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(sc.Conn, frame); err != nil {
+		return nil, err
+	}
+
+	plain, err := sc.rAead.Open(frame[:0], secureNonce(sc.rAead, sc.rNonce), frame, head)
+	if err != nil {
+		return nil, err
+	}
+	sc.rNonce++
+	return plain, nil
+}
+
+// Write seals p into frames of at most maxSecureFrameSize bytes.
+func (sc *SecureConn) Write(p []byte) (n int, err error) {
+	sc.wmu.Lock()
+	defer sc.wmu.Unlock()
+
+	for len(p) > 0 {
+		size := len(p)
+		if size > maxSecureFrameSize {
+			size = maxSecureFrameSize
+		}
+
+		frame := make([]byte, secureFrameHeadLen, secureFrameHeadLen+size+sc.wAead.Overhead())
+		binary.BigEndian.PutUint32(frame, uint32(size+sc.wAead.Overhead()))
+		frame = sc.wAead.Seal(frame, secureNonce(sc.wAead, sc.wNonce), p[:size], frame[:secureFrameHeadLen])
+		sc.wNonce++
+
+		if _, err = sc.Conn.Write(frame); err != nil {
+			return
+		}
+
+		n += size
+		p = p[size:]
+	}
+
+	return
+}
@@ -0,0 +1,96 @@
+package p2p
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/p2p/discovery"
+)
+
+func mockSecureConns(t *testing.T, key1, key2 ed25519.PrivateKey) (*SecureConn, *SecureConn) {
+	c1, c2 := net.Pipe()
+
+	type result struct {
+		sc  *SecureConn
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		sc, err := SecureHandshake(c2, key2, false)
+		ch <- result{sc, err}
+	}()
+
+	sc1, err := SecureHandshake(c1, key1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := <-ch
+	if r.err != nil {
+		t.Fatal(r.err)
+	}
+	return sc1, r.sc
+}
+
+func TestSecureHandshake(t *testing.T) {
+	_, key1, _ := ed25519.GenerateKey(nil)
+	_, key2, _ := ed25519.GenerateKey(nil)
+	sc1, sc2 := mockSecureConns(t, key1, key2)
+	defer sc1.Close()
+	defer sc2.Close()
+
+	id1, _ := discovery.Priv2NodeID(key1)
+	id2, _ := discovery.Priv2NodeID(key2)
+	if sc1.RemoteID() != id2 || sc2.RemoteID() != id1 {
+		t.Fatalf("remote ID of secure channel is wrong")
+	}
+
+	// payload crosses several frames
+	payload := make([]byte, 3*maxSecureFrameSize+100)
+	for i := range payload {
+		payload[i] = byte(i)
+	}
+
+	errch := make(chan error, 1)
+	go func() {
+		msg := NewMsg()
+		msg.CmdSet = 1
+		msg.Cmd = 2
+		msg.Id = 3
+		msg.Payload = payload
+		errch <- WriteMsg(sc1, msg)
+	}()
+
+	msg, err := ReadMsg(sc2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = <-errch; err != nil {
+		t.Fatal(err)
+	}
+	if msg.CmdSet != 1 || msg.Cmd != 2 || msg.Id != 3 || !bytes.Equal(msg.Payload, payload) {
+		t.Fatalf("message read from secure channel is wrong")
+	}
+}
+
+func TestSecureConn_Tampered(t *testing.T) {
+	_, key1, _ := ed25519.GenerateKey(nil)
+	_, key2, _ := ed25519.GenerateKey(nil)
+	sc1, sc2 := mockSecureConns(t, key1, key2)
+	defer sc1.Close()
+	defer sc2.Close()
+
+	// write a sealed frame with a flipped bit directly to the underlying connection
+	go func() {
+		frame := make([]byte, secureFrameHeadLen, secureFrameHeadLen+10+sc1.wAead.Overhead())
+		frame[3] = byte(10 + sc1.wAead.Overhead())
+		frame = sc1.wAead.Seal(frame, secureNonce(sc1.wAead, sc1.wNonce), make([]byte, 10), frame[:secureFrameHeadLen])
+		frame[len(frame)-1] ^= 1
+		sc1.Conn.Write(frame)
+	}()
+
+	if _, err := sc2.Read(make([]byte, 10)); err == nil {
+		t.Fatalf("read a tampered frame")
+	}
+}
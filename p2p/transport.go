@@ -7,6 +7,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/golang/snappy"
 	"github.com/pkg/errors"
 	"github.com/vitelabs/go-vite/common"
 	"github.com/vitelabs/go-vite/crypto/ed25519"
@@ -15,23 +16,36 @@ import (
 
 type P2PVersion = uint32
 
-const Version P2PVersion = 1
+// Version 2 sets up a secure channel after head shake if both peers advertise headFlagSecure
+const Version P2PVersion = 2
+
+// MinVersion is the lowest version accepted, peers of version 1 are still accepted without a secure channel
+// until the network has upgraded, then it is raised to 2 and the secure channel is required
+const MinVersion P2PVersion = 1
 
 const baseProtocolCmdSet = 0
 const handshakeCmd = 0
 const discCmd = 1
 
+// cmdSet[4] + cmd[2] + id[8] + size[4] + sendAt[8] + flag[1] + reserved[5]
 const headerLength = 32
 const maxPayloadSize = ^uint32(0) >> 8 // 15MB
 const shakeTimeout = 10 * time.Second
 
+// flags of message header, peers of old version always write 0 and ignore the flag byte
+const msgFlagCompressed byte = 1
+
 // head message is the first message in a tcp connection
 type headMsg struct {
 	Version P2PVersion
 	NetID   network.ID
+	Secure  bool // the peer can set up the secure channel
 }
 
-const headMsgLen = 32 // netId[4] + version[4]
+const headMsgLen = 32 // netId[4] + version[4] + flag[1] + reserved[23]
+
+// flags of head message, peers of version 1 always write 0 and ignore the flag byte
+const headFlagSecure byte = 1
 
 func readHead(conn net.Conn) (head *headMsg, err error) {
 	conn.SetReadDeadline(time.Now().Add(shakeTimeout))
@@ -46,6 +60,7 @@ func readHead(conn net.Conn) (head *headMsg, err error) {
 	head = new(headMsg)
 	head.NetID = network.ID(binary.BigEndian.Uint32(headPacket[:4]))
 	head.Version = binary.BigEndian.Uint32(headPacket[4:8])
+	head.Secure = headPacket[8]&headFlagSecure != 0
 
 	return
 }
@@ -57,6 +72,9 @@ func writeHead(conn net.Conn, head *headMsg) error {
 	headPacket := make([]byte, headMsgLen)
 	binary.BigEndian.PutUint32(headPacket[:4], uint32(head.NetID))
 	binary.BigEndian.PutUint32(headPacket[4:8], head.Version)
+	if head.Secure {
+		headPacket[8] |= headFlagSecure
+	}
 
 	if n, err := conn.Write(headPacket); err != nil {
 		return err
@@ -137,6 +155,13 @@ func ReadMsg(reader io.Reader) (msg *Msg, err error) {
 		return
 	}
 
+	if head[26]&msgFlagCompressed != 0 {
+		if payload, err = decompressPayload(payload); err != nil {
+			return nil, err
+		}
+		msg.Compressed = true
+	}
+
 	msg.Payload = payload
 	msg.SendAt = time.Unix(int64(binary.BigEndian.Uint64(head[18:26])), 0)
 	msg.ReceivedAt = time.Now()
@@ -147,7 +172,8 @@ func ReadMsg(reader io.Reader) (msg *Msg, err error) {
 func WriteMsg(writer io.Writer, msg *Msg) (err error) {
 	defer msg.Recycle()
 
-	size := uint32(len(msg.Payload))
+	payload := msg.Payload
+	size := uint32(len(payload))
 
 	if size == 0 {
 		return errMsgNull
@@ -157,12 +183,23 @@ func WriteMsg(writer io.Writer, msg *Msg) (err error) {
 		return errMsgTooLarge
 	}
 
+	var flag byte
+	if msg.Compressed {
+		// send the raw payload if compression doesn`t save anything
+		if compressed := snappy.Encode(nil, payload); len(compressed) < len(payload) {
+			payload = compressed
+			size = uint32(len(payload))
+			flag |= msgFlagCompressed
+		}
+	}
+
 	head := make([]byte, headerLength)
 	binary.BigEndian.PutUint32(head[:4], msg.CmdSet)
 	binary.BigEndian.PutUint16(head[4:6], msg.Cmd)
 	binary.BigEndian.PutUint64(head[6:14], msg.Id)
 	binary.BigEndian.PutUint32(head[14:18], size)
 	binary.BigEndian.PutUint64(head[18:26], uint64(time.Now().Unix()))
+	head[26] = flag
 
 	// write header
 	var n int
@@ -173,7 +210,7 @@ func WriteMsg(writer io.Writer, msg *Msg) (err error) {
 	}
 
 	// write payload
-	if n, err = writer.Write(msg.Payload); err != nil {
+	if n, err = writer.Write(payload); err != nil {
 		return
 	} else if uint32(n) != size {
 		return fmt.Errorf("write incomplement message payload %d/%d bytes", n, size)
@@ -182,6 +219,19 @@ func WriteMsg(writer io.Writer, msg *Msg) (err error) {
 	return
 }
 
+// decompressPayload check the decoded length first, so a small payload can`t be decompressed to a huge one
+func decompressPayload(payload []byte) ([]byte, error) {
+	n, err := snappy.DecodedLen(payload)
+	if err != nil {
+		return nil, err
+	}
+	if n > int(maxPayloadSize) {
+		return nil, errMsgTooLarge
+	}
+
+	return snappy.Decode(nil, payload)
+}
+
 var errHandshakeVerify = errors.New("signature of handshake Msg verify failed")
 var errHandshakeNotComp = errors.New("handshake payload is too small, maybe old version")
 
@@ -1,7 +1,12 @@
 package p2p
 
 import (
+	"bytes"
 	"math/rand"
+	"net"
+	"testing"
+
+	"github.com/vitelabs/go-vite/p2p/network"
 )
 
 func mockPayload() ([]byte, int) {
@@ -34,3 +39,104 @@ func mockMsg() (*Msg, error) {
 
 	return msg, nil
 }
+
+func TestWriteMsg_Compressed(t *testing.T) {
+	payload := bytes.Repeat([]byte("vite"), 1000)
+
+	msg := NewMsg()
+	msg.CmdSet = 2
+	msg.Cmd = 3
+	msg.Id = 4
+	msg.Payload = payload
+	msg.Compressed = true
+
+	buf := new(bytes.Buffer)
+	if err := WriteMsg(buf, msg); err != nil {
+		t.Fatal(err)
+	}
+	if buf.Len() >= headerLength+len(payload) {
+		t.Fatalf("payload should be compressed, message is %d bytes", buf.Len())
+	}
+
+	msg2, err := ReadMsg(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !msg2.Compressed || msg2.CmdSet != 2 || msg2.Cmd != 3 || msg2.Id != 4 || !bytes.Equal(msg2.Payload, payload) {
+		t.Fatalf("compressed message read is wrong")
+	}
+}
+
+func TestWriteMsg_Incompressible(t *testing.T) {
+	msg, _ := mockMsg()
+	payload := make([]byte, 1000)
+	rand.Read(payload)
+	msg.Payload = payload
+	msg.Compressed = true
+
+	buf := new(bytes.Buffer)
+	if err := WriteMsg(buf, msg); err != nil {
+		t.Fatal(err)
+	}
+
+	// random payload is sent raw, so peers of old version can read it
+	if buf.Bytes()[26] != 0 {
+		t.Fatalf("incompressible payload should not be flagged as compressed")
+	}
+
+	msg2, err := ReadMsg(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg2.Compressed || !bytes.Equal(msg2.Payload, payload) {
+		t.Fatalf("message read is wrong")
+	}
+}
+
+// checkHeadWith runs the head shake of svr against a peer which sends head
+func checkHeadWith(svr *server, head *headMsg) (*headMsg, *headMsg, error) {
+	c1, c2 := net.Pipe()
+	defer c1.Close()
+	defer c2.Close()
+
+	ch := make(chan *headMsg, 1)
+	go func() {
+		our, _ := headShake(c2, head)
+		ch <- our
+	}()
+
+	their, err := svr.checkHead(c1)
+	return their, <-ch, err
+}
+
+func TestCheckHead_Version(t *testing.T) {
+	svr := &server{config: &Config{NetID: network.Aquarius}}
+
+	// peers of version 1 write 0 in the flag byte, they are accepted without the secure channel
+	their, our, err := checkHeadWith(svr, &headMsg{Version: 1, NetID: network.Aquarius})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if their.Secure {
+		t.Fatal("peer of version 1 should not set up the secure channel")
+	}
+	if our.Version != Version || !our.Secure {
+		t.Fatalf("head should advertise version %d and the secure channel: %+v", Version, our)
+	}
+
+	their, _, err = checkHeadWith(svr, &headMsg{Version: Version, NetID: network.Aquarius, Secure: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !their.Secure {
+		t.Fatal("peer of version 2 should set up the secure channel")
+	}
+
+	// a version mismatch is reported as errVersionTooLow, so the peer is not blocked
+	if _, _, err = checkHeadWith(svr, &headMsg{Version: 0, NetID: network.Aquarius}); err != errVersionTooLow {
+		t.Fatalf("version 0 should be rejected as %v, but %v", errVersionTooLow, err)
+	}
+	if _, _, err = checkHeadWith(svr, &headMsg{Version: Version, NetID: network.Aquarius + 1}); err == nil || err == errVersionTooLow {
+		t.Fatalf("different NetID should be rejected, but %v", err)
+	}
+}
@@ -35,6 +35,9 @@ type Msg struct {
 	Payload    []byte
 	ReceivedAt time.Time
 	SendAt     time.Time
+	// Compressed payload is compressed by snappy on the wire, set it only if the remote node can read compressed
+	// messages. ReadMsg decompresses the payload, so handlers always get the raw payload.
+	Compressed bool
 }
 
 func (msg *Msg) Recycle() {
@@ -0,0 +1,265 @@
+package pool
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/monitor"
+)
+
+const (
+	PriorityQuota      = "quota"      // accounts with more pledge quota are kept
+	PriorityDifficulty = "difficulty" // accounts whose latest block has a higher PoW difficulty are kept
+)
+
+// reasons of the account blocks rejected by pool
+const (
+	rejectVerifyFail   = "verifyFail"
+	rejectAccountLimit = "accountLimit"
+	rejectGlobalLimit  = "globalLimit"
+)
+
+var errAccountLimit = errors.New("pending blocks of account reach the limit")
+var errGlobalLimit = errors.New("pending blocks of pool reach the limit")
+
+// the pending total is recounted from all account pools at most once every admissionRecount
+const admissionRecount = time.Second
+
+// the pledge quota of an account is queried from chain at most once every quotaCacheTime
+const quotaCacheTime = time.Minute
+
+type quotaReader interface {
+	GetPledgeQuota(snapshotHash types.Hash, beneficial types.Address) (uint64, error)
+}
+
+type accountPriority struct {
+	value   uint64
+	updated time.Time
+}
+
+// admission decides whether a block from network can be added to pool. An account can have at most accountLimit
+// pending blocks, and when there are globalLimit pending blocks in pool, the accounts with the lowest priority are
+// evicted for a block of higher priority.
+type admission struct {
+	accountLimit int // 0 means no limit
+	globalLimit  int // 0 means no limit
+	priority     string
+	quota        quotaReader // nil if chain can't query pledge quota
+
+	mu         sync.Mutex
+	total      int
+	counted    time.Time
+	priorities map[types.Address]*accountPriority
+	rejected   map[string]uint64
+	evicted    uint64
+}
+
+func newAdmission(bc chainDb) *admission {
+	a := &admission{
+		priority:   PriorityQuota,
+		priorities: make(map[types.Address]*accountPriority),
+		rejected:   make(map[string]uint64),
+	}
+	a.quota, _ = bc.(quotaReader)
+	return a
+}
+
+func (self *admission) reject(reason string, err error) error {
+	monitor.LogEvent("pool", "reject_"+reason)
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.rejected[reason]++
+	return err
+}
+
+// priorityOf updates the priority of account by its new block.
+func (self *admission) priorityOf(bc chainDb, block *ledger.AccountBlock) uint64 {
+	self.mu.Lock()
+	p, ok := self.priorities[block.AccountAddress]
+	if !ok {
+		p = &accountPriority{}
+		self.priorities[block.AccountAddress] = p
+	}
+	self.mu.Unlock()
+
+	now := time.Now()
+	switch self.priority {
+	case PriorityDifficulty:
+		var value uint64
+		if block.Difficulty != nil {
+			if block.Difficulty.IsUint64() {
+				value = block.Difficulty.Uint64()
+			} else {
+				value = math.MaxUint64
+			}
+		}
+		self.setPriority(p, value, now)
+	default:
+		if self.quota == nil {
+			break
+		}
+		self.mu.Lock()
+		fresh := now.Sub(p.updated) < quotaCacheTime
+		self.mu.Unlock()
+		if fresh {
+			break
+		}
+		head := bc.GetLatestSnapshotBlock()
+		if head == nil {
+			break
+		}
+		value, err := self.quota.GetPledgeQuota(head.Hash, block.AccountAddress)
+		if err != nil {
+			break
+		}
+		self.setPriority(p, value, now)
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return p.value
+}
+
+func (self *admission) setPriority(p *accountPriority, value uint64, now time.Time) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	p.value = value
+	p.updated = now
+}
+
+// candidates are the accounts whose priority is lower than priority, the lowest first.
+func (self *admission) candidates(except types.Address, priority uint64) []types.Address {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	var addrs []types.Address
+	for addr, p := range self.priorities {
+		if addr != except && p.value < priority {
+			addrs = append(addrs, addr)
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return self.priorities[addrs[i]].value < self.priorities[addrs[j]].value
+	})
+	return addrs
+}
+
+// pendingTotal returns the number of pending account blocks in pool.
+func (self *pool) pendingTotal() int {
+	a := self.admission
+	now := time.Now()
+	a.mu.Lock()
+	if now.Sub(a.counted) < admissionRecount {
+		defer a.mu.Unlock()
+		return a.total
+	}
+	a.mu.Unlock()
+
+	total := 0
+	var empty []types.Address
+	self.pendingAc.Range(func(_, v interface{}) bool {
+		p := v.(*accountPool)
+		n := p.blockpool.size()
+		if n == 0 {
+			empty = append(empty, p.address)
+		}
+		total += n
+		return true
+	})
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.total = total
+	a.counted = now
+	for _, addr := range empty {
+		delete(a.priorities, addr)
+	}
+	return total
+}
+
+// admitAccountBlock checks the limits before a verified block from network is added to the pool of its account.
+func (self *pool) admitAccountBlock(ac *accountPool, block *ledger.AccountBlock) error {
+	a := self.admission
+	if ac.existInPool(block.Hash) {
+		return nil
+	}
+	if a.accountLimit > 0 && ac.blockpool.size() >= a.accountLimit {
+		return a.reject(rejectAccountLimit, errAccountLimit)
+	}
+	if a.globalLimit <= 0 {
+		return nil
+	}
+
+	priority := a.priorityOf(self.bc, block)
+	total := self.pendingTotal()
+	if total >= a.globalLimit {
+		for _, addr := range a.candidates(ac.address, priority) {
+			evicted := self.selfPendingAc(addr).evict()
+			n := len(evicted)
+			if n == 0 {
+				continue
+			}
+			// the evicted blocks are not replayed after restart
+			self.journal.delAccountBlocks(evicted)
+			self.log.Warn("evict account blocks from pool", "addr", addr, "num", n)
+			monitor.LogEventNum("pool", "evict", n)
+
+			a.mu.Lock()
+			a.evicted += uint64(n)
+			a.total -= n
+			total = a.total
+			a.mu.Unlock()
+			if total < a.globalLimit {
+				break
+			}
+		}
+		if total >= a.globalLimit {
+			return a.reject(rejectGlobalLimit, errGlobalLimit)
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.total++
+	return nil
+}
+
+// SetAdmission limits the pending blocks from network of an account and of the whole pool, 0 means no limit.
+// priority is PriorityQuota or PriorityDifficulty, the pledge quota is used if it is empty.
+func (self *pool) SetAdmission(accountLimit int, globalLimit int, priority string) {
+	a := self.admission
+	a.accountLimit = accountLimit
+	a.globalLimit = globalLimit
+	switch priority {
+	case "":
+	case PriorityQuota, PriorityDifficulty:
+		a.priority = priority
+	default:
+		self.log.Warn("unknown pool priority, use quota.", "priority", priority)
+	}
+}
+
+func (self *pool) Admission() map[string]interface{} {
+	a := self.admission
+	total := self.pendingTotal()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	rejected := make(map[string]uint64)
+	for k, v := range a.rejected {
+		rejected[k] = v
+	}
+	result := make(map[string]interface{})
+	result["AccountLimit"] = a.accountLimit
+	result["GlobalLimit"] = a.globalLimit
+	result["Priority"] = a.priority
+	result["Pending"] = total
+	result["Rejected"] = rejected
+	result["Evicted"] = a.evicted
+	return result
+}
@@ -0,0 +1,147 @@
+package pool
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+// admissionChain has no account blocks, the pledge quota is not queried when priority is difficulty
+type admissionChain struct {
+	chainDb
+}
+
+func (c *admissionChain) GetLatestAccountBlock(addr *types.Address) (*ledger.AccountBlock, error) {
+	return nil, nil
+}
+
+func admissionBlock(addr types.Address, height uint64, difficulty int64) *ledger.AccountBlock {
+	return &ledger.AccountBlock{
+		AccountAddress: addr,
+		Height:         height,
+		Hash:           types.Hash{addr[0], byte(height)},
+		Difficulty:     big.NewInt(difficulty),
+	}
+}
+
+func admitAndAdd(p *pool, block *ledger.AccountBlock) error {
+	ac := p.selfPendingAc(block.AccountAddress)
+	if err := p.admitAccountBlock(ac, block); err != nil {
+		return err
+	}
+	ac.AddBlock(newAccountPoolBlock(block, nil, p.version, types.RemoteBroadcast))
+	return nil
+}
+
+func TestAdmission_AccountLimit(t *testing.T) {
+	p := NewPool(&admissionChain{})
+	p.SetAdmission(2, 0, "")
+
+	addr := types.Address{1}
+	for i := uint64(1); i <= 2; i++ {
+		if err := admitAndAdd(p, admissionBlock(addr, i, 0)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// duplicate block isn't counted
+	if err := admitAndAdd(p, admissionBlock(addr, 2, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if err := admitAndAdd(p, admissionBlock(addr, 3, 0)); err != errAccountLimit {
+		t.Fatalf("expect %v, got %v", errAccountLimit, err)
+	}
+	// other accounts are not affected
+	if err := admitAndAdd(p, admissionBlock(types.Address{2}, 1, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	rejected := p.Admission()["Rejected"].(map[string]uint64)
+	if rejected[rejectAccountLimit] != 1 {
+		t.Fatalf("rejected %v", rejected)
+	}
+}
+
+func TestAdmission_GlobalLimit(t *testing.T) {
+	p := NewPool(&admissionChain{})
+	p.SetAdmission(0, 3, PriorityDifficulty)
+
+	low := types.Address{1}
+	for i := uint64(1); i <= 3; i++ {
+		if err := admitAndAdd(p, admissionBlock(low, i, 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	high := types.Address{2}
+	// a block of the same priority can't evict others
+	if err := admitAndAdd(p, admissionBlock(high, 1, 1)); err != errGlobalLimit {
+		t.Fatalf("expect %v, got %v", errGlobalLimit, err)
+	}
+	if err := admitAndAdd(p, admissionBlock(high, 1, 10)); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := p.selfPendingAc(low).blockpool.size(); n != 0 {
+		t.Fatalf("%d blocks of low priority account are left", n)
+	}
+	stat := p.Admission()
+	if stat["Evicted"].(uint64) != 3 || stat["Pending"].(int) != 1 {
+		t.Fatalf("admission stat %v", stat)
+	}
+	if stat["Rejected"].(map[string]uint64)[rejectGlobalLimit] != 1 {
+		t.Fatalf("admission stat %v", stat)
+	}
+}
+
+func TestAdmission_EvictJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pool_journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := NewPool(&admissionChain{})
+	p.SetAdmission(0, 2, PriorityDifficulty)
+	p.SetJournal(dir)
+	if err = p.journal.open(); err != nil {
+		t.Fatal(err)
+	}
+	defer p.journal.close()
+
+	// the fields needed by serialization
+	journalBlock := func(block *ledger.AccountBlock) *ledger.AccountBlock {
+		now := time.Now()
+		block.Timestamp = &now
+		block.Amount = big.NewInt(0)
+		block.Fee = big.NewInt(0)
+		return block
+	}
+
+	low := types.Address{1}
+	for i := uint64(1); i <= 2; i++ {
+		block := journalBlock(admissionBlock(low, i, 1))
+		if err := admitAndAdd(p, block); err != nil {
+			t.Fatal(err)
+		}
+		p.journal.putAccountBlock(block, types.RemoteBroadcast)
+	}
+	high := journalBlock(admissionBlock(types.Address{2}, 1, 10))
+	if err := admitAndAdd(p, high); err != nil {
+		t.Fatal(err)
+	}
+	p.journal.putAccountBlock(high, types.RemoteBroadcast)
+
+	var hashes []types.Hash
+	p.journal.foreachAccountBlock(func(block *ledger.AccountBlock, _ types.BlockSource, _ time.Time) bool {
+		hashes = append(hashes, block.Hash)
+		return false
+	})
+	if len(hashes) != 1 || hashes[0] != high.Hash {
+		t.Fatalf("evicted blocks are left in journal: %v", hashes)
+	}
+}
@@ -638,6 +638,11 @@ func (self *blockPool) delFromCompound(ws map[uint64]commonBlock) {
 		delete(self.compoundBlocks, b.Hash())
 	}
 }
+func (self *blockPool) size() int {
+	self.pendingMu.Lock()
+	defer self.pendingMu.Unlock()
+	return len(self.freeBlocks) + len(self.compoundBlocks)
+}
 func (self *blockPool) reInit(max uint64) {
 	self.pendingMu.Lock()
 	defer self.pendingMu.Unlock()
@@ -1003,6 +1008,34 @@ func (self *BCPool) delSnippet(c *snippetChain) {
 	delete(self.chainpool.snippetChains, c.id())
 	self.blockpool.delFromCompound(c.heightBlocks)
 }
+
+// evict drops the free blocks and snippet chains, the blocks in forked chains are kept. return the hashes of dropped blocks.
+func (self *BCPool) evict() []types.Hash {
+	// if an insert operation is in progress, do nothing.
+	if !self.compactLock.TryLock() {
+		return nil
+	} else {
+		defer self.compactLock.UnLock()
+	}
+	self.rMu.Lock()
+	defer self.rMu.Unlock()
+
+	var evicted []types.Hash
+	for _, c := range self.chainpool.snippetChains {
+		for _, b := range c.heightBlocks {
+			evicted = append(evicted, b.Hash())
+		}
+		self.delSnippet(c)
+	}
+
+	self.blockpool.pendingMu.Lock()
+	defer self.blockpool.pendingMu.Unlock()
+	for hash := range self.blockpool.freeBlocks {
+		evicted = append(evicted, hash)
+	}
+	self.blockpool.freeBlocks = make(map[types.Hash]commonBlock)
+	return evicted
+}
 func (self *BCPool) info() map[string]interface{} {
 	result := make(map[string]interface{})
 	bp := self.blockpool
@@ -229,3 +229,78 @@ func TestUselessChain(t *testing.T) {
 	bc.LIMIT_HEIGHT = 75 * 2
 	bc.loopDelUselessChain()
 }
+
+func TestBCPool_Evict(t *testing.T) {
+	mock := &mockChainPool{c: mockChain(nil, 1, 1, 10)}
+	mock.c.referChain = nil
+
+	diskChain := &diskChain{chainId: "diskchain", rw: mock, v: &ForkVersion{}}
+	cp := &chainPool{
+		poolId:    "chain-Pool-Id",
+		diskChain: diskChain,
+		log:       log15.New("module", "mock"),
+	}
+	cp.current = &forkedChain{}
+	cp.current.chainId = cp.genChainId()
+	cp.init()
+
+	bp := &blockPool{
+		freeBlocks:     make(map[types.Hash]commonBlock),
+		compoundBlocks: make(map[types.Hash]commonBlock),
+	}
+	kept := make(map[types.Hash]bool)
+	for _, v := range mockBlocks(1, 11, 15) {
+		cp.current.addHead(v)
+		bp.compoundBlocks[v.Hash()] = v
+		kept[v.Hash()] = true
+	}
+	forked := mockChain(cp.current, 2, 13, 16)
+	forked.chainId = cp.genChainId()
+	cp.addChain(forked)
+	for i := uint64(13); i <= 16; i++ {
+		v := forked.GetBlock(i)
+		bp.compoundBlocks[v.Hash()] = v
+		kept[v.Hash()] = true
+	}
+
+	dropped := make(map[types.Hash]bool)
+	snippet := &snippetChain{}
+	snippet.chainId = cp.genChainId()
+	snippet.init(newMockCommonBlock(3, 31))
+	snippet.addTail(newMockCommonBlock(3, 30))
+	cp.snippetChains[snippet.id()] = snippet
+	for _, v := range snippet.heightBlocks {
+		bp.compoundBlocks[v.Hash()] = v
+		dropped[v.Hash()] = true
+	}
+	for _, v := range mockBlocks(4, 40, 42) {
+		bp.putBlock(v.Hash(), v)
+		dropped[v.Hash()] = true
+	}
+
+	bc := &BCPool{chainpool: cp, blockpool: bp, compactLock: &common.NonBlockLock{}}
+	evicted := bc.evict()
+	if len(evicted) != len(dropped) {
+		t.Fatalf("%d blocks are evicted, expected %d", len(evicted), len(dropped))
+	}
+	for _, hash := range evicted {
+		if !dropped[hash] {
+			t.Fatalf("block %s shouldn't be evicted", hash)
+		}
+	}
+
+	if len(cp.snippetChains) != 0 || len(bp.freeBlocks) != 0 {
+		t.Fatalf("%d snippet chains and %d free blocks are left", len(cp.snippetChains), len(bp.freeBlocks))
+	}
+	if cp.getChain(forked.id()) == nil {
+		t.Fatal("forked chain is evicted")
+	}
+	if len(bp.compoundBlocks) != len(kept) {
+		t.Fatalf("%d compound blocks are left, expected %d", len(bp.compoundBlocks), len(kept))
+	}
+	for hash := range kept {
+		if _, ok := bp.compoundBlocks[hash]; !ok {
+			t.Fatalf("block %s in forked chains is evicted", hash)
+		}
+	}
+}
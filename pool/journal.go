@@ -0,0 +1,233 @@
+package pool
+
+import (
+	"encoding/binary"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+)
+
+var (
+	journalAccountPrefix  = []byte("a")
+	journalSnapshotPrefix = []byte("s")
+)
+
+// blocks which can't reach chain in journalMaxAge are dropped from journal
+const journalMaxAge = 24 * time.Hour
+
+const journalHeadLen = 10 // source[2] + addAt[8]
+
+var errJournalEntry = errors.New("invalid journal entry")
+
+// journal keeps the blocks received from network but not yet inserted into chain on disk, so they survive a restart.
+// A journal entry is source[2] + addAt[8] + serialized block, keyed by prefix + hash.
+type journal struct {
+	dir string
+	db  *leveldb.DB
+	mu  sync.RWMutex
+
+	replayed bool
+
+	log log15.Logger
+}
+
+func newJournal(dir string, log log15.Logger) *journal {
+	return &journal{dir: dir, log: log.New("t", "journal")}
+}
+
+func (self *journal) open() error {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	if self.db != nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(self.dir, 0700); err != nil {
+		return err
+	}
+	db, err := leveldb.OpenFile(self.dir, nil)
+	if err != nil {
+		return err
+	}
+	self.db = db
+	return nil
+}
+
+func (self *journal) close() {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	if self.db == nil {
+		return
+	}
+
+	if err := self.db.Close(); err != nil {
+		self.log.Error("close journal fail.", "err", err)
+	}
+	self.db = nil
+}
+
+// put records the block if it isn't in journal, the first time a block is received is kept.
+func (self *journal) put(prefix []byte, hash types.Hash, source types.BlockSource, data []byte) {
+	if self == nil {
+		return
+	}
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	if self.db == nil {
+		return
+	}
+
+	key := append(append([]byte{}, prefix...), hash.Bytes()...)
+	if ok, _ := self.db.Has(key, nil); ok {
+		return
+	}
+
+	value := make([]byte, journalHeadLen+len(data))
+	binary.BigEndian.PutUint16(value, uint16(source))
+	binary.BigEndian.PutUint64(value[2:journalHeadLen], uint64(time.Now().Unix()))
+	copy(value[journalHeadLen:], data)
+
+	if err := self.db.Put(key, value, nil); err != nil {
+		self.log.Error("put journal fail.", "err", err, "hash", hash)
+	}
+}
+
+func (self *journal) putAccountBlock(block *ledger.AccountBlock, source types.BlockSource) {
+	if self == nil {
+		return
+	}
+	data, err := block.Serialize()
+	if err != nil {
+		self.log.Error("serialize account block fail.", "err", err, "hash", block.Hash)
+		return
+	}
+	self.put(journalAccountPrefix, block.Hash, source, data)
+}
+
+func (self *journal) putSnapshotBlock(block *ledger.SnapshotBlock, source types.BlockSource) {
+	if self == nil {
+		return
+	}
+	data, err := block.Serialize()
+	if err != nil {
+		self.log.Error("serialize snapshot block fail.", "err", err, "hash", block.Hash)
+		return
+	}
+	self.put(journalSnapshotPrefix, block.Hash, source, data)
+}
+
+// del deletes the entries of hashes.
+func (self *journal) del(prefix []byte, hashes []types.Hash) {
+	if self == nil {
+		return
+	}
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	if self.db == nil {
+		return
+	}
+
+	batch := new(leveldb.Batch)
+	for _, hash := range hashes {
+		batch.Delete(append(append([]byte{}, prefix...), hash.Bytes()...))
+	}
+	if err := self.db.Write(batch, nil); err != nil {
+		self.log.Error("delete journal fail.", "err", err)
+	}
+}
+
+func (self *journal) delAccountBlocks(hashes []types.Hash) {
+	self.del(journalAccountPrefix, hashes)
+}
+
+// foreach calls fn with every entry of prefix, the entry is deleted if fn returns true.
+func (self *journal) foreach(prefix []byte, fn func(source types.BlockSource, addAt time.Time, data []byte) (del bool)) {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+	if self.db == nil {
+		return
+	}
+
+	batch := new(leveldb.Batch)
+	itr := self.db.NewIterator(util.BytesPrefix(prefix), nil)
+	for itr.Next() {
+		value := itr.Value()
+		var del bool
+		if len(value) <= journalHeadLen {
+			del = true
+		} else {
+			source := types.BlockSource(binary.BigEndian.Uint16(value))
+			addAt := time.Unix(int64(binary.BigEndian.Uint64(value[2:journalHeadLen])), 0)
+			del = fn(source, addAt, value[journalHeadLen:])
+		}
+		if del {
+			batch.Delete(append([]byte{}, itr.Key()...))
+		}
+	}
+	itr.Release()
+	if err := itr.Error(); err != nil {
+		self.log.Error("iterate journal fail.", "err", err)
+	}
+
+	if batch.Len() > 0 {
+		if err := self.db.Write(batch, nil); err != nil {
+			self.log.Error("delete journal fail.", "err", err)
+		}
+	}
+}
+
+func (self *journal) foreachAccountBlock(fn func(block *ledger.AccountBlock, source types.BlockSource, addAt time.Time) (del bool)) {
+	self.foreach(journalAccountPrefix, func(source types.BlockSource, addAt time.Time, data []byte) bool {
+		block := &ledger.AccountBlock{}
+		if err := block.Deserialize(data); err != nil {
+			self.log.Error("deserialize account block fail.", "err", err)
+			return true
+		}
+		return fn(block, source, addAt)
+	})
+}
+
+func (self *journal) foreachSnapshotBlock(fn func(block *ledger.SnapshotBlock, source types.BlockSource, addAt time.Time) (del bool)) {
+	self.foreach(journalSnapshotPrefix, func(source types.BlockSource, addAt time.Time, data []byte) bool {
+		block := &ledger.SnapshotBlock{}
+		if err := block.Deserialize(data); err != nil {
+			self.log.Error("deserialize snapshot block fail.", "err", err)
+			return true
+		}
+		return fn(block, source, addAt)
+	})
+}
+
+// compact drops the blocks which have been inserted into chain, forked out, or expired.
+func (self *journal) compact(bc chainDb) {
+	now := time.Now()
+
+	self.foreachAccountBlock(func(block *ledger.AccountBlock, _ types.BlockSource, addAt time.Time) bool {
+		if now.Sub(addAt) > journalMaxAge {
+			return true
+		}
+		if b, _ := bc.GetAccountBlockByHash(&block.Hash); b != nil {
+			return true
+		}
+		head, _ := bc.GetLatestAccountBlock(&block.AccountAddress)
+		return head != nil && head.Height >= block.Height
+	})
+
+	head := bc.GetLatestSnapshotBlock()
+	self.foreachSnapshotBlock(func(block *ledger.SnapshotBlock, _ types.BlockSource, addAt time.Time) bool {
+		if now.Sub(addAt) > journalMaxAge {
+			return true
+		}
+		if b, _ := bc.GetSnapshotBlockByHash(&block.Hash); b != nil {
+			return true
+		}
+		return head != nil && head.Height >= block.Height
+	})
+}
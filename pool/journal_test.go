@@ -0,0 +1,118 @@
+package pool
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+)
+
+// journalChain answers the queries of journal.compact, other methods of chainDb are not used
+type journalChain struct {
+	chainDb
+	accountHead  map[types.Address]*ledger.AccountBlock
+	inChain      map[types.Hash]bool
+	snapshotHead *ledger.SnapshotBlock
+}
+
+func (c *journalChain) GetAccountBlockByHash(hash *types.Hash) (*ledger.AccountBlock, error) {
+	if c.inChain[*hash] {
+		return &ledger.AccountBlock{Hash: *hash}, nil
+	}
+	return nil, nil
+}
+
+func (c *journalChain) GetLatestAccountBlock(addr *types.Address) (*ledger.AccountBlock, error) {
+	return c.accountHead[*addr], nil
+}
+
+func (c *journalChain) GetSnapshotBlockByHash(hash *types.Hash) (*ledger.SnapshotBlock, error) {
+	if c.inChain[*hash] {
+		return &ledger.SnapshotBlock{Hash: *hash}, nil
+	}
+	return nil, nil
+}
+
+func (c *journalChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
+	return c.snapshotHead
+}
+
+func mockJournalAccountBlock(addr types.Address, height uint64) *ledger.AccountBlock {
+	now := time.Now()
+	block := &ledger.AccountBlock{
+		Timestamp:      &now,
+		BlockType:      ledger.BlockTypeSendCall,
+		AccountAddress: addr,
+		Height:         height,
+		Hash:           types.Hash{byte(height)},
+		Amount:         big.NewInt(1),
+		Fee:            big.NewInt(0),
+	}
+	return block
+}
+
+func TestJournal(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pool_journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	j := newJournal(dir, log15.New("module", "pool/test"))
+	if err = j.open(); err != nil {
+		t.Fatal(err)
+	}
+
+	addr := types.Address{1}
+	inserted := mockJournalAccountBlock(addr, 1)
+	forked := mockJournalAccountBlock(addr, 2)
+	pending := mockJournalAccountBlock(addr, 3)
+	for _, block := range []*ledger.AccountBlock{inserted, forked, pending} {
+		j.putAccountBlock(block, types.RemoteBroadcast)
+	}
+	now := time.Now()
+	snapshot := &ledger.SnapshotBlock{Height: 10, Hash: types.Hash{10}, Timestamp: &now}
+	j.putSnapshotBlock(snapshot, types.RemoteFetch)
+
+	// journal survives a restart
+	j.close()
+	if err = j.open(); err != nil {
+		t.Fatal(err)
+	}
+	defer j.close()
+
+	j.compact(&journalChain{
+		accountHead:  map[types.Address]*ledger.AccountBlock{addr: {Height: 2}},
+		inChain:      map[types.Hash]bool{inserted.Hash: true},
+		snapshotHead: &ledger.SnapshotBlock{Height: 9},
+	})
+
+	var accounts []*ledger.AccountBlock
+	j.foreachAccountBlock(func(block *ledger.AccountBlock, source types.BlockSource, addAt time.Time) bool {
+		if source != types.RemoteBroadcast || time.Since(addAt) > time.Minute {
+			t.Errorf("source %d or addAt %s of journal is wrong", source, addAt)
+		}
+		accounts = append(accounts, block)
+		return false
+	})
+	if len(accounts) != 1 || accounts[0].Hash != pending.Hash || accounts[0].Height != 3 {
+		t.Fatalf("only the pending account block should be kept: %v", accounts)
+	}
+
+	var snapshots []*ledger.SnapshotBlock
+	j.foreachSnapshotBlock(func(block *ledger.SnapshotBlock, source types.BlockSource, _ time.Time) bool {
+		if source != types.RemoteFetch {
+			t.Errorf("source %d of journal is wrong", source)
+		}
+		snapshots = append(snapshots, block)
+		return false
+	})
+	if len(snapshots) != 1 || snapshots[0].Hash != snapshot.Hash {
+		t.Fatalf("snapshot block should be kept: %v", snapshots)
+	}
+}
@@ -53,6 +53,7 @@ type Debug interface {
 	Account(addr types.Address) map[string]interface{}
 	SnapshotChainDetail(chainId string) map[string]interface{}
 	AccountChainDetail(addr types.Address, chainId string) map[string]interface{}
+	Admission() map[string]interface{}
 }
 
 type BlockPool interface {
@@ -125,6 +126,10 @@ type pool struct {
 	log log15.Logger
 
 	stat *recoverStat
+
+	journal *journal // nil if journal is not enabled
+
+	admission *admission
 }
 
 func (self *pool) Snapshot() map[string]interface{} {
@@ -178,9 +183,15 @@ func (self *pool) RUnLock() {
 func NewPool(bc chainDb) *pool {
 	self := &pool{bc: bc, rwMutex: sync.RWMutex{}, version: &ForkVersion{}, accountCond: sync.NewCond(&sync.Mutex{})}
 	self.log = log15.New("module", "pool")
+	self.admission = newAdmission(bc)
 	return self
 }
 
+// SetJournal enables the journal in dir, the blocks received but not yet inserted are replayed when pool start.
+func (self *pool) SetJournal(dir string) {
+	self.journal = newJournal(dir, self.log)
+}
+
 func (self *pool) Init(s syncer,
 	wt *wallet.Manager,
 	snapshotV *verifier.SnapshotVerifier,
@@ -275,6 +286,14 @@ func (self *pool) Start() {
 	defer self.log.Info("pool started.")
 	self.closed = make(chan struct{})
 
+	if self.journal != nil {
+		if err := self.journal.open(); err != nil {
+			self.log.Error("open pool journal fail.", "err", err, "dir", self.journal.dir)
+		} else if !self.journal.replayed {
+			self.replayJournal()
+		}
+	}
+
 	self.accountSubId = self.sync.SubscribeAccountBlock(self.AddAccountBlock)
 	self.snapshotSubId = self.sync.SubscribeSnapshotBlock(self.AddSnapshotBlock)
 
@@ -297,6 +316,10 @@ func (self *pool) Stop() {
 	self.pendingSc.Stop()
 	close(self.closed)
 	self.wg.Wait()
+
+	if self.journal != nil {
+		self.journal.close()
+	}
 }
 func (self *pool) Restart() {
 	self.Lock()
@@ -319,6 +342,7 @@ func (self *pool) AddSnapshotBlock(block *ledger.SnapshotBlock, source types.Blo
 		self.log.Error("snapshot error", "err", err, "height", block.Height, "hash", block.Hash)
 		return
 	}
+	self.journal.putSnapshotBlock(block, source)
 	self.pendingSc.AddBlock(newSnapshotPoolBlock(block, self.version, source))
 }
 
@@ -345,8 +369,14 @@ func (self *pool) AddAccountBlock(address types.Address, block *ledger.AccountBl
 	err := ac.v.verifyAccountData(block)
 	if err != nil {
 		self.log.Error("account err", "err", err, "height", block.Height, "hash", block.Hash, "addr", address)
+		self.admission.reject(rejectVerifyFail, err)
 		return
 	}
+	if err := self.admitAccountBlock(ac, block); err != nil {
+		self.log.Warn("account block rejected", "err", err, "height", block.Height, "hash", block.Hash, "addr", address)
+		return
+	}
+	self.journal.putAccountBlock(block, source)
 	ac.AddBlock(newAccountPoolBlock(block, nil, self.version, source))
 	ac.AddReceivedBlock(block)
 
@@ -679,9 +709,11 @@ func (self *pool) loopBroadcastAndDel() {
 	broadcastT := time.NewTicker(time.Second * 30)
 	delT := time.NewTicker(time.Minute * 2)
 	delUselessChainT := time.NewTicker(time.Minute)
+	journalT := time.NewTicker(time.Minute)
 
 	defer broadcastT.Stop()
 	defer delT.Stop()
+	defer journalT.Stop()
 	for {
 		select {
 		case <-self.closed:
@@ -704,10 +736,47 @@ func (self *pool) loopBroadcastAndDel() {
 		case <-delUselessChainT.C:
 			// del some useless chain in pool
 			self.delUseLessChains()
+		case <-journalT.C:
+			if self.journal != nil {
+				self.journal.compact(self.bc)
+			}
 		}
 	}
 }
 
+// replayJournal adds the blocks in journal to pool again, they are verified as received from network.
+func (self *pool) replayJournal() {
+	self.journal.compact(self.bc)
+
+	type accountEntry struct {
+		block  *ledger.AccountBlock
+		source types.BlockSource
+	}
+	type snapshotEntry struct {
+		block  *ledger.SnapshotBlock
+		source types.BlockSource
+	}
+	var accounts []accountEntry
+	var snapshots []snapshotEntry
+	self.journal.foreachAccountBlock(func(block *ledger.AccountBlock, source types.BlockSource, _ time.Time) bool {
+		accounts = append(accounts, accountEntry{block, source})
+		return false
+	})
+	self.journal.foreachSnapshotBlock(func(block *ledger.SnapshotBlock, source types.BlockSource, _ time.Time) bool {
+		snapshots = append(snapshots, snapshotEntry{block, source})
+		return false
+	})
+
+	self.log.Info("replay pool journal.", "accountBlocks", len(accounts), "snapshotBlocks", len(snapshots))
+	for _, e := range accounts {
+		self.AddAccountBlock(e.block.AccountAddress, e.block, e.source)
+	}
+	for _, e := range snapshots {
+		self.AddSnapshotBlock(e.block, e.source)
+	}
+	self.journal.replayed = true
+}
+
 func (self *pool) delUseLessChains() {
 	self.pendingSc.loopDelUselessChain()
 	var pendings []*accountPool
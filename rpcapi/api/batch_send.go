@@ -0,0 +1,41 @@
+package api
+
+import (
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+)
+
+type BatchSendApi struct {
+}
+
+func NewBatchSendApi() *BatchSendApi {
+	return &BatchSendApi{}
+}
+
+func (b BatchSendApi) String() string {
+	return "BatchSendApi"
+}
+
+type BatchTransferParam struct {
+	ToAddr  types.Address     `json:"toAddr"`
+	TokenId types.TokenTypeId `json:"tokenId"`
+	Amount  string            `json:"amount"`
+}
+
+// GetBatchSendData packs the transfers of a batch send, the send block to the batch send contract must carry the
+// sum of the amounts in the same token.
+func (b *BatchSendApi) GetBatchSendData(transfers []BatchTransferParam) ([]byte, error) {
+	if len(transfers) == 0 {
+		return nil, errors.New("transfer list is empty")
+	}
+	list := make([]abi.BatchTransfer, len(transfers))
+	for i, transfer := range transfers {
+		amount, err := stringToBigInt(&transfer.Amount)
+		if err != nil {
+			return nil, err
+		}
+		list[i] = abi.BatchTransfer{To: transfer.ToAddr, TokenId: transfer.TokenId, Amount: amount}
+	}
+	return abi.ABIBatchSend.PackMethod(abi.MethodNameBatchSend, list)
+}
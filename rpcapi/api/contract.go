@@ -112,3 +112,51 @@ func (c *ContractApi) CallOffChainMethod(param CallOffChainMethodParam) ([]byte,
 	}
 	return vm.NewVM().OffChainReader(db, param.OffChainCode, param.Data)
 }
+
+// CallOffChainMethodAt runs the off-chain method against the contract state confirmed at the snapshot height.
+func (c *ContractApi) CallOffChainMethodAt(param CallOffChainMethodParam, snapshotHeight uint64) ([]byte, error) {
+	block, _, err := c.chain.GetConfirmedStateTrie(&param.SelfAddr, snapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errors.New("contract is not confirmed at the snapshot height")
+	}
+
+	snapshotBlock, err := c.chain.GetSnapshotBlockHeadByHeight(snapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+	if snapshotBlock == nil {
+		return nil, errors.New("snapshot block is not existed")
+	}
+
+	db, err := vm_context.NewVmContext(c.chain, &snapshotBlock.Hash, &block.Hash, &param.SelfAddr)
+	if err != nil {
+		return nil, err
+	}
+	return vm.NewVM().OffChainReader(db, param.OffChainCode, param.Data)
+}
+
+// GetStorageAt returns the storage value of the contract confirmed at the snapshot height, key and value are hex.
+func (c *ContractApi) GetStorageAt(addr types.Address, key string, snapshotHeight uint64) (string, error) {
+	keyBytes, err := hex.DecodeString(key)
+	if err != nil {
+		return "", err
+	}
+
+	_, stateTrie, err := c.chain.GetConfirmedStateTrie(&addr, snapshotHeight)
+	if err != nil {
+		return "", err
+	}
+	if stateTrie == nil {
+		return "", nil
+	}
+	return hex.EncodeToString(stateTrie.GetValue(keyBytes)), nil
+}
+
+// DecodeCallData decodes the data of a call to a built-in contract, or to a contract whose abi is registered in the
+// node.
+func (c *ContractApi) DecodeCallData(to types.Address, data []byte) (*DecodedData, error) {
+	return decodeCallData(to, data)
+}
@@ -0,0 +1,203 @@
+package api
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/abi"
+	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
+)
+
+// DecodedEvent is a vm log decoded by the abi registered for the contract, Params holds the inputs of the event by
+// name. Integers of 64 bits or more are decimal strings and bytes are hex strings.
+type DecodedEvent struct {
+	Name   string                 `json:"name"`
+	Params map[string]interface{} `json:"params"`
+}
+
+// VmLog is a vm log with the event decoded if asked and the abi of the contract is registered.
+type VmLog struct {
+	*ledger.VmLog
+	Event *DecodedEvent `json:"event,omitempty"`
+}
+
+type abiRegistry struct {
+	lock sync.RWMutex
+	abis map[types.Address]abi.ABIContract
+}
+
+// contractAbis holds the abis used to decode the logs of contracts, the abis of the built-in contracts are always
+// registered.
+var contractAbis = newAbiRegistry()
+
+func newAbiRegistry() *abiRegistry {
+	return &abiRegistry{
+		abis: map[types.Address]abi.ABIContract{
+			types.AddressRegister:       cabi.ABIRegister,
+			types.AddressVote:           cabi.ABIVote,
+			types.AddressPledge:         cabi.ABIPledge,
+			types.AddressConsensusGroup: cabi.ABIConsensusGroup,
+			types.AddressMintage:        cabi.ABIMintage,
+			types.AddressBatchSend:      cabi.ABIBatchSend,
+			types.AddressEscrow:         cabi.ABIEscrow,
+			types.AddressMultiSig:       cabi.ABIMultiSig,
+		},
+	}
+}
+
+// isBuiltinContract is whether the abi of addr is registered as a built-in contract, which it is even before the fork
+// adding the contract
+func isBuiltinContract(addr types.Address) bool {
+	for _, cAddr := range types.PrecompiledContractAddressList {
+		if cAddr == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *abiRegistry) register(addr types.Address, abiStr string) error {
+	if isBuiltinContract(addr) {
+		return errors.New("the abi of a built-in contract can't be changed")
+	}
+	abiContract, err := abi.JSONToABIContract(strings.NewReader(abiStr))
+	if err != nil {
+		return err
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.abis[addr] = abiContract
+	return nil
+}
+
+func (r *abiRegistry) unregister(addr types.Address) error {
+	if isBuiltinContract(addr) {
+		return errors.New("the abi of a built-in contract can't be changed")
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.abis, addr)
+	return nil
+}
+
+func (r *abiRegistry) get(addr types.Address) (abi.ABIContract, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	abiContract, ok := r.abis[addr]
+	return abiContract, ok
+}
+
+// InitContractAbis registers the abis of the config, each one is in the format of "address|abiFilePath".
+func InitContractAbis(abiFiles []string) error {
+	for _, abiFile := range abiFiles {
+		splits := strings.SplitN(abiFile, "|", 2)
+		if len(splits) != 2 {
+			return errors.New(fmt.Sprintf("contract abi config %v is not in the format of address|abiFilePath", abiFile))
+		}
+		addr, err := types.HexToAddress(splits[0])
+		if err != nil {
+			return err
+		}
+		abiStr, err := ioutil.ReadFile(splits[1])
+		if err != nil {
+			return err
+		}
+		if err := contractAbis.register(addr, string(abiStr)); err != nil {
+			return errors.New(fmt.Sprintf("register the abi of %v failed, %v", addr, err))
+		}
+	}
+	return nil
+}
+
+// DecodeVmLog decodes the log of the contract by the registered abi, nil is returned if the abi is not registered or
+// the log doesn't match any event.
+func DecodeVmLog(addr types.Address, vmLog *ledger.VmLog) *DecodedEvent {
+	abiContract, ok := contractAbis.get(addr)
+	if !ok || len(vmLog.Topics) == 0 {
+		return nil
+	}
+	event, err := abiContract.EventById(vmLog.Topics[0])
+	if err != nil {
+		return nil
+	}
+	values, err := event.UnpackLog(vmLog.Topics, vmLog.Data)
+	if err != nil {
+		return nil
+	}
+	return &DecodedEvent{Name: event.Name, Params: abiParams(event.Inputs, values)}
+}
+
+func abiParams(inputs abi.Arguments, values []interface{}) map[string]interface{} {
+	params := make(map[string]interface{}, len(inputs))
+	for i, input := range inputs {
+		params[input.Name] = abiValueToRpc(values[i])
+	}
+	return params
+}
+
+// abiValueToRpc converts an unpacked abi value to the one returned by rpc, tuples are converted to maps by the raw
+// field names.
+func abiValueToRpc(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *big.Int:
+		return v.String()
+	case int64:
+		return big.NewInt(v).String()
+	case uint64:
+		return new(big.Int).SetUint64(v).String()
+	case []byte:
+		return hex.EncodeToString(v)
+	case types.Address, types.Gid, types.TokenTypeId, types.Hash:
+		return v
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Array, reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(b), rv)
+			return hex.EncodeToString(b)
+		}
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			list[i] = abiValueToRpc(rv.Index(i).Interface())
+		}
+		return list
+	case reflect.Struct:
+		fields := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			fields[rv.Type().Field(i).Tag.Get("json")] = abiValueToRpc(rv.Field(i).Interface())
+		}
+		return fields
+	}
+	return value
+}
+
+// PrivateContractApi manages the abis registered in the node, which are used to decode the logs of the contracts.
+type PrivateContractApi struct {
+}
+
+func NewPrivateContractApi() *PrivateContractApi {
+	return &PrivateContractApi{}
+}
+
+func (c PrivateContractApi) String() string {
+	return "PrivateContractApi"
+}
+
+// RegisterAbi registers or replaces the abi of a contract, which is kept until the node is restarted.
+func (c *PrivateContractApi) RegisterAbi(addr types.Address, abiStr string) error {
+	return contractAbis.register(addr, abiStr)
+}
+
+func (c *PrivateContractApi) UnregisterAbi(addr types.Address) error {
+	return contractAbis.unregister(addr)
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
+)
+
+func TestDecodeVmLog(t *testing.T) {
+	// the abis of the built-in contracts are registered
+	topics, data, err := cabi.ABIMintage.PackEvent(cabi.EventNameBurn, ledger.ViteTokenId, ledger.GenesisAccountAddress, big.NewInt(1e18))
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := DecodeVmLog(types.AddressMintage, &ledger.VmLog{Topics: topics, Data: data})
+	if event == nil || event.Name != cabi.EventNameBurn || !reflect.DeepEqual(event.Params, map[string]interface{}{
+		"tokenId": ledger.ViteTokenId,
+		"address": ledger.GenesisAccountAddress,
+		"amount":  "1000000000000000000",
+	}) {
+		t.Fatalf("decoded event %+v", event)
+	}
+	if err := contractAbis.register(types.AddressMintage, "[]"); err == nil {
+		t.Fatal("the abi of a built-in contract is replaced")
+	}
+
+	addr, _ := types.BytesToAddress([]byte("abcdefghijklmnopqrst"))
+	abiStr := `[{"type":"event","name":"paid","inputs":[{"name":"payer","type":"address","indexed":true},{"name":"order","type":"tuple","components":[{"name":"id","type":"uint64"},{"name":"note","type":"bytes"}]}]}]`
+	if err := contractAbis.register(addr, abiStr); err != nil {
+		t.Fatal(err)
+	}
+	defer contractAbis.unregister(addr)
+	abiContract, _ := contractAbis.get(addr)
+	order := struct {
+		Id   uint64 `json:"id"`
+		Note []byte `json:"note"`
+	}{7, []byte{1, 2}}
+	topics, data, err = abiContract.PackEvent("paid", ledger.GenesisAccountAddress, order)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event = DecodeVmLog(addr, &ledger.VmLog{Topics: topics, Data: data})
+	if event == nil {
+		t.Fatal("event is not decoded")
+	}
+	result, _ := json.Marshal(event)
+	if string(result) != `{"name":"paid","params":{"order":{"id":"7","note":"0102"},"payer":"`+ledger.GenesisAccountAddress.String()+`"}}` {
+		t.Fatalf("decoded event %s", result)
+	}
+
+	// the logs of unknown events or contracts are not decoded
+	if DecodeVmLog(addr, &ledger.VmLog{Topics: []types.Hash{{1}}, Data: data}) != nil ||
+		DecodeVmLog(types.AddressPledge, &ledger.VmLog{Topics: topics, Data: data}) != nil {
+		t.Fatal("unknown event is decoded")
+	}
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm"
+)
+
+// DecodedData is the data of a block decoded by the abi of the called contract. For a receive block, Method and Params
+// are of the received call and Result tells whether the call succeeded. For a refund sent by a built-in contract,
+// Method is the failed call and Params is empty.
+type DecodedData struct {
+	Method string                 `json:"method"`
+	Params map[string]interface{} `json:"params,omitempty"`
+	Refund bool                   `json:"refund,omitempty"`
+	Result string                 `json:"result,omitempty"`
+}
+
+var receiveResults = map[byte]string{
+	vm.ResultSuccess:  "success",
+	vm.ResultFail:     "fail",
+	vm.ResultDepthErr: "depthError",
+}
+
+// decodeCallData decodes the data of a call to a built-in contract, or to a contract with a registered abi.
+func decodeCallData(to types.Address, data []byte) (*DecodedData, error) {
+	abiContract, ok := contractAbis.get(to)
+	if !ok {
+		return nil, errors.New("the abi of the contract is not registered")
+	}
+	method, err := abiContract.MethodById(data)
+	if err != nil {
+		return nil, err
+	}
+	values, err := method.Inputs.UnpackValues(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	return &DecodedData{Method: method.Name, Params: abiParams(method.Inputs, values)}, nil
+}
+
+// decodeBlockData returns nil if the block is not a call, a receive or a refund which can be decoded.
+func decodeBlockData(c chain.Chain, block *ledger.AccountBlock) *DecodedData {
+	if block.IsSendBlock() {
+		// nobody can sign a block of the address of a built-in contract before its fork
+		if isBuiltinContract(block.AccountAddress) {
+			if method, ok := vm.GetPrecompiledContractRefundMethod(block.AccountAddress, block.Data); ok {
+				return &DecodedData{Method: method, Refund: true}
+			}
+			return nil
+		}
+		decoded, _ := decodeCallData(block.ToAddress, block.Data)
+		return decoded
+	}
+
+	if _, ok := contractAbis.get(block.AccountAddress); !ok {
+		return nil
+	}
+	sendBlock, err := c.GetAccountBlockByHash(&block.FromBlockHash)
+	if err != nil || sendBlock == nil {
+		return nil
+	}
+	decoded, err := decodeCallData(block.AccountAddress, sendBlock.Data)
+	if err != nil {
+		return nil
+	}
+	if len(block.Data) == types.HashSize+1 {
+		decoded.Result = receiveResults[block.Data[types.HashSize]]
+	}
+	return decoded
+}
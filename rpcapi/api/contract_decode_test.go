@@ -0,0 +1,90 @@
+package api
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm"
+	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
+)
+
+type testChain struct {
+	chain.Chain
+	blocks map[types.Hash]*ledger.AccountBlock
+}
+
+func (c *testChain) GetAccountBlockByHash(blockHash *types.Hash) (*ledger.AccountBlock, error) {
+	return c.blocks[*blockHash], nil
+}
+
+func TestDecodeBlockData(t *testing.T) {
+	voteData, err := cabi.ABIVote.PackMethod(cabi.MethodNameVote, types.SNAPSHOT_GID, "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := decodeCallData(types.AddressVote, voteData)
+	if err != nil || !reflect.DeepEqual(decoded, &DecodedData{
+		Method: cabi.MethodNameVote,
+		Params: map[string]interface{}{"gid": types.SNAPSHOT_GID, "nodeName": "s1"},
+	}) {
+		t.Fatalf("decoded %+v, err %v", decoded, err)
+	}
+	if _, err := decodeCallData(types.AddressPledge, voteData); err == nil {
+		t.Fatal("call data of another contract is decoded")
+	}
+	if _, err := decodeCallData(ledger.GenesisAccountAddress, voteData); err == nil {
+		t.Fatal("call data of an unknown contract is decoded")
+	}
+
+	pledgeData, _ := cabi.ABIPledge.PackMethod(cabi.MethodNameCancelPledge, ledger.GenesisAccountAddress, big.NewInt(10))
+	sendBlock := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		Hash:           types.DataHash([]byte("send")),
+		AccountAddress: ledger.GenesisAccountAddress,
+		ToAddress:      types.AddressPledge,
+		Data:           pledgeData,
+	}
+	c := &testChain{blocks: map[types.Hash]*ledger.AccountBlock{sendBlock.Hash: sendBlock}}
+	expected := &DecodedData{
+		Method: cabi.MethodNameCancelPledge,
+		Params: map[string]interface{}{"beneficial": ledger.GenesisAccountAddress, "amount": "10"},
+	}
+	if decoded := decodeBlockData(c, sendBlock); !reflect.DeepEqual(decoded, expected) {
+		t.Fatalf("decoded send block %+v", decoded)
+	}
+
+	// the receive block of the built-in contract has the result of the call
+	receiveBlock := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeReceiveError,
+		AccountAddress: types.AddressPledge,
+		FromBlockHash:  sendBlock.Hash,
+		Data:           append(types.DataHash([]byte("storage")).Bytes(), vm.ResultFail),
+	}
+	expected.Result = "fail"
+	if decoded := decodeBlockData(c, receiveBlock); !reflect.DeepEqual(decoded, expected) {
+		t.Fatalf("decoded receive block %+v", decoded)
+	}
+
+	refundBlock := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		AccountAddress: types.AddressPledge,
+		ToAddress:      ledger.GenesisAccountAddress,
+		Data:           []byte{2},
+	}
+	if decoded := decodeBlockData(c, refundBlock); !reflect.DeepEqual(decoded, &DecodedData{Method: cabi.MethodNameCancelPledge, Refund: true}) {
+		t.Fatalf("decoded refund block %+v", decoded)
+	}
+
+	transferBlock := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		AccountAddress: ledger.GenesisAccountAddress,
+		ToAddress:      types.AddressPledge,
+	}
+	if decoded := decodeBlockData(c, transferBlock); decoded != nil {
+		t.Fatalf("decoded transfer block %+v", decoded)
+	}
+}
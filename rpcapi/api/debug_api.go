@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"math/big"
 	"time"
 
@@ -16,6 +17,8 @@ import (
 	"github.com/vitelabs/go-vite/consensus/core"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm"
+	"github.com/vitelabs/go-vite/vm_context"
 )
 
 type DebugApi struct {
@@ -53,6 +56,10 @@ func (api DebugApi) PoolAccountChainDetail(addr types.Address, chainId string) m
 	return api.v.Pool().AccountChainDetail(addr, chainId)
 }
 
+func (api DebugApi) PoolAdmission() map[string]interface{} {
+	return api.v.Pool().Admission()
+}
+
 func (api DebugApi) PoolAccountBlockDetail(addr types.Address, hash types.Hash) map[string]interface{} {
 	info := api.v.Pool().AccountBlockInfo(addr, hash)
 	m := make(map[string]interface{})
@@ -315,3 +322,55 @@ func (api DebugApi) SetGetTestTokenLimitSize(size int) error {
 func (api DebugApi) GetForkInfo() config.ForkPoints {
 	return fork.GetForkPoints()
 }
+
+// PrivateDebugApi has the debug methods which are too expensive to be served to anonymous clients.
+type PrivateDebugApi struct {
+	v *vite.Vite
+}
+
+func NewPrivateDebugApi(v *vite.Vite) *PrivateDebugApi {
+	return &PrivateDebugApi{
+		v: v,
+	}
+}
+
+// TraceAccountBlock re-executes a receive block against the state before it and returns every vm step.
+func (api PrivateDebugApi) TraceAccountBlock(hash types.Hash) (result *vm.TraceResult, resultErr error) {
+	ch := api.v.Chain()
+	block, err := ch.GetAccountBlockByHash(&hash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errors.New("account block is not existed")
+	}
+	if !block.IsReceiveBlock() {
+		return nil, errors.New("only receive block can be traced")
+	}
+
+	sendBlock, err := ch.GetAccountBlockByHash(&block.FromBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if sendBlock == nil {
+		return nil, errors.New("send block is not existed")
+	}
+
+	db, err := vm_context.NewVmContext(ch, &block.SnapshotHash, &block.PrevHash, &block.AccountAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		if err := recover(); err != nil {
+			result = nil
+			resultErr = errors.New(fmt.Sprintf("trace panic error %v", err))
+		}
+	}()
+
+	tracer := vm.NewStructLogger()
+	newVm := vm.NewVM()
+	newVm.Tracer = tracer
+	newVm.Run(db, block, sendBlock)
+	return tracer.Result(), nil
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"github.com/vitelabs/go-vite/chain"
 	"github.com/vitelabs/go-vite/verifier"
 	"github.com/vitelabs/go-vite/vm/util"
 	"github.com/vitelabs/go-vite/wallet/walleterrors"
@@ -78,6 +79,12 @@ var (
 		Code:    -36005,
 	}
 
+	// -37001 ~ -37999 chain
+	ErrLedgerPruned = JsonRpc2Error{
+		Message: chain.ErrPruned.Error(),
+		Code:    -37001,
+	}
+
 	concernedErrorMap map[string]JsonRpc2Error
 )
 
@@ -98,6 +105,8 @@ func init() {
 	concernedErrorMap[ErrVerifySignature.Error()] = ErrVerifySignature
 	concernedErrorMap[ErrVerifyNonce.Error()] = ErrVerifyNonce
 	concernedErrorMap[ErrVerifySnapshotOfReferredBlock.Error()] = ErrVerifySnapshotOfReferredBlock
+
+	concernedErrorMap[ErrLedgerPruned.Error()] = ErrLedgerPruned
 }
 
 func TryMakeConcernedError(err error) (newerr error, concerned bool) {
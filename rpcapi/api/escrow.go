@@ -0,0 +1,128 @@
+package api
+
+import (
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm_context"
+	"sort"
+)
+
+type EscrowApi struct {
+	chain chain.Chain
+	log   log15.Logger
+}
+
+func NewEscrowApi(vite *vite.Vite) *EscrowApi {
+	return &EscrowApi{
+		chain: vite.Chain(),
+		log:   log15.New("module", "rpc_api/escrow_api"),
+	}
+}
+
+func (e EscrowApi) String() string {
+	return "EscrowApi"
+}
+
+func (e *EscrowApi) GetDepositData(beneficialAddr types.Address, unlockHeight string) ([]byte, error) {
+	height, err := StringToUint64(unlockHeight)
+	if err != nil {
+		return nil, err
+	}
+	return abi.ABIEscrow.PackMethod(abi.MethodNameEscrowDeposit, beneficialAddr, height)
+}
+
+// GetDepositByTimeData packs a deposit which can be withdrawn once the timestamp of the latest snapshot block reaches
+// unlockTime, in unix seconds.
+func (e *EscrowApi) GetDepositByTimeData(beneficialAddr types.Address, unlockTime int64) ([]byte, error) {
+	if unlockTime <= 0 {
+		return nil, errors.New("invalid unlock time")
+	}
+	return abi.ABIEscrow.PackMethod(abi.MethodNameEscrowDepositByTime, beneficialAddr, uint64(unlockTime))
+}
+
+// GetWithdrawData packs the withdrawal of a deposit, the id is the hash of the deposit send block.
+func (e *EscrowApi) GetWithdrawData(id types.Hash) ([]byte, error) {
+	return abi.ABIEscrow.PackMethod(abi.MethodNameEscrowWithdraw, id)
+}
+
+// GetUnlockHeight estimates the snapshot height reached at unlockTime by the latest snapshot block, which can be
+// used as the unlock height of a deposit.
+func (e *EscrowApi) GetUnlockHeight(unlockTime int64) (string, error) {
+	snapshotBlock := e.chain.GetLatestSnapshotBlock()
+	if unlockTime <= snapshotBlock.Timestamp.Unix() {
+		return "", errors.New("unlock time has passed")
+	}
+	return uint64ToString(snapshotBlock.Height + uint64((unlockTime-snapshotBlock.Timestamp.Unix())/secondBetweenSnapshotBlocks)), nil
+}
+
+type EscrowInfoList struct {
+	Count int           `json:"totalCount"`
+	List  []*EscrowInfo `json:"escrowInfoList"`
+}
+
+// EscrowInfo is a deposit, the unlock height of a deposit by time is 0, the unlock time of a deposit by height is
+// estimated by the latest snapshot block.
+type EscrowInfo struct {
+	Id             types.Hash        `json:"id"`
+	DepositorAddr  types.Address     `json:"depositorAddr"`
+	BeneficialAddr types.Address     `json:"beneficialAddr"`
+	TokenId        types.TokenTypeId `json:"tokenId"`
+	Amount         string            `json:"amount"`
+	UnlockHeight   string            `json:"unlockHeight"`
+	UnlockTime     int64             `json:"unlockTime"`
+}
+type byUnlockTime []*EscrowInfo
+
+func (a byUnlockTime) Len() int      { return len(a) }
+func (a byUnlockTime) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a byUnlockTime) Less(i, j int) bool {
+	if a[i].UnlockTime == a[j].UnlockTime {
+		return a[i].Id.String() < a[j].Id.String()
+	}
+	return a[i].UnlockTime < a[j].UnlockTime
+}
+
+// GetEscrowList returns the deposits locked for the beneficial, ordered by the unlock time.
+func (e *EscrowApi) GetEscrowList(beneficialAddr types.Address, index int, count int) (*EscrowInfoList, error) {
+	return e.getEscrowList(func(db abi.StorageDatabase) []*abi.EscrowInfo {
+		return abi.GetEscrowInfoList(db, beneficialAddr)
+	}, index, count)
+}
+
+// GetEscrowListByDepositor returns the deposits made by the depositor, ordered by the unlock time.
+func (e *EscrowApi) GetEscrowListByDepositor(depositorAddr types.Address, index int, count int) (*EscrowInfoList, error) {
+	return e.getEscrowList(func(db abi.StorageDatabase) []*abi.EscrowInfo {
+		return abi.GetEscrowInfoListByDepositor(db, depositorAddr)
+	}, index, count)
+}
+
+func (e *EscrowApi) getEscrowList(getList func(db abi.StorageDatabase) []*abi.EscrowInfo, index int, count int) (*EscrowInfoList, error) {
+	snapshotBlock := e.chain.GetLatestSnapshotBlock()
+	vmContext, err := vm_context.NewVmContext(e.chain, &snapshotBlock.Hash, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	infoList := getList(vmContext)
+	list := make([]*EscrowInfo, len(infoList))
+	for i, info := range infoList {
+		unlockTime := int64(info.UnlockTime)
+		if unlockTime == 0 {
+			unlockTime = getWithdrawTime(snapshotBlock.Timestamp, snapshotBlock.Height, info.UnlockHeight)
+		}
+		list[i] = &EscrowInfo{
+			info.Id,
+			info.Depositor,
+			info.Beneficial,
+			info.TokenId,
+			*bigIntToString(info.Amount),
+			uint64ToString(info.UnlockHeight),
+			unlockTime}
+	}
+	sort.Sort(byUnlockTime(list))
+	start, end := getRange(index, count, len(list))
+	return &EscrowInfoList{len(list), list[start:end]}, nil
+}
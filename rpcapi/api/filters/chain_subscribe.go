@@ -11,23 +11,31 @@ import (
 type AccountChainEvent struct {
 	Hash   types.Hash
 	Height uint64
-	Addr   types.Address
+	Addr   *types.Address
 	Logs   []*ledger.VmLog
 }
 
+type SnapshotChainEvent struct {
+	Hash   types.Hash
+	Height uint64
+}
+
+// ChainSubscribe wakes up the event system when the block events of the chain change, and keeps the account blocks
+// and the snapshot blocks to be deleted, which can't be read from the chain any more after being deleted.
 type ChainSubscribe struct {
-	vite                   *vite.Vite
-	es                     *EventSystem
-	listenIdList           []uint64
-	preDeleteAccountBlocks []*AccountChainEvent
+	vite         *vite.Vite
+	es           *EventSystem
+	listenIdList []uint64
 }
 
 func NewChainSubscribe(v *vite.Vite, e *EventSystem) *ChainSubscribe {
 	c := &ChainSubscribe{vite: v, es: e}
-	list := make([]uint64, 0, 3)
+	list := make([]uint64, 0, 5)
 	list = append(list, v.Chain().RegisterInsertAccountBlocksSuccess(c.InsertedAccountBlocks))
 	list = append(list, v.Chain().RegisterDeleteAccountBlocks(c.PreDeleteAccountBlocks))
 	list = append(list, v.Chain().RegisterDeleteAccountBlocksSuccess(c.DeletedAccountBlocks))
+	list = append(list, v.Chain().RegisterInsertSnapshotBlocksSuccess(c.InsertedSnapshotBlocks))
+	list = append(list, v.Chain().RegisterDeleteSnapshotBlocksSuccess(c.DeletedSnapshotBlocks))
 	c.listenIdList = list
 	return c
 }
@@ -39,33 +47,42 @@ func (c *ChainSubscribe) Stop() {
 }
 
 func (c *ChainSubscribe) InsertedAccountBlocks(blocks []*vm_context.VmAccountBlock) {
-	acEvents := make([]*AccountChainEvent, len(blocks))
-	for i, b := range blocks {
-		acEvents[i] = &AccountChainEvent{b.AccountBlock.Hash, b.AccountBlock.Height, b.AccountBlock.AccountAddress, b.VmContext.GetLogList()}
-	}
-	c.es.acCh <- acEvents
+	c.es.notify()
 }
 
 func (c *ChainSubscribe) PreDeleteAccountBlocks(batch *leveldb.Batch, subLedger map[types.Address][]*ledger.AccountBlock) error {
 	acEvents := make([]*AccountChainEvent, 0)
 	for addr, blocks := range subLedger {
+		addr := addr
 		for _, b := range blocks {
+			var logList ledger.VmLogList
 			if b.LogHash != nil {
-				logList, err := c.vite.Chain().GetVmLogList(b.LogHash)
+				var err error
+				logList, err = c.vite.Chain().GetVmLogList(b.LogHash)
 				if err != nil {
 					c.es.log.Error("get log list failed when preDeleteAccountBlocks", "addr", addr, "hash", b.Hash, "height", b.Height, "err", err)
 				}
-				acEvents = append(acEvents, &AccountChainEvent{b.Hash, b.Height, addr, logList})
-			} else {
-				acEvents = append(acEvents, &AccountChainEvent{b.Hash, b.Height, addr, nil})
 			}
+			acEvents = append(acEvents, &AccountChainEvent{Hash: b.Hash, Height: b.Height, Addr: &addr, Logs: logList})
 		}
 	}
-	c.preDeleteAccountBlocks = append(c.preDeleteAccountBlocks, acEvents...)
+	c.es.setDeletedAccountBlocks(acEvents)
 	return nil
 }
+
 func (c *ChainSubscribe) DeletedAccountBlocks(subLedger map[types.Address][]*ledger.AccountBlock) {
-	deletedBlocks := c.preDeleteAccountBlocks
-	c.preDeleteAccountBlocks = nil
-	c.es.acDelCh <- deletedBlocks
+	c.es.notify()
+}
+
+func (c *ChainSubscribe) InsertedSnapshotBlocks(blocks []*ledger.SnapshotBlock) {
+	c.es.notify()
+}
+
+func (c *ChainSubscribe) DeletedSnapshotBlocks(blocks []*ledger.SnapshotBlock) {
+	sbEvents := make([]*SnapshotChainEvent, len(blocks))
+	for i, b := range blocks {
+		sbEvents[i] = &SnapshotChainEvent{Hash: b.Hash, Height: b.Height}
+	}
+	c.es.setDeletedSnapshotBlocks(sbEvents)
+	c.es.notify()
 }
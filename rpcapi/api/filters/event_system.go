@@ -1,13 +1,16 @@
 package filters
 
 import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/chain_db/access"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/rpc"
 	"github.com/vitelabs/go-vite/vite"
-	"sync"
-	"time"
 )
 
 type FilterType byte
@@ -17,6 +20,7 @@ var Es *EventSystem
 const (
 	LogsSubscription FilterType = iota
 	AccountBlocksSubscription
+	SnapshotBlocksSubscription
 )
 
 type heightRange struct {
@@ -27,51 +31,80 @@ type heightRange struct {
 type filterParam struct {
 	addrRange map[types.Address]heightRange
 	topics    [][]types.Hash
+	decode    bool
 }
 
 type subscription struct {
-	id             rpc.ID
-	typ            FilterType
-	createTime     time.Time
-	installed      chan struct{}
-	err            chan error
-	param          *filterParam
-	accountBlockCh chan []*AccountBlock
-	logsCh         chan []*Logs
+	id              rpc.ID
+	typ             FilterType
+	createTime      time.Time
+	installed       chan struct{}
+	err             chan error
+	param           *filterParam
+	startEventId    *uint64
+	accountBlockCh  chan []*AccountBlock
+	logsCh          chan []*Logs
+	snapshotBlockCh chan []*SnapshotBlock
+}
+
+// chainEvent is a block event of the chain, the account blocks or the snapshot blocks inserted or deleted together.
+type chainEvent struct {
+	id       uint64
+	removed  bool
+	acEvents []*AccountChainEvent
+	sbEvents []*SnapshotChainEvent
 }
 
 type EventSystem struct {
 	vite      *vite.Vite
 	chain     *ChainSubscribe
-	install   chan *subscription        // install filter
-	uninstall chan *subscription        // remove filter
-	acCh      chan []*AccountChainEvent // Channel to receive new account chain event
-	acDelCh   chan []*AccountChainEvent // Channel to receive new account chain delete event when account chain fork
+	install   chan *subscription // install filter
+	uninstall chan *subscription // remove filter
+	eventCh   chan struct{}      // Channel to be notified when new block events are written
 	stop      chan struct{}
 	log       log15.Logger
+
+	deletedLock           sync.Mutex
+	deletedAccountBlocks  map[types.Hash]*AccountChainEvent
+	deletedSnapshotBlocks map[types.Hash]*SnapshotChainEvent
+
+	latestEventId  uint64 // the latest block event handled by the event loop
+	waitingEventId uint64
+	waitingTime    time.Time
 }
 
 const (
-	acChanSize    = 100
-	acDelChanSize = 10
 	installSize   = 10
 	uninstallSize = 10
+
+	checkEventInterval = time.Second
+
+	// a block event id is allocated before the block event is written, so the event loop waits for a missing event
+	// for a while, in case that a later event is written first
+	waitEventTimeout = 10 * time.Second
 )
 
 func NewEventSystem(v *vite.Vite) *EventSystem {
 	es := &EventSystem{
-		vite:      v,
-		acCh:      make(chan []*AccountChainEvent, acChanSize),
-		acDelCh:   make(chan []*AccountChainEvent, acDelChanSize),
-		install:   make(chan *subscription, installSize),
-		uninstall: make(chan *subscription, uninstallSize),
-		stop:      make(chan struct{}),
-		log:       log15.New("module", "rpc_api/event_system"),
+		vite:                  v,
+		install:               make(chan *subscription, installSize),
+		uninstall:             make(chan *subscription, uninstallSize),
+		eventCh:               make(chan struct{}, 1),
+		stop:                  make(chan struct{}),
+		log:                   log15.New("module", "rpc_api/event_system"),
+		deletedAccountBlocks:  make(map[types.Hash]*AccountChainEvent),
+		deletedSnapshotBlocks: make(map[types.Hash]*SnapshotChainEvent),
 	}
 	return es
 }
 
 func (es *EventSystem) Start() {
+	latestEventId, err := es.vite.Chain().GetLatestBlockEventId()
+	if err != nil {
+		es.log.Error("GetLatestBlockEventId failed, error is "+err.Error(), "method", "Start")
+	}
+	es.latestEventId = latestEventId
+
 	es.chain = NewChainSubscribe(es.vite, es)
 	go es.eventLoop()
 }
@@ -81,23 +114,59 @@ func (es *EventSystem) Stop() {
 	es.chain.Stop()
 }
 
+func (es *EventSystem) notify() {
+	select {
+	case es.eventCh <- struct{}{}:
+	default:
+	}
+}
+
+func (es *EventSystem) setDeletedAccountBlocks(acEvents []*AccountChainEvent) {
+	es.deletedLock.Lock()
+	defer es.deletedLock.Unlock()
+	for _, e := range acEvents {
+		es.deletedAccountBlocks[e.Hash] = e
+	}
+}
+
+func (es *EventSystem) setDeletedSnapshotBlocks(sbEvents []*SnapshotChainEvent) {
+	es.deletedLock.Lock()
+	defer es.deletedLock.Unlock()
+	for _, e := range sbEvents {
+		es.deletedSnapshotBlocks[e.Hash] = e
+	}
+}
+
+func (es *EventSystem) clearDeleted() {
+	es.deletedLock.Lock()
+	defer es.deletedLock.Unlock()
+	es.deletedAccountBlocks = make(map[types.Hash]*AccountChainEvent)
+	es.deletedSnapshotBlocks = make(map[types.Hash]*SnapshotChainEvent)
+}
+
 func (es *EventSystem) eventLoop() {
 	es.log.Info("start event loop")
 	index := make(map[FilterType]map[rpc.ID]*subscription)
-	for i := LogsSubscription; i <= AccountBlocksSubscription; i++ {
+	for i := LogsSubscription; i <= SnapshotBlocksSubscription; i++ {
 		index[i] = make(map[rpc.ID]*subscription)
 	}
 
+	ticker := time.NewTicker(checkEventInterval)
+	defer ticker.Stop()
+
 	for {
 		select {
-		case acEvent := <-es.acCh:
-			es.handleAcEvent(index, acEvent, false)
-		case acDelEvent := <-es.acDelCh:
-			es.handleAcEvent(index, acDelEvent, true)
+		case <-es.eventCh:
+			es.handleEvents(index)
+		case <-ticker.C:
+			es.handleEvents(index)
 		case i := <-es.install:
 			es.log.Info("install ", "id", i.id)
 			index[i.typ][i.id] = i
 			close(i.installed)
+			if i.startEventId != nil {
+				es.replayEvents(i, *i.startEventId)
+			}
 		case u := <-es.uninstall:
 			es.log.Info("uninstall ", "id", u.id)
 			delete(index[u.typ], u.id)
@@ -117,39 +186,221 @@ func (es *EventSystem) eventLoop() {
 	}
 }
 
-func (es *EventSystem) handleAcEvent(filters map[FilterType]map[rpc.ID]*subscription, acEvent []*AccountChainEvent, removed bool) {
-	if len(acEvent) == 0 {
+// handleEvents sends the block events after the latest handled one to the subscriptions in order.
+func (es *EventSystem) handleEvents(filters map[FilterType]map[rpc.ID]*subscription) {
+	latestEventId, err := es.vite.Chain().GetLatestBlockEventId()
+	if err != nil {
+		es.log.Error("GetLatestBlockEventId failed, error is "+err.Error(), "method", "handleEvents")
+		return
+	}
+
+	subscribed := false
+	for _, subscriptions := range filters {
+		if len(subscriptions) > 0 {
+			subscribed = true
+			break
+		}
+	}
+	if !subscribed {
+		es.latestEventId = latestEventId
+		es.clearDeleted()
 		return
 	}
-	// handle account blocks
-	msgs := make([]*AccountBlock, len(acEvent))
-	for i, e := range acEvent {
-		msgs[i] = &AccountBlock{Hash: e.Hash, Removed: removed}
+
+	for es.latestEventId < latestEventId {
+		eventId := es.latestEventId + 1
+		eventType, hashList, err := es.vite.Chain().GetEvent(eventId)
+		if err != nil {
+			es.log.Error("GetEvent failed, error is "+err.Error(), "method", "handleEvents", "eventId", eventId)
+			return
+		}
+		if (len(hashList) <= 0 || !es.isDeletedSaved(eventType, hashList)) && !es.waitEvent(eventId) {
+			return
+		}
+
+		es.latestEventId = eventId
+		if len(hashList) <= 0 {
+			continue
+		}
+		e := es.newChainEvent(eventId, eventType, hashList, true)
+		for _, subscriptions := range filters {
+			for _, s := range subscriptions {
+				es.sendEvent(s, e)
+			}
+		}
+	}
+}
+
+// replayEvents sends the block events from startEventId + 1 to the latest handled one to the new subscription. The
+// deleted blocks of these events can't be read from the chain, so only their hashes are sent and their logs are lost.
+func (es *EventSystem) replayEvents(s *subscription, startEventId uint64) {
+	for eventId := startEventId + 1; eventId <= es.latestEventId; eventId++ {
+		eventType, hashList, err := es.vite.Chain().GetEvent(eventId)
+		if err != nil {
+			es.log.Error("GetEvent failed, error is "+err.Error(), "method", "replayEvents", "eventId", eventId)
+			return
+		}
+		if len(hashList) <= 0 {
+			continue
+		}
+		es.sendEvent(s, es.newChainEvent(eventId, eventType, hashList, false))
+	}
+}
+
+// isDeletedSaved returns false if the deleted snapshot blocks of the event have not been saved, they are saved after
+// the event is written.
+func (es *EventSystem) isDeletedSaved(eventType byte, hashList []types.Hash) bool {
+	if eventType != access.DeleteSnapshotBlocksEvent {
+		return true
+	}
+	es.deletedLock.Lock()
+	defer es.deletedLock.Unlock()
+	_, ok := es.deletedSnapshotBlocks[hashList[0]]
+	return ok
+}
+
+// waitEvent returns true if the event has been waited for too long and should be handled as it is.
+func (es *EventSystem) waitEvent(eventId uint64) bool {
+	if es.waitingEventId != eventId {
+		es.waitingEventId = eventId
+		es.waitingTime = time.Now()
+		return false
+	}
+	if time.Since(es.waitingTime) < waitEventTimeout {
+		return false
+	}
+	es.log.Warn("wait for block event timeout", "method", "waitEvent", "eventId", eventId)
+	return true
+}
+
+func (es *EventSystem) newChainEvent(eventId uint64, eventType byte, hashList []types.Hash, consume bool) *chainEvent {
+	e := &chainEvent{id: eventId}
+	switch eventType {
+	case access.AddAccountBlocksEvent:
+		for _, hash := range hashList {
+			e.acEvents = append(e.acEvents, es.getAccountChainEvent(hash))
+		}
+	case access.DeleteAccountBlocksEvent:
+		e.removed = true
+		es.deletedLock.Lock()
+		for _, hash := range hashList {
+			acEvent, ok := es.deletedAccountBlocks[hash]
+			if !ok {
+				acEvent = &AccountChainEvent{Hash: hash}
+			} else if consume {
+				delete(es.deletedAccountBlocks, hash)
+			}
+			e.acEvents = append(e.acEvents, acEvent)
+		}
+		es.deletedLock.Unlock()
+	case access.AddSnapshotBlocksEvent:
+		for _, hash := range hashList {
+			sbEvent := &SnapshotChainEvent{Hash: hash}
+			if block, err := es.vite.Chain().GetSnapshotBlockByHash(&hash); err != nil {
+				es.log.Error("GetSnapshotBlockByHash failed, error is "+err.Error(), "method", "newChainEvent", "hash", hash)
+			} else if block != nil {
+				sbEvent.Height = block.Height
+			}
+			e.sbEvents = append(e.sbEvents, sbEvent)
+		}
+	case access.DeleteSnapshotBlocksEvent:
+		e.removed = true
+		es.deletedLock.Lock()
+		for _, hash := range hashList {
+			sbEvent, ok := es.deletedSnapshotBlocks[hash]
+			if !ok {
+				sbEvent = &SnapshotChainEvent{Hash: hash}
+			} else if consume {
+				delete(es.deletedSnapshotBlocks, hash)
+			}
+			e.sbEvents = append(e.sbEvents, sbEvent)
+		}
+		es.deletedLock.Unlock()
+	}
+	return e
+}
+
+// getAccountChainEvent reads the inserted account block, only the hash is kept if it has been deleted.
+func (es *EventSystem) getAccountChainEvent(hash types.Hash) *AccountChainEvent {
+	acEvent := &AccountChainEvent{Hash: hash}
+	block, err := es.vite.Chain().GetAccountBlockByHash(&hash)
+	if err != nil {
+		es.log.Error("GetAccountBlockByHash failed, error is "+err.Error(), "method", "getAccountChainEvent", "hash", hash)
+		return acEvent
+	}
+	if block == nil {
+		return acEvent
 	}
-	for _, f := range filters[AccountBlocksSubscription] {
-		f.accountBlockCh <- msgs
+	addr := block.AccountAddress
+	acEvent.Height = block.Height
+	acEvent.Addr = &addr
+	if block.LogHash != nil {
+		logList, err := es.vite.Chain().GetVmLogList(block.LogHash)
+		if err != nil {
+			es.log.Error("GetVmLogList failed, error is "+err.Error(), "method", "getAccountChainEvent", "hash", hash)
+		}
+		acEvent.Logs = logList
+	}
+	return acEvent
+}
+
+func (es *EventSystem) sendEvent(s *subscription, e *chainEvent) {
+	// the events before the start event have been received by the subscriber
+	if s.startEventId != nil && e.id <= *s.startEventId {
+		return
 	}
-	// handle logs
-	for _, f := range filters[LogsSubscription] {
+	eventId := strconv.FormatUint(e.id, 10)
+	switch s.typ {
+	case AccountBlocksSubscription:
+		if len(e.acEvents) > 0 {
+			s.accountBlockCh <- newAccountBlocks(eventId, e)
+		}
+	case LogsSubscription:
 		var logs []*Logs
-		for _, e := range acEvent {
-			if matchedLogs := filterLogs(e, f.param, removed); len(matchedLogs) > 0 {
+		for _, acEvent := range e.acEvents {
+			if matchedLogs := filterLogs(eventId, acEvent, s.param, e.removed); len(matchedLogs) > 0 {
 				logs = append(logs, matchedLogs...)
 			}
 		}
 		if len(logs) > 0 {
-			f.logsCh <- logs
+			s.logsCh <- logs
+		}
+	case SnapshotBlocksSubscription:
+		if len(e.sbEvents) > 0 {
+			s.snapshotBlockCh <- newSnapshotBlocks(eventId, e)
 		}
 	}
 }
 
-func filterLogs(e *AccountChainEvent, filter *filterParam, removed bool) []*Logs {
-	if len(e.Logs) == 0 {
+func newAccountBlocks(eventId string, e *chainEvent) []*AccountBlock {
+	msgs := make([]*AccountBlock, len(e.acEvents))
+	for i, acEvent := range e.acEvents {
+		msgs[i] = &AccountBlock{Hash: acEvent.Hash, Addr: acEvent.Addr, EventId: eventId, Removed: e.removed}
+		if acEvent.Height > 0 {
+			msgs[i].Height = strconv.FormatUint(acEvent.Height, 10)
+		}
+	}
+	return msgs
+}
+
+func newSnapshotBlocks(eventId string, e *chainEvent) []*SnapshotBlock {
+	msgs := make([]*SnapshotBlock, len(e.sbEvents))
+	for i, sbEvent := range e.sbEvents {
+		msgs[i] = &SnapshotBlock{Hash: sbEvent.Hash, EventId: eventId, Removed: e.removed}
+		if sbEvent.Height > 0 {
+			msgs[i].Height = strconv.FormatUint(sbEvent.Height, 10)
+		}
+	}
+	return msgs
+}
+
+func filterLogs(eventId string, e *AccountChainEvent, filter *filterParam, removed bool) []*Logs {
+	if len(e.Logs) == 0 || e.Addr == nil {
 		return nil
 	}
 	var logs []*Logs
 	if filter.addrRange != nil {
-		if hr, ok := filter.addrRange[e.Addr]; !ok {
+		if hr, ok := filter.addrRange[*e.Addr]; !ok {
 			return nil
 		} else if (hr.fromHeight > 0 && hr.fromHeight > e.Height) || (hr.toHeight > 0 && hr.toHeight < e.Height) {
 			return nil
@@ -157,7 +408,7 @@ func filterLogs(e *AccountChainEvent, filter *filterParam, removed bool) []*Logs
 	}
 	for _, l := range e.Logs {
 		if filterLog(filter, l) {
-			logs = append(logs, &Logs{l, e.Hash, &e.Addr, removed})
+			logs = append(logs, &Logs{Log: l, Event: decodeLog(filter, *e.Addr, l), AccountBlockHash: e.Hash, Addr: e.Addr, EventId: eventId, Removed: removed})
 		}
 	}
 	return logs
@@ -205,35 +456,57 @@ func (s *RpcSubscription) Unsubscribe() {
 				break uninstallLoop
 			case <-s.sub.accountBlockCh:
 			case <-s.sub.logsCh:
+			case <-s.sub.snapshotBlockCh:
 			}
 		}
 		<-s.Err()
 	})
 }
 
-func (es *EventSystem) SubscribeAccountBlocks(ch chan []*AccountBlock) *RpcSubscription {
+// SubscribeAccountBlocks subscribes the inserted and deleted account blocks, the block events after startEventId are
+// sent first if it is not nil.
+func (es *EventSystem) SubscribeAccountBlocks(ch chan []*AccountBlock, startEventId *uint64) *RpcSubscription {
+	sub := &subscription{
+		id:              rpc.NewID(),
+		typ:             AccountBlocksSubscription,
+		createTime:      time.Now(),
+		installed:       make(chan struct{}),
+		err:             make(chan error),
+		startEventId:    startEventId,
+		accountBlockCh:  ch,
+		logsCh:          make(chan []*Logs),
+		snapshotBlockCh: make(chan []*SnapshotBlock),
+	}
+	return es.subscribe(sub)
+}
+
+func (es *EventSystem) SubscribeLogs(p *filterParam, ch chan []*Logs, startEventId *uint64) *RpcSubscription {
 	sub := &subscription{
-		id:             rpc.NewID(),
-		typ:            AccountBlocksSubscription,
-		createTime:     time.Now(),
-		installed:      make(chan struct{}),
-		err:            make(chan error),
-		accountBlockCh: ch,
-		logsCh:         make(chan []*Logs),
+		id:              rpc.NewID(),
+		typ:             LogsSubscription,
+		param:           p,
+		createTime:      time.Now(),
+		installed:       make(chan struct{}),
+		err:             make(chan error),
+		startEventId:    startEventId,
+		accountBlockCh:  make(chan []*AccountBlock),
+		logsCh:          ch,
+		snapshotBlockCh: make(chan []*SnapshotBlock),
 	}
 	return es.subscribe(sub)
 }
 
-func (es *EventSystem) SubscribeLogs(p *filterParam, ch chan []*Logs) *RpcSubscription {
+func (es *EventSystem) SubscribeSnapshotBlocks(ch chan []*SnapshotBlock, startEventId *uint64) *RpcSubscription {
 	sub := &subscription{
-		id:             rpc.NewID(),
-		typ:            LogsSubscription,
-		param:          p,
-		createTime:     time.Now(),
-		installed:      make(chan struct{}),
-		err:            make(chan error),
-		accountBlockCh: make(chan []*AccountBlock),
-		logsCh:         ch,
+		id:              rpc.NewID(),
+		typ:             SnapshotBlocksSubscription,
+		createTime:      time.Now(),
+		installed:       make(chan struct{}),
+		err:             make(chan error),
+		startEventId:    startEventId,
+		accountBlockCh:  make(chan []*AccountBlock),
+		logsCh:          make(chan []*Logs),
+		snapshotBlockCh: ch,
 	}
 	return es.subscribe(sub)
 }
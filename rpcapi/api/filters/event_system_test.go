@@ -0,0 +1,77 @@
+package filters
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+func TestEventSystem_sendEvent(t *testing.T) {
+	es := &EventSystem{}
+	addr, _, _ := types.CreateAddress()
+	hash := types.DataHash([]byte("block"))
+	log := &ledger.VmLog{Data: []byte("log")}
+
+	startEventId := uint64(2)
+	sub := &subscription{
+		typ:          LogsSubscription,
+		param:        &filterParam{addrRange: map[types.Address]heightRange{addr: {}}},
+		startEventId: &startEventId,
+		logsCh:       make(chan []*Logs, 3),
+	}
+
+	inserted := &chainEvent{id: 2, acEvents: []*AccountChainEvent{{Hash: hash, Height: 1, Addr: &addr, Logs: []*ledger.VmLog{log}}}}
+	es.sendEvent(sub, inserted)
+	if len(sub.logsCh) != 0 {
+		t.Fatal("the event before the start event is sent")
+	}
+
+	inserted.id = 3
+	es.sendEvent(sub, inserted)
+	deleted := &chainEvent{id: 4, removed: true, acEvents: []*AccountChainEvent{{Hash: hash, Height: 1, Addr: &addr, Logs: []*ledger.VmLog{log}}}}
+	es.sendEvent(sub, deleted)
+	// the logs of a replayed deleted block are unknown
+	es.sendEvent(sub, &chainEvent{id: 5, removed: true, acEvents: []*AccountChainEvent{{Hash: hash}}})
+
+	if len(sub.logsCh) != 2 {
+		t.Fatalf("%d messages are sent", len(sub.logsCh))
+	}
+	for i, expected := range []*Logs{
+		{Log: log, AccountBlockHash: hash, Addr: &addr, EventId: "3"},
+		{Log: log, AccountBlockHash: hash, Addr: &addr, EventId: "4", Removed: true},
+	} {
+		logs := <-sub.logsCh
+		if len(logs) != 1 || logs[0].Log != expected.Log || logs[0].AccountBlockHash != expected.AccountBlockHash ||
+			*logs[0].Addr != *expected.Addr || logs[0].EventId != expected.EventId || logs[0].Removed != expected.Removed {
+			t.Fatalf("message %d is wrong: %+v", i, logs[0])
+		}
+	}
+}
+
+func TestEventSystem_sendSnapshotEvent(t *testing.T) {
+	es := &EventSystem{}
+	hash := types.DataHash([]byte("snapshot"))
+	sub := &subscription{
+		typ:             SnapshotBlocksSubscription,
+		snapshotBlockCh: make(chan []*SnapshotBlock, 2),
+	}
+
+	es.sendEvent(sub, &chainEvent{id: 1, sbEvents: []*SnapshotChainEvent{{Hash: hash, Height: 10}}})
+	es.sendEvent(sub, &chainEvent{id: 2, removed: true, sbEvents: []*SnapshotChainEvent{{Hash: hash}}})
+	// account block events are not sent to snapshot block subscriptions
+	es.sendEvent(sub, &chainEvent{id: 3, acEvents: []*AccountChainEvent{{Hash: hash}}})
+
+	if len(sub.snapshotBlockCh) != 2 {
+		t.Fatalf("%d messages are sent", len(sub.snapshotBlockCh))
+	}
+	for i, expected := range []SnapshotBlock{
+		{Hash: hash, Height: "10", EventId: "1"},
+		{Hash: hash, EventId: "2", Removed: true},
+	} {
+		blocks := <-sub.snapshotBlockCh
+		if len(blocks) != 1 || *blocks[0] != expected {
+			t.Fatalf("message %d is wrong: %+v", i, blocks[0])
+		}
+	}
+}
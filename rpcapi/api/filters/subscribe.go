@@ -2,6 +2,7 @@ package filters
 
 import (
 	"context"
+	"fmt"
 	"github.com/pkg/errors"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
@@ -24,6 +25,7 @@ type filter struct {
 	s        *RpcSubscription
 	blocks   []*AccountBlock
 	logs     []*Logs
+	sbs      []*SnapshotBlock
 }
 
 type SubscribeApi struct {
@@ -94,6 +96,8 @@ func (r *Range) toHeightRange() (*heightRange, error) {
 type RpcFilterParam struct {
 	AddrRange map[string]*Range `json:"addrRange"`
 	Topics    [][]types.Hash    `json:"topics"`
+	// Decode decodes the events of the logs by the abis registered in the node
+	Decode bool `json:"decode"`
 }
 
 func (p *RpcFilterParam) toFilterParam() (*filterParam, error) {
@@ -119,27 +123,66 @@ func (p *RpcFilterParam) toFilterParam() (*filterParam, error) {
 	target := &filterParam{
 		addrRange: addrRange,
 		topics:    p.Topics,
+		decode:    p.Decode,
 	}
 	return target, nil
 }
 
+// AccountBlock is an inserted account block, or a deleted one if Removed is true. EventId is the block event of the
+// chain which the block belongs to, and can be used to resume a subscription.
 type AccountBlock struct {
+	Hash    types.Hash     `json:"hash"`
+	Height  string         `json:"height,omitempty"`
+	Addr    *types.Address `json:"addr,omitempty"`
+	EventId string         `json:"eventId"`
+	Removed bool           `json:"removed"`
+}
+
+type Logs struct {
+	Log              *ledger.VmLog     `json:"log"`
+	Event            *api.DecodedEvent `json:"event,omitempty"`
+	AccountBlockHash types.Hash        `json:"accountBlockHash"`
+	Addr             *types.Address    `json:"addr"`
+	EventId          string            `json:"eventId,omitempty"`
+	Removed          bool              `json:"removed"`
+}
+
+type SnapshotBlock struct {
 	Hash    types.Hash `json:"hash"`
+	Height  string     `json:"height,omitempty"`
+	EventId string     `json:"eventId"`
 	Removed bool       `json:"removed"`
 }
 
-type Logs struct {
-	Log              *ledger.VmLog  `json:"log"`
-	AccountBlockHash types.Hash     `json:"accountBlockHash"`
-	Addr             *types.Address `json:"addr"`
-	Removed          bool           `json:"removed"`
+// the max count of the block events which can be replayed when subscribing
+const maxReplayEventCount = 10000
+
+func (s *SubscribeApi) toStartEventId(startEventId *string) (*uint64, error) {
+	if startEventId == nil {
+		return nil, nil
+	}
+	eventId, err := api.StringToUint64(*startEventId)
+	if err != nil {
+		return nil, err
+	}
+	latestEventId, err := s.vite.Chain().GetLatestBlockEventId()
+	if err != nil {
+		return nil, err
+	}
+	if eventId > latestEventId {
+		return nil, errors.New("start event id > latest event id")
+	}
+	if latestEventId-eventId > maxReplayEventCount {
+		return nil, errors.New(fmt.Sprintf("can't replay more than %d events", maxReplayEventCount))
+	}
+	return &eventId, nil
 }
 
 func (s *SubscribeApi) NewAccountBlocksFilter() (rpc.ID, error) {
 	s.log.Info("NewAccountBlocksFilter")
 	var (
 		acCh  = make(chan []*AccountBlock)
-		acSub = s.eventSystem.SubscribeAccountBlocks(acCh)
+		acSub = s.eventSystem.SubscribeAccountBlocks(acCh, nil)
 	)
 
 	s.filterMapMu.Lock()
@@ -175,7 +218,7 @@ func (s *SubscribeApi) NewLogsFilter(param RpcFilterParam) (rpc.ID, error) {
 	}
 	var (
 		logsCh  = make(chan []*Logs)
-		logsSub = s.eventSystem.SubscribeLogs(p, logsCh)
+		logsSub = s.eventSystem.SubscribeLogs(p, logsCh, nil)
 	)
 
 	s.filterMapMu.Lock()
@@ -203,6 +246,38 @@ func (s *SubscribeApi) NewLogsFilter(param RpcFilterParam) (rpc.ID, error) {
 	return logsSub.ID, nil
 }
 
+func (s *SubscribeApi) NewSnapshotBlocksFilter() (rpc.ID, error) {
+	s.log.Info("NewSnapshotBlocksFilter")
+	var (
+		sbCh  = make(chan []*SnapshotBlock)
+		sbSub = s.eventSystem.SubscribeSnapshotBlocks(sbCh, nil)
+	)
+
+	s.filterMapMu.Lock()
+	s.filterMap[sbSub.ID] = &filter{typ: sbSub.sub.typ, deadline: time.NewTimer(deadline), s: sbSub}
+	s.filterMapMu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case sb := <-sbCh:
+				s.filterMapMu.Lock()
+				if f, found := s.filterMap[sbSub.ID]; found {
+					f.sbs = append(f.sbs, sb...)
+				}
+				s.filterMapMu.Unlock()
+			case <-sbSub.Err():
+				s.filterMapMu.Lock()
+				delete(s.filterMap, sbSub.ID)
+				s.filterMapMu.Unlock()
+				return
+			}
+		}
+	}()
+
+	return sbSub.ID, nil
+}
+
 func (s *SubscribeApi) UninstallFilter(id rpc.ID) bool {
 	s.log.Info("UninstallFilter")
 	s.filterMapMu.Lock()
@@ -227,6 +302,11 @@ type LogsMsg struct {
 	Id   rpc.ID  `json:"subscription"`
 }
 
+type SnapshotBlocksMsg struct {
+	Blocks []*SnapshotBlock `json:"result"`
+	Id     rpc.ID           `json:"subscription"`
+}
+
 func (s *SubscribeApi) GetFilterChanges(id rpc.ID) (interface{}, error) {
 	s.log.Info("GetFilterChanges", "id", id)
 	s.filterMapMu.Lock()
@@ -247,14 +327,25 @@ func (s *SubscribeApi) GetFilterChanges(id rpc.ID) (interface{}, error) {
 			logs := f.logs
 			f.logs = nil
 			return LogsMsg{logs, id}, nil
+		case SnapshotBlocksSubscription:
+			sbs := f.sbs
+			f.sbs = nil
+			return SnapshotBlocksMsg{sbs, id}, nil
 		}
 	}
 
 	return nil, errors.New("filter not found")
 }
 
-func (s *SubscribeApi) NewAccountBlocks(ctx context.Context) (*rpc.Subscription, error) {
+// NewAccountBlocks subscribes the inserted and deleted account blocks. A reconnecting client can pass the event id of
+// the latest block it received as startEventId, the block events after it are sent first.
+func (s *SubscribeApi) NewAccountBlocks(ctx context.Context, startEventId *string) (*rpc.Subscription, error) {
 	s.log.Info("NewAccountBlocks")
+	start, err := s.toStartEventId(startEventId)
+	if err != nil {
+		return nil, err
+	}
+
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
@@ -263,7 +354,7 @@ func (s *SubscribeApi) NewAccountBlocks(ctx context.Context) (*rpc.Subscription,
 
 	go func() {
 		accountBlockHashCh := make(chan []*AccountBlock, 128)
-		acSub := s.eventSystem.SubscribeAccountBlocks(accountBlockHashCh)
+		acSub := s.eventSystem.SubscribeAccountBlocks(accountBlockHashCh, start)
 		for {
 			select {
 			case h := <-accountBlockHashCh:
@@ -281,12 +372,18 @@ func (s *SubscribeApi) NewAccountBlocks(ctx context.Context) (*rpc.Subscription,
 	return rpcSub, nil
 }
 
-func (s *SubscribeApi) NewLogs(ctx context.Context, param RpcFilterParam) (*rpc.Subscription, error) {
+// NewLogs subscribes the logs of the inserted and deleted account blocks, startEventId works as in NewAccountBlocks,
+// but the logs of the blocks deleted before subscribing can't be replayed.
+func (s *SubscribeApi) NewLogs(ctx context.Context, param RpcFilterParam, startEventId *string) (*rpc.Subscription, error) {
 	s.log.Info("NewLogs")
 	p, err := param.toFilterParam()
 	if err != nil {
 		return nil, err
 	}
+	start, err := s.toStartEventId(startEventId)
+	if err != nil {
+		return nil, err
+	}
 
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
@@ -296,7 +393,7 @@ func (s *SubscribeApi) NewLogs(ctx context.Context, param RpcFilterParam) (*rpc.
 
 	go func() {
 		logsMsg := make(chan []*Logs, 128)
-		sub := s.eventSystem.SubscribeLogs(p, logsMsg)
+		sub := s.eventSystem.SubscribeLogs(p, logsMsg, start)
 
 		for {
 			select {
@@ -314,6 +411,47 @@ func (s *SubscribeApi) NewLogs(ctx context.Context, param RpcFilterParam) (*rpc.
 	return rpcSub, nil
 }
 
+// NewSnapshotBlocks subscribes the inserted and deleted snapshot blocks, startEventId works as in NewAccountBlocks.
+func (s *SubscribeApi) NewSnapshotBlocks(ctx context.Context, startEventId *string) (*rpc.Subscription, error) {
+	s.log.Info("NewSnapshotBlocks")
+	start, err := s.toStartEventId(startEventId)
+	if err != nil {
+		return nil, err
+	}
+
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		snapshotBlockCh := make(chan []*SnapshotBlock, 128)
+		sbSub := s.eventSystem.SubscribeSnapshotBlocks(snapshotBlockCh, start)
+		for {
+			select {
+			case sb := <-snapshotBlockCh:
+				notifier.Notify(rpcSub.ID, sb)
+			case <-rpcSub.Err():
+				sbSub.Unsubscribe()
+				return
+			case <-notifier.Closed():
+				sbSub.Unsubscribe()
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+func decodeLog(filter *filterParam, addr types.Address, l *ledger.VmLog) *api.DecodedEvent {
+	if !filter.decode {
+		return nil
+	}
+	return api.DecodeVmLog(addr, l)
+}
+
 var getAccountBlocksCount uint64 = 100
 
 func (s *SubscribeApi) GetLogs(param RpcFilterParam) ([]*Logs, error) {
@@ -352,7 +490,7 @@ func (s *SubscribeApi) GetLogs(param RpcFilterParam) ([]*Logs, error) {
 					}
 					for _, l := range list {
 						if filterLog(filterParam, l) {
-							logs = append(logs, &Logs{l, b.Hash, &addr, false})
+							logs = append(logs, &Logs{Log: l, Event: decodeLog(filterParam, addr, l), AccountBlockHash: b.Hash, Addr: &addr})
 						}
 					}
 				}
@@ -1,15 +1,21 @@
 package api
 
 import (
+	"encoding/hex"
+	"fmt"
 	"github.com/pkg/errors"
 	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/chain/index"
 	"github.com/vitelabs/go-vite/chain/trie_gc"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/generator"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/trie"
 	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm_context"
 	"strconv"
+	"strings"
 )
 
 // !!! Block = Transaction = TX
@@ -41,14 +47,42 @@ func (l LedgerApi) String() string {
 	return "LedgerApi"
 }
 
-func (l *LedgerApi) ledgerBlockToRpcBlock(block *ledger.AccountBlock) (*AccountBlock, error) {
-	return ledgerToRpcBlock(block, l.chain)
+// ledgerBlockToRpcBlock converts the block, the data of the calls to the built-in contracts and the contracts with
+// registered abis is decoded if decode is true.
+func (l *LedgerApi) ledgerBlockToRpcBlock(block *ledger.AccountBlock, decode *bool) (*AccountBlock, error) {
+	if err := l.checkPruned(block); err != nil {
+		return nil, err
+	}
+	rpcBlock, err := ledgerToRpcBlock(block, l.chain)
+	if err != nil {
+		return nil, err
+	}
+	if decode != nil && *decode {
+		rpcBlock.DecodedData = decodeBlockData(l.chain, block)
+	}
+	return rpcBlock, nil
+}
+
+// checkPruned returns ErrLedgerPruned if the body of the block is not served on a pruned node.
+func (l *LedgerApi) checkPruned(block *ledger.AccountBlock) error {
+	if block == nil {
+		return nil
+	}
+	pruned, err := l.chain.IsAccountBlockPruned(block)
+	if err != nil {
+		l.log.Error("IsAccountBlockPruned failed, error is "+err.Error(), "method", "checkPruned")
+		return err
+	}
+	if pruned {
+		return ErrLedgerPruned
+	}
+	return nil
 }
 
-func (l *LedgerApi) ledgerBlocksToRpcBlocks(list []*ledger.AccountBlock) ([]*AccountBlock, error) {
+func (l *LedgerApi) ledgerBlocksToRpcBlocks(list []*ledger.AccountBlock, decode *bool) ([]*AccountBlock, error) {
 	var blocks []*AccountBlock
 	for _, item := range list {
-		rpcBlock, err := l.ledgerBlockToRpcBlock(item)
+		rpcBlock, err := l.ledgerBlockToRpcBlock(item, decode)
 		if err != nil {
 			return nil, err
 		}
@@ -57,7 +91,7 @@ func (l *LedgerApi) ledgerBlocksToRpcBlocks(list []*ledger.AccountBlock) ([]*Acc
 	return blocks, nil
 }
 
-func (l *LedgerApi) GetBlockByHash(blockHash *types.Hash) (*AccountBlock, error) {
+func (l *LedgerApi) GetBlockByHash(blockHash *types.Hash, decode *bool) (*AccountBlock, error) {
 	block, getError := l.chain.GetAccountBlockByHash(blockHash)
 
 	if getError != nil {
@@ -69,10 +103,10 @@ func (l *LedgerApi) GetBlockByHash(blockHash *types.Hash) (*AccountBlock, error)
 		return nil, nil
 	}
 
-	return l.ledgerBlockToRpcBlock(block)
+	return l.ledgerBlockToRpcBlock(block, decode)
 }
 
-func (l *LedgerApi) GetBlocksByHash(addr types.Address, originBlockHash *types.Hash, count uint64) ([]*AccountBlock, error) {
+func (l *LedgerApi) GetBlocksByHash(addr types.Address, originBlockHash *types.Hash, count uint64, decode *bool) ([]*AccountBlock, error) {
 	l.log.Info("GetBlocksByHash")
 
 	list, getError := l.chain.GetAccountBlocksByHash(addr, originBlockHash, count, false)
@@ -80,7 +114,7 @@ func (l *LedgerApi) GetBlocksByHash(addr types.Address, originBlockHash *types.H
 		return nil, getError
 	}
 
-	if blocks, err := l.ledgerBlocksToRpcBlocks(list); err != nil {
+	if blocks, err := l.ledgerBlocksToRpcBlocks(list, decode); err != nil {
 		l.log.Error("GetConfirmTimes failed, error is "+err.Error(), "method", "GetBlocksByHash")
 		return nil, err
 	} else {
@@ -89,7 +123,7 @@ func (l *LedgerApi) GetBlocksByHash(addr types.Address, originBlockHash *types.H
 
 }
 
-func (l *LedgerApi) GetBlocksByHashInToken(addr types.Address, originBlockHash *types.Hash, tokenTypeId types.TokenTypeId, count uint64) ([]*AccountBlock, error) {
+func (l *LedgerApi) GetBlocksByHashInToken(addr types.Address, originBlockHash *types.Hash, tokenTypeId types.TokenTypeId, count uint64, decode *bool) ([]*AccountBlock, error) {
 	l.log.Info("GetBlocksByHashInToken")
 	fti := l.chain.Fti()
 	if fti == nil {
@@ -119,7 +153,97 @@ func (l *LedgerApi) GetBlocksByHashInToken(addr types.Address, originBlockHash *
 
 		blockList[index] = block
 	}
-	return l.ledgerBlocksToRpcBlocks(blockList)
+	return l.ledgerBlocksToRpcBlocks(blockList, decode)
+}
+
+func (l *LedgerApi) indexer() (*chain_index.Indexer, error) {
+	indexer := l.chain.Indexer()
+	if indexer == nil {
+		return nil, errors.New("config.OpenSecondaryIndex is false, api can't work")
+	}
+	return indexer, nil
+}
+
+func (l *LedgerApi) hashListToRpcBlocks(hashList []types.Hash, decode *bool) ([]*AccountBlock, error) {
+	blockList := make([]*ledger.AccountBlock, 0, len(hashList))
+	for _, blockHash := range hashList {
+		block, err := l.chain.GetAccountBlockByHash(&blockHash)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			continue
+		}
+
+		blockList = append(blockList, block)
+	}
+	return l.ledgerBlocksToRpcBlocks(blockList, decode)
+}
+
+func (l *LedgerApi) GetBlocksBySendFromTo(fromAddr types.Address, toAddr types.Address, originBlockHash *types.Hash, count uint64, decode *bool) ([]*AccountBlock, error) {
+	indexer, err := l.indexer()
+	if err != nil {
+		return nil, err
+	}
+
+	hashList, err := indexer.GetBlockHashList(chain_index.INDEX_SEND_FROM_TO, append(fromAddr.Bytes(), toAddr.Bytes()...), originBlockHash, count)
+	if err != nil {
+		return nil, err
+	}
+	return l.hashListToRpcBlocks(hashList, decode)
+}
+
+func (l *LedgerApi) GetBlocksByFromBlockHash(fromBlockHash types.Hash, count uint64, decode *bool) ([]*AccountBlock, error) {
+	indexer, err := l.indexer()
+	if err != nil {
+		return nil, err
+	}
+
+	hashList, err := indexer.GetBlockHashList(chain_index.INDEX_FROM_BLOCK_HASH, fromBlockHash.Bytes(), nil, count)
+	if err != nil {
+		return nil, err
+	}
+	return l.hashListToRpcBlocks(hashList, decode)
+}
+
+// GetBlocksByMethodSelector returns the calls to a contract method, methodSelector is the hex of the first 4 bytes of the call data.
+func (l *LedgerApi) GetBlocksByMethodSelector(contractAddr types.Address, methodSelector string, originBlockHash *types.Hash, count uint64, decode *bool) ([]*AccountBlock, error) {
+	indexer, err := l.indexer()
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := hex.DecodeString(strings.TrimPrefix(methodSelector, "0x"))
+	if err != nil {
+		return nil, err
+	}
+	if len(selector) != chain_index.METHOD_SELECTOR_LENGTH {
+		return nil, errors.New(fmt.Sprintf("method selector must be %d bytes", chain_index.METHOD_SELECTOR_LENGTH))
+	}
+
+	hashList, err := indexer.GetBlockHashList(chain_index.INDEX_METHOD_SELECTOR, append(contractAddr.Bytes(), selector...), originBlockHash, count)
+	if err != nil {
+		return nil, err
+	}
+	return l.hashListToRpcBlocks(hashList, decode)
+}
+
+// GetBlocksByTimestampRange returns at most count account blocks with startTime <= timestamp <= endTime, in unix seconds.
+func (l *LedgerApi) GetBlocksByTimestampRange(startTime int64, endTime int64, count uint64, decode *bool) ([]*AccountBlock, error) {
+	indexer, err := l.indexer()
+	if err != nil {
+		return nil, err
+	}
+	if startTime > endTime {
+		return nil, errors.New("startTime is greater than endTime")
+	}
+
+	hashList, err := indexer.GetBlockHashListByRange(chain_index.INDEX_BLOCK_TIMESTAMP,
+		chain_index.TimestampKey(startTime), chain_index.TimestampEndKey(endTime), count)
+	if err != nil {
+		return nil, err
+	}
+	return l.hashListToRpcBlocks(hashList, decode)
 }
 
 type Statistics struct {
@@ -151,10 +275,14 @@ func (l *LedgerApi) GetVmLogListByHash(logHash types.Hash) (ledger.VmLogList, er
 		l.log.Error("GetVmLogList failed, error is "+err.Error(), "method", "GetVmLogListByHash")
 		return nil, err
 	}
+	// the log lists of the pruned blocks are deleted, a missing one can't be told from a pruned one
+	if logList == nil && l.chain.PrunedHeight() > 0 {
+		return nil, ErrLedgerPruned
+	}
 	return logList, err
 }
 
-func (l *LedgerApi) GetBlocksByHeight(addr types.Address, height uint64, count uint64, forward bool) ([]*AccountBlock, error) {
+func (l *LedgerApi) GetBlocksByHeight(addr types.Address, height uint64, count uint64, forward bool, decode *bool) ([]*AccountBlock, error) {
 	accountBlocks, err := l.chain.GetAccountBlocksByHeight(addr, height, count, forward)
 	if err != nil {
 		l.log.Error("GetAccountBlocksByHeight failed, error is "+err.Error(), "method", "GetBlocksByHeight")
@@ -163,10 +291,10 @@ func (l *LedgerApi) GetBlocksByHeight(addr types.Address, height uint64, count u
 	if len(accountBlocks) <= 0 {
 		return nil, nil
 	}
-	return l.ledgerBlocksToRpcBlocks(accountBlocks)
+	return l.ledgerBlocksToRpcBlocks(accountBlocks, decode)
 }
 
-func (l *LedgerApi) GetBlockByHeight(addr types.Address, heightStr string) (*AccountBlock, error) {
+func (l *LedgerApi) GetBlockByHeight(addr types.Address, heightStr string, decode *bool) (*AccountBlock, error) {
 	height, err := strconv.ParseUint(heightStr, 10, 64)
 	if err != nil {
 		return nil, err
@@ -181,10 +309,10 @@ func (l *LedgerApi) GetBlockByHeight(addr types.Address, heightStr string) (*Acc
 	if accountBlock == nil {
 		return nil, nil
 	}
-	return l.ledgerBlockToRpcBlock(accountBlock)
+	return l.ledgerBlockToRpcBlock(accountBlock, decode)
 }
 
-func (l *LedgerApi) GetBlocksByAccAddr(addr types.Address, index int, count int) ([]*AccountBlock, error) {
+func (l *LedgerApi) GetBlocksByAccAddr(addr types.Address, index int, count int, decode *bool) ([]*AccountBlock, error) {
 	l.log.Info("GetBlocksByAccAddr")
 
 	list, getErr := l.chain.GetAccountBlocksByAddress(&addr, index, 1, count)
@@ -194,7 +322,7 @@ func (l *LedgerApi) GetBlocksByAccAddr(addr types.Address, index int, count int)
 		return nil, getErr
 	}
 
-	if blocks, err := l.ledgerBlocksToRpcBlocks(list); err != nil {
+	if blocks, err := l.ledgerBlocksToRpcBlocks(list, decode); err != nil {
 		l.log.Error("GetConfirmTimes failed, error is "+err.Error(), "method", "GetBlocksByAccAddr")
 		return nil, err
 	} else {
@@ -251,6 +379,33 @@ func (l *LedgerApi) GetAccountByAccAddr(addr types.Address) (*RpcAccountInfo, er
 	return rpcAccount, nil
 }
 
+// GetBalanceAt returns the balances of addr at the snapshot height, totalNumber is the height of the confirmed account block.
+func (l *LedgerApi) GetBalanceAt(addr types.Address, snapshotHeight uint64) (*RpcAccountInfo, error) {
+	block, balanceMap, err := l.chain.GetAccountBalanceAt(&addr, snapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, nil
+	}
+
+	tokenBalanceInfoMap := make(map[types.TokenTypeId]*RpcTokenBalanceInfo)
+	for tokenId, amount := range balanceMap {
+		token, _ := l.chain.GetTokenInfoById(&tokenId)
+		tokenBalanceInfoMap[tokenId] = &RpcTokenBalanceInfo{
+			TokenInfo:   RawTokenInfoToRpc(token, tokenId),
+			TotalAmount: amount.String(),
+			Number:      nil,
+		}
+	}
+
+	return &RpcAccountInfo{
+		AccountAddress:      addr,
+		TotalNumber:         strconv.FormatUint(block.Height, 10),
+		TokenBalanceInfoMap: tokenBalanceInfoMap,
+	}, nil
+}
+
 func (l *LedgerApi) GetSnapshotBlockByHash(hash types.Hash) (*ledger.SnapshotBlock, error) {
 	block, err := l.chain.GetSnapshotBlockByHash(&hash)
 	if err != nil {
@@ -277,7 +432,7 @@ func (l *LedgerApi) GetLatestSnapshotChainHash() *types.Hash {
 	return &l.chain.GetLatestSnapshotBlock().Hash
 }
 
-func (l *LedgerApi) GetLatestBlock(addr types.Address) (*AccountBlock, error) {
+func (l *LedgerApi) GetLatestBlock(addr types.Address, decode *bool) (*AccountBlock, error) {
 	l.log.Info("GetLatestBlock")
 	block, getError := l.chain.GetLatestAccountBlock(&addr)
 	if getError != nil {
@@ -289,7 +444,7 @@ func (l *LedgerApi) GetLatestBlock(addr types.Address) (*AccountBlock, error) {
 		return nil, nil
 	}
 
-	return l.ledgerBlockToRpcBlock(block)
+	return l.ledgerBlockToRpcBlock(block, decode)
 }
 
 func (l *LedgerApi) GetTokenMintage(tti types.TokenTypeId) (*RpcTokenInfo, error) {
@@ -439,7 +594,9 @@ func (l *LedgerApi) AccountType(addr types.Address) (uint64, error) {
 	return l.chain.AccountType(&addr)
 }
 
-func (l *LedgerApi) GetVmLogList(blockHash types.Hash) (ledger.VmLogList, error) {
+// GetVmLogList returns the logs of the block, the events are decoded by the registered abi of the contract if decode
+// is true.
+func (l *LedgerApi) GetVmLogList(blockHash types.Hash, decode *bool) ([]*VmLog, error) {
 	block, err := l.chain.GetAccountBlockByHash(&blockHash)
 	if block == nil {
 		if err != nil {
@@ -447,6 +604,9 @@ func (l *LedgerApi) GetVmLogList(blockHash types.Hash) (ledger.VmLogList, error)
 		}
 		return nil, errors.New("get block failed")
 	}
+	if err := l.checkPruned(block); err != nil {
+		return nil, err
+	}
 	if block.LogHash == nil {
 		code, err2 := l.chain.AccountType(&block.AccountAddress)
 		if err2 != nil {
@@ -457,7 +617,18 @@ func (l *LedgerApi) GetVmLogList(blockHash types.Hash) (ledger.VmLogList, error)
 		}
 		return nil, nil
 	}
-	return l.chain.GetVmLogList(block.LogHash)
+	logList, err := l.chain.GetVmLogList(block.LogHash)
+	if err != nil || logList == nil {
+		return nil, err
+	}
+	list := make([]*VmLog, len(logList))
+	for i, vmLog := range logList {
+		list[i] = &VmLog{VmLog: vmLog}
+		if decode != nil && *decode {
+			list[i].Event = DecodeVmLog(block.AccountAddress, vmLog)
+		}
+	}
+	return list, nil
 }
 
 func (l *LedgerApi) GetGcStatus() *GcStatus {
@@ -476,3 +647,114 @@ func (l *LedgerApi) GetGcStatus() *GcStatus {
 	}
 	return gStatus
 }
+
+type AccountStateProof struct {
+	SnapshotHash     types.Hash  `json:"snapshotHash"`
+	StateHash        types.Hash  `json:"stateHash"`
+	AccountStateHash *types.Hash `json:"accountStateHash"`
+	Proof            trie.Proof  `json:"proof"`
+
+	BalanceProofs []*StorageProof `json:"balanceProofs,omitempty"`
+	StorageProofs []*StorageProof `json:"storageProofs,omitempty"`
+}
+
+// StorageProof proves a key of the account state trie under AccountStateHash, verify it with trie.VerifySubProof,
+// a value of hash size is reported as trie.ErrProofAmbiguous.
+type StorageProof struct {
+	TokenTypeId *types.TokenTypeId `json:"tokenTypeId,omitempty"`
+	Key         string             `json:"key"`   // hex
+	Value       string             `json:"value"` // hex, empty if the key is not in the account state
+	Proof       trie.Proof         `json:"proof"`
+}
+
+// AccountStateProofKeys are the balances and storage keys to prove in the account state trie.
+type AccountStateProofKeys struct {
+	TokenTypeIds []types.TokenTypeId `json:"tokenTypeIds"`
+	StorageKeys  []string            `json:"storageKeys"` // hex
+}
+
+// GetAccountStateProof proves the account state hash of addr under the state hash of the snapshot block,
+// accountStateHash is nil when the proof shows the account is not in the state.
+// The balances and storage keys in keys are proved under the account state hash.
+func (l *LedgerApi) GetAccountStateProof(addr types.Address, snapshotHash types.Hash, keys *AccountStateProofKeys) (*AccountStateProof, error) {
+	snapshotBlock, err := l.chain.GetSnapshotBlockHeadByHash(&snapshotHash)
+	if err != nil {
+		l.log.Error("GetSnapshotBlockHeadByHash failed, error is "+err.Error(), "method", "GetAccountStateProof")
+		return nil, err
+	}
+	if snapshotBlock == nil {
+		return nil, errors.New("snapshot block is not existed")
+	}
+
+	stateTrie := l.chain.GetStateTrie(&snapshotBlock.StateHash)
+	if stateTrie == nil || stateTrie.Root == nil {
+		return nil, errors.New(fmt.Sprintf("state trie of snapshot block %d is not existed, it may have been cleared by trie gc",
+			snapshotBlock.Height))
+	}
+
+	proof, err := stateTrie.Prove(addr.Bytes())
+	if err != nil {
+		l.log.Error("Prove failed, error is "+err.Error(), "method", "GetAccountStateProof")
+		return nil, err
+	}
+
+	result := &AccountStateProof{
+		SnapshotHash: snapshotBlock.Hash,
+		StateHash:    snapshotBlock.StateHash,
+		Proof:        proof,
+	}
+
+	if stateHashBytes := stateTrie.GetValue(addr.Bytes()); len(stateHashBytes) > 0 {
+		accountStateHash, err := types.BytesToHash(stateHashBytes)
+		if err != nil {
+			return nil, err
+		}
+		result.AccountStateHash = &accountStateHash
+	}
+
+	if keys == nil || result.AccountStateHash == nil {
+		return result, nil
+	}
+
+	accountTrie := l.chain.GetStateTrie(result.AccountStateHash)
+	if accountTrie == nil || accountTrie.Root == nil {
+		return nil, errors.New(fmt.Sprintf("account state trie %s is not existed, it may have been cleared by trie gc",
+			result.AccountStateHash))
+	}
+
+	for i := range keys.TokenTypeIds {
+		tokenTypeId := keys.TokenTypeIds[i]
+		storageProof, err := proveStorage(accountTrie, vm_context.BalanceKey(&tokenTypeId))
+		if err != nil {
+			l.log.Error("Prove balance failed, error is "+err.Error(), "method", "GetAccountStateProof")
+			return nil, err
+		}
+		storageProof.TokenTypeId = &tokenTypeId
+		result.BalanceProofs = append(result.BalanceProofs, storageProof)
+	}
+	for _, hexKey := range keys.StorageKeys {
+		key, err := hex.DecodeString(hexKey)
+		if err != nil {
+			return nil, err
+		}
+		storageProof, err := proveStorage(accountTrie, key)
+		if err != nil {
+			l.log.Error("Prove storage failed, error is "+err.Error(), "method", "GetAccountStateProof")
+			return nil, err
+		}
+		result.StorageProofs = append(result.StorageProofs, storageProof)
+	}
+	return result, nil
+}
+
+func proveStorage(accountTrie *trie.Trie, key []byte) (*StorageProof, error) {
+	proof, err := accountTrie.Prove(key)
+	if err != nil {
+		return nil, err
+	}
+	return &StorageProof{
+		Key:   hex.EncodeToString(key),
+		Value: hex.EncodeToString(accountTrie.GetValue(key)),
+		Proof: proof,
+	}, nil
+}
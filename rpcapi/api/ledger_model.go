@@ -29,6 +29,9 @@ type AccountBlock struct {
 	TokenInfo      *RpcTokenInfo `json:"tokenInfo"`
 
 	ReceiveBlockHeights []string `json:"receiveBlockHeights"`
+
+	// DecodedData is only set when asked in the ledger queries
+	DecodedData *DecodedData `json:"decodedData,omitempty"`
 }
 
 func (ab *AccountBlock) LedgerAccountBlock() (*ledger.AccountBlock, error) {
@@ -188,8 +191,10 @@ type KafkaSendInfo struct {
 
 type KafkaProducerInfo struct {
 	ProducerId uint8    `json:"producerId"`
+	Kind       string   `json:"kind"`
 	BrokerList []string `json:"brokerList"`
 	Topic      string   `json:"topic"`
+	Target     string   `json:"target"`
 	HasSend    uint64   `json:"hasSend"`
 	Status     string   `json:"status"`
 }
@@ -205,8 +210,10 @@ func createKafkaProducerInfo(producer *sender.Producer) *KafkaProducerInfo {
 
 	producerInfo := &KafkaProducerInfo{
 		ProducerId: producer.ProducerId(),
+		Kind:       producer.Kind(),
 		BrokerList: producer.BrokerList(),
 		Topic:      producer.Topic(),
+		Target:     producer.Target(),
 		HasSend:    producer.HasSend(),
 		Status:     status,
 	}
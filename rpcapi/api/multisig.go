@@ -0,0 +1,110 @@
+package api
+
+import (
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm_context"
+)
+
+type MultiSigApi struct {
+	chain chain.Chain
+	log   log15.Logger
+}
+
+func NewMultiSigApi(vite *vite.Vite) *MultiSigApi {
+	return &MultiSigApi{
+		chain: vite.Chain(),
+		log:   log15.New("module", "rpc_api/multisig_api"),
+	}
+}
+
+func (m MultiSigApi) String() string {
+	return "MultiSigApi"
+}
+
+type MultiSigInfo struct {
+	Threshold uint8               `json:"threshold"`
+	Keys      []ed25519.PublicKey `json:"keys"`
+}
+
+// MultiSignature is the signature of a signer of a multisig account, which is collected offline and combined into the
+// block by CombineSignatures.
+type MultiSignature struct {
+	PublicKey ed25519.PublicKey `json:"publicKey"`
+	Signature []byte            `json:"signature"`
+}
+
+func toMultiSigKeys(keys []ed25519.PublicKey) ([][32]byte, error) {
+	list := make([][32]byte, len(keys))
+	for i, key := range keys {
+		if len(key) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid public key length")
+		}
+		copy(list[i][:], key)
+	}
+	return list, nil
+}
+
+// GetMultiSigAddress returns the address of the key set, which doesn't depend on the order of the keys.
+func (m *MultiSigApi) GetMultiSigAddress(threshold uint8, keys []ed25519.PublicKey) (*types.Address, error) {
+	list, err := toMultiSigKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+	addr := abi.NewMultiSigAddress(threshold, list)
+	return &addr, nil
+}
+
+func (m *MultiSigApi) GetRegisterData(threshold uint8, keys []ed25519.PublicKey) ([]byte, error) {
+	list, err := toMultiSigKeys(keys)
+	if err != nil {
+		return nil, err
+	}
+	abi.SortMultiSigKeys(list)
+	return abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigRegister, threshold, list)
+}
+
+// GetMultiSigInfo returns the key set registered for the address, nil if the address is not registered.
+func (m *MultiSigApi) GetMultiSigInfo(addr types.Address) (*MultiSigInfo, error) {
+	snapshotBlock := m.chain.GetLatestSnapshotBlock()
+	vmContext, err := vm_context.NewVmContext(m.chain, &snapshotBlock.Hash, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	info := abi.GetMultiSigInfo(vmContext, addr)
+	if info == nil {
+		return nil, nil
+	}
+	keys := make([]ed25519.PublicKey, len(info.Keys))
+	for i := range info.Keys {
+		keys[i] = append(ed25519.PublicKey{}, info.Keys[i][:]...)
+	}
+	return &MultiSigInfo{info.Threshold, keys}, nil
+}
+
+// CombineSignatures adds the signatures of the signers to the block, the hash of the block must be computed already.
+// The result can be sent by tx_sendRawTx once there are enough signatures.
+func (m *MultiSigApi) CombineSignatures(block *AccountBlock, signatures []MultiSignature) (*AccountBlock, error) {
+	if block == nil || block.AccountBlock == nil {
+		return nil, errors.New("block is nil")
+	}
+	lb, err := block.LedgerAccountBlock()
+	if err != nil {
+		return nil, err
+	}
+	if lb.ComputeHash() != lb.Hash {
+		return nil, errors.New("hash of the block doesn't match")
+	}
+	for _, s := range signatures {
+		if err := lb.AddSignature(s.PublicKey, s.Signature); err != nil {
+			return nil, err
+		}
+	}
+	block.AccountBlock = lb
+	return block, nil
+}
@@ -47,7 +47,10 @@ func (t Tx) SendRawTx(block *AccountBlock) error {
 	//if len(lb.Data) != 0 && block.BlockType == ledger.BlockTypeReceive {
 	//	return ErrorNotSupportRecvAddNote
 	//}
+	return t.sendLedgerBlock(lb)
+}
 
+func (t Tx) sendLedgerBlock(lb *ledger.AccountBlock) error {
 	v := verifier.NewAccountVerifier(t.vite.Chain(), t.vite.Consensus())
 
 	blocks, err := v.VerifyforRPC(lb)
@@ -57,7 +60,7 @@ func (t Tx) SendRawTx(block *AccountBlock) error {
 	}
 
 	if len(blocks) > 0 && blocks[0] != nil {
-		return t.vite.Pool().AddDirectAccountBlock(block.AccountAddress, blocks[0])
+		return t.vite.Pool().AddDirectAccountBlock(lb.AccountAddress, blocks[0])
 	} else {
 		return errors.New("generator gen an empty block")
 	}
@@ -183,8 +186,9 @@ func (t Tx) CalcPoWDifficulty(param CalcPoWDifficultyParam) (difficulty string,
 		if param.ToAddr == nil {
 			return "", errors.New("toAddr is nil")
 		}
-		if types.IsPrecompiledContractAddress(*param.ToAddr) {
-			if method, ok, err := vm.GetPrecompiledContract(*param.ToAddr, param.Data); !ok || err != nil {
+		sbHeight := t.vite.Chain().GetLatestSnapshotBlock().Height
+		if types.IsPrecompiledContractAddress(*param.ToAddr, sbHeight) {
+			if method, ok, err := vm.GetPrecompiledContract(*param.ToAddr, param.Data, sbHeight); !ok || err != nil {
 				return "", errors.New("precompiled contract method not exists")
 			} else {
 				quotaRequired = method.GetQuota()
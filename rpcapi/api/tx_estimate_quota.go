@@ -0,0 +1,102 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/helper"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/quota"
+	"github.com/vitelabs/go-vite/vm/util"
+	"github.com/vitelabs/go-vite/vm_context"
+)
+
+// QuotaEstimate is the quota of the next block of an account, the quota used and left are counted in the blocks
+// referring to the same snapshot block.
+type QuotaEstimate struct {
+	QuotaRequired string `json:"quotaRequired"`
+	PledgeQuota   string `json:"pledgeQuota"`
+	QuotaUsed     string `json:"quotaUsed"`
+	QuotaLeft     string `json:"quotaLeft"`
+	// Difficulty is 0 if the pledge quota is enough, and it is empty if the gap can't be covered by PoW,
+	// DifficultyError tells the reason.
+	Difficulty      string `json:"difficulty"`
+	DifficultyError string `json:"difficultyError,omitempty"`
+}
+
+// EstimateQuota runs a send block without the quota limit of the account and returns the quota required by the block,
+// and the PoW difficulty needed if the pledge quota is not enough. Nothing is written into the pool or the chain, the
+// block is filled in the same way as Simulate.
+func (t Tx) EstimateQuota(block *AccountBlock) (result *QuotaEstimate, resultErr error) {
+	log.Info("EstimateQuota")
+	lb, err := t.fillUnsentBlock(block)
+	if err != nil {
+		return nil, err
+	}
+	if !lb.IsSendBlock() {
+		return nil, errors.New("only the quota of send block can be estimated")
+	}
+	return t.estimateQuota(lb)
+}
+
+// estimateQuota runs a filled send block, the block is changed by the vm.
+func (t Tx) estimateQuota(lb *ledger.AccountBlock) (result *QuotaEstimate, resultErr error) {
+	prevHash := &lb.PrevHash
+	if lb.Height <= 1 {
+		prevHash = nil
+	}
+	db, err := vm_context.NewVmContext(t.vite.Chain(), &lb.SnapshotHash, prevHash, &lb.AccountAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	pledgeAmount := abi.GetPledgeBeneficialAmount(db, lb.AccountAddress)
+	pledgeQuota, quotaUsed, err := quota.CalcPledgeQuota(db, lb.AccountAddress, pledgeAmount)
+	if err != nil {
+		newerr, _ := TryMakeConcernedError(err)
+		return nil, newerr
+	}
+
+	defer func() {
+		if err := recover(); err != nil {
+			result = nil
+			resultErr = errors.New(fmt.Sprintf("estimate quota panic error %v", err))
+		}
+	}()
+
+	newVm := vm.NewVM()
+	newVm.QuotaLimit = helper.MaxUint64
+	blockList, _, err := newVm.Run(db, lb, nil)
+	if err != nil {
+		newerr, _ := TryMakeConcernedError(err)
+		return nil, newerr
+	}
+	if len(blockList) <= 0 {
+		return nil, errors.New("vm failed, blockList is empty")
+	}
+	quotaRequired := blockList[0].AccountBlock.Quota
+
+	result = &QuotaEstimate{
+		QuotaRequired: uint64ToString(quotaRequired),
+		PledgeQuota:   uint64ToString(pledgeQuota),
+		QuotaUsed:     uint64ToString(quotaUsed),
+		QuotaLeft:     "0",
+	}
+	if pledgeQuota > quotaUsed {
+		result.QuotaLeft = uint64ToString(pledgeQuota - quotaUsed)
+	}
+	// the state of the account is changed by the vm, while the previous blocks and the pledge amount are not
+	difficulty, err := quota.CalcPoWDifficultyWithPledge(db, lb.AccountAddress, pledgeAmount, quotaRequired)
+	if err != nil {
+		if err != util.ErrOutOfQuota && err != util.ErrCalcPoWTwice {
+			newerr, _ := TryMakeConcernedError(err)
+			return nil, newerr
+		}
+		result.DifficultyError = err.Error()
+	} else {
+		result.Difficulty = difficulty.String()
+	}
+	return result, nil
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"math/big"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/generator"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/pow"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/quota"
+	"github.com/vitelabs/go-vite/vm/util"
+	"github.com/vitelabs/go-vite/vm_context"
+	"github.com/vitelabs/go-vite/wallet/offline"
+)
+
+// UnsignedTx is a block built for offline signing, the payload is signed by `gvite wallet sign` on the machine
+// holding the entropy store and sent back by tx_sendSignedTx. If the block needs PoW but no nonce is given, only the
+// difficulty and the hash to calculate the nonce on are returned.
+type UnsignedTx struct {
+	Block      *AccountBlock `json:"block,omitempty"`
+	Payload    string        `json:"payload,omitempty"`
+	Difficulty *string       `json:"difficulty,omitempty"`
+	PoWHash    *types.Hash   `json:"powHash,omitempty"`
+}
+
+// CreateUnsignedTx builds a block without signature, the block is filled in the same way as Simulate. The node doesn't
+// do the PoW, if the pledge quota of the account is not enough, the caller calculates the nonce by the returned
+// difficulty, e.g. by pow_getPowNonce, and creates the block again with the difficulty and the nonce.
+func (t Tx) CreateUnsignedTx(block *AccountBlock) (*UnsignedTx, error) {
+	log.Info("CreateUnsignedTx")
+	lb, err := t.fillUnsentBlock(block)
+	if err != nil {
+		return nil, err
+	}
+	if lb.BlockType == ledger.BlockTypeReceive && lb.FromBlockHash == types.ZERO_HASH {
+		return nil, errors.New("fromBlockHash can't be empty when create receive block")
+	}
+
+	powHash := types.DataHash(append(lb.AccountAddress.Bytes(), lb.PrevHash.Bytes()...))
+	if len(lb.Nonce) > 0 {
+		if lb.Difficulty.Sign() <= 0 || !pow.CheckPowNonce(lb.Difficulty, lb.Nonce, powHash.Bytes()) {
+			return nil, ErrVerifyNonce
+		}
+	} else {
+		difficulty, err := t.calcUnsentBlockDifficulty(lb)
+		if err != nil {
+			return nil, err
+		}
+		if difficulty.Sign() > 0 {
+			difficultyStr := difficulty.String()
+			return &UnsignedTx{Difficulty: &difficultyStr, PoWHash: &powHash}, nil
+		}
+		lb.Difficulty = nil
+	}
+
+	var prevHash *types.Hash
+	if lb.Height > 1 {
+		prevHash = &lb.PrevHash
+	}
+	gen, err := generator.NewGenerator(t.vite.Chain(), &lb.SnapshotHash, prevHash, &lb.AccountAddress)
+	if err != nil {
+		return nil, err
+	}
+	genResult, err := gen.GenerateWithBlock(lb, nil)
+	if err != nil {
+		newerr, _ := TryMakeConcernedError(err)
+		return nil, newerr
+	}
+	if genResult.Err != nil {
+		newerr, _ := TryMakeConcernedError(genResult.Err)
+		return nil, newerr
+	}
+	if len(genResult.BlockGenList) <= 0 || genResult.BlockGenList[0] == nil {
+		return nil, errors.New("generator gen an empty block")
+	}
+
+	unsignedBlock := genResult.BlockGenList[0].AccountBlock
+	payload, err := offline.EncodeBlock(unsignedBlock)
+	if err != nil {
+		return nil, err
+	}
+	rpcBlock, err := ledgerToRpcBlock(unsignedBlock, t.vite.Chain())
+	if err != nil {
+		return nil, err
+	}
+	return &UnsignedTx{Block: rpcBlock, Payload: payload}, nil
+}
+
+// calcUnsentBlockDifficulty returns the PoW difficulty needed by the block, 0 if the pledge quota is enough.
+func (t Tx) calcUnsentBlockDifficulty(lb *ledger.AccountBlock) (*big.Int, error) {
+	if lb.IsSendBlock() {
+		estimate, err := t.estimateQuota(lb.Copy())
+		if err != nil {
+			return nil, err
+		}
+		if estimate.Difficulty == "" {
+			return nil, errors.New(estimate.DifficultyError)
+		}
+		difficulty, ok := new(big.Int).SetString(estimate.Difficulty, 10)
+		if !ok {
+			return nil, ErrStrToBigInt
+		}
+		return difficulty, nil
+	}
+
+	var prevHash *types.Hash
+	if lb.Height > 1 {
+		prevHash = &lb.PrevHash
+	}
+	db, err := vm_context.NewVmContext(t.vite.Chain(), &lb.SnapshotHash, prevHash, &lb.AccountAddress)
+	if err != nil {
+		return nil, err
+	}
+	quotaRequired, _ := util.IntrinsicGasCost(nil, false)
+	pledgeAmount := abi.GetPledgeBeneficialAmount(db, lb.AccountAddress)
+	difficulty, err := quota.CalcPoWDifficultyWithPledge(db, lb.AccountAddress, pledgeAmount, quotaRequired)
+	if err != nil {
+		newerr, _ := TryMakeConcernedError(err)
+		return nil, newerr
+	}
+	return difficulty, nil
+}
+
+// SendSignedTx sends a block signed offline, the payload is the output of `gvite wallet sign`.
+func (t Tx) SendSignedTx(payload string) error {
+	log.Info("SendSignedTx")
+	lb, err := offline.DecodeBlock(payload)
+	if err != nil {
+		return err
+	}
+	return t.sendLedgerBlock(lb)
+}
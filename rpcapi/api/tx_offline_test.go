@@ -0,0 +1,261 @@
+package api
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/chain/unittest"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/generator"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/pow"
+	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/wallet"
+	"github.com/vitelabs/go-vite/wallet/offline"
+)
+
+// newTestNode creates a node on a temp chain whose genesis account is genesisAddr, the chain, the consensus and the
+// pool are started for sending blocks, the net is not.
+func newTestNode(t *testing.T, genesisAddr types.Address) (*vite.Vite, func()) {
+	dir, err := ioutil.TempDir("", "tx_offline")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genesis := chain_unittest.MakeChainConfig("")
+	genesis.GenesisAccountAddress = genesisAddr
+	v, err := vite.New(&config.Config{
+		DataDir:  dir,
+		Genesis:  genesis,
+		Producer: &config.Producer{},
+		Net:      &config.Net{Single: true},
+		// the test quota params need a small PoW difficulty
+		Vm: &config.Vm{IsUseVmTestParam: true},
+	}, wallet.New(&wallet.Config{DataDir: dir}))
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+
+	if err := v.Init(); err != nil {
+		t.Fatal(err)
+	}
+	v.OnRoad().Start()
+	v.Chain().Start()
+	if err := v.Consensus().Init(); err != nil {
+		t.Fatal(err)
+	}
+	v.Pool().Init(v.Net(), v.WalletManager(), v.SnapshotVerifier(), v.AccountVerifier())
+	v.Consensus().Start()
+	v.Pool().Start()
+
+	return v, func() {
+		v.Pool().Stop()
+		v.Consensus().Stop()
+		v.Chain().Stop()
+		v.OnRoad().Stop()
+		v.Chain().Destroy()
+		os.RemoveAll(dir)
+	}
+}
+
+// insertSnapshotBlock snapshots the unconfirmed account blocks, the block isn't produced by a producer, so it's
+// inserted into the chain directly.
+func insertSnapshotBlock(t *testing.T, v *vite.Vite) {
+	c := v.Chain()
+	latestBlock := c.GetLatestSnapshotBlock()
+	now := time.Now()
+	sb := &ledger.SnapshotBlock{
+		Height:          latestBlock.Height + 1,
+		PrevHash:        latestBlock.Hash,
+		Timestamp:       &now,
+		SnapshotContent: c.GetNeedSnapshotContent(),
+	}
+	stateTrie, err := c.GenStateTrie(latestBlock.StateHash, sb.SnapshotContent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sb.StateTrie = stateTrie
+	sb.StateHash = *stateTrie.Hash()
+	sb.Hash = sb.ComputeHash()
+	if err := c.InsertSnapshotBlock(sb); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// signOffline signs the payload like `gvite wallet sign` and returns the signed payload.
+func signOffline(t *testing.T, payload string, priv ed25519.PrivateKey) (*ledger.AccountBlock, string) {
+	block, err := offline.DecodeBlock(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := offline.SignBlock(block, func(addr types.Address, data []byte) ([]byte, []byte, error) {
+		return ed25519.Sign(priv, data), priv.PubByte(), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	signed, err := offline.EncodeBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return block, signed
+}
+
+// createWithPoW creates the unsigned block, calculates the nonce by the returned difficulty and creates it again.
+func createWithPoW(t *testing.T, tx *Tx, newBlock func() *AccountBlock) *UnsignedTx {
+	unsigned, err := tx.CreateUnsignedTx(newBlock())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unsigned.Difficulty == nil || unsigned.PoWHash == nil || unsigned.Block != nil || unsigned.Payload != "" {
+		t.Fatalf("the block needs PoW, got %+v", unsigned)
+	}
+	difficulty, ok := new(big.Int).SetString(*unsigned.Difficulty, 10)
+	if !ok || difficulty.Sign() <= 0 {
+		t.Fatalf("invalid difficulty %v", *unsigned.Difficulty)
+	}
+	nonce, err := pow.GetPowNonce(difficulty, *unsigned.PoWHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := newBlock()
+	block.AccountBlock.Nonce = nonce
+	block.Difficulty = unsigned.Difficulty
+	unsigned, err = tx.CreateUnsignedTx(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unsigned.Block == nil || unsigned.Payload == "" || unsigned.Block.Difficulty == nil ||
+		*unsigned.Block.Difficulty != difficulty.String() {
+		t.Fatalf("unsigned block with PoW is %+v", unsigned)
+	}
+	return unsigned
+}
+
+func TestTx_SendSignedTx(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := types.PubkeyToAddress(priv.PubByte())
+	v, closeVite := newTestNode(t, addr)
+	defer closeVite()
+	tx := NewTxApi(v)
+	c := v.Chain()
+
+	// the first block of an account needs PoW, its height is given as the account doesn't exist
+	mintBlock, err := c.GetLatestAccountBlock(&types.AddressMintage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned := createWithPoW(t, tx, func() *AccountBlock {
+		return &AccountBlock{
+			AccountBlock: &ledger.AccountBlock{
+				BlockType:      ledger.BlockTypeReceive,
+				AccountAddress: addr,
+				FromBlockHash:  mintBlock.Hash,
+			},
+			Height: "1",
+		}
+	})
+	block, payload := signOffline(t, unsigned.Payload, priv)
+
+	// the signature is checked by the node
+	tampered := block.Copy()
+	tampered.Signature = append([]byte{}, block.Signature...)
+	tampered.Signature[0] ^= 1
+	tamperedPayload, err := offline.EncodeBlock(tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.SendSignedTx(tamperedPayload); err == nil {
+		t.Fatal("block with a tampered signature is sent")
+	}
+	if latest, err := c.GetLatestAccountBlock(&addr); err != nil || latest != nil {
+		t.Fatalf("latest block is %+v, err %v", latest, err)
+	}
+
+	if err := tx.SendSignedTx(payload); err != nil {
+		t.Fatal(err)
+	}
+	if latest, err := c.GetLatestAccountBlock(&addr); err != nil || latest == nil || latest.Hash != block.Hash {
+		t.Fatalf("latest block is %+v, err %v", latest, err)
+	}
+	insertSnapshotBlock(t, v)
+
+	// the account doesn't pledge yet, so the pledge needs PoW too
+	pledgeData, err := abi.ABIPledge.PackMethod(abi.MethodNamePledge, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pledgeAmount := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18)).String()
+	unsigned = createWithPoW(t, tx, func() *AccountBlock {
+		return &AccountBlock{
+			AccountBlock: &ledger.AccountBlock{
+				BlockType:      ledger.BlockTypeSendCall,
+				AccountAddress: addr,
+				ToAddress:      types.AddressPledge,
+				TokenId:        ledger.ViteTokenId,
+				Data:           pledgeData,
+			},
+			Amount: &pledgeAmount,
+		}
+	})
+	block, payload = signOffline(t, unsigned.Payload, priv)
+	if err := tx.SendSignedTx(payload); err != nil {
+		t.Fatal(err)
+	}
+	insertSnapshotBlock(t, v)
+
+	// the pledge contract receives the pledge, the contract worker isn't started
+	gen, err := generator.NewGenerator(c, &c.GetLatestSnapshotBlock().Hash, nil, &types.AddressPledge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	genResult, err := gen.GenerateWithOnroad(*block, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if genResult.Err != nil {
+		t.Fatal(genResult.Err)
+	}
+	if err := c.InsertAccountBlocks(genResult.BlockGenList); err != nil {
+		t.Fatal(err)
+	}
+	insertSnapshotBlock(t, v)
+
+	// the pledge quota is enough for a transfer
+	_, other, _ := ed25519.GenerateKey(nil)
+	amount := "1"
+	unsigned, err = tx.CreateUnsignedTx(&AccountBlock{
+		AccountBlock: &ledger.AccountBlock{
+			BlockType:      ledger.BlockTypeSendCall,
+			AccountAddress: addr,
+			ToAddress:      types.PubkeyToAddress(other.PubByte()),
+			TokenId:        ledger.ViteTokenId,
+		},
+		Amount: &amount,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unsigned.Difficulty != nil || unsigned.Block == nil || unsigned.Payload == "" ||
+		unsigned.Block.Difficulty != nil || len(unsigned.Block.Nonce) != 0 {
+		t.Fatalf("unsigned block without PoW is %+v", unsigned)
+	}
+	block, payload = signOffline(t, unsigned.Payload, priv)
+	if err := tx.SendSignedTx(payload); err != nil {
+		t.Fatal(err)
+	}
+	if latest, err := c.GetLatestAccountBlock(&addr); err != nil || latest == nil || latest.Hash != block.Hash ||
+		latest.Height != 3 {
+		t.Fatalf("latest block is %+v, err %v", latest, err)
+	}
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/generator"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm_context"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
+)
+
+type SimulateResult struct {
+	Send    *SimulateBlockResult `json:"send"`
+	Receive *SimulateBlockResult `json:"receive,omitempty"`
+}
+
+// SimulateBlockResult is the block generated by the vm, Error is set if the vm failed, and a receive block with an
+// error is refunded or becomes a receive error block.
+type SimulateBlockResult struct {
+	Block         *AccountBlock    `json:"block,omitempty"`
+	SendBlockList []*AccountBlock  `json:"sendBlockList,omitempty"`
+	Error         string           `json:"error,omitempty"`
+	Refunded      bool             `json:"refunded"`
+	Logs          ledger.VmLogList `json:"logs,omitempty"`
+	StorageDiffs  []*StorageDiff   `json:"storageDiffs,omitempty"`
+	BalanceDiffs  []*BalanceDiff   `json:"balanceDiffs,omitempty"`
+}
+
+type StorageDiff struct {
+	Key    string `json:"key"`    // hex
+	Before string `json:"before"` // hex, empty if the key is not existed
+	After  string `json:"after"`  // hex, empty if the key is deleted
+}
+
+type BalanceDiff struct {
+	TokenId types.TokenTypeId `json:"tokenId"`
+	Before  string            `json:"before"` // big int
+	After   string            `json:"after"`  // big int
+}
+
+// stateDiffs compares the storage of the account after the vm with the storage before the block. The send blocks of a
+// contract are generated on the copies of the database, so the whole storage after the vm is iterated instead of
+// recording the writes, the keys are sorted to keep the result stable.
+func stateDiffs(before, after vmctxt_interface.VmDatabase) ([]*StorageDiff, []*BalanceDiff) {
+	var storageDiffs []*StorageDiff
+	var balanceDiffs []*BalanceDiff
+
+	iter := after.UnsavedCache().Trie().NewIterator(nil)
+	for {
+		key, value, ok := iter.Next()
+		if !ok {
+			break
+		}
+		original := before.GetOriginalStorage(key)
+		if bytes.Equal(original, value) {
+			continue
+		}
+
+		switch {
+		case bytes.HasPrefix(key, vm_context.STORAGE_KEY_CODE):
+			continue
+		case bytes.HasPrefix(key, vm_context.STORAGE_KEY_BALANCE):
+			tokenId, err := types.BytesToTokenTypeId(key[len(vm_context.STORAGE_KEY_BALANCE):])
+			if err != nil {
+				continue
+			}
+			balanceBefore := new(big.Int).SetBytes(original)
+			balanceAfter := new(big.Int).SetBytes(value)
+			if balanceBefore.Cmp(balanceAfter) == 0 {
+				continue
+			}
+			balanceDiffs = append(balanceDiffs, &BalanceDiff{TokenId: tokenId, Before: balanceBefore.String(), After: balanceAfter.String()})
+		default:
+			storageDiffs = append(storageDiffs, &StorageDiff{
+				Key:    hex.EncodeToString(key),
+				Before: hex.EncodeToString(original),
+				After:  hex.EncodeToString(value),
+			})
+		}
+	}
+
+	sort.Slice(storageDiffs, func(i, j int) bool { return storageDiffs[i].Key < storageDiffs[j].Key })
+	sort.Slice(balanceDiffs, func(i, j int) bool {
+		return bytes.Compare(balanceDiffs[i].TokenId.Bytes(), balanceDiffs[j].TokenId.Bytes()) < 0
+	})
+	return storageDiffs, balanceDiffs
+}
+
+// Simulate runs a send block and the receive block of the contract if the send block is sent to a contract, nothing
+// is written into the pool or the chain. The block is not verified, so the hash and the signature can be omitted,
+// the latest account block and the latest snapshot block are referred if the height and the snapshot hash are omitted.
+func (t Tx) Simulate(block *AccountBlock) (*SimulateResult, error) {
+	log.Info("Simulate")
+	lb, err := t.fillUnsentBlock(block)
+	if err != nil {
+		return nil, err
+	}
+	if !lb.IsSendBlock() {
+		return nil, errors.New("only send block can be simulated")
+	}
+
+	ch := t.vite.Chain()
+	var prevHash *types.Hash
+	if lb.Height > 1 {
+		prevHash = &lb.PrevHash
+	}
+	sendResult, sendBlock, err := t.simulateBlock(&lb.AccountAddress, prevHash, &lb.SnapshotHash, func(gen *generator.Generator) (*generator.GenResult, error) {
+		return gen.GenerateWithBlock(lb, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &SimulateResult{Send: sendResult}
+	if sendBlock == nil {
+		return result, nil
+	}
+
+	if sendBlock.BlockType != ledger.BlockTypeSendCreate {
+		if !types.IsPrecompiledContractAddress(sendBlock.ToAddress, ch.GetLatestSnapshotBlock().Height) {
+			accountType, err := ch.AccountType(&sendBlock.ToAddress)
+			if err != nil {
+				return nil, err
+			}
+			if accountType != ledger.AccountTypeContract {
+				return result, nil
+			}
+		}
+	}
+
+	latestBlock, err := ch.GetLatestAccountBlock(&sendBlock.ToAddress)
+	if err != nil {
+		return nil, err
+	}
+	prevHash = nil
+	if latestBlock != nil {
+		prevHash = &latestBlock.Hash
+	}
+	result.Receive, _, err = t.simulateBlock(&sendBlock.ToAddress, prevHash, &lb.SnapshotHash, func(gen *generator.Generator) (*generator.GenResult, error) {
+		return gen.GenerateWithOnroad(*sendBlock, nil, nil, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// fillUnsentBlock converts a block which is not sent yet, the latest account block and the latest snapshot block are
+// referred if the height and the snapshot hash are omitted.
+func (t Tx) fillUnsentBlock(block *AccountBlock) (*ledger.AccountBlock, error) {
+	if block == nil {
+		return nil, errors.New("empty block")
+	}
+	if block.Height == "" {
+		block.Height = "0"
+	}
+
+	lb, err := block.LedgerAccountBlock()
+	if err != nil {
+		return nil, err
+	}
+
+	ch := t.vite.Chain()
+	if lb.Height <= 0 {
+		latestBlock, err := ch.GetLatestAccountBlock(&lb.AccountAddress)
+		if err != nil {
+			return nil, err
+		}
+		if latestBlock == nil {
+			return nil, errors.New("account address doesn't exist")
+		}
+		lb.Height = latestBlock.Height + 1
+		lb.PrevHash = latestBlock.Hash
+	}
+	if lb.SnapshotHash == types.ZERO_HASH {
+		lb.SnapshotHash = ch.GetLatestSnapshotBlock().Hash
+	}
+	if block.Timestamp <= 0 {
+		now := time.Now()
+		lb.Timestamp = &now
+	}
+	return lb, nil
+}
+
+func (t Tx) simulateBlock(addr *types.Address, prevHash, snapshotHash *types.Hash,
+	generate func(gen *generator.Generator) (*generator.GenResult, error)) (*SimulateBlockResult, *ledger.AccountBlock, error) {
+	vmContext, err := vm_context.NewVmContext(t.vite.Chain(), snapshotHash, prevHash, addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	gen, err := generator.NewGeneratorWithVmDatabase(vmContext)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &SimulateBlockResult{}
+	genResult, err := generate(gen)
+	if err != nil {
+		result.Error = err.Error()
+		return result, nil, nil
+	}
+	if genResult.Err != nil {
+		result.Error = genResult.Err.Error()
+	}
+	if len(genResult.BlockGenList) <= 0 {
+		if result.Error == "" {
+			result.Error = "vm failed, blockList is empty"
+		}
+		return result, nil, nil
+	}
+
+	block := genResult.BlockGenList[0].AccountBlock
+	if result.Block, err = ledgerToRpcBlock(block, t.vite.Chain()); err != nil {
+		return nil, nil, err
+	}
+	// the send blocks generated by a failed receive block are reverted, only the refund block is left
+	result.Refunded = genResult.Err != nil && len(genResult.BlockGenList) > 1
+	for _, vmBlock := range genResult.BlockGenList[1:] {
+		rpcBlock, err := ledgerToRpcBlock(vmBlock.AccountBlock, t.vite.Chain())
+		if err != nil {
+			return nil, nil, err
+		}
+		result.SendBlockList = append(result.SendBlockList, rpcBlock)
+	}
+	result.Logs = genResult.BlockGenList[0].VmContext.GetLogList()
+	// the state after the send blocks of the contract
+	result.StorageDiffs, result.BalanceDiffs = stateDiffs(vmContext, genResult.BlockGenList[len(genResult.BlockGenList)-1].VmContext)
+	return result, block, nil
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/vitelabs/go-vite/chain/unittest"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/generator"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/trie"
+	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm_context"
+)
+
+func TestStateDiffs(t *testing.T) {
+	original := trie.NewTrie(nil, nil, nil)
+	original.SetValue([]byte("a"), []byte{1})
+	original.SetValue([]byte("b"), []byte{2})
+	original.SetValue(vm_context.BalanceKey(&ledger.ViteTokenId), big.NewInt(10).Bytes())
+
+	db := vm_context.NewEmptyVmContextByTrie(original)
+	db.SetStorage([]byte("a"), []byte{3})
+	db.SetStorage([]byte("b"), []byte{4})
+	db.SetStorage([]byte("b"), []byte{2})
+	db.SetContractCode([]byte{6})
+
+	// the vm writes the send blocks of a contract on the copies
+	copied := db.CopyAndFreeze()
+	copied.SetStorage([]byte("c"), []byte{5})
+	copied.SetStorage(vm_context.BalanceKey(&ledger.ViteTokenId), big.NewInt(7).Bytes())
+
+	storageDiffs, balanceDiffs := stateDiffs(db, copied)
+	if len(storageDiffs) != 2 {
+		t.Fatalf("%d storage diffs", len(storageDiffs))
+	}
+	for i, expected := range []StorageDiff{
+		{Key: "61", Before: "01", After: "03"},
+		{Key: "63", Before: "", After: "05"},
+	} {
+		if *storageDiffs[i] != expected {
+			t.Fatalf("storage diff %d is %+v", i, storageDiffs[i])
+		}
+	}
+	// SetStorage can't change the balance
+	if len(balanceDiffs) != 0 {
+		t.Fatalf("balance diffs are %+v", balanceDiffs)
+	}
+
+	// the reverted changes are ignored
+	db.Reset()
+	if storageDiffs, balanceDiffs := stateDiffs(db, db); len(storageDiffs) != 0 || len(balanceDiffs) != 0 {
+		t.Fatal("the reverted changes are listed")
+	}
+}
+
+func newTestVite(t *testing.T) (*vite.Vite, func()) {
+	dir, err := ioutil.TempDir("", "tx_simulate")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := vite.New(&config.Config{
+		DataDir:  dir,
+		Genesis:  chain_unittest.MakeChainConfig(""),
+		Producer: &config.Producer{},
+		Net:      &config.Net{Single: true},
+	}, nil)
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	// the test vm gets unlimited quota
+	vm.InitVmConfig(true, false, false, dir)
+	v.Chain().Init()
+	v.Chain().Start()
+
+	// the genesis account receives the vite token
+	c := v.Chain()
+	sendBlock, err := c.GetLatestAccountBlock(&types.AddressMintage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gen, err := generator.NewGenerator(c, &c.GetLatestSnapshotBlock().Hash, nil, &ledger.GenesisAccountAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	genResult, err := gen.GenerateWithOnroad(*sendBlock, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if genResult.Err != nil {
+		t.Fatal(genResult.Err)
+	}
+	if err := c.InsertAccountBlocks(genResult.BlockGenList); err != nil {
+		t.Fatal(err)
+	}
+
+	return v, func() {
+		v.Chain().Stop()
+		v.Chain().Destroy()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestTx_Simulate(t *testing.T) {
+	v, closeVite := newTestVite(t)
+	defer closeVite()
+
+	genesisAddr := ledger.GenesisAccountAddress
+	amount := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	data, err := abi.ABIPledge.PackMethod(abi.MethodNamePledge, genesisAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	amountStr := amount.String()
+	fee := "0"
+
+	balanceBefore, err := v.Chain().GetAccountBalanceByTokenId(&genesisAddr, &ledger.ViteTokenId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pledgeBefore, err := v.Chain().GetAccountBalanceByTokenId(&types.AddressPledge, &ledger.ViteTokenId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := NewTxApi(v).Simulate(&AccountBlock{
+		AccountBlock: &ledger.AccountBlock{
+			BlockType:      ledger.BlockTypeSendCall,
+			AccountAddress: genesisAddr,
+			ToAddress:      types.AddressPledge,
+			TokenId:        ledger.ViteTokenId,
+			Data:           data,
+		},
+		Amount: &amountStr,
+		Fee:    &fee,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Send.Error != "" || result.Receive == nil || result.Receive.Error != "" || result.Receive.Refunded {
+		t.Fatalf("simulate result is %+v, %+v", result.Send, result.Receive)
+	}
+
+	// the pledge amount is sent to the pledge contract
+	checkBalanceDiffs(t, result.Send.BalanceDiffs, balanceBefore, new(big.Int).Sub(balanceBefore, amount))
+	checkBalanceDiffs(t, result.Receive.BalanceDiffs, pledgeBefore, new(big.Int).Add(pledgeBefore, amount))
+	if len(result.Send.StorageDiffs) != 0 {
+		t.Fatalf("storage diffs of the send block are %+v", result.Send.StorageDiffs)
+	}
+
+	// the receive block writes the pledge info and the pledge amount of the beneficiary
+	beneficialKey := abi.GetPledgeBeneficialKey(genesisAddr)
+	keys := map[string]bool{
+		hex.EncodeToString(beneficialKey):                                false,
+		hex.EncodeToString(abi.GetPledgeKey(genesisAddr, beneficialKey)): false,
+	}
+	for _, diff := range result.Receive.StorageDiffs {
+		if _, ok := keys[diff.Key]; !ok || diff.Before != "" || diff.After == "" {
+			t.Fatalf("unexpected storage diff %+v", diff)
+		}
+		keys[diff.Key] = true
+	}
+	for key, found := range keys {
+		if !found {
+			t.Fatalf("storage diff of %s is missing", key)
+		}
+	}
+
+	// nothing is written into the chain
+	if balance, _ := v.Chain().GetAccountBalanceByTokenId(&types.AddressPledge, &ledger.ViteTokenId); balance.Cmp(pledgeBefore) != 0 {
+		t.Fatalf("balance of the pledge contract is changed to %s", balance)
+	}
+}
+
+func checkBalanceDiffs(t *testing.T, diffs []*BalanceDiff, before, after *big.Int) {
+	if len(diffs) != 1 || diffs[0].TokenId != ledger.ViteTokenId ||
+		diffs[0].Before != before.String() || diffs[0].After != after.String() {
+		t.Fatalf("balance diffs are %+v, want %s to %s", diffs, before, after)
+	}
+}
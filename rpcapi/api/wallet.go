@@ -382,6 +382,29 @@ func (m WalletApi) SignDataWithPassphrase(addr types.Address, hexMsg string, pas
 	return &t, nil
 }
 
+// SignMultiSigBlock signs the block of a multisig account with the key of the signer, the returned signature is
+// combined into the block by multisig_combineSignatures.
+func (m WalletApi) SignMultiSigBlock(signer types.Address, block *AccountBlock) (*MultiSignature, error) {
+	if block == nil || block.AccountBlock == nil {
+		return nil, errors.New("block is nil")
+	}
+	lb, err := block.LedgerAccountBlock()
+	if err != nil {
+		return nil, err
+	}
+	_, key, _, e := m.wallet.GlobalFindAddr(signer)
+	if e != nil {
+		return nil, e
+	}
+	hash := lb.ComputeHash()
+	signature, pubkey, err := key.SignData(hash.Bytes())
+	if err != nil {
+		newerr, _ := TryMakeConcernedError(err)
+		return nil, newerr
+	}
+	return &MultiSignature{pubkey, signature}, nil
+}
+
 func (m WalletApi) IsMayValidKeystoreFile(path string) IsMayValidKeystoreFileResponse {
 	b, addr, _ := entropystore.IsMayValidEntropystoreFile(path)
 	if b && addr != nil {
@@ -14,6 +14,12 @@ func Init(dir, lvl string, testApi_prikey, testApi_tti string, netId uint) {
 	api.InitConfig(netId)
 }
 
+// InitContractAbis registers the abis of the contracts in the format of "address|abiFilePath", which are used to
+// decode the logs.
+func InitContractAbis(abiFiles []string) error {
+	return api.InitContractAbis(abiFiles)
+}
+
 func GetApi(vite *vite.Vite, apiModule string) rpc.API {
 	switch apiModule {
 	// private IPC
@@ -68,6 +74,13 @@ func GetApi(vite *vite.Vite, apiModule string) rpc.API {
 			Service:   api.NewContractApi(vite),
 			Public:    true,
 		}
+	case "private_contract":
+		return rpc.API{
+			Namespace: "contract",
+			Version:   "1.0",
+			Service:   api.NewPrivateContractApi(),
+			Public:    false,
+		}
 	case "register":
 		return rpc.API{
 			Namespace: "register",
@@ -96,6 +109,27 @@ func GetApi(vite *vite.Vite, apiModule string) rpc.API {
 			Service:   api.NewPledgeApi(vite),
 			Public:    true,
 		}
+	case "batchSend":
+		return rpc.API{
+			Namespace: "batchSend",
+			Version:   "1.0",
+			Service:   api.NewBatchSendApi(),
+			Public:    true,
+		}
+	case "escrow":
+		return rpc.API{
+			Namespace: "escrow",
+			Version:   "1.0",
+			Service:   api.NewEscrowApi(vite),
+			Public:    true,
+		}
+	case "multisig":
+		return rpc.API{
+			Namespace: "multisig",
+			Version:   "1.0",
+			Service:   api.NewMultiSigApi(vite),
+			Public:    true,
+		}
 	case "consensusGroup":
 		return rpc.API{
 			Namespace: "consensusGroup",
@@ -125,6 +159,13 @@ func GetApi(vite *vite.Vite, apiModule string) rpc.API {
 			Service:   api.NewDebugApi(vite),
 			Public:    true,
 		}
+	case "private_debug":
+		return rpc.API{
+			Namespace: "debug",
+			Version:   "1.0",
+			Service:   api.NewPrivateDebugApi(vite),
+			Public:    false,
+		}
 	case "dashboard":
 		return rpc.API{
 			Namespace: "dashboard",
@@ -160,9 +201,9 @@ func GetApis(vite *vite.Vite, apiModule ...string) []rpc.API {
 }
 
 func GetPublicApis(vite *vite.Vite) []rpc.API {
-	return GetApis(vite, "ledger", "public_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "consensusGroup", "testapi", "pow", "tx", "debug", "dashboard")
+	return GetApis(vite, "ledger", "public_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "batchSend", "escrow", "multisig", "consensusGroup", "testapi", "pow", "tx", "debug", "dashboard")
 }
 
 func GetAllApis(vite *vite.Vite) []rpc.API {
-	return GetApis(vite, "ledger", "wallet", "private_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "consensusGroup", "testapi", "pow", "tx", "debug", "dashboard", "vmdebug", "subscribe")
+	return GetApis(vite, "ledger", "wallet", "private_onroad", "net", "contract", "private_contract", "pledge", "register", "vote", "mintage", "batchSend", "escrow", "multisig", "consensusGroup", "testapi", "pow", "tx", "debug", "private_debug", "dashboard", "vmdebug", "subscribe")
 }
@@ -135,35 +135,38 @@ func (trieNode *TrieNode) IsLeafNode() bool {
 
 func (trieNode *TrieNode) Hash() *types.Hash {
 	if trieNode.hash == nil {
-		var source []byte
-		switch trieNode.NodeType() {
-		case TRIE_FULL_NODE:
-			source = []byte{TRIE_FULL_NODE}
-			if trieNode.child != nil {
-				source = append(source, trieNode.child.Hash().Bytes()...)
-			}
+		hash, _ := types.BytesToHash(crypto.Hash256(trieNode.hashSource()))
+		trieNode.hash = &hash
+	}
+	return trieNode.hash
+}
 
-			sc := newSortedChildren(trieNode.children)
-			for _, c := range sc {
-				source = append(source, c.Key)
-				source = append(source, c.Value.Hash().Bytes()...)
-			}
-		case TRIE_SHORT_NODE:
-			source = []byte{TRIE_SHORT_NODE}
-			source = append(source, trieNode.key[:]...)
+func (trieNode *TrieNode) hashSource() []byte {
+	var source []byte
+	switch trieNode.NodeType() {
+	case TRIE_FULL_NODE:
+		source = []byte{TRIE_FULL_NODE}
+		if trieNode.child != nil {
 			source = append(source, trieNode.child.Hash().Bytes()...)
-		case TRIE_HASH_NODE:
-			source = []byte{TRIE_HASH_NODE}
-			source = trieNode.value
-		case TRIE_VALUE_NODE:
-			source = []byte{TRIE_VALUE_NODE}
-			source = trieNode.value
 		}
 
-		hash, _ := types.BytesToHash(crypto.Hash256(source))
-		trieNode.hash = &hash
+		sc := newSortedChildren(trieNode.children)
+		for _, c := range sc {
+			source = append(source, c.Key)
+			source = append(source, c.Value.Hash().Bytes()...)
+		}
+	case TRIE_SHORT_NODE:
+		source = []byte{TRIE_SHORT_NODE}
+		source = append(source, trieNode.key[:]...)
+		source = append(source, trieNode.child.Hash().Bytes()...)
+	case TRIE_HASH_NODE:
+		source = []byte{TRIE_HASH_NODE}
+		source = trieNode.value
+	case TRIE_VALUE_NODE:
+		source = []byte{TRIE_VALUE_NODE}
+		source = trieNode.value
 	}
-	return trieNode.hash
+	return source
 }
 
 func (trieNode *TrieNode) SetChild(child *TrieNode) {
@@ -178,6 +181,14 @@ func (trieNode *TrieNode) Children() map[byte]*TrieNode {
 	return trieNode.children
 }
 
+func (trieNode *TrieNode) Child() *TrieNode {
+	return trieNode.child
+}
+
+func (trieNode *TrieNode) Key() []byte {
+	return trieNode.key
+}
+
 func (trieNode *TrieNode) parseChildrenToPb(children map[byte]*TrieNode) map[uint32][]byte {
 	if children == nil {
 		return nil
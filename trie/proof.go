@@ -0,0 +1,255 @@
+package trie
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/crypto"
+)
+
+var (
+	ErrProofIncomplete = errors.New("proof is incomplete")
+	ErrProofRedundant  = errors.New("proof has redundant nodes")
+
+	// ErrProofAmbiguous is returned for a value node of types.HashSize bytes, because the node hash doesn't cover the
+	// node type, a hash node referencing a longer value has the same hash and the value node can be forged from it.
+	ErrProofAmbiguous = errors.New("proof ends in a value node of hash size, it can't be told from a hash node")
+
+	ErrNodeType = errors.New("trie node is not of the type expected at its position")
+)
+
+// CheckNodeType checks a node decoded from an untrusted source, like a proof or a state snapshot, against its position.
+// The hash of a value or hash node doesn't cover the node type, so a value node longer than types.HashSize, a hash node
+// of another size or a full node without children would have the hash of a node of another type, e.g. a forged value
+// node holding the serialized source of a full node. parentType is TRIE_UNKNOW_NODE for the root, and isValueChild
+// reports whether node is the child of a full node for the key ending there.
+func CheckNodeType(node *TrieNode, parentType byte, isValueChild bool) error {
+	switch node.NodeType() {
+	case TRIE_FULL_NODE:
+		if node.child == nil && len(node.children) == 0 {
+			return ErrNodeType
+		}
+	case TRIE_SHORT_NODE:
+		// the child of a short node is a full node or a leaf
+		if parentType == TRIE_SHORT_NODE {
+			return ErrNodeType
+		}
+	case TRIE_VALUE_NODE:
+		if len(node.value) > types.HashSize {
+			return ErrNodeType
+		}
+	case TRIE_HASH_NODE:
+		if len(node.value) != types.HashSize {
+			return ErrNodeType
+		}
+	default:
+		return ErrNodeType
+	}
+
+	if isValueChild && !node.IsLeafNode() {
+		return ErrNodeType
+	}
+	return nil
+}
+
+// Proof is the list of db-serialized nodes on the path from the root to a key, root first.
+// When the key ends in a hash node, the referenced value is appended as the last item.
+type Proof [][]byte
+
+// Prove returns the node path of key. If the key is not in the trie, the returned path
+// ends at the node where the key diverges, which proves its absence.
+func (trie *Trie) Prove(key []byte) (Proof, error) {
+	var proof Proof
+
+	node := trie.Root
+	for node != nil {
+		var next *TrieNode
+		var missing bool
+
+		switch node.NodeType() {
+		case TRIE_FULL_NODE:
+			for _, child := range node.children {
+				if child == nil {
+					return nil, errors.New(fmt.Sprintf("child of trie node %s is missing", node.Hash()))
+				}
+			}
+
+			if len(key) > 0 {
+				next = node.children[key[0]]
+				key = key[1:]
+			} else {
+				next = node.child
+			}
+		case TRIE_SHORT_NODE:
+			if node.child == nil {
+				missing = true
+			} else if bytes.HasPrefix(key, node.key) {
+				next = node.child
+				key = key[len(node.key):]
+			}
+		case TRIE_HASH_NODE:
+			buf, err := node.DbSerialize()
+			if err != nil {
+				return nil, err
+			}
+			proof = append(proof, buf)
+
+			if len(key) > 0 {
+				return proof, nil
+			}
+
+			value, err := trie.getRefValue(node.value)
+			if err != nil {
+				return nil, errors.New("getRefValue failed, error is " + err.Error())
+			}
+			return append(proof, value), nil
+		case TRIE_VALUE_NODE:
+		default:
+			missing = true
+		}
+
+		if missing {
+			return nil, errors.New(fmt.Sprintf("trie node %s is not loaded", node.Hash()))
+		}
+
+		buf, err := node.DbSerialize()
+		if err != nil {
+			return nil, err
+		}
+		proof = append(proof, buf)
+
+		node = next
+	}
+
+	return proof, nil
+}
+
+// VerifyProof checks the proof against rootHash without a database and returns the value of key.
+// A nil value with a nil error means the proof shows the key is not in the trie.
+// A value of exactly types.HashSize bytes can't be proved and ErrProofAmbiguous is returned, use VerifyShortValueProof
+// if the value of key is known to be never longer than types.HashSize bytes.
+func VerifyProof(rootHash *types.Hash, key []byte, proof Proof) ([]byte, error) {
+	return verifyProof(rootHash, key, proof, false)
+}
+
+// VerifyShortValueProof is VerifyProof for a key whose value is never longer than types.HashSize bytes, like the
+// account state hash in the state trie, so the value is always in a value node and a hash node is rejected.
+func VerifyShortValueProof(rootHash *types.Hash, key []byte, proof Proof) ([]byte, error) {
+	return verifyProof(rootHash, key, proof, true)
+}
+
+func verifyProof(rootHash *types.Hash, key []byte, proof Proof, shortValue bool) ([]byte, error) {
+	if rootHash == nil {
+		if len(proof) > 0 {
+			return nil, ErrProofRedundant
+		}
+		return nil, nil
+	}
+
+	expectedHash := *rootHash
+	parentType, isValueChild := TRIE_UNKNOW_NODE, false
+	for index := 0; index < len(proof); index++ {
+		node := &TrieNode{}
+		if err := node.DbDeserialize(proof[index]); err != nil {
+			return nil, errors.New(fmt.Sprintf("DbDeserialize proof node %d failed, error is %s", index, err.Error()))
+		}
+
+		if *node.Hash() != expectedHash {
+			return nil, errors.New(fmt.Sprintf("hash of proof node %d is %s, expected %s", index, node.Hash(), expectedHash))
+		}
+		// a leaf reached before the key is consumed proves the absence, it must not be another node in disguise
+		if err := CheckNodeType(node, parentType, isValueChild); err != nil {
+			return nil, errors.New(fmt.Sprintf("proof node %d: %s", index, err.Error()))
+		}
+		parentType, isValueChild = node.NodeType(), false
+
+		isLast := index == len(proof)-1
+
+		var next *TrieNode
+		switch node.NodeType() {
+		case TRIE_FULL_NODE:
+			if len(key) > 0 {
+				next = node.children[key[0]]
+				key = key[1:]
+			} else {
+				next = node.child
+				isValueChild = true
+			}
+		case TRIE_SHORT_NODE:
+			if bytes.HasPrefix(key, node.key) {
+				next = node.child
+				key = key[len(node.key):]
+			}
+		case TRIE_VALUE_NODE:
+			if !isLast {
+				return nil, ErrProofRedundant
+			}
+			if len(key) > 0 {
+				return nil, nil
+			}
+			if !shortValue && len(node.value) == types.HashSize {
+				return nil, ErrProofAmbiguous
+			}
+			return node.value, nil
+		case TRIE_HASH_NODE:
+			if len(key) > 0 {
+				if !isLast {
+					return nil, ErrProofRedundant
+				}
+				return nil, nil
+			}
+			if shortValue {
+				return nil, errors.New("value of the key is longer than hash size")
+			}
+
+			if isLast {
+				return nil, ErrProofIncomplete
+			}
+			if index+1 != len(proof)-1 {
+				return nil, ErrProofRedundant
+			}
+
+			value := proof[index+1]
+			if !bytes.Equal(crypto.Hash256(value), node.value) {
+				return nil, errors.New("hash of the referenced value is not matched")
+			}
+			return value, nil
+		default:
+			return nil, errors.New(fmt.Sprintf("unknown type of proof node %d", index))
+		}
+
+		if next == nil {
+			if !isLast {
+				return nil, ErrProofRedundant
+			}
+			return nil, nil
+		}
+		expectedHash = *next.hash
+	}
+
+	return nil, ErrProofIncomplete
+}
+
+// VerifySubProof checks a proof of key in a sub trie whose root hash is the value of subKey under rootHash, like the
+// storage of an account under the state hash of a snapshot block. A nil value with a nil error means the proofs show
+// the key or the sub trie is not existed. The key is verified by VerifyProof.
+func VerifySubProof(rootHash *types.Hash, subKey []byte, subProof Proof, key []byte, proof Proof) ([]byte, error) {
+	subRootBytes, err := VerifyShortValueProof(rootHash, subKey, subProof)
+	if err != nil {
+		return nil, err
+	}
+	if len(subRootBytes) == 0 {
+		if len(proof) > 0 {
+			return nil, ErrProofRedundant
+		}
+		return nil, nil
+	}
+
+	subRoot, err := types.BytesToHash(subRootBytes)
+	if err != nil {
+		return nil, err
+	}
+	return VerifyProof(&subRoot, key, proof)
+}
@@ -0,0 +1,280 @@
+package trie
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/vitelabs/go-vite/common/types"
+)
+
+func TestProve(t *testing.T) {
+	trie, db, close := getTrieOfNewContext()
+	defer close()
+
+	kvs := map[string][]byte{
+		"":        []byte("NilNilNilNilNil"),
+		"IamG":    []byte("ki10$%^%&@#!@#"),
+		"IamGood": []byte("a1230xm90zm19ma"),
+		"tesab":   []byte("value.555value.555value.555value.555value.555value.555value.555"),
+		"tesabcd": []byte("asdfvale....asdfasdfasdfvalue.555val"),
+		"te":      []byte("AVDED09%^$%@#@#"),
+	}
+	for key, value := range kvs {
+		trie.SetValue([]byte(key), value)
+	}
+
+	check := func(trie *Trie) {
+		rootHash := trie.Hash()
+		for key, value := range kvs {
+			proof, err := trie.Prove([]byte(key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			provedValue, err := VerifyProof(rootHash, []byte(key), proof)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(provedValue, value) {
+				t.Fatalf("key %s, proved value is %s, expected %s", key, provedValue, value)
+			}
+		}
+
+		for _, key := range []string{"I", "IamGo", "IamGoodd", "tesabc", "x", "tesabcde"} {
+			proof, err := trie.Prove([]byte(key))
+			if err != nil {
+				t.Fatal(err)
+			}
+			provedValue, err := VerifyProof(rootHash, []byte(key), proof)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if provedValue != nil {
+				t.Fatalf("key %s should be absent, proved value is %s", key, provedValue)
+			}
+		}
+	}
+
+	check(trie)
+
+	batch := new(leveldb.Batch)
+	callback, err := trie.Save(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Write(batch, nil); err != nil {
+		t.Fatal(err)
+	}
+	callback()
+
+	check(NewTrie(db, trie.Hash(), nil))
+}
+
+func TestVerifyProofTampered(t *testing.T) {
+	trie, _, close := getTrieOfNewContext()
+	defer close()
+
+	trie.SetValue([]byte("IamG"), []byte("ki10$%^%&@#!@#"))
+	trie.SetValue([]byte("IamGood"), []byte("a1230xm90zm19ma"))
+	trie.SetValue([]byte("tesab"), []byte("value.555value.555value.555value.555value.555value.555value.555"))
+
+	proof, err := trie.Prove([]byte("IamGood"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wrongRoot := types.Hash{}
+	if _, err := VerifyProof(&wrongRoot, []byte("IamGood"), proof); err == nil {
+		t.Fatal("proof should fail against a wrong root")
+	}
+
+	forged := NewValueNode([]byte("forged"))
+	forgedBuf, _ := forged.DbSerialize()
+	tampered := append(Proof{}, proof[:len(proof)-1]...)
+	tampered = append(tampered, forgedBuf)
+	if _, err := VerifyProof(trie.Hash(), []byte("IamGood"), tampered); err == nil {
+		t.Fatal("proof with a forged leaf should fail")
+	}
+
+	if _, err := VerifyProof(trie.Hash(), []byte("IamGood"), proof[:len(proof)-1]); err != ErrProofIncomplete {
+		t.Fatalf("truncated proof should be incomplete, error is %v", err)
+	}
+
+	longProof, err := trie.Prove([]byte("tesab"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	longProof[len(longProof)-1] = []byte("forged")
+	if _, err := VerifyProof(trie.Hash(), []byte("tesab"), longProof); err == nil {
+		t.Fatal("proof with a forged referenced value should fail")
+	}
+}
+
+func TestVerifyProofForgedValueNode(t *testing.T) {
+	trie, _, close := getTrieOfNewContext()
+	defer close()
+
+	longValue := []byte("value.555value.555value.555value.555value.555value.555value.555")
+	hashValue := types.Hash{1, 2, 3}.Bytes()
+	trie.SetValue([]byte("IamGood"), []byte("a1230xm90zm19ma"))
+	trie.SetValue([]byte("tesab"), longValue)
+	trie.SetValue([]byte("tesabcd"), hashValue)
+
+	proof, err := trie.Prove([]byte("tesab"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := VerifyProof(trie.Hash(), []byte("tesab"), proof); err != nil || !bytes.Equal(value, longValue) {
+		t.Fatalf("proved value is %x, error is %v", value, err)
+	}
+
+	// the hash node and a value node holding the same hash have the same node hash
+	hashNode := &TrieNode{}
+	if err := hashNode.DbDeserialize(proof[len(proof)-2]); err != nil {
+		t.Fatal(err)
+	}
+	if hashNode.NodeType() != TRIE_HASH_NODE {
+		t.Fatalf("node type is %d, expected a hash node", hashNode.NodeType())
+	}
+	forgedNode, err := NewValueNode(hashNode.Value()).DbSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := append(Proof{}, proof[:len(proof)-2]...)
+	forged = append(forged, forgedNode)
+	if value, err := VerifyProof(trie.Hash(), []byte("tesab"), forged); err != ErrProofAmbiguous {
+		t.Fatalf("forged proof is verified, value is %x, error is %v", value, err)
+	}
+	if _, err := VerifyShortValueProof(trie.Hash(), []byte("tesab"), proof); err == nil {
+		t.Fatal("value longer than hash size is verified as a short value")
+	}
+
+	hashProof, err := trie.Prove([]byte("tesabcd"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifyProof(trie.Hash(), []byte("tesabcd"), hashProof); err != ErrProofAmbiguous {
+		t.Fatalf("value of hash size should be ambiguous, error is %v", err)
+	}
+	if value, err := VerifyShortValueProof(trie.Hash(), []byte("tesabcd"), hashProof); err != nil || !bytes.Equal(value, hashValue) {
+		t.Fatalf("proved short value is %x, error is %v", value, err)
+	}
+}
+
+func TestVerifyProofForgedNodeType(t *testing.T) {
+	trie, _, close := getTrieOfNewContext()
+	defer close()
+
+	trie.SetValue([]byte("IamG"), []byte("ki10$%^%&@#!@#"))
+	trie.SetValue([]byte("IamGood"), []byte("a1230xm90zm19ma"))
+	trie.SetValue([]byte("tesab"), []byte{TRIE_FULL_NODE})
+	trie.SetValue([]byte("tesabcd"), []byte("asdfvale....asdfasdfasdfvalue.555val"))
+
+	// a value node holding the source of the root has the hash of the root, it would prove any key absent
+	forgedRoot, err := NewValueNode(trie.Root.hashSource()).DbSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := VerifyProof(trie.Hash(), []byte("IamGood"), Proof{forgedRoot}); err == nil {
+		t.Fatalf("forged root is verified, value is %x", value)
+	}
+
+	// the same for an interior node
+	proof, err := trie.Prove([]byte("IamGood"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proof) < 3 {
+		t.Fatalf("proof of IamGood has %d nodes", len(proof))
+	}
+	interior := &TrieNode{}
+	if err := interior.DbDeserialize(proof[1]); err != nil {
+		t.Fatal(err)
+	}
+	forgedInterior, err := NewValueNode(interior.hashSource()).DbSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := VerifyProof(trie.Hash(), []byte("IamGood"), Proof{proof[0], forgedInterior}); err == nil {
+		t.Fatalf("forged interior node is verified, value is %x", value)
+	}
+
+	// a full node without children has the hash of the value node of its type byte
+	proof, err = trie.Prove([]byte("tesab"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := VerifyProof(trie.Hash(), []byte("tesab"), proof); err != nil || !bytes.Equal(value, []byte{TRIE_FULL_NODE}) {
+		t.Fatalf("proved value is %x, error is %v", value, err)
+	}
+	emptyFullNode, err := NewFullNode(nil).DbSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := append(append(Proof{}, proof[:len(proof)-1]...), emptyFullNode)
+	if value, err := VerifyProof(trie.Hash(), []byte("tesab"), forged); err == nil {
+		t.Fatalf("forged empty full node is verified, value is %x", value)
+	}
+}
+
+func TestVerifySubProof(t *testing.T) {
+	accountTrie, _, close := getTrieOfNewContext()
+	defer close()
+
+	balanceKey := []byte("$balance\x01\x02")
+	storageKey := []byte("storage key of a contract")
+	accountTrie.SetValue(balanceKey, []byte{0x03, 0xe8})
+	accountTrie.SetValue(storageKey, []byte("value.555value.555value.555value.555value.555value.555value.555"))
+
+	addr := []byte("an address of 21 byte")
+	absent := []byte("another address 21 b")
+	stateTrie := NewTrie(nil, nil, nil)
+	stateTrie.SetValue(addr, accountTrie.Hash().Bytes())
+	stateTrie.SetValue([]byte("another account"), types.Hash{1}.Bytes())
+
+	stateProof, err := stateTrie.Prove(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range [][]byte{balanceKey, storageKey} {
+		proof, err := accountTrie.Prove(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		value, err := VerifySubProof(stateTrie.Hash(), addr, stateProof, key, proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(value, accountTrie.GetValue(key)) {
+			t.Fatalf("key %s, proved value is %x, expected %x", key, value, accountTrie.GetValue(key))
+		}
+
+		// the proof of a key can't be verified under another account
+		otherProof, err := stateTrie.Prove([]byte("another account"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := VerifySubProof(stateTrie.Hash(), []byte("another account"), otherProof, key, proof); err == nil {
+			t.Fatalf("key %s is proved under another account", key)
+		}
+	}
+
+	missingProof, err := accountTrie.Prove([]byte("missing key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := VerifySubProof(stateTrie.Hash(), addr, stateProof, []byte("missing key"), missingProof); err != nil || value != nil {
+		t.Fatalf("missing key is proved, value is %x, error is %v", value, err)
+	}
+
+	absentProof, err := stateTrie.Prove(absent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if value, err := VerifySubProof(stateTrie.Hash(), absent, absentProof, balanceKey, nil); err != nil || value != nil {
+		t.Fatalf("key of an absent account is proved, value is %x, error is %v", value, err)
+	}
+	if _, err := VerifySubProof(stateTrie.Hash(), absent, absentProof, balanceKey, missingProof); err != ErrProofRedundant {
+		t.Fatalf("proof under an absent account should be redundant, error is %v", err)
+	}
+}
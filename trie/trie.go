@@ -29,6 +29,24 @@ func DeleteNodes(db *leveldb.DB, hashList []types.Hash) error {
 	return db.Write(batch, nil)
 }
 
+// SaveNodes writes nodes and ref values into batch as they are, the caller makes sure that the tries are complete.
+func SaveNodes(batch *leveldb.Batch, nodes []*TrieNode, refValueMap map[types.Hash][]byte) error {
+	for _, node := range nodes {
+		dbKey, _ := database.EncodeKey(database.DBKP_TRIE_NODE, node.Hash().Bytes())
+		data, err := node.DbSerialize()
+		if err != nil {
+			return errors.New("DbSerialize trie node failed, error is " + err.Error())
+		}
+		batch.Put(dbKey, data)
+	}
+
+	for hash, value := range refValueMap {
+		dbKey, _ := database.EncodeKey(database.DBKP_TRIE_REF_VALUE, hash.Bytes())
+		batch.Put(dbKey, value)
+	}
+	return nil
+}
+
 func ShallowCheck(db *leveldb.DB, rootHash *types.Hash) (bool, error) {
 	dbKey, _ := database.EncodeKey(database.DBKP_TRIE_NODE, rootHash.Bytes())
 	return db.Has(dbKey, nil)
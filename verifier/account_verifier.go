@@ -18,6 +18,7 @@ import (
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/monitor"
 	"github.com/vitelabs/go-vite/pow"
+	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
 	"github.com/vitelabs/go-vite/vm_context"
 )
 
@@ -191,11 +192,7 @@ func (verifier *AccountVerifier) VerifySigature(block *ledger.AccountBlock) erro
 	if len(block.Signature) == 0 || len(block.PublicKey) == 0 {
 		return errors.New("signature or publicKey can't be nil")
 	}
-	isVerified, verifyErr := crypto.VerifySig(block.PublicKey, block.Hash.Bytes(), block.Signature)
-	if !isVerified {
-		if verifyErr != nil {
-			verifier.log.Error("VerifySig failed", "error", verifyErr)
-		}
+	if !block.VerifySignature() {
 		return ErrVerifySignatureFailed
 	}
 	return nil
@@ -252,14 +249,40 @@ func (verifier *AccountVerifier) VerifyProducerLegality(block *ledger.AccountBlo
 		}
 	}
 	if accType == ledger.AccountTypeGeneral {
-		if types.PubkeyToAddress(block.PublicKey) != block.AccountAddress {
-			return errors.New("publicKey doesn't match with the accountAddress")
+		if block.IsMultiSigned() || types.PubkeyToAddress(block.PublicKey) != block.AccountAddress {
+			return verifier.verifyMultiSigProducer(block)
 		}
 	}
 
 	return nil
 }
 
+// verifyMultiSigProducer checks the signers of a block of a multisig address against the key set registered at the
+// snapshot block referred, no key set can be registered before the multisig fork.
+func (verifier *AccountVerifier) verifyMultiSigProducer(block *ledger.AccountBlock) error {
+	vmContext, err := vm_context.NewVmContext(verifier.chain, &block.SnapshotHash, nil, nil)
+	if err != nil {
+		return err
+	}
+	info := cabi.GetMultiSigInfo(vmContext, block.AccountAddress)
+	if info == nil {
+		return errors.New("publicKey doesn't match with the accountAddress")
+	}
+	pubkeys, err := block.SignerPublicKeys()
+	if err != nil {
+		return err
+	}
+	if len(pubkeys) < int(info.Threshold) {
+		return errors.New("signer count is less than the threshold of the multisig address")
+	}
+	for _, pubkey := range pubkeys {
+		if !info.IsMultiSigKey(pubkey) {
+			return errors.New("signer is not in the key set of the multisig address")
+		}
+	}
+	return nil
+}
+
 func (verifier *AccountVerifier) VerifyDataValidity(block *ledger.AccountBlock, sbHeight uint64, accType uint64) error {
 	defer monitor.LogTime("AccountVerifier", "VerifyDataValidity", time.Now())
 
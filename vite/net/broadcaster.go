@@ -100,6 +100,7 @@ type broadcaster struct {
 	verifier Verifier
 	feed     blockNotifier
 	filter   blockFilter
+	receipts *blockReceipts
 
 	store blockStore
 
@@ -118,6 +119,7 @@ func newBroadcaster(peers *peerSet, verifier Verifier, feed blockNotifier, store
 		feed:     feed,
 		store:    store,
 		filter:   newBlockFilter(filterCap),
+		receipts: newBlockReceipts(),
 	}
 }
 
@@ -143,6 +145,7 @@ func (b *broadcaster) Handle(msg *p2p.Msg, sender Peer) (err error) {
 
 		// check if block has exist first
 		if exist := b.filter.has(block.Hash[:]); exist {
+			b.duplicate(sender, block.Hash)
 			return nil
 		}
 
@@ -151,13 +154,17 @@ func (b *broadcaster) Handle(msg *p2p.Msg, sender Peer) (err error) {
 
 		// check if has exist or record, return true if has exist
 		if exist := b.filter.lookAndRecord(hash[:]); exist {
+			b.duplicate(sender, hash)
 			return nil
 		}
+		b.receipts.record(hash, time.Now())
 
 		if err = b.verifier.VerifyNetSb(block); err != nil {
 			b.log.Error(fmt.Sprintf("verify new snapshotblock %s/%d from %s error: %v", hash, block.Height, sender.RemoteAddr(), err))
+			b.peers.score(sender.ID(), scoreInvalidBlock)
 			return err
 		}
+		b.peers.score(sender.ID(), scoreUsefulBlock)
 
 		b.BroadcastSnapshotBlock(block)
 
@@ -178,6 +185,7 @@ func (b *broadcaster) Handle(msg *p2p.Msg, sender Peer) (err error) {
 
 		// check if block has exist first
 		if exist := b.filter.has(block.Hash[:]); exist {
+			b.duplicate(sender, block.Hash)
 			return nil
 		}
 
@@ -186,13 +194,17 @@ func (b *broadcaster) Handle(msg *p2p.Msg, sender Peer) (err error) {
 
 		// check if has exist or record, return true if has exist
 		if exist := b.filter.lookAndRecord(hash[:]); exist {
+			b.duplicate(sender, hash)
 			return nil
 		}
+		b.receipts.record(hash, time.Now())
 
 		if err = b.verifier.VerifyNetAb(block); err != nil {
 			b.log.Error(fmt.Sprintf("verify new accountblock %s from %s error: %v", hash, sender.RemoteAddr(), err))
+			b.peers.score(sender.ID(), scoreInvalidBlock)
 			return err
 		}
+		b.peers.score(sender.ID(), scoreUsefulBlock)
 
 		b.BroadcastAccountBlock(block)
 
@@ -206,6 +218,13 @@ func (b *broadcaster) Handle(msg *p2p.Msg, sender Peer) (err error) {
 	return nil
 }
 
+// duplicate scores a block which has been received already, see scoreDuplicateGrace
+func (b *broadcaster) duplicate(sender Peer, hash types.Hash) {
+	if !b.receipts.inGrace(hash, time.Now()) {
+		b.peers.score(sender.ID(), scoreDuplicateBlock)
+	}
+}
+
 const records_1 = 3600
 const records_12 = 12 * records_1
 const records_24 = 24 * records_1
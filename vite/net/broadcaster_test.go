@@ -2,9 +2,15 @@ package net
 
 import (
 	"fmt"
+	mrand "math/rand"
+	net2 "net"
 	"testing"
+	"time"
 
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/config"
 	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/p2p"
 
 	"github.com/vitelabs/go-vite/vite/net/circle"
 )
@@ -175,3 +181,81 @@ func TestMemStore_EnqueueSnapshotBlock(t *testing.T) {
 		}
 	}
 }
+
+// floodPeer is a peer without connection, Handle only logs its address
+type floodPeer struct {
+	*peer
+}
+
+func (p floodPeer) RemoteAddr() *net2.TCPAddr {
+	return &net2.TCPAddr{}
+}
+
+func newFloodBroadcaster(count int) (*broadcaster, []*peer) {
+	peers := newPeerSet()
+	ps := make([]*peer, count)
+	for i := range ps {
+		ps[i] = mockPeer()
+		ps[i].knownBlocks = newBlockFilter(filterCap)
+		ps[i].errChan = make(chan error, 1)
+		peers.Add(ps[i])
+	}
+	return newBroadcaster(peers, mock_verifier{}, nil, newMemBlockStore(10)), ps
+}
+
+func floodMsg(t *testing.T, height uint64) (*p2p.Msg, *ledger.SnapshotBlock) {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}})
+
+	now := time.Now()
+	block := &ledger.SnapshotBlock{Height: height, Timestamp: &now}
+	block.Hash = block.ComputeHash()
+	payload, err := block.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &p2p.Msg{Cmd: p2p.Cmd(NewSnapshotBlockCode), Payload: payload}, block
+}
+
+func TestBroadcaster_HonestFlooding(t *testing.T) {
+	const peerCount, blockCount = 16, 1000
+	b, ps := newFloodBroadcaster(peerCount)
+
+	// every peer sends every block, in a random order, so most blocks of a peer are duplicates
+	for height := uint64(1); height <= blockCount; height++ {
+		msg, block := floodMsg(t, height)
+		for _, p := range ps {
+			p.SeeBlock(block.Hash)
+		}
+		for _, i := range mrand.Perm(peerCount) {
+			if err := b.Handle(msg, floodPeer{ps[i]}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	for _, p := range ps {
+		select {
+		case err := <-p.errChan:
+			t.Fatalf("honest peer should not be disconnected: %v", err)
+		default:
+		}
+		if v := b.peers.scores.value(p.id); v < scoreBadThreshold {
+			t.Fatalf("score of honest peer should not be bad, but %f", v)
+		}
+	}
+}
+
+func TestBroadcaster_LateDuplicate(t *testing.T) {
+	b, ps := newFloodBroadcaster(1)
+	msg, block := floodMsg(t, 1)
+	ps[0].SeeBlock(block.Hash)
+
+	b.receipts.record(block.Hash, time.Now().Add(-2*scoreDuplicateGrace))
+	b.filter.record(block.Hash[:])
+	if err := b.Handle(msg, floodPeer{ps[0]}); err != nil {
+		t.Fatal(err)
+	}
+	if v := b.peers.scores.value(ps[0].id); v >= 0 {
+		t.Fatalf("duplicate after the grace window should be penalised, but score is %f", v)
+	}
+}
@@ -66,6 +66,7 @@ type chunkRequest struct {
 	ch       chan error
 	once     sync.Once
 	ctx      context.Context
+	peer     peerId // the peer request sent to
 	// for status
 	_done  bool
 	target string
@@ -273,6 +274,7 @@ func (p *chunkPool) handleLoop() {
 
 			if err := p.handleResponse(leg); err != nil {
 				p.log.Error(fmt.Sprintf("handle SubLedgerMsg from %s error: %v", leg.sender.RemoteAddr(), err))
+				p.peers.score(leg.sender.ID(), scoreInvalidBlock)
 				leg.sender.Report(err)
 				v, ok := p.chunks.Load(leg.Id)
 				if ok {
@@ -356,6 +358,9 @@ func (p *chunkPool) checkLoop() {
 
 		default:
 			if time.Now().After(c.deadline) {
+				if c.peer != "" {
+					p.peers.score(c.peer, scoreTimeout)
+				}
 				p.request(c)
 				p.log.Info(fmt.Sprintf("chunkRequest<%d-%d> is timeout", c.from, c.to))
 			}
@@ -399,6 +404,7 @@ func (p *chunkPool) request(c *chunkRequest) {
 	if err != nil {
 		p.log.Error(fmt.Sprintf("send %s to %s error: %v", c.msg.String(), p1.RemoteAddr(), err))
 	} else {
+		c.peer = p1.ID()
 		c.target = p1.RemoteAddr().String()
 		p.log.Info(fmt.Sprintf("send %s to %s", c.msg.String(), p1.RemoteAddr()))
 	}
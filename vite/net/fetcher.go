@@ -45,7 +45,7 @@ type fp struct {
 func (p *fp) accountTargets(height uint64) []Peer {
 	var l, taller []Peer
 
-	peers := p.peers.Peers()
+	peers := p.peers.good(p.peers.Peers())
 	total := len(peers)
 
 	if total == 0 {
@@ -110,12 +110,15 @@ func (p *fp) snapshotTarget(height uint64) Peer {
 // fetch filter
 const maxMark = 3       // times
 const timeThreshold = 3 // second
+const fetchTimeout = 10 // second, the targets of a record undone after fetchTimeout will be scored as timeout
 
 type record struct {
-	addAt  int64
-	doneAt int64
-	mark   int
-	_done  bool
+	addAt   int64
+	doneAt  int64
+	mark    int
+	_done   bool
+	targets []peerId // peers the fetch request sent to
+	scored  bool     // targets have been scored as timeout
 }
 
 func (r *record) inc() {
@@ -126,6 +129,8 @@ func (r *record) reset() {
 	r.mark = 0
 	r._done = false
 	r.addAt = time.Now().Unix()
+	r.targets = nil
+	r.scored = false
 }
 
 func (r *record) done() {
@@ -192,6 +197,31 @@ func (f *filter) done(hash types.Hash) {
 	}
 }
 
+// target records the peer which the fetch request of hash sent to
+func (f *filter) target(hash types.Hash, id peerId) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if r, ok := f.records[hash]; ok {
+		r.targets = append(r.targets, id)
+	}
+}
+
+// timeout returns the targets of records which are undone after fetchTimeout, every record is returned only once
+func (f *filter) timeout(t int64) (ids []peerId) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, r := range f.records {
+		if !r._done && !r.scored && (t-r.addAt) > fetchTimeout {
+			r.scored = true
+			ids = append(ids, r.targets...)
+		}
+	}
+
+	return
+}
+
 func (f *filter) fail(hash types.Hash) {
 	f.lock.RLock()
 	defer f.lock.RUnlock()
@@ -207,6 +237,7 @@ func (f *filter) fail(hash types.Hash) {
 
 type fetcher struct {
 	filter *filter
+	peers  *peerSet
 
 	st       SyncState
 	verifier Verifier
@@ -223,6 +254,7 @@ type fetcher struct {
 func newFetcher(peers *peerSet, pool MsgIder, verifier Verifier, notifier blockNotifier) *fetcher {
 	return &fetcher{
 		filter:   newFilter(),
+		peers:    peers,
 		policy:   &fp{peers},
 		pool:     pool,
 		notifier: notifier,
@@ -252,12 +284,19 @@ func (f *fetcher) cleanLoop() {
 	ticker := time.NewTicker(5 * time.Minute)
 	defer ticker.Stop()
 
+	timeoutTicker := time.NewTicker(fetchTimeout * time.Second)
+	defer timeoutTicker.Stop()
+
 	for {
 		select {
 		case <-f.term:
 			return
 		case now := <-ticker.C:
 			f.filter.clean(now.Unix())
+		case now := <-timeoutTicker.C:
+			for _, id := range f.filter.timeout(now.Unix()) {
+				f.peers.score(id, scoreTimeout)
+			}
 		}
 	}
 }
@@ -288,6 +327,7 @@ func (f *fetcher) Handle(msg *p2p.Msg, sender Peer) (err error) {
 
 		for _, block := range bs.Blocks {
 			if err = f.verifier.VerifyNetSb(block); err != nil {
+				f.peers.score(sender.ID(), scoreInvalidBlock)
 				return err
 			}
 
@@ -296,6 +336,7 @@ func (f *fetcher) Handle(msg *p2p.Msg, sender Peer) (err error) {
 
 		if len(bs.Blocks) > 0 {
 			f.filter.done(bs.Blocks[len(bs.Blocks)-1].Hash)
+			f.peers.score(sender.ID(), scoreUsefulBlock)
 		}
 
 	case AccountBlocksCode:
@@ -306,6 +347,7 @@ func (f *fetcher) Handle(msg *p2p.Msg, sender Peer) (err error) {
 
 		for _, block := range bs.Blocks {
 			if err = f.verifier.VerifyNetAb(block); err != nil {
+				f.peers.score(sender.ID(), scoreInvalidBlock)
 				return err
 			}
 
@@ -314,6 +356,7 @@ func (f *fetcher) Handle(msg *p2p.Msg, sender Peer) (err error) {
 
 		if len(bs.Blocks) > 0 {
 			f.filter.done(bs.Blocks[len(bs.Blocks)-1].Hash)
+			f.peers.score(sender.ID(), scoreUsefulBlock)
 		}
 	}
 
@@ -346,6 +389,7 @@ func (f *fetcher) FetchSnapshotBlocks(start types.Hash, count uint64) {
 		if err := p.Send(GetSnapshotBlocksCode, id, m); err != nil {
 			f.log.Error(fmt.Sprintf("send GetSnapshotBlocks[hash %s, count %d] to %s error: %v", start, count, p.RemoteAddr(), err))
 		} else {
+			f.filter.target(start, p.ID())
 			f.log.Info(fmt.Sprintf("send GetSnapshotBlocks[hash %s, count %d] to %s", start, count, p.RemoteAddr()))
 		}
 		monitor.LogEvent("net/fetch", "GetSnapshotBlocks_Send")
@@ -388,6 +432,7 @@ func (f *fetcher) FetchAccountBlocks(start types.Hash, count uint64, address *ty
 			if err := p.Send(GetAccountBlocksCode, id, m); err != nil {
 				f.log.Error(fmt.Sprintf("send GetAccountBlocks[hash %s, count %d] to %s error: %v", start, count, p.RemoteAddr(), err))
 			} else {
+				f.filter.target(start, p.ID())
 				f.log.Info(fmt.Sprintf("send GetAccountBlocks[hash %s, count %d] to %s", start, count, p.RemoteAddr()))
 			}
 			monitor.LogEvent("net/fetch", "GetAccountBlocks_Send")
@@ -431,6 +476,7 @@ func (f *fetcher) FetchAccountBlocksWithHeight(start types.Hash, count uint64, a
 			if err := p.Send(GetAccountBlocksCode, id, m); err != nil {
 				f.log.Error(fmt.Sprintf("send GetAccountBlocks[hash %s, count %d] to %s error: %v", start, count, p.RemoteAddr(), err))
 			} else {
+				f.filter.target(start, p.ID())
 				f.log.Info(fmt.Sprintf("send GetAccountBlocks[hash %s, count %d] to %s", start, count, p.RemoteAddr()))
 			}
 			monitor.LogEvent("net/fetch", "GetAccountBlocks_Send")
@@ -12,8 +12,9 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/golang/snappy"
 	"github.com/vitelabs/go-vite/common"
-
+	"github.com/vitelabs/go-vite/crypto/ed25519"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/p2p"
@@ -70,21 +71,23 @@ type FileConnStatus struct {
 
 type fileConn struct {
 	net2.Conn
-	id     peerId
-	busy   int32   // atomic
-	t      int64   // timestamp
-	speed  float64 // download speed, byte/s
-	parser fileParser
-	closed int32
-	log    log15.Logger
+	id       peerId
+	busy     int32   // atomic
+	t        int64   // timestamp
+	speed    float64 // download speed, byte/s
+	parser   fileParser
+	compress bool // request files as snappy stream
+	closed   int32
+	log      log15.Logger
 }
 
-func newFileConn(conn net2.Conn, id peerId, parser fileParser, log log15.Logger) *fileConn {
+func newFileConn(conn net2.Conn, id peerId, compress bool, parser fileParser, log log15.Logger) *fileConn {
 	return &fileConn{
-		Conn:   conn,
-		id:     id,
-		parser: parser,
-		log:    log,
+		Conn:     conn,
+		id:       id,
+		compress: compress,
+		parser:   parser,
+		log:      log,
 	}
 }
 
@@ -106,34 +109,15 @@ func (f *fileConn) download(file File, rec blockReceiver) (outerr *downloadError
 
 	f.log.Info(fmt.Sprintf("begin download <file %s> from %s", file.Filename, f.RemoteAddr()))
 
-	getFiles := &message.GetFiles{
-		Names: []string{file.Filename},
-	}
-
-	msg, err := p2p.PackMsg(CmdSet, p2p.Cmd(GetFilesCode), 0, getFiles)
-	if err != nil {
-		f.log.Error(fmt.Sprintf("pack GetFilesMsg<file %s> to %s error: %v", file.Filename, f.RemoteAddr(), err))
-		return &downloadError{
-			code: downloadPackMsgErr,
-			err:  err.Error(),
-		}
-	}
-
-	f.Conn.SetWriteDeadline(time.Now().Add(fWriteTimeout))
-	if err = p2p.WriteMsg(f.Conn, msg); err != nil {
-		f.log.Error(fmt.Sprintf("write GetFilesMsg<file %s> to %s error: %v", file.Filename, f.RemoteAddr(), err))
-		return &downloadError{
-			code: downloadSendErr,
-			err:  err.Error(),
-		}
+	start := time.Now()
+	// todo fileTimeout can be a flexible value, like calc through fileSize and download speed
+	reader, outerr := f.request(file.Filename, fileTimeout)
+	if outerr != nil {
+		return
 	}
 
 	var sCount, aCount uint64
-
-	start := time.Now()
-	// todo fileTimeout can be a flexible value, like calc through fileSize and download speed
-	f.Conn.SetReadDeadline(time.Now().Add(fileTimeout))
-	f.parser.BlockParser(f.Conn, file.BlockNumbers, func(block ledger.Block, err error) {
+	f.parser.BlockParser(reader, file.BlockNumbers, func(block ledger.Block, err error) {
 		// Fatal error, then close the connection to interrupt the stream
 		if outerr != nil && outerr.Fatal() {
 			f.log.Error(fmt.Sprintf("download <file %s> from %s error: %v, close connection", file.Filename, f.RemoteAddr(), outerr))
@@ -184,6 +168,38 @@ func (f *fileConn) download(file File, rec blockReceiver) (outerr *downloadError
 	return
 }
 
+// request sends GetFilesMsg of the file name, the returned reader reads the file in timeout.
+func (f *fileConn) request(name string, timeout time.Duration) (io.Reader, *downloadError) {
+	getFiles := &message.GetFiles{
+		Names:    []string{name},
+		Compress: f.compress,
+	}
+
+	msg, err := p2p.PackMsg(CmdSet, p2p.Cmd(GetFilesCode), 0, getFiles)
+	if err != nil {
+		f.log.Error(fmt.Sprintf("pack GetFilesMsg<file %s> to %s error: %v", name, f.RemoteAddr(), err))
+		return nil, &downloadError{
+			code: downloadPackMsgErr,
+			err:  err.Error(),
+		}
+	}
+
+	f.Conn.SetWriteDeadline(time.Now().Add(fWriteTimeout))
+	if err = p2p.WriteMsg(f.Conn, msg); err != nil {
+		f.log.Error(fmt.Sprintf("write GetFilesMsg<file %s> to %s error: %v", name, f.RemoteAddr(), err))
+		return nil, &downloadError{
+			code: downloadSendErr,
+			err:  err.Error(),
+		}
+	}
+
+	f.Conn.SetReadDeadline(time.Now().Add(timeout))
+	if f.compress {
+		return snappy.NewReader(f.Conn), nil
+	}
+	return f.Conn, nil
+}
+
 func (f *fileConn) setBusy() {
 	atomic.StoreInt32(&f.busy, 1)
 	atomic.StoreInt64(&f.t, time.Now().Unix())
@@ -231,9 +247,11 @@ func (fl fileConns) del(i int) fileConns {
 }
 
 type filePeer struct {
-	id   peerId
-	addr string
-	fail int32
+	id       peerId
+	addr     string
+	fail     int32
+	compress bool
+	secure   bool // the file server of peers of version 1 can't set up the secure channel
 }
 
 type FilePoolStatus struct {
@@ -273,7 +291,7 @@ func (fp *filePeerPool) status() FilePoolStatus {
 	}
 }
 
-func (fp *filePeerPool) addPeer(files []filename, addr string, id peerId) {
+func (fp *filePeerPool) addPeer(files []filename, addr string, id peerId, compress, secure bool) {
 	fp.mu.Lock()
 	defer fp.mu.Unlock()
 
@@ -285,9 +303,11 @@ func (fp *filePeerPool) addPeer(files []filename, addr string, id peerId) {
 	}
 
 	if _, ok := fp.mp[id]; !ok {
-		fp.mp[id] = &filePeer{id, addr, 0}
+		fp.mp[id] = &filePeer{id, addr, 0, compress, secure}
 	} else {
 		fp.mp[id].addr = addr
+		fp.mp[id].compress = compress
+		fp.mp[id].secure = secure
 	}
 }
 
@@ -466,6 +486,7 @@ type fileClient struct {
 	dialing map[string]struct{}
 
 	dialer *net2.Dialer
+	key    ed25519.PrivateKey // set up the secure channel with the file servers
 
 	term    chan struct{}
 	wg      sync.WaitGroup
@@ -487,7 +508,7 @@ func newFileClient(chain Chain, rec blockReceiver, peers *peerSet) *fileClient {
 }
 
 func (fc *fileClient) addFilePeer(files []filename, sender Peer) {
-	fc.pool.addPeer(files, sender.FileAddress().String(), sender.ID())
+	fc.pool.addPeer(files, sender.FileAddress().String(), sender.ID(), sender.Compress(), sender.Secure())
 }
 
 func (fc *fileClient) download(ctx context.Context, file File) <-chan error {
@@ -560,8 +581,10 @@ func (fc *fileClient) doJob(c *fileConn, file File) error {
 	start := time.Now()
 	if derr := c.download(file, fc.rec); derr != nil {
 		if derr.Fatal() {
+			fc.peers.score(c.id, scoreInvalidBlock)
 			fc.fatalPeer(c.id, derr)
 		} else {
+			fc.peers.score(c.id, scoreFileFail)
 			fc.pool.catch(c.id)
 		}
 
@@ -570,6 +593,12 @@ func (fc *fileClient) doJob(c *fileConn, file File) error {
 		return derr
 	}
 
+	if c.speed < fileSlowSpeed {
+		fc.peers.score(c.id, scoreSlowFile)
+	} else {
+		fc.peers.score(c.id, scoreFileDone)
+	}
+
 	fc.log.Info(fmt.Sprintf("download <file %s> from %s elapse %s", file.Filename, c.RemoteAddr(), time.Now().Sub(start)))
 
 	return nil
@@ -653,7 +682,7 @@ func (fc *fileClient) createConn(p *filePeer) (c *fileConn, err error) {
 	fc.dialing[addr] = struct{}{}
 	fc.mu.Unlock()
 
-	tcp, err := fc.dialer.Dial("tcp", addr)
+	conn, err := fc.dial(p)
 	fc.dialed(addr)
 
 	if err != nil {
@@ -661,7 +690,7 @@ func (fc *fileClient) createConn(p *filePeer) (c *fileConn, err error) {
 		return nil, err
 	}
 
-	c = newFileConn(tcp, p.id, fc.chain.Compressor(), fc.log)
+	c = newFileConn(conn, p.id, p.compress, fc.chain.Compressor(), fc.log)
 
 	err = fc.pool.addConn(c)
 	if err != nil {
@@ -672,6 +701,30 @@ func (fc *fileClient) createConn(p *filePeer) (c *fileConn, err error) {
 	return
 }
 
+// dial connects to the file server of p, the connection isn't added into pool. The secure channel is set up
+// only if the p2p connection of p runs on it.
+func (fc *fileClient) dial(p *filePeer) (net2.Conn, error) {
+	tcp, err := fc.dialer.Dial("tcp", p.addr)
+	if err != nil {
+		return nil, err
+	}
+	if !p.secure {
+		return tcp, nil
+	}
+
+	sc, err := p2p.SecureHandshake(tcp, fc.key, true)
+	if err != nil {
+		tcp.Close()
+		return nil, err
+	}
+	if sc.RemoteID().String() != p.id {
+		tcp.Close()
+		return nil, fmt.Errorf("unmatched file server ID, want %s got %s", p.id, sc.RemoteID())
+	}
+
+	return sc, nil
+}
+
 func (fc *fileClient) status() FileClientStatus {
 	fc.mu.Lock()
 	queue := make([]File, len(fc.fqueue))
@@ -1,6 +1,8 @@
 package net
 
 import (
+	"bufio"
+	"encoding/binary"
 	"fmt"
 	"io"
 	net2 "net"
@@ -8,8 +10,10 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/golang/snappy"
 	"github.com/pkg/errors"
 	"github.com/vitelabs/go-vite/common"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/p2p"
 	"github.com/vitelabs/go-vite/vite/net/message"
@@ -36,9 +40,12 @@ type fileServer struct {
 	mu      sync.Mutex
 	conns   map[string]net2.Conn // key is addr
 	chain   Chain
+	key     ed25519.PrivateKey // set up the secure channel with the downloaders
 	running int32
 	wg      sync.WaitGroup
 	log     log15.Logger
+
+	stateSnapshotDir string // state snapshots are served from it, named by state_snapshot.Filename
 }
 
 func newFileServer(addr string, chain Chain) *fileServer {
@@ -66,8 +73,10 @@ func (s *fileServer) status() FileServerStatus {
 	}
 }
 
-func (s *fileServer) start() error {
+func (s *fileServer) start(key ed25519.PrivateKey) error {
 	if atomic.CompareAndSwapInt32(&s.running, 0, 1) {
+		s.key = key
+
 		if ln, err := net2.Listen("tcp", s.addr); err != nil {
 			return err
 		} else {
@@ -147,9 +156,28 @@ func (s *fileServer) handleConn(conn net2.Conn) {
 	s.addConn(conn)
 	defer s.deleteConn(conn)
 
+	// a downloader of version 1 sends requests in plain, they start with the message header of CmdSet,
+	// the secure hello starts with the node ID of the downloader
+	br := bufio.NewReader(conn)
+	conn.SetReadDeadline(time.Now().Add(fReadTimeout))
+	first, err := br.Peek(4)
+	conn.SetReadDeadline(time.Time{})
+	if err != nil {
+		s.log.Warn(fmt.Sprintf("read message from %s error: %v", conn.RemoteAddr(), err))
+		return
+	}
+
+	var sc net2.Conn = peekedConn{conn, br}
+	if binary.BigEndian.Uint32(first) != CmdSet {
+		if sc, err = p2p.SecureHandshake(sc, s.key, false); err != nil {
+			s.log.Warn(fmt.Sprintf("secure handshake with %s error: %v", conn.RemoteAddr(), err))
+			return
+		}
+	}
+
 	for {
 		//conn.SetReadDeadline(time.Now().Add(fReadTimeout))
-		msg, err := p2p.ReadMsg(conn)
+		msg, err := p2p.ReadMsg(sc)
 		if err != nil {
 			s.log.Warn(fmt.Sprintf("read message from %s error: %v", conn.RemoteAddr(), err))
 			return
@@ -171,13 +199,22 @@ func (s *fileServer) handleConn(conn net2.Conn) {
 
 		for _, name := range req.Names {
 			conn.SetWriteDeadline(time.Now().Add(fileTimeout))
-			reader, err := s.chain.Compressor().FileReader(name)
+			reader, err := s.openFile(name)
 			if err != nil {
 				s.log.Error(fmt.Sprintf("read file %s to %s error: %v", name, conn.RemoteAddr(), err))
 				return
 			}
 
-			_, err = io.Copy(conn, reader)
+			if req.Compress {
+				// every file is a complete snappy stream
+				w := snappy.NewBufferedWriter(sc)
+				if _, err = io.Copy(w, reader); err == nil {
+					err = w.Close()
+				}
+			} else {
+				_, err = io.Copy(sc, reader)
+			}
+			reader.Close()
 
 			if err != nil {
 				s.log.Error(fmt.Sprintf("send file<%s> to %s error: %v", name, conn.RemoteAddr(), err))
@@ -188,3 +225,13 @@ func (s *fileServer) handleConn(conn net2.Conn) {
 		}
 	}
 }
+
+// peekedConn is a connection whose first bytes have been peeked by r
+type peekedConn struct {
+	net2.Conn
+	r *bufio.Reader
+}
+
+func (c peekedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
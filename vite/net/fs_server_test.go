@@ -6,13 +6,16 @@ import (
 	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/vitelabs/go-vite/crypto/ed25519"
 )
 
 func Test_File_Server(t *testing.T) {
 	const addr = "localhost:8484"
 	fs := newFileServer(addr, nil)
 
-	if err := fs.start(); err != nil {
+	_, key, _ := ed25519.GenerateKey(nil)
+	if err := fs.start(key); err != nil {
 		t.Fatal(err)
 	}
 
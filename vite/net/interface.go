@@ -1,6 +1,7 @@
 package net
 
 import (
+	"github.com/vitelabs/go-vite/chain/state_snapshot"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/compress"
 	"github.com/vitelabs/go-vite/ledger"
@@ -25,6 +26,10 @@ type Chain interface {
 	GetAccountBlocksByHash(addr types.Address, origin *types.Hash, count uint64, forward bool) ([]*ledger.AccountBlock, error)
 	GetAccountBlocksByHeight(addr types.Address, start, count uint64, forward bool) ([]*ledger.AccountBlock, error)
 
+	// the account blocks confirmed by the snapshot blocks up to PrunedHeight are not served
+	PrunedHeight() uint64
+	IsAccountBlockPruned(block *ledger.AccountBlock) (bool, error)
+
 	GetLatestSnapshotBlock() *ledger.SnapshotBlock
 	GetGenesisSnapshotBlock() *ledger.SnapshotBlock
 
@@ -101,6 +106,8 @@ type Net interface {
 	Fetcher
 	Broadcaster
 	BlockSubscriber
+	// DownloadStateSnapshot downloads the state snapshot at the snapshot block of hash from peers and verifies it
+	DownloadStateSnapshot(hash types.Hash) (*state_snapshot.StateSnapshot, error)
 	Protocols() []*p2p.Protocol
 	Start(svr p2p.Server) error
 	Stop()
@@ -18,6 +18,7 @@ const (
 	UnMatchedMsgVersion
 	UnIdenticalGenesis
 	FileTransDone
+	Pruned // the resource you requested has been pruned
 )
 
 var exception = [...]string{
@@ -31,6 +32,7 @@ var exception = [...]string{
 	UnMatchedMsgVersion: "UnMatchedMsgVersion",
 	UnIdenticalGenesis:  "UnIdenticalGenesis",
 	FileTransDone:       "FileTransDone",
+	Pruned:              "the resource you requested has been pruned",
 }
 
 func (exp Exception) String() string {
@@ -90,8 +90,9 @@ func (f *FileList) Deserialize(buf []byte) error {
 // @section GetFiles
 
 type GetFiles struct {
-	Names []string
-	Nonce uint64
+	Names    []string
+	Nonce    uint64
+	Compress bool // files are sent as snappy stream, only set if the server advertised compression in HandShake
 }
 
 func (f *GetFiles) String() string {
@@ -111,6 +112,7 @@ func (f *GetFiles) Serialize() ([]byte, error) {
 	pb := new(vitepb.GetFiles)
 	pb.Nonce = f.Nonce
 	pb.Names = f.Names
+	pb.Compress = f.Compress
 	return proto.Marshal(pb)
 }
 
@@ -123,6 +125,7 @@ func (f *GetFiles) Deserialize(buf []byte) error {
 
 	f.Names = pb.Names
 	f.Nonce = pb.Nonce
+	f.Compress = pb.Compress
 
 	return nil
 }
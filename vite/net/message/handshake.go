@@ -11,6 +11,9 @@ type HandShake struct {
 	Port    uint16
 	Current types.Hash
 	Genesis types.Hash
+	// Compress advertises that the node can read compressed messages and files, peers don't compress for nodes
+	// which don't set it
+	Compress bool
 }
 
 func (h *HandShake) Serialize() ([]byte, error) {
@@ -20,6 +23,7 @@ func (h *HandShake) Serialize() ([]byte, error) {
 	pb.Port = uint32(h.Port)
 	pb.Current = h.Current[:]
 	pb.Genesis = h.Genesis[:]
+	pb.Compress = h.Compress
 
 	return proto.Marshal(pb)
 }
@@ -36,6 +40,7 @@ func (h *HandShake) Deserialize(data []byte) error {
 	h.Port = uint16(pb.Port)
 	copy(h.Current[:], pb.Current)
 	copy(h.Genesis[:], pb.Genesis)
+	h.Compress = pb.Compress
 
 	return nil
 }
@@ -0,0 +1,42 @@
+package message
+
+import (
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/vitelabs/go-vite/vitepb"
+)
+
+func TestHandShake_Compress(t *testing.T) {
+	h := &HandShake{
+		Height:   10,
+		Port:     8484,
+		Compress: true,
+	}
+
+	buf, err := h.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	h2 := new(HandShake)
+	if err = h2.Deserialize(buf); err != nil {
+		t.Fatal(err)
+	}
+	if !h2.Compress || h2.Height != 10 || h2.Port != 8484 {
+		t.Fatalf("handshake deserialized is wrong: %+v", h2)
+	}
+
+	// handshake of old version has no Compress
+	buf, err = proto.Marshal(&vitepb.Handshake{Height: 10, Port: 8484})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h3 := new(HandShake)
+	if err = h3.Deserialize(buf); err != nil {
+		t.Fatal(err)
+	}
+	if h3.Compress {
+		t.Fatal("old handshake should not advertise compression")
+	}
+}
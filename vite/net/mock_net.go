@@ -3,6 +3,8 @@ package net
 import (
 	"sync"
 
+	"github.com/vitelabs/go-vite/chain/state_snapshot"
+	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/p2p"
 	"github.com/vitelabs/go-vite/vite/net/circle"
@@ -24,6 +26,10 @@ func (n *mockNet) Info() NodeInfo {
 	return NodeInfo{}
 }
 
+func (n *mockNet) DownloadStateSnapshot(hash types.Hash) (*state_snapshot.StateSnapshot, error) {
+	return nil, errNoSuitablePeers
+}
+
 func (n *mockNet) Protocols() []*p2p.Protocol {
 	return nil
 }
@@ -74,6 +80,7 @@ func mock(cfg *Config) Net {
 		chain:  cfg.Chain,
 		syncer: syncer,
 		fetcher: &fetcher{
+			peers:  peers,
 			policy: &fp{peers},
 			pool:   pool,
 		},
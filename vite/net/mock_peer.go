@@ -69,6 +69,14 @@ func (mp *MockPeer) FileAddress() *net2.TCPAddr {
 	return mp.faddr
 }
 
+func (mp *MockPeer) Compress() bool {
+	return false
+}
+
+func (mp *MockPeer) Secure() bool {
+	return false
+}
+
 func (mp *MockPeer) SetHead(head types.Hash, height uint64) {
 	panic("implement me")
 }
@@ -6,7 +6,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/vitelabs/go-vite/chain/state_snapshot"
 	"github.com/vitelabs/go-vite/common"
+	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/monitor"
@@ -21,6 +23,11 @@ type Config struct {
 	FileAddress string
 	Chain       Chain
 	Verifier    Verifier
+
+	// StateSnapshotDir holds the state snapshots served to peers, none is served if it is empty
+	StateSnapshotDir string
+	// SyncDisabled is set when the ledger will be bootstrapped from a state snapshot downloaded from peers
+	SyncDisabled bool
 }
 
 const DefaultPort uint16 = 8484
@@ -52,6 +59,9 @@ func New(cfg *Config) Net {
 		return mock(cfg)
 	}
 
+	fs := newFileServer(cfg.FileAddress, cfg.Chain)
+	fs.stateSnapshotDir = cfg.StateSnapshotDir
+
 	g := new(gid)
 	peers := newPeerSet()
 
@@ -71,7 +81,7 @@ func New(cfg *Config) Net {
 		syncer:          syncer,
 		fetcher:         fetcher,
 		broadcaster:     broadcaster,
-		fs:              newFileServer(cfg.FileAddress, cfg.Chain),
+		fs:              fs,
 		handlers:        make(map[ViteCmd]MsgHandler),
 		log:             netLog,
 	}
@@ -96,6 +106,10 @@ func New(cfg *Config) Net {
 	return n
 }
 
+func (n *net) DownloadStateSnapshot(hash types.Hash) (*state_snapshot.StateSnapshot, error) {
+	return n.syncer.fc.downloadStateSnapshot(hash)
+}
+
 func (n *net) Protocols() []*p2p.Protocol {
 	return n.protocols
 }
@@ -122,7 +136,14 @@ func (n *net) addHandler(handler MsgHandler) {
 func (n *net) Start(svr p2p.Server) (err error) {
 	n.term = make(chan struct{})
 
-	if err = n.fs.start(); err != nil {
+	// file server and file client use the secure channel of p2p
+	key := svr.Config().PeerKey
+	n.syncer.fc.key = key
+
+	// scores of peers are persisted in the node database of discovery
+	n.peers.scores.setStore(svr)
+
+	if err = n.fs.start(key); err != nil {
 		return
 	}
 
@@ -183,10 +204,11 @@ func (n *net) handlePeer(p *peer) (err error) {
 	}
 
 	err = p.Handshake(&message.HandShake{
-		Height:  current.Height,
-		Port:    port,
-		Current: current.Hash,
-		Genesis: genesis.Hash,
+		Height:   current.Height,
+		Port:     port,
+		Current:  current.Hash,
+		Genesis:  genesis.Hash,
+		Compress: true,
 	})
 
 	if err != nil {
@@ -206,7 +228,9 @@ func (n *net) startPeer(p *peer) (err error) {
 
 	defer n.peers.Del(p)
 
-	common.Go(n.syncer.Start)
+	if !n.SyncDisabled {
+		common.Go(n.syncer.Start)
+	}
 
 loop:
 	for {
@@ -22,6 +22,8 @@ var errDiffGesis = errors.New("different genesis block")
 type Peer interface {
 	RemoteAddr() *net2.TCPAddr
 	FileAddress() *net2.TCPAddr
+	Compress() bool
+	Secure() bool
 	SetHead(head types.Hash, height uint64)
 	SeeBlock(hash types.Hash)
 	SendSnapshotBlocks(bs []*ledger.SnapshotBlock, msgId uint64) (err error)
@@ -46,6 +48,7 @@ type peer struct {
 	head        types.Hash // hash of the top snapshotblock in snapshotchain
 	height      uint64     // height of the snapshotchain
 	filePort    uint16     // fileServer port, for request file
+	compress    bool       // peer can read compressed messages and files
 	CmdSet      p2p.CmdSet // which cmdSet it belongs
 	knownBlocks blockFilter
 	errChan     chan error
@@ -66,6 +69,11 @@ func (p *peer) ID() string {
 	return p.id
 }
 
+// Compress is whether the peer advertised compression in handshake
+func (p *peer) Compress() bool {
+	return p.compress
+}
+
 func newPeer(p *p2p.Peer, mrw *p2p.ProtoFrame, cmdSet p2p.CmdSet) *peer {
 	return &peer{
 		Peer:        p,
@@ -111,6 +119,7 @@ func (p *peer) Handshake(our *message.HandShake) error {
 	}
 
 	p.SetHead(their.Current, their.Height)
+	p.compress = our.Compress && their.Compress
 	p.filePort = their.Port
 	if p.filePort == 0 {
 		p.filePort = DefaultPort
@@ -178,7 +187,11 @@ func (p *peer) Send(code ViteCmd, msgId uint64, payload p2p.Serializable) (err e
 
 	if msg, err = p2p.PackMsg(p.CmdSet, p2p.Cmd(code), msgId, payload); err != nil {
 		return err
-	} else if err = p.mrw.WriteMsg(msg); err != nil {
+	}
+
+	msg.Compressed = p.compress && code.compressible()
+
+	if err = p.mrw.WriteMsg(msg); err != nil {
 		return err
 	}
 
@@ -190,11 +203,12 @@ func (p *peer) SendMsg(msg *p2p.Msg) (err error) {
 }
 
 type PeerInfo struct {
-	ID      string `json:"id"`
-	Addr    string `json:"addr"`
-	Head    string `json:"head"`
-	Height  uint64 `json:"height"`
-	Created string `json:"created"`
+	ID      string        `json:"id"`
+	Addr    string        `json:"addr"`
+	Head    string        `json:"head"`
+	Height  uint64        `json:"height"`
+	Created string        `json:"created"`
+	Score   PeerScoreInfo `json:"score"`
 }
 
 func (p *PeerInfo) String() string {
@@ -229,15 +243,17 @@ type peerEvent struct {
 }
 
 type peerSet struct {
-	m   map[peerId]Peer
-	prw sync.RWMutex
+	m      map[peerId]Peer
+	prw    sync.RWMutex
+	scores *peerScores
 
 	subs []chan<- peerEvent
 }
 
 func newPeerSet() *peerSet {
 	return &peerSet{
-		m: make(map[peerId]Peer),
+		m:      make(map[peerId]Peer),
+		scores: newPeerScores(),
 	}
 }
 
@@ -263,13 +279,37 @@ func (m *peerSet) Notify(e peerEvent) {
 	}
 }
 
-// BestPeer is the tallest peer
-func (m *peerSet) BestPeer() (best Peer) {
-	m.prw.RLock()
-	defer m.prw.RUnlock()
+// score records the behaviour of peer, the peer is disconnected if its score is too low
+func (m *peerSet) score(id peerId, e scoreEvent) {
+	p := m.Get(id)
+	if p == nil {
+		return
+	}
+
+	if m.scores.add(id, e) <= scoreMin {
+		p.Report(errScoreTooLow)
+	}
+}
 
+// good filters out the peers whose score is below scoreBadThreshold, l is returned if no peer is good
+func (m *peerSet) good(l peers) peers {
+	ret := make(peers, 0, len(l))
+	for _, p := range l {
+		if m.scores.value(p.ID()) >= scoreBadThreshold {
+			ret = append(ret, p)
+		}
+	}
+
+	if len(ret) == 0 {
+		return l
+	}
+	return ret
+}
+
+// BestPeer is the tallest peer, peers with bad score are chosen only if there are no other peers
+func (m *peerSet) BestPeer() (best Peer) {
 	var maxHeight uint64
-	for _, p := range m.m {
+	for _, p := range m.good(m.Peers()) {
 		peerHeight := p.Height()
 		if peerHeight > maxHeight {
 			maxHeight = peerHeight
@@ -280,9 +320,9 @@ func (m *peerSet) BestPeer() (best Peer) {
 	return
 }
 
-// SyncPeer is the middle Peer
+// SyncPeer is the middle Peer of the peers with good score
 func (m *peerSet) SyncPeer() Peer {
-	l := m.Peers()
+	l := m.good(m.Peers())
 	if len(l) == 0 {
 		return nil
 	}
@@ -324,6 +364,7 @@ func (m *peerSet) Del(peer *peer) {
 	defer m.prw.Unlock()
 
 	delete(m.m, peer.id)
+	m.scores.save(peer.id)
 
 	go m.Notify(peerEvent{
 		code:  delPeer,
@@ -339,19 +380,19 @@ func (m *peerSet) Count() int {
 	return len(m.m)
 }
 
-// Pick peers whose height taller than the target height
-// has sorted from low to high
+// Pick peers whose height taller than the target height, peers with bad score are picked only if there are no
+// other peers
 func (m *peerSet) Pick(height uint64) (l []Peer) {
 	m.prw.RLock()
-	defer m.prw.RUnlock()
-
+	var taller peers
 	for _, p := range m.m {
 		if p.Height() >= height {
-			l = append(l, p)
+			taller = append(taller, p)
 		}
 	}
+	m.prw.RUnlock()
 
-	return
+	return m.good(taller)
 }
 
 func (m *peerSet) Info() (info []PeerInfo) {
@@ -361,8 +402,9 @@ func (m *peerSet) Info() (info []PeerInfo) {
 	info = make([]PeerInfo, len(m.m))
 
 	i := 0
-	for _, p := range m.m {
+	for id, p := range m.m {
 		info[i] = p.Info()
+		info[i].Score = m.scores.info(id)
 		i++
 	}
 
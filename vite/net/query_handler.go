@@ -49,6 +49,16 @@ const (
 	ExceptionCode = 127
 )
 
+// compressible messages carry batches of blocks, small messages are not worth compressing
+func (t ViteCmd) compressible() bool {
+	switch t {
+	case SubLedgerCode, SnapshotBlocksCode, AccountBlocksCode, FullSnapshotBlocksCode:
+		return true
+	default:
+		return false
+	}
+}
+
 var msgNames = [...]string{
 	HandshakeCode:                      "HandShakeMsg",
 	StatusCode:                         "StatusMsg",
@@ -263,6 +273,7 @@ type getSubLedgerHandler struct {
 	chain interface {
 		GetSubLedgerByHeight(start, count uint64, forward bool) ([]*ledger.CompressedFileMeta, [][2]uint64)
 		GetSubLedgerByHash(origin *types.Hash, count uint64, forward bool) ([]*ledger.CompressedFileMeta, [][2]uint64, error)
+		PrunedHeight() uint64
 	}
 }
 
@@ -298,6 +309,11 @@ func (s *getSubLedgerHandler) Handle(msg *p2p.Msg, sender Peer) (err error) {
 		return sender.Send(ExceptionCode, msg.Id, message.Missing)
 	}
 
+	if isSubLedgerPruned(s.chain.PrunedHeight(), files, chunks) {
+		netLog.Warn(fmt.Sprintf("handle %s from %s error: pruned", req, sender.RemoteAddr()))
+		return sender.Send(ExceptionCode, msg.Id, message.Pruned)
+	}
+
 	if len(files) == 0 {
 		fileList := &message.FileList{
 			Chunks: chunks,
@@ -336,6 +352,24 @@ func (s *getSubLedgerHandler) Handle(msg *p2p.Msg, sender Peer) (err error) {
 	return
 }
 
+// isSubLedgerPruned returns true if some snapshot blocks of the files or chunks are pruned
+func isSubLedgerPruned(prunedHeight uint64, fs []*ledger.CompressedFileMeta, cs [][2]uint64) bool {
+	if prunedHeight == 0 {
+		return false
+	}
+	for _, f := range fs {
+		if f.StartHeight <= prunedHeight {
+			return true
+		}
+	}
+	for _, c := range cs {
+		if c[0] <= prunedHeight {
+			return true
+		}
+	}
+	return false
+}
+
 func splitFiles(fs []*ledger.CompressedFileMeta, batch int) (fss [][]*ledger.CompressedFileMeta) {
 	total := len(fs)
 	i := 0
@@ -436,6 +470,7 @@ type getAccountBlocksHandler struct {
 		GetAccountBlockByHeight(addr *types.Address, height uint64) (*ledger.AccountBlock, error)
 		GetAccountBlocksByHash(addr types.Address, origin *types.Hash, count uint64, forward bool) ([]*ledger.AccountBlock, error)
 		GetAccountBlocksByHeight(addr types.Address, start, count uint64, forward bool) ([]*ledger.AccountBlock, error)
+		IsAccountBlockPruned(block *ledger.AccountBlock) (bool, error)
 	}
 }
 
@@ -506,6 +541,14 @@ func (a *getAccountBlocksHandler) Handle(msg *p2p.Msg, sender Peer) (err error)
 			return sender.Send(ExceptionCode, msg.Id, message.Missing)
 		}
 
+		// the blocks are in ascending order, the lower ones are pruned first
+		var pruned bool
+		if pruned, err = a.chain.IsAccountBlockPruned(blocks[0]); err != nil || pruned {
+			netLog.Warn(fmt.Sprintf("handle %s from %s error: pruned %v, %v", req, sender.RemoteAddr(), pruned, err))
+			monitor.LogEvent("net/handle", "GetAccountBlocks_Fail")
+			return sender.Send(ExceptionCode, msg.Id, message.Pruned)
+		}
+
 		monitor.LogEvent("net/handle", "GetAccountBlocks_Success")
 
 		if err = sender.SendAccountBlocks(blocks, msg.Id); err != nil {
@@ -523,6 +566,7 @@ func (a *getAccountBlocksHandler) Handle(msg *p2p.Msg, sender Peer) (err error)
 type getChunkHandler struct {
 	chain interface {
 		GetConfirmSubLedger(start, end uint64) ([]*ledger.SnapshotBlock, accountBlockMap, error)
+		PrunedHeight() uint64
 	}
 }
 
@@ -550,6 +594,11 @@ func (c *getChunkHandler) Handle(msg *p2p.Msg, sender Peer) (err error) {
 		start, end = end, start
 	}
 
+	if prunedHeight := c.chain.PrunedHeight(); start <= prunedHeight {
+		netLog.Warn(fmt.Sprintf("handle %s from %s error: snapshot blocks up to %d are pruned", req, sender.RemoteAddr(), prunedHeight))
+		return sender.Send(ExceptionCode, msg.Id, message.Pruned)
+	}
+
 	// split chunk
 	chunks := splitChunk(start, end, 50)
 
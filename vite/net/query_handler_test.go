@@ -141,6 +141,7 @@ func Test_SplitFiles(t *testing.T) {
 }
 
 type chain_getSubLedger struct {
+	prunedHeight uint64
 }
 
 func (c *chain_getSubLedger) GetSubLedgerByHeight(start, count uint64, forward bool) (fs []*ledger.CompressedFileMeta, cs [][2]uint64) {
@@ -170,6 +171,10 @@ func (c *chain_getSubLedger) GetSubLedgerByHash(origin *types.Hash, count uint64
 	return
 }
 
+func (c *chain_getSubLedger) PrunedHeight() uint64 {
+	return c.prunedHeight
+}
+
 func Test_getSubLedgerHandler(t *testing.T) {
 	var count = uint64(10000000)
 	var from = uint64(1)
@@ -229,3 +234,21 @@ func Test_getSubLedgerHandler(t *testing.T) {
 		t.Fatalf("handle error: %v\n", err)
 	}
 }
+
+func Test_isSubLedgerPruned(t *testing.T) {
+	fs := []*ledger.CompressedFileMeta{{StartHeight: 101, EndHeight: 200}}
+	cs := [][2]uint64{{201, 250}}
+
+	if isSubLedgerPruned(0, fs, cs) {
+		t.Fatal("nothing is pruned")
+	}
+	if isSubLedgerPruned(100, fs, cs) {
+		t.Fatal("snapshot blocks higher than 100 are not pruned")
+	}
+	if !isSubLedgerPruned(101, fs, cs) {
+		t.Fatal("the file is pruned")
+	}
+	if !isSubLedgerPruned(210, nil, cs) {
+		t.Fatal("the chunk is pruned")
+	}
+}
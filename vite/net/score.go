@@ -0,0 +1,200 @@
+package net
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/p2p/discovery"
+)
+
+var errScoreTooLow = errors.New("score of peer is too low")
+
+// scoreEvent is a behaviour of a peer which changes its score
+type scoreEvent byte
+
+const (
+	scoreUsefulBlock    scoreEvent = iota // a new block is received by broadcast or fetch
+	scoreDuplicateBlock                   // a broadcast block has been received already
+	scoreInvalidBlock                     // a block fails the verification
+	scoreTimeout                          // a fetch or chunk request gets no response in time
+	scoreFileDone                         // a file is downloaded
+	scoreSlowFile                         // a file is downloaded slower than fileSlowSpeed
+	scoreFileFail                         // a file download fails
+	scoreEventCount
+)
+
+var scoreDeltas = [scoreEventCount]float64{1, -0.2, -50, -5, 5, -5, -10}
+var scoreEventNames = [scoreEventCount]string{"usefulBlock", "duplicateBlock", "invalidBlock", "timeout", "fileDone", "slowFile", "fileFail"}
+
+const scoreMax = 100
+const scoreMin = -100                  // peer is disconnected when its score reaches scoreMin
+const scoreBadThreshold = -20          // peer is not chosen to sync or fetch if there are better peers
+const scoreHalfLife = 30 * time.Minute // the score halves towards 0 every scoreHalfLife
+const fileSlowSpeed = 100 * 1024       // bytes/s
+
+// every peer floods a new block to the peers which don't know it, so an honest peer sends most blocks after
+// another peer, duplicates received within scoreDuplicateGrace after the first receipt are not penalised
+const scoreDuplicateGrace = 10 * time.Second
+
+// scoreStore persists the scores of peers, p2p.Server stores them in the node database of discovery
+type scoreStore interface {
+	RetrieveScore(id discovery.NodeID) (score float64, updated time.Time)
+	StoreScore(id discovery.NodeID, score float64, updated time.Time)
+}
+
+type peerScore struct {
+	value   float64
+	updated time.Time
+	events  [scoreEventCount]uint64
+}
+
+// decay the score to now
+func (s *peerScore) decay(now time.Time) {
+	if elapsed := now.Sub(s.updated); elapsed > 0 {
+		s.value *= math.Pow(0.5, float64(elapsed)/float64(scoreHalfLife))
+		s.updated = now
+	}
+}
+
+type PeerScoreInfo struct {
+	Score  float64           `json:"score"`
+	Events map[string]uint64 `json:"events,omitempty"`
+}
+
+type peerScores struct {
+	mu    sync.Mutex
+	m     map[peerId]*peerScore
+	store scoreStore
+}
+
+func newPeerScores() *peerScores {
+	return &peerScores{
+		m: make(map[peerId]*peerScore),
+	}
+}
+
+func (s *peerScores) setStore(store scoreStore) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store = store
+}
+
+// get the score of peer, load from store if the peer is not recorded, must hold the lock
+func (s *peerScores) get(id peerId, now time.Time) *peerScore {
+	if score, ok := s.m[id]; ok {
+		score.decay(now)
+		return score
+	}
+
+	score := &peerScore{updated: now}
+	if s.store != nil {
+		if nodeID, err := discovery.HexStr2NodeID(id); err == nil {
+			if value, updated := s.store.RetrieveScore(nodeID); !updated.IsZero() {
+				score.value, score.updated = value, updated
+				score.decay(now)
+			}
+		}
+	}
+	s.m[id] = score
+	return score
+}
+
+// add the delta of event to the score of peer, return the new score
+func (s *peerScores) add(id peerId, e scoreEvent) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	score := s.get(id, time.Now())
+	score.value = math.Max(scoreMin, math.Min(scoreMax, score.value+scoreDeltas[e]))
+	score.events[e]++
+	return score.value
+}
+
+func (s *peerScores) value(id peerId) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.get(id, time.Now()).value
+}
+
+func (s *peerScores) info(id peerId) PeerScoreInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	score := s.get(id, time.Now())
+	info := PeerScoreInfo{Score: score.value}
+	for e, count := range score.events {
+		if count > 0 {
+			if info.Events == nil {
+				info.Events = make(map[string]uint64)
+			}
+			info.Events[scoreEventNames[e]] = count
+		}
+	}
+	return info
+}
+
+// save the score of peer to store and forget it, call when the peer is disconnected
+func (s *peerScores) save(id peerId) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	score, ok := s.m[id]
+	if !ok {
+		return
+	}
+	delete(s.m, id)
+
+	if s.store != nil {
+		if nodeID, err := discovery.HexStr2NodeID(id); err == nil {
+			score.decay(time.Now())
+			s.store.StoreScore(nodeID, score.value, score.updated)
+		}
+	}
+}
+
+// blockReceipts records when the blocks of the last scoreDuplicateGrace were received first
+type blockReceipts struct {
+	mu    sync.Mutex
+	times map[types.Hash]time.Time
+	queue []types.Hash // in the order of receipt
+}
+
+func newBlockReceipts() *blockReceipts {
+	return &blockReceipts{
+		times: make(map[types.Hash]time.Time),
+	}
+}
+
+// forget the blocks received before the grace window, must hold the lock
+func (r *blockReceipts) expire(now time.Time) {
+	for len(r.queue) > 0 && now.Sub(r.times[r.queue[0]]) > scoreDuplicateGrace {
+		delete(r.times, r.queue[0])
+		r.queue = r.queue[1:]
+	}
+}
+
+func (r *blockReceipts) record(hash types.Hash, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.expire(now)
+	if _, ok := r.times[hash]; !ok {
+		r.times[hash] = now
+		r.queue = append(r.queue, hash)
+	}
+}
+
+// inGrace is whether the block was received first within scoreDuplicateGrace before now
+func (r *blockReceipts) inGrace(hash types.Hash, now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.expire(now)
+	_, ok := r.times[hash]
+	return ok
+}
@@ -0,0 +1,113 @@
+package net
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/p2p/discovery"
+)
+
+type mockScoreStore map[discovery.NodeID][2]float64
+
+func (s mockScoreStore) RetrieveScore(id discovery.NodeID) (score float64, updated time.Time) {
+	if v, ok := s[id]; ok {
+		return v[0], time.Unix(int64(v[1]), 0)
+	}
+	return
+}
+
+func (s mockScoreStore) StoreScore(id discovery.NodeID, score float64, updated time.Time) {
+	s[id] = [2]float64{score, float64(updated.Unix())}
+}
+
+func TestPeerScore_Decay(t *testing.T) {
+	now := time.Now()
+	s := &peerScore{value: 40, updated: now}
+
+	s.decay(now.Add(scoreHalfLife))
+	if math.Abs(s.value-20) > 1e-9 {
+		t.Fatalf("score should be 20 after a half life, but %f", s.value)
+	}
+
+	s.decay(now.Add(3 * scoreHalfLife))
+	if math.Abs(s.value-5) > 1e-9 {
+		t.Fatalf("score should be 5 after three half lives, but %f", s.value)
+	}
+}
+
+func TestPeerScores_Clamp(t *testing.T) {
+	s := newPeerScores()
+	p := mockPeer()
+
+	for i := 0; i < 10; i++ {
+		s.add(p.id, scoreInvalidBlock)
+	}
+	if v := s.value(p.id); v < scoreMin || v > scoreMin+1 {
+		t.Fatalf("score should be clamped to %d, but %f", scoreMin, v)
+	}
+
+	info := s.info(p.id)
+	if info.Events[scoreEventNames[scoreInvalidBlock]] != 10 {
+		t.Fatalf("invalidBlock events should be 10: %v", info.Events)
+	}
+}
+
+func TestPeerSet_Score(t *testing.T) {
+	m := newPeerSet()
+
+	bad, good := mockPeer(), mockPeer()
+	bad.height, good.height = 100, 10
+	bad.errChan = make(chan error, 1)
+	m.Add(bad)
+	m.Add(good)
+
+	if m.BestPeer() != bad {
+		t.Fatal("tallest peer should be the best")
+	}
+
+	m.score(bad.id, scoreInvalidBlock)
+	if m.BestPeer() != good {
+		t.Fatal("peer with bad score should not be the best")
+	}
+	if ps := m.Pick(5); len(ps) != 1 || ps[0] != good {
+		t.Fatal("peer with bad score should not be picked")
+	}
+	// no good peer taller
+	if ps := m.Pick(50); len(ps) != 1 || ps[0] != bad {
+		t.Fatal("peer with bad score should be picked if there is no better peer")
+	}
+
+	// score decays between events, so it is clamped to scoreMin by the third invalid block
+	m.score(bad.id, scoreInvalidBlock)
+	m.score(bad.id, scoreInvalidBlock)
+	select {
+	case err := <-bad.errChan:
+		if err != errScoreTooLow {
+			t.Fatalf("peer should be reported as %v, but %v", errScoreTooLow, err)
+		}
+	default:
+		t.Fatal("peer should be reported when score reaches the minimum")
+	}
+}
+
+func TestPeerScores_Store(t *testing.T) {
+	store := make(mockScoreStore)
+
+	s := newPeerScores()
+	s.setStore(store)
+
+	p := mockPeer()
+	s.add(p.id, scoreFileDone)
+	s.save(p.id)
+
+	if len(store) != 1 {
+		t.Fatal("score should be stored")
+	}
+
+	s2 := newPeerScores()
+	s2.setStore(store)
+	if v := s2.value(p.id); math.Abs(v-scoreDeltas[scoreFileDone]) > 0.01 {
+		t.Fatalf("score should be retrieved from store, but %f", v)
+	}
+}
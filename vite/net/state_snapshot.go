@@ -0,0 +1,70 @@
+package net
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/chain/state_snapshot"
+	"github.com/vitelabs/go-vite/common/types"
+)
+
+// a state snapshot is much larger than a ledger file
+const stateSnapshotTimeout = time.Hour
+
+var errNoStateSnapshotDir = errors.New("state snapshots are not served")
+
+// openFile opens a ledger file of the compressor, or a state snapshot in the state snapshot dir.
+func (s *fileServer) openFile(name string) (io.ReadCloser, error) {
+	if _, ok := state_snapshot.ParseFilename(name); ok {
+		if s.stateSnapshotDir == "" {
+			return nil, errNoStateSnapshotDir
+		}
+		// name has been checked, it can't be out of the dir
+		return os.Open(filepath.Join(s.stateSnapshotDir, name))
+	}
+
+	return s.chain.Compressor().FileReader(name)
+}
+
+// downloadStateSnapshot asks the peers for the state snapshot at the snapshot block of hash one by one, until one
+// of them sends a state snapshot which is verified against hash.
+func (fc *fileClient) downloadStateSnapshot(hash types.Hash) (*state_snapshot.StateSnapshot, error) {
+	l := fc.peers.Peers()
+	if len(l) == 0 {
+		return nil, errNoSuitablePeers
+	}
+
+	for _, p := range l {
+		fp := &filePeer{id: p.ID(), addr: p.FileAddress().String(), compress: p.Compress(), secure: p.Secure()}
+		snapshot, err := fc.downloadStateSnapshotFrom(fp, hash)
+		if err == nil {
+			return snapshot, nil
+		}
+
+		fc.peers.score(p.ID(), scoreFileFail)
+		fc.log.Warn(fmt.Sprintf("download state snapshot %s from %s error: %v", hash, p.FileAddress(), err))
+	}
+
+	return nil, errors.New(fmt.Sprintf("no peers can send the state snapshot %s", hash))
+}
+
+// downloadStateSnapshotFrom uses a new file connection, the connections in pool are kept for ledger files.
+func (fc *fileClient) downloadStateSnapshotFrom(p *filePeer, hash types.Hash) (*state_snapshot.StateSnapshot, error) {
+	conn, err := fc.dial(p)
+	if err != nil {
+		return nil, err
+	}
+	c := newFileConn(conn, p.id, p.compress, nil, fc.log)
+	defer c.close()
+
+	// the server closes the connection if it doesn't have the file, the state snapshot ends with its trailer
+	reader, derr := c.request(state_snapshot.Filename(hash), stateSnapshotTimeout)
+	if derr != nil {
+		return nil, derr
+	}
+	return state_snapshot.Load(reader, hash)
+}
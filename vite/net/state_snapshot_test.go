@@ -0,0 +1,132 @@
+package net
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/storage"
+	"github.com/vitelabs/go-vite/chain/state_snapshot"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/p2p/discovery"
+	"github.com/vitelabs/go-vite/trie"
+)
+
+// writeStateSnapshot writes a state snapshot of one account into dir and returns its snapshot block hash.
+func writeStateSnapshot(t *testing.T, dir string) types.Hash {
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}})
+
+	db, _ := leveldb.Open(storage.NewMemStorage(), nil)
+	batch := new(leveldb.Batch)
+
+	accountTrie := trie.NewTrie(db, nil, nil)
+	accountTrie.SetValue([]byte("key"), []byte("value"))
+	accountTrie.Save(batch)
+
+	addr, key, _ := types.CreateAddress()
+	snapshotTrie := trie.NewTrie(db, nil, nil)
+	snapshotTrie.SetValue(addr.Bytes(), accountTrie.Hash().Bytes())
+	snapshotTrie.Save(batch)
+	db.Write(batch, nil)
+
+	now := time.Unix(1546275661, 0)
+	block := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		Height:         1,
+		AccountAddress: addr,
+		ToAddress:      addr,
+		Amount:         big.NewInt(1),
+		Fee:            big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		Timestamp:      &now,
+		StateHash:      *accountTrie.Hash(),
+		PublicKey:      key.PubByte(),
+	}
+	block.Hash = block.ComputeHash()
+	block.Signature = ed25519.Sign(key, block.Hash.Bytes())
+
+	snapshotBlock := &ledger.SnapshotBlock{
+		Height:    2,
+		Timestamp: &now,
+		StateHash: *snapshotTrie.Hash(),
+		PublicKey: key.PubByte(),
+	}
+	snapshotBlock.Hash = snapshotBlock.ComputeHash()
+	snapshotBlock.Signature = ed25519.Sign(key, snapshotBlock.Hash.Bytes())
+
+	fd, err := os.Create(filepath.Join(dir, state_snapshot.Filename(snapshotBlock.Hash)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+
+	writer, err := state_snapshot.NewWriter(fd, snapshotBlock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, stateTrie := range []*trie.Trie{snapshotTrie, accountTrie} {
+		ni := stateTrie.NewNodeIterator()
+		for ni.Next(func(*trie.TrieNode) bool { return true }) {
+			writer.WriteTrieNode(ni.Node())
+		}
+	}
+	writer.WriteAccount(&state_snapshot.AccountState{Address: addr, PublicKey: key.PubByte(), LatestBlock: block})
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return snapshotBlock.Hash
+}
+
+func TestDownloadStateSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state_snapshot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	hash := writeStateSnapshot(t, dir)
+
+	const addr = "localhost:8485"
+	fs := newFileServer(addr, nil)
+	fs.stateSnapshotDir = dir
+	_, serverKey, _ := ed25519.GenerateKey(nil)
+	if err := fs.start(serverKey); err != nil {
+		t.Fatal(err)
+	}
+	defer fs.stop()
+	serverID, _ := discovery.Priv2NodeID(serverKey)
+
+	fc := newFileClient(nil, nil, newPeerSet())
+	_, fc.key, _ = ed25519.GenerateKey(nil)
+
+	// peers of version 1 download without the secure channel
+	for _, secure := range []bool{false, true} {
+		for _, compress := range []bool{false, true} {
+			snapshot, err := fc.downloadStateSnapshotFrom(&filePeer{id: serverID.String(), addr: addr, compress: compress, secure: secure}, hash)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if snapshot.SnapshotBlock.Hash != hash || len(snapshot.Accounts) != 1 {
+				t.Fatalf("downloaded state snapshot %s has %d accounts", snapshot.SnapshotBlock.Hash, len(snapshot.Accounts))
+			}
+		}
+	}
+
+	// the server closes the connection if it doesn't have the state snapshot
+	if _, err := fc.downloadStateSnapshotFrom(&filePeer{id: serverID.String(), addr: addr, secure: true}, types.Hash{1}); err == nil {
+		t.Fatal("state snapshot which isn't served should fail")
+	}
+
+	// the file server must be the peer
+	otherID, _ := discovery.Priv2NodeID(fc.key)
+	if _, err := fc.downloadStateSnapshotFrom(&filePeer{id: otherID.String(), addr: addr, secure: true}, hash); err == nil {
+		t.Fatal("file server of other ID should be rejected")
+	}
+}
@@ -52,6 +52,12 @@ func New(cfg *config.Config, walletManager *wallet.Manager) (vite *Vite, err err
 
 	// pool
 	pl := pool.NewPool(chain)
+	if cfg.Pool != nil && cfg.Pool.PoolJournal {
+		pl.SetJournal(cfg.PoolJournalDir())
+	}
+	if cfg.Pool != nil {
+		pl.SetAdmission(cfg.Pool.PoolAccountLimit, cfg.Pool.PoolGlobalLimit, cfg.Pool.PoolPriority)
+	}
 	genesis := chain.GetGenesisSnapshotBlock()
 
 	// consensus
@@ -64,10 +70,12 @@ func New(cfg *config.Config, walletManager *wallet.Manager) (vite *Vite, err err
 	// net
 	netVerifier := verifier.NewNetVerifier(sbVerifier, aVerifier)
 	net := net.New(&net.Config{
-		Single:      cfg.Single,
-		FileAddress: cfg.FileAddress,
-		Chain:       chain,
-		Verifier:    netVerifier,
+		Single:           cfg.Single,
+		FileAddress:      cfg.FileAddress,
+		Chain:            chain,
+		Verifier:         netVerifier,
+		StateSnapshotDir: cfg.StateSnapshotDir,
+		SyncDisabled:     cfg.SyncDisabled,
 	})
 
 	// vite
@@ -215,6 +223,14 @@ func (v *Vite) P2P() p2p.Server {
 	return v.p2p
 }
 
+func (v *Vite) AccountVerifier() *verifier.AccountVerifier {
+	return v.accountVerifier
+}
+
+func (v *Vite) SnapshotVerifier() *verifier.SnapshotVerifier {
+	return v.snapshotVerifier
+}
+
 func parseCoinbase(coinbaseCfg string) (*types.Address, uint32, error) {
 	splits := strings.Split(coinbaseCfg, ":")
 	if len(splits) != 2 {
@@ -21,6 +21,8 @@ const _ = proto.ProtoPackageIsVersion2 // please upgrade the proto package
 type Producer struct {
 	BrokerList           []string `protobuf:"bytes,1,rep,name=brokerList,proto3" json:"brokerList,omitempty"`
 	Topic                string   `protobuf:"bytes,2,opt,name=topic,proto3" json:"topic,omitempty"`
+	Kind                 string   `protobuf:"bytes,3,opt,name=kind,proto3" json:"kind,omitempty"`
+	Target               string   `protobuf:"bytes,4,opt,name=target,proto3" json:"target,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -30,7 +32,7 @@ func (m *Producer) Reset()         { *m = Producer{} }
 func (m *Producer) String() string { return proto.CompactTextString(m) }
 func (*Producer) ProtoMessage()    {}
 func (*Producer) Descriptor() ([]byte, []int) {
-	return fileDescriptor_producer_87d18ae861c53c3e, []int{0}
+	return fileDescriptor_producer_b0402f456a2066d0, []int{0}
 }
 func (m *Producer) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_Producer.Unmarshal(m, b)
@@ -64,19 +66,35 @@ func (m *Producer) GetTopic() string {
 	return ""
 }
 
+func (m *Producer) GetKind() string {
+	if m != nil {
+		return m.Kind
+	}
+	return ""
+}
+
+func (m *Producer) GetTarget() string {
+	if m != nil {
+		return m.Target
+	}
+	return ""
+}
+
 func init() {
 	proto.RegisterType((*Producer)(nil), "vitepb.Producer")
 }
 
-func init() { proto.RegisterFile("vitepb/producer.proto", fileDescriptor_producer_87d18ae861c53c3e) }
+func init() { proto.RegisterFile("vitepb/producer.proto", fileDescriptor_producer_b0402f456a2066d0) }
 
-var fileDescriptor_producer_87d18ae861c53c3e = []byte{
-	// 104 bytes of a gzipped FileDescriptorProto
+var fileDescriptor_producer_b0402f456a2066d0 = []byte{
+	// 130 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0x2d, 0xcb, 0x2c, 0x49,
 	0x2d, 0x48, 0xd2, 0x2f, 0x28, 0xca, 0x4f, 0x29, 0x4d, 0x4e, 0x2d, 0xd2, 0x2b, 0x28, 0xca, 0x2f,
-	0xc9, 0x17, 0x62, 0x83, 0x08, 0x2b, 0x39, 0x70, 0x71, 0x04, 0x40, 0x65, 0x84, 0xe4, 0xb8, 0xb8,
+	0xc9, 0x17, 0x62, 0x83, 0x08, 0x2b, 0xe5, 0x70, 0x71, 0x04, 0x40, 0x65, 0x84, 0xe4, 0xb8, 0xb8,
 	0x92, 0x8a, 0xf2, 0xb3, 0x53, 0x8b, 0x7c, 0x32, 0x8b, 0x4b, 0x24, 0x18, 0x15, 0x98, 0x35, 0x38,
 	0x83, 0x90, 0x44, 0x84, 0x44, 0xb8, 0x58, 0x4b, 0xf2, 0x0b, 0x32, 0x93, 0x25, 0x98, 0x14, 0x18,
-	0x35, 0x38, 0x83, 0x20, 0x9c, 0x24, 0x36, 0xb0, 0x81, 0xc6, 0x80, 0x00, 0x00, 0x00, 0xff, 0xff,
-	0x4c, 0x8c, 0x3d, 0x70, 0x69, 0x00, 0x00, 0x00,
+	0x35, 0x38, 0x83, 0x20, 0x1c, 0x21, 0x21, 0x2e, 0x96, 0xec, 0xcc, 0xbc, 0x14, 0x09, 0x66, 0xb0,
+	0x20, 0x98, 0x2d, 0x24, 0xc6, 0xc5, 0x56, 0x92, 0x58, 0x94, 0x9e, 0x5a, 0x22, 0xc1, 0x02, 0x16,
+	0x85, 0xf2, 0x92, 0xd8, 0xc0, 0x96, 0x1b, 0x03, 0x06, 0x00, 0x1c, 0x13, 0xb7, 0xc8, 0x95, 0x00,
+	0x00, 0x00,
 }
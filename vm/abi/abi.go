@@ -198,3 +198,14 @@ func (abi *ABIContract) MethodById(sigdata []byte) (*Method, error) {
 	}
 	return nil, fmt.Errorf("no method with id: %#x", sigdata[:4])
 }
+
+// EventById looks up an event by the first topic of a log
+// returns nil if none found
+func (abi *ABIContract) EventById(topic types.Hash) (*Event, error) {
+	for _, event := range abi.Events {
+		if !event.Anonymous && event.Id() == topic {
+			return &event, nil
+		}
+	}
+	return nil, fmt.Errorf("no event with id: %v", topic)
+}
@@ -3,7 +3,6 @@ package abi
 import (
 	"encoding/json"
 	"fmt"
-	"github.com/vitelabs/go-vite/common/helper"
 	"reflect"
 	"strings"
 )
@@ -18,19 +17,23 @@ type Argument struct {
 
 type Arguments []Argument
 
+// ArgumentMarshaling is the json form of an argument, Components are the fields of a tuple.
+type ArgumentMarshaling struct {
+	Name       string
+	Type       string
+	Components []ArgumentMarshaling
+	Indexed    bool
+}
+
 // UnmarshalJSON implements json.Unmarshaler interface
 func (argument *Argument) UnmarshalJSON(data []byte) error {
-	var extarg struct {
-		Name    string
-		Type    string
-		Indexed bool
-	}
+	var extarg ArgumentMarshaling
 	err := json.Unmarshal(data, &extarg)
 	if err != nil {
 		return fmt.Errorf("argument json err: %v", err)
 	}
 
-	argument.Type, err = NewType(extarg.Type)
+	argument.Type, err = NewTupleType(extarg.Type, extarg.Components)
 	if err != nil {
 		return err
 	}
@@ -159,11 +162,15 @@ func (arguments Arguments) unpackAtomic(v interface{}, marshalledValues []interf
 
 	var abi2struct map[string]string
 	if kind == reflect.Struct {
+		arg := arguments.NonIndexed()[0]
+		// a single tuple is unpacked into the struct directly, unless the struct has a field for it
+		if arg.Type.T == TupleTy && !hasStructField(elem.Type(), arg.Name) {
+			return set(elem, reflectValue, arg)
+		}
 		var err error
 		if abi2struct, err = mapAbiToStructFields(arguments, elem); err != nil {
 			return err
 		}
-		arg := arguments.NonIndexed()[0]
 		if structField, ok := abi2struct[arg.Name]; ok {
 			return set(elem.FieldByName(structField), reflectValue, arg)
 		}
@@ -174,42 +181,18 @@ func (arguments Arguments) unpackAtomic(v interface{}, marshalledValues []interf
 
 }
 
-// Computes the full size of an array;
-// i.e. counting nested arrays, which count towards size for unpacking.
-func getArraySize(arr *Type) int {
-	size := arr.Size
-	// Arrays can be nested, with each element being the same size
-	arr = arr.Elem
-	for arr.T == ArrayTy {
-		// Keep multiplying by elem.Size while the elem is an array.
-		size *= arr.Size
-		arr = arr.Elem
-	}
-	// Now we have the full array size, including its children.
-	return size
-}
-
 // UnpackValues can be used to unpack ABI-encoded hexdata according to the ABI-specification,
 // without supplying a struct to unpack into. Instead, this method returns a list containing the
 // values. An atomic argument will be a list with one element.
 func (arguments Arguments) UnpackValues(data []byte) ([]interface{}, error) {
 	retval := make([]interface{}, 0, arguments.LengthNonIndexed())
-	virtualArgs := 0
-	for index, arg := range arguments.NonIndexed() {
-		marshalledValue, err := toGoType((index+virtualArgs)*helper.WordSize, arg.Type, data)
-		if arg.Type.T == ArrayTy {
-			// If we have a static array, like [3]uint256, these are coded as
-			// just like uint256,uint256,uint256.
-			// This means that we need to add two 'virtual' arguments when
-			// we count the index from now on.
-			//
-			// Array values nested multiple levels deep are also encoded inline:
-			// [2][3]uint256: uint256,uint256,uint256,uint256,uint256,uint256
-			//
-			// Calculate the full array size to get the correct offset for the next argument.
-			// Decrement it by 1, as the normal index increment is still applied.
-			virtualArgs += getArraySize(&arg.Type) - 1
-		}
+	offset := 0
+	for _, arg := range arguments.NonIndexed() {
+		marshalledValue, err := toGoType(offset, arg.Type, data)
+		// Static arrays and static tuples are coded in place, like [2][3]uint256 is coded
+		// as uint256,uint256,uint256,uint256,uint256,uint256, so the offset of the next
+		// argument is increased by the full size.
+		offset += getTypeSize(arg.Type)
 		if err != nil {
 			return nil, err
 		}
@@ -238,11 +221,7 @@ func (arguments Arguments) Pack(args ...interface{}) ([]byte, error) {
 	// input offset is the bytes offset for packed output
 	inputOffset := 0
 	for _, abiArg := range abiArgs {
-		if abiArg.Type.T == ArrayTy {
-			inputOffset += helper.WordSize * abiArg.Type.Size
-		} else {
-			inputOffset += helper.WordSize
-		}
+		inputOffset += getTypeSize(abiArg.Type)
 	}
 	var ret []byte
 	for i, a := range args {
@@ -252,8 +231,8 @@ func (arguments Arguments) Pack(args ...interface{}) ([]byte, error) {
 		if err != nil {
 			return nil, err
 		}
-		// check for a slice type (string, bytes, slice)
-		if input.Type.requiresLengthPrefix() {
+		// check for a dynamic type (string, bytes, slice, dynamic tuple)
+		if isDynamicType(input.Type) {
 			// calculate the offset
 			offset := inputOffset + len(variableInput)
 			// set the offset
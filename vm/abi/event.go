@@ -76,3 +76,39 @@ func (e Event) Pack(args ...interface{}) ([]types.Hash, []byte, error) {
 	}
 
 }
+
+// UnpackLog returns the values of all the inputs of the event from the topics and data of a log. The indexed inputs
+// which don't fit in a topic are hashed when packed, their values are the topics.
+func (e Event) UnpackLog(topics []types.Hash, data []byte) ([]interface{}, error) {
+	topicIndex := 1
+	if e.Anonymous {
+		topicIndex = 0
+	}
+	if len(topics) != e.Inputs.LengthIndexed()+topicIndex {
+		return nil, fmt.Errorf("event topic count mismatch: %d for %d", len(topics), e.Inputs.LengthIndexed()+topicIndex)
+	}
+	nonIndexedValues, err := e.Inputs.UnpackValues(data)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, 0, len(e.Inputs))
+	for _, input := range e.Inputs {
+		if !input.Indexed {
+			values = append(values, nonIndexedValues[0])
+			nonIndexedValues = nonIndexedValues[1:]
+			continue
+		}
+		topic := topics[topicIndex]
+		topicIndex = topicIndex + 1
+		if isDynamicType(input.Type) || getTypeSize(input.Type) > types.HashSize {
+			values = append(values, topic)
+			continue
+		}
+		value, err := toGoType(0, input.Type, topic.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/vitelabs/go-vite/common/helper"
 	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
 )
 
 var jsonEventTransfer = []byte(`{
@@ -342,3 +343,23 @@ func TestEventIndexedWithArrayUnpack(t *testing.T) {
 	require.Equal(t, [2]uint8{0, 0}, rst.Value1)
 	require.Equal(t, stringOut, rst.Value2)
 }
+
+func TestEventUnpackLog(t *testing.T) {
+	definition := `[{"name": "test", "type": "event", "inputs": [{"indexed": true, "name":"value1", "type":"uint8"},{"indexed": false, "name":"value2", "type":"string"},{"indexed": true, "name":"value3", "type":"string"},{"indexed": false, "name":"value4", "type":"address"}]}]`
+	abi, err := JSONToABIContract(strings.NewReader(definition))
+	require.NoError(t, err)
+	topics, data, err := abi.PackEvent("test", uint8(8), "abc", "def", ledger.GenesisAccountAddress)
+	require.NoError(t, err)
+
+	event, err := abi.EventById(topics[0])
+	require.NoError(t, err)
+	require.Equal(t, "test", event.Name)
+	values, err := event.UnpackLog(topics, data)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{uint8(8), "abc", types.DataHash(packBytesSlice([]byte("def"), 3)), ledger.GenesisAccountAddress}, values)
+
+	_, err = event.UnpackLog(topics[:2], data)
+	require.Error(t, err)
+	_, err = abi.EventById(topics[1])
+	require.Error(t, err)
+}
@@ -435,3 +435,78 @@ func TestPackNumber(t *testing.T) {
 		}
 	}
 }
+
+func TestPackTuple(t *testing.T) {
+	const definition = `[{"name":"f","type":"function","inputs":[
+	{"name":"s","type":"tuple","components":[{"name":"a","type":"uint256"},{"name":"b","type":"string"}]},
+	{"name":"p","type":"tuple[2]","components":[{"name":"addr","type":"address"},{"name":"n","type":"uint8"}]},
+	{"name":"x","type":"uint64"}]},
+	{"name":"g","type":"function","inputs":[
+	{"name":"l","type":"tuple[]","components":[{"name":"a","type":"uint256"},{"name":"b","type":"string"}]}]}]`
+	abi, err := JSONToABIContract(strings.NewReader(definition))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig := abi.Methods["f"].Sig(); sig != "f((uint256,string),(address,uint8)[2],uint64)" {
+		t.Fatalf("unexpected signature %v", sig)
+	}
+
+	type S struct {
+		A *big.Int
+		B string
+	}
+	type P struct {
+		Address types.Address `abi:"addr"`
+		N       uint8
+	}
+	var addrA, addrB = types.Address{1}, types.Address{2}
+	word := func(b ...byte) []byte { return helper.LeftPadBytes(b, helper.WordSize) }
+
+	// the static tuple array is packed in place, the dynamic tuple is packed at the end
+	expected := abi.Methods["f"].Id()
+	expected = append(expected, word(0xc0)...)
+	expected = append(expected, helper.LeftPadBytes(addrA[:], helper.WordSize)...)
+	expected = append(expected, word(1)...)
+	expected = append(expected, helper.LeftPadBytes(addrB[:], helper.WordSize)...)
+	expected = append(expected, word(2)...)
+	expected = append(expected, word(7)...)
+	expected = append(expected, word(1)...)
+	expected = append(expected, word(0x40)...)
+	expected = append(expected, word(2)...)
+	expected = append(expected, helper.RightPadBytes([]byte("hi"), helper.WordSize)...)
+
+	packed, err := abi.PackMethod("f", S{big.NewInt(1), "hi"}, [2]P{{addrA, 1}, {addrB, 2}}, uint64(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(packed, expected) {
+		t.Errorf("expected %x got %x", expected, packed)
+	}
+
+	// the offsets of the dynamic tuples in a slice are relative to the start of the elements
+	expected = abi.Methods["g"].Id()
+	expected = append(expected, word(0x20)...)
+	expected = append(expected, word(2)...)
+	expected = append(expected, word(0x40)...)
+	expected = append(expected, word(0xc0)...)
+	expected = append(expected, word(1)...)
+	expected = append(expected, word(0x40)...)
+	expected = append(expected, word(1)...)
+	expected = append(expected, helper.RightPadBytes([]byte("a"), helper.WordSize)...)
+	expected = append(expected, word(2)...)
+	expected = append(expected, word(0x40)...)
+	expected = append(expected, word(1)...)
+	expected = append(expected, helper.RightPadBytes([]byte("b"), helper.WordSize)...)
+
+	packed, err = abi.PackMethod("g", []S{{big.NewInt(1), "a"}, {big.NewInt(2), "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(packed, expected) {
+		t.Errorf("expected %x got %x", expected, packed)
+	}
+
+	if _, err := abi.PackMethod("f", struct{ A *big.Int }{big.NewInt(1)}, [2]P{}, uint64(7)); err == nil {
+		t.Error("expected error for the missing tuple field")
+	}
+}
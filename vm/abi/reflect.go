@@ -64,13 +64,66 @@ func set(dst, src reflect.Value, output Argument) error {
 	case dstType.Kind() == reflect.Interface:
 		dst.Set(src)
 	case dstType.Kind() == reflect.Ptr:
+		if dst.IsNil() && dst.CanSet() {
+			dst.Set(reflect.New(dstType.Elem()))
+		}
 		return set(dst.Elem(), src, output)
+	case dstType.Kind() == reflect.Struct && srcType.Kind() == reflect.Struct && output.Type.T == TupleTy:
+		return setTuple(dst, src, output.Type)
+	case dstType.Kind() == reflect.Slice && srcType.Kind() == reflect.Slice && output.Type.T == SliceTy:
+		slice := reflect.MakeSlice(dstType, src.Len(), src.Len())
+		if err := setElems(slice, src, *output.Type.Elem); err != nil {
+			return err
+		}
+		dst.Set(slice)
+	case dstType.Kind() == reflect.Array && srcType.Kind() == reflect.Array && output.Type.T == ArrayTy &&
+		dst.Len() == src.Len():
+		return setElems(dst, src, *output.Type.Elem)
 	default:
 		return fmt.Errorf("abi: cannot unmarshal %v in to %v", src.Type(), dst.Type())
 	}
 	return nil
 }
 
+// setTuple assigns the fields of an unpacked tuple to the struct fields mapped in the same way as arguments.
+func setTuple(dst, src reflect.Value, t Type) error {
+	args := t.tupleArguments()
+	abi2struct, err := mapAbiToStructFields(args, dst)
+	if err != nil {
+		return err
+	}
+	for i, arg := range args {
+		if structField, ok := abi2struct[arg.Name]; ok {
+			if err := set(dst.FieldByName(structField), src.Field(i), arg); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// setElems assigns the elements of an unpacked slice or array one by one, so that tuples can be unpacked into
+// slices of structs.
+func setElems(dst, src reflect.Value, elem Type) error {
+	for i := 0; i < src.Len(); i++ {
+		if err := set(dst.Index(i), src.Index(i), Argument{Type: elem}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasStructField returns whether an argument is mapped to a field of the struct type.
+func hasStructField(typ reflect.Type, name string) bool {
+	for i := 0; i < typ.NumField(); i++ {
+		if tagName, ok := typ.Field(i).Tag.Lookup("abi"); ok && tagName == name {
+			return true
+		}
+	}
+	_, ok := typ.FieldByName(capitalise(name))
+	return ok
+}
+
 // requireAssignable assures that `dest` is a pointer and it's not an interface.
 func requireAssignable(dst, src reflect.Value) error {
 	if dst.Kind() != reflect.Ptr && dst.Kind() != reflect.Interface {
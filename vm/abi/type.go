@@ -2,11 +2,13 @@ package abi
 
 import (
 	"fmt"
+	"github.com/vitelabs/go-vite/common/helper"
 	"github.com/vitelabs/go-vite/common/types"
 	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
 // Type enumerator
@@ -24,6 +26,7 @@ const (
 	BytesTy
 	HashTy
 	FunctionTy
+	TupleTy
 )
 
 // Type is the reflection of the supported argument type
@@ -36,6 +39,10 @@ type Type struct {
 	T    byte // Our own type checking
 
 	stringKind string // holds the unparsed string for deriving signatures
+
+	// Tuple relative fields
+	TupleElems    []*Type  // Type information of all tuple fields
+	TupleRawNames []string // Raw field name of all tuple fields
 }
 
 var (
@@ -45,6 +52,12 @@ var (
 
 // NewType creates a new reflection type of abi type given in t.
 func NewType(t string) (typ Type, err error) {
+	return NewTupleType(t, nil)
+}
+
+// NewTupleType creates a new reflection type of abi type given in t, the components are the fields of the tuple if t
+// is a tuple or an array of tuples.
+func NewTupleType(t string, components []ArgumentMarshaling) (typ Type, err error) {
 	// check that array brackets are equal if they exist
 	if strings.Count(t, "[") != strings.Count(t, "]") {
 		return Type{}, fmt.Errorf("invalid arg type in abi")
@@ -57,12 +70,14 @@ func NewType(t string) (typ Type, err error) {
 	if strings.Count(t, "[") != 0 {
 		i := strings.LastIndex(t, "[")
 		// recursively embed the type
-		embeddedType, err := NewType(t[:i])
+		embeddedType, err := NewTupleType(t[:i], components)
 		if err != nil {
 			return Type{}, err
 		}
 		// grab the last cell and create a type from there
 		sliced := t[i:]
+		// the signature of a tuple array is made of the fields, like (uint256,bool)[]
+		typ.stringKind = embeddedType.stringKind + sliced
 		// grab the slice size with regexp
 		re := regexp.MustCompile("[0-9]+")
 		intz := re.FindAllString(sliced, -1)
@@ -154,6 +169,39 @@ func NewType(t string) (typ Type, err error) {
 		typ.T = FunctionTy
 		typ.Size = 24
 		typ.Type = reflect.ArrayOf(24, reflect.TypeOf(byte(0)))
+	case "tuple":
+		if len(components) == 0 {
+			return Type{}, fmt.Errorf("abi: empty tuple")
+		}
+		fields := make([]reflect.StructField, 0, len(components))
+		fieldNames := make(map[string]struct{}, len(components))
+		elemStrings := make([]string, 0, len(components))
+		for _, c := range components {
+			elem, err := NewTupleType(c.Type, c.Components)
+			if err != nil {
+				return Type{}, err
+			}
+			fieldName := capitalise(c.Name)
+			if !isValidFieldName(fieldName) {
+				return Type{}, fmt.Errorf("abi: invalid tuple field name '%s'", c.Name)
+			}
+			if _, ok := fieldNames[fieldName]; ok {
+				return Type{}, fmt.Errorf("abi: duplicated tuple field name '%s'", c.Name)
+			}
+			fieldNames[fieldName] = struct{}{}
+			fields = append(fields, reflect.StructField{
+				Name: fieldName,
+				Type: elem.Type,
+				Tag:  reflect.StructTag(fmt.Sprintf(`json:"%s"`, c.Name)),
+			})
+			typ.TupleElems = append(typ.TupleElems, &elem)
+			typ.TupleRawNames = append(typ.TupleRawNames, c.Name)
+			elemStrings = append(elemStrings, elem.stringKind)
+		}
+		typ.Kind = reflect.Struct
+		typ.Type = reflect.StructOf(fields)
+		typ.T = TupleTy
+		typ.stringKind = "(" + strings.Join(elemStrings, ",") + ")"
 	default:
 		return Type{}, fmt.Errorf("unsupported arg type: %s", t)
 	}
@@ -174,25 +222,138 @@ func (t Type) pack(v reflect.Value) ([]byte, error) {
 		return nil, err
 	}
 
-	if t.T == SliceTy || t.T == ArrayTy {
+	switch t.T {
+	case SliceTy, ArrayTy:
 		var packed []byte
-
-		for i := 0; i < v.Len(); i++ {
-			val, err := t.Elem.pack(v.Index(i))
-			if err != nil {
+		if hasDynamicTupleElem(t) {
+			// dynamic tuples are encoded with the offsets relative to the start of the elements
+			elems := make([]*Type, v.Len())
+			values := make([]reflect.Value, v.Len())
+			for i := range elems {
+				elems[i], values[i] = t.Elem, v.Index(i)
+			}
+			var err error
+			if packed, err = packElems(elems, values); err != nil {
 				return nil, err
 			}
-			packed = append(packed, val...)
+		} else {
+			for i := 0; i < v.Len(); i++ {
+				val, err := t.Elem.pack(v.Index(i))
+				if err != nil {
+					return nil, err
+				}
+				packed = append(packed, val...)
+			}
 		}
 		if t.T == SliceTy {
 			return packBytesSlice(packed, v.Len()), nil
-		} else if t.T == ArrayTy {
-			return packed, nil
 		}
+		return packed, nil
+	case TupleTy:
+		abi2struct, err := mapAbiToStructFields(t.tupleArguments(), v)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]reflect.Value, len(t.TupleElems))
+		for i, name := range t.TupleRawNames {
+			structField, ok := abi2struct[name]
+			if !ok {
+				return nil, fmt.Errorf("abi: field %s for tuple not found in %v", name, v.Type())
+			}
+			values[i] = v.FieldByName(structField)
+		}
+		return packElems(t.TupleElems, values)
 	}
 	return packElement(t, v), nil
 }
 
+// packElems packs the fields of a tuple or the dynamic elements of an array, static elements are packed in place and
+// dynamic elements are appended at the end with the offsets relative to the start of the first element.
+func packElems(elems []*Type, values []reflect.Value) ([]byte, error) {
+	offset := 0
+	for _, elem := range elems {
+		offset += getTypeSize(*elem)
+	}
+	var ret, tail []byte
+	for i, elem := range elems {
+		val, err := elem.pack(values[i])
+		if err != nil {
+			return nil, err
+		}
+		if isDynamicType(*elem) {
+			ret = append(ret, packNum(reflect.ValueOf(offset+len(tail)))...)
+			tail = append(tail, val...)
+		} else {
+			ret = append(ret, val...)
+		}
+	}
+	return append(ret, tail...), nil
+}
+
+// tupleArguments returns the fields of a tuple as arguments, so that they can be mapped to struct fields in the same
+// way as arguments.
+func (t Type) tupleArguments() Arguments {
+	args := make(Arguments, len(t.TupleElems))
+	for i, elem := range t.TupleElems {
+		args[i] = Argument{Name: t.TupleRawNames[i], Type: *elem}
+	}
+	return args
+}
+
+// isDynamicType returns whether the type is packed at the end with an offset in place. Arrays of strings, bytes and
+// slices are packed in place, while arrays of dynamic tuples are dynamic as the abi spec.
+func isDynamicType(t Type) bool {
+	switch t.T {
+	case TupleTy:
+		for _, elem := range t.TupleElems {
+			if isDynamicType(*elem) {
+				return true
+			}
+		}
+		return false
+	case ArrayTy:
+		return hasDynamicTupleElem(t)
+	default:
+		return t.requiresLengthPrefix()
+	}
+}
+
+// hasDynamicTupleElem returns whether the elements of an array or a slice are dynamic tuples or arrays of them.
+func hasDynamicTupleElem(t Type) bool {
+	return (t.Elem.T == TupleTy || t.Elem.T == ArrayTy) && isDynamicType(*t.Elem)
+}
+
+// getTypeSize returns the size of the type in place, dynamic types only take the offset word.
+func getTypeSize(t Type) int {
+	if isDynamicType(t) {
+		return helper.WordSize
+	}
+	switch t.T {
+	case ArrayTy:
+		return t.Size * getTypeSize(*t.Elem)
+	case TupleTy:
+		size := 0
+		for _, elem := range t.TupleElems {
+			size += getTypeSize(*elem)
+		}
+		return size
+	default:
+		return helper.WordSize
+	}
+}
+
+func isValidFieldName(name string) bool {
+	for i, c := range name {
+		if i == 0 && !unicode.IsUpper(c) {
+			return false
+		}
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && c != '_' {
+			return false
+		}
+	}
+	return len(name) > 0
+}
+
 // requireLengthPrefix returns whether the type requires any sort of length
 // prefixing.
 func (t Type) requiresLengthPrefix() bool {
@@ -274,3 +274,49 @@ func TestTypeCheck(t *testing.T) {
 		}
 	}
 }
+
+func TestNewTupleType(t *testing.T) {
+	components := []ArgumentMarshaling{
+		{Name: "a", Type: "uint64"},
+		{Name: "_b", Type: "tuple[]", Components: []ArgumentMarshaling{{Name: "c", Type: "string"}}},
+	}
+	typ, err := NewTupleType("tuple[2]", components)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if typ.String() != "(uint64,(string)[])[2]" || typ.T != ArrayTy || typ.Elem.T != TupleTy {
+		t.Fatalf("unexpected type %v", typeWithoutStringer(typ))
+	}
+	if !isDynamicType(typ) || getTypeSize(typ) != 32 {
+		t.Fatalf("array of dynamic tuples should be dynamic")
+	}
+	if !reflect.DeepEqual(typ.Elem.TupleRawNames, []string{"a", "_b"}) {
+		t.Fatalf("unexpected names %v", typ.Elem.TupleRawNames)
+	}
+	if field, ok := typ.Elem.Type.FieldByName("B"); !ok || field.Tag.Get("json") != "_b" {
+		t.Fatalf("unexpected struct type %v", typ.Elem.Type)
+	}
+
+	static, err := NewTupleType("tuple", []ArgumentMarshaling{{Name: "a", Type: "uint64[3]"}, {Name: "b", Type: "bool"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if isDynamicType(static) || getTypeSize(static) != 4*32 {
+		t.Fatalf("unexpected size of static tuple %v", getTypeSize(static))
+	}
+
+	for i, test := range []struct {
+		components []ArgumentMarshaling
+		err        string
+	}{
+		{nil, "abi: empty tuple"},
+		{[]ArgumentMarshaling{{Name: "", Type: "bool"}}, "abi: invalid tuple field name ''"},
+		{[]ArgumentMarshaling{{Name: "a$", Type: "bool"}}, "abi: invalid tuple field name 'a$'"},
+		{[]ArgumentMarshaling{{Name: "a", Type: "bool"}, {Name: "_a", Type: "bool"}}, "abi: duplicated tuple field name '_a'"},
+		{[]ArgumentMarshaling{{Name: "a", Type: "uint"}}, "unsupported arg type: uint"},
+	} {
+		if _, err := NewTupleType("tuple", test.components); err == nil || err.Error() != test.err {
+			t.Errorf("%d failed. Expected err: '%v' got err: '%v'", i, test.err, err)
+		}
+	}
+}
@@ -77,24 +77,21 @@ func readFixedBytes(t Type, word []byte) (interface{}, error) {
 
 }
 
-func getFullElemSize(elem *Type) int {
-	//all other should be counted as 32 (slices have pointers to respective elements)
-	size := helper.WordSize
-	//arrays wrap it, each element being the same size
-	for elem.T == ArrayTy {
-		size *= elem.Size
-		elem = elem.Elem
-	}
-	return size
-}
-
 // iteratively unpack elements
 func forEachUnpack(t Type, output []byte, start, size int) (interface{}, error) {
 	if size < 0 {
 		return nil, fmt.Errorf("cannot marshal input to array, size is negative (%d)", size)
 	}
-	if start+helper.WordSize*size > len(output) {
-		return nil, fmt.Errorf("abi: cannot marshal in to go array: offset %d would go over slice boundary (len=%d)", len(output), start+helper.WordSize*size)
+	// Arrays and tuples are packed in place, resulting in longer unpack steps.
+	// Other dynamic elements have just 32 bytes per element (pointing to the contents).
+	elemSize := getTypeSize(*t.Elem)
+	if start+elemSize*size > len(output) {
+		return nil, fmt.Errorf("abi: cannot marshal in to go array: offset %d would go over slice boundary (len=%d)", len(output), start+elemSize*size)
+	}
+	// the offsets of dynamic tuples are relative to the start of the elements
+	if hasDynamicTupleElem(t) {
+		output = output[start:]
+		start = 0
 	}
 
 	// this value will become our slice or our array, depending on the type
@@ -110,13 +107,6 @@ func forEachUnpack(t Type, output []byte, start, size int) (interface{}, error)
 		return nil, fmt.Errorf("abi: invalid type in array/slice unpacking stage")
 	}
 
-	// Arrays have packed elements, resulting in longer unpack steps.
-	// Slices have just 32 bytes per element (pointing to the contents).
-	elemSize := helper.WordSize
-	if t.T == ArrayTy {
-		elemSize = getFullElemSize(t.Elem)
-	}
-
 	for i, j := start, 0; j < size; i, j = i+elemSize, j+1 {
 
 		inter, err := toGoType(i, *t.Elem, output)
@@ -132,6 +122,30 @@ func forEachUnpack(t Type, output []byte, start, size int) (interface{}, error)
 	return refSlice.Interface(), nil
 }
 
+// forTupleUnpack unpacks the fields of a tuple packed at index into the struct type of the tuple.
+func forTupleUnpack(t Type, output []byte, index int) (interface{}, error) {
+	retval := reflect.New(t.Type).Elem()
+	offset := 0
+	for i, elem := range t.TupleElems {
+		marshalledValue, err := toGoType(index+offset, *elem, output)
+		if err != nil {
+			return nil, err
+		}
+		offset += getTypeSize(*elem)
+		retval.Field(i).Set(reflect.ValueOf(marshalledValue))
+	}
+	return retval.Interface(), nil
+}
+
+// tupleOffset reads the offset of a dynamic tuple at index.
+func tupleOffset(index int, output []byte) (int, error) {
+	bigOffset := new(big.Int).SetBytes(output[index : index+helper.WordSize])
+	if !bigOffset.IsUint64() || bigOffset.Uint64() >= uint64(len(output)) {
+		return 0, fmt.Errorf("abi: cannot marshal in to go tuple: offset %v would go over slice boundary (len=%v)", bigOffset, len(output))
+	}
+	return int(bigOffset.Uint64()), nil
+}
+
 // toGoType parses the output bytes and recursively assigns the value of these bytes
 // into a go type with accordance with the ABI spec.
 func toGoType(index int, t Type, output []byte) (interface{}, error) {
@@ -145,6 +159,20 @@ func toGoType(index int, t Type, output []byte) (interface{}, error) {
 		err          error
 	)
 
+	// dynamic tuples and arrays of them are packed at the offset, which is relative to the start of the output
+	if t.T == TupleTy || t.T == ArrayTy {
+		if isDynamicType(t) {
+			offset, err := tupleOffset(index, output)
+			if err != nil {
+				return nil, err
+			}
+			output, index = output[offset:], 0
+		}
+		if t.T == TupleTy {
+			return forTupleUnpack(t, output, index)
+		}
+	}
+
 	// if we require a length prefix, find the beginning word and size returned.
 	if t.requiresLengthPrefix() {
 		begin, end, err = lengthPrefixPointsTo(index, output)
@@ -3,6 +3,7 @@ package abi
 import (
 	"bytes"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/require"
 	"github.com/vitelabs/go-vite/common/helper"
@@ -799,3 +800,105 @@ func TestOOMMaliciousInput(t *testing.T) {
 		}
 	}
 }
+
+func TestUnpackTuple(t *testing.T) {
+	const definition = `[{"name":"e","type":"event","inputs":[
+	{"name":"id","type":"uint64","indexed":true},
+	{"name":"order","type":"tuple","components":[
+		{"name":"owner","type":"address"},
+		{"name":"items","type":"tuple[]","components":[{"name":"name","type":"string"},{"name":"amounts","type":"uint256[]"}]},
+		{"name":"fee","type":"tuple","components":[{"name":"tokenId","type":"tokenId"},{"name":"amount","type":"uint256"}]}]},
+	{"name":"pairs","type":"tuple[2]","components":[{"name":"a","type":"uint8"},{"name":"b","type":"bool"}]},
+	{"name":"memo","type":"bytes"}]}]`
+	abi, err := JSONToABIContract(strings.NewReader(definition))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, expected := abi.Events["e"].Id(), types.DataHash([]byte("e(uint64,(address,(string,uint256[])[],(tokenId,uint256)),(uint8,bool)[2],bytes)")); id != expected {
+		t.Fatalf("unexpected event id %v", id)
+	}
+
+	type Item struct {
+		Name    string
+		Amounts []*big.Int
+	}
+	type Fee struct {
+		TokenId types.TokenTypeId
+		Amount  *big.Int
+	}
+	type Order struct {
+		Owner types.Address
+		Items []Item
+		Fee   *Fee
+	}
+	type Pair struct {
+		A uint8
+		B bool
+	}
+	type Event struct {
+		Order Order
+		Pairs [2]Pair
+		Memo  []byte
+	}
+	expected := Event{
+		Order: Order{
+			Owner: types.Address{1},
+			Items: []Item{{"a", []*big.Int{big.NewInt(1), big.NewInt(2)}}, {"bc", []*big.Int{}}},
+			Fee:   &Fee{types.TokenTypeId{2}, big.NewInt(3)},
+		},
+		Pairs: [2]Pair{{1, true}, {2, false}},
+		Memo:  []byte("memo"),
+	}
+	topics, data, err := abi.PackEvent("e", uint64(9), expected.Order, expected.Pairs, expected.Memo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(topics) != 2 {
+		t.Fatalf("unexpected topics %v", topics)
+	}
+
+	var ev Event
+	if err := abi.UnpackEvent(&ev, "e", data); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ev, expected) {
+		t.Errorf("unpacked %+v, expected %+v", ev, expected)
+	}
+
+	// without a given struct, tuples are unpacked into the generated struct types
+	values, err := abi.Events["e"].Inputs.UnpackValues(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 3 {
+		t.Fatalf("unexpected values %v", values)
+	}
+	js, err := json.Marshal(values[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(js) != `[{"a":1,"b":true},{"a":2,"b":false}]` {
+		t.Errorf("unexpected json %s", js)
+	}
+
+	// a single tuple is unpacked into the struct directly
+	var fee Fee
+	feeArgs := Arguments{{Name: "fee", Type: *abi.Events["e"].Inputs[1].Type.TupleElems[2]}}
+	feeData, err := feeArgs.Pack(expected.Order.Fee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := feeArgs.Unpack(&fee, feeData); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(&fee, expected.Order.Fee) {
+		t.Errorf("unpacked %+v, expected %+v", fee, expected.Order.Fee)
+	}
+
+	// malicious offset of a dynamic tuple
+	malicious := append([]byte{}, data...)
+	malicious[helper.WordSize-2] = 0xff
+	if err := abi.UnpackEvent(&ev, "e", malicious); err == nil {
+		t.Error("expected error for the malicious tuple offset")
+	}
+}
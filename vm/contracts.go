@@ -1,6 +1,8 @@
 package vm
 
 import (
+	"bytes"
+
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/vm/abi"
 	"github.com/vitelabs/go-vite/vm/contracts"
@@ -58,9 +60,34 @@ var simpleContracts = map[types.Address]*precompiledContract{
 		},
 		cabi.ABIMintage,
 	},
+	types.AddressBatchSend: {
+		map[string]contracts.PrecompiledContractMethod{
+			cabi.MethodNameBatchSend: &contracts.MethodBatchSend{},
+		},
+		cabi.ABIBatchSend,
+	},
+	types.AddressEscrow: {
+		map[string]contracts.PrecompiledContractMethod{
+			cabi.MethodNameEscrowDeposit:       &contracts.MethodEscrowDeposit{},
+			cabi.MethodNameEscrowDepositByTime: &contracts.MethodEscrowDepositByTime{},
+			cabi.MethodNameEscrowWithdraw:      &contracts.MethodEscrowWithdraw{},
+		},
+		cabi.ABIEscrow,
+	},
+	types.AddressMultiSig: {
+		map[string]contracts.PrecompiledContractMethod{
+			cabi.MethodNameMultiSigRegister: &contracts.MethodMultiSigRegister{},
+		},
+		cabi.ABIMultiSig,
+	},
 }
 
-func GetPrecompiledContract(addr types.Address, methodSelector []byte) (contracts.PrecompiledContractMethod, bool, error) {
+// GetPrecompiledContract returns the method of the precompiled contract at snapshot height sbHeight, ok is false if
+// addr is not a precompiled contract at the height.
+func GetPrecompiledContract(addr types.Address, methodSelector []byte, sbHeight uint64) (contracts.PrecompiledContractMethod, bool, error) {
+	if !types.IsPrecompiledContractAddress(addr, sbHeight) {
+		return nil, false, nil
+	}
 	p, ok := simpleContracts[addr]
 	if ok {
 		if method, err := p.abi.MethodById(methodSelector); err == nil {
@@ -72,3 +99,18 @@ func GetPrecompiledContract(addr types.Address, methodSelector []byte) (contract
 	}
 	return nil, ok, nil
 }
+
+// GetPrecompiledContractRefundMethod returns the name of the method of the built-in contract whose failed call is
+// refunded with refundData.
+func GetPrecompiledContractRefundMethod(addr types.Address, refundData []byte) (string, bool) {
+	p, ok := simpleContracts[addr]
+	if !ok || len(refundData) == 0 {
+		return "", false
+	}
+	for name, method := range p.m {
+		if bytes.Equal(method.GetRefundData(), refundData) {
+			return name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,27 @@
+package abi
+
+import (
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/vm/abi"
+	"math/big"
+	"strings"
+)
+
+const (
+	jsonBatchSend = `
+	[
+		{"type":"function","name":"BatchSend","inputs":[{"name":"transfers","type":"tuple[]","components":[{"name":"to","type":"address"},{"name":"tokenId","type":"tokenId"},{"name":"amount","type":"uint256"}]}]}
+	]`
+
+	MethodNameBatchSend = "BatchSend"
+)
+
+var (
+	ABIBatchSend, _ = abi.JSONToABIContract(strings.NewReader(jsonBatchSend))
+)
+
+type BatchTransfer struct {
+	To      types.Address
+	TokenId types.TokenTypeId
+	Amount  *big.Int
+}
@@ -0,0 +1,103 @@
+package abi
+
+import (
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/abi"
+	"math/big"
+	"strings"
+)
+
+const (
+	jsonEscrow = `
+	[
+		{"type":"function","name":"Deposit","inputs":[{"name":"beneficial","type":"address"},{"name":"unlockHeight","type":"uint64"}]},
+		{"type":"function","name":"DepositByTime","inputs":[{"name":"beneficial","type":"address"},{"name":"unlockTime","type":"uint64"}]},
+		{"type":"function","name":"Withdraw","inputs":[{"name":"id","type":"bytes32"}]},
+		{"type":"variable","name":"escrowInfo","inputs":[{"name":"depositor","type":"address"},{"name":"tokenId","type":"tokenId"},{"name":"amount","type":"uint256"},{"name":"unlockHeight","type":"uint64"},{"name":"unlockTime","type":"uint64"}]}
+	]`
+
+	MethodNameEscrowDeposit       = "Deposit"
+	MethodNameEscrowDepositByTime = "DepositByTime"
+	MethodNameEscrowWithdraw      = "Withdraw"
+	VariableNameEscrowInfo        = "escrowInfo"
+)
+
+var (
+	ABIEscrow, _ = abi.JSONToABIContract(strings.NewReader(jsonEscrow))
+)
+
+type ParamEscrowDeposit struct {
+	Beneficial   types.Address
+	UnlockHeight uint64
+}
+
+type ParamEscrowDepositByTime struct {
+	Beneficial types.Address
+	UnlockTime uint64
+}
+
+// EscrowInfo is a deposit locked for a beneficial until the unlock height, or until the unlock time in seconds if
+// it is deposited by time, in which case the unlock height is 0. The id of the deposit is the hash of the send block.
+type EscrowInfo struct {
+	Depositor    types.Address
+	TokenId      types.TokenTypeId
+	Amount       *big.Int
+	UnlockHeight uint64
+	UnlockTime   uint64
+	Beneficial   types.Address
+	Id           types.Hash
+}
+
+// IsDue returns whether the deposit can be withdrawn at the snapshot block.
+func (info *EscrowInfo) IsDue(snapshotBlock *ledger.SnapshotBlock) bool {
+	if info.UnlockTime > 0 {
+		return snapshotBlock.Timestamp != nil && uint64(snapshotBlock.Timestamp.Unix()) >= info.UnlockTime
+	}
+	return snapshotBlock.Height >= info.UnlockHeight
+}
+
+func GetEscrowKey(beneficial types.Address, id types.Hash) []byte {
+	return append(beneficial.Bytes(), id.Bytes()...)
+}
+func IsEscrowKey(key []byte) bool {
+	return len(key) == types.AddressSize+types.HashSize
+}
+func getBeneficialAndIdFromEscrowKey(key []byte) (types.Address, types.Hash) {
+	beneficial, _ := types.BytesToAddress(key[:types.AddressSize])
+	id, _ := types.BytesToHash(key[types.AddressSize:])
+	return beneficial, id
+}
+
+// GetEscrowInfoList returns the deposits locked for the beneficial.
+func GetEscrowInfoList(db StorageDatabase, beneficial types.Address) []*EscrowInfo {
+	return getEscrowInfoList(db, beneficial.Bytes(), func(info *EscrowInfo) bool { return true })
+}
+
+// GetEscrowInfoListByDepositor returns the deposits made by the depositor, all the deposits are iterated.
+func GetEscrowInfoListByDepositor(db StorageDatabase, depositor types.Address) []*EscrowInfo {
+	return getEscrowInfoList(db, nil, func(info *EscrowInfo) bool { return info.Depositor == depositor })
+}
+
+func getEscrowInfoList(db StorageDatabase, prefix []byte, filter func(info *EscrowInfo) bool) []*EscrowInfo {
+	escrowInfoList := make([]*EscrowInfo, 0)
+	iterator := db.NewStorageIteratorBySnapshotHash(&types.AddressEscrow, prefix, nil)
+	if iterator == nil {
+		return escrowInfoList
+	}
+	for {
+		key, value, ok := iterator.Next()
+		if !ok {
+			break
+		}
+		if !IsEscrowKey(key) {
+			continue
+		}
+		escrowInfo := new(EscrowInfo)
+		if err := ABIEscrow.UnpackVariable(escrowInfo, VariableNameEscrowInfo, value); err == nil && filter(escrowInfo) {
+			escrowInfo.Beneficial, escrowInfo.Id = getBeneficialAndIdFromEscrowKey(key)
+			escrowInfoList = append(escrowInfoList, escrowInfo)
+		}
+	}
+	return escrowInfoList
+}
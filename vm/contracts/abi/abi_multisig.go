@@ -0,0 +1,71 @@
+package abi
+
+import (
+	"bytes"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/vm/abi"
+	"sort"
+	"strings"
+)
+
+const (
+	jsonMultiSig = `
+	[
+		{"type":"function","name":"Register","inputs":[{"name":"threshold","type":"uint8"},{"name":"keys","type":"bytes32[]"}]},
+		{"type":"variable","name":"multiSigInfo","inputs":[{"name":"threshold","type":"uint8"},{"name":"keys","type":"bytes32[]"}]}
+	]`
+
+	MethodNameMultiSigRegister = "Register"
+	VariableNameMultiSigInfo   = "multiSigInfo"
+)
+
+var (
+	ABIMultiSig, _ = abi.JSONToABIContract(strings.NewReader(jsonMultiSig))
+)
+
+// MultiSigInfo is the key set of a multisig address, a block of the address must be signed by at least threshold
+// keys of the set.
+type MultiSigInfo struct {
+	Threshold uint8
+	Keys      [][32]byte
+}
+
+// SortMultiSigKeys sorts the keys in place, the keys of a multisig address are always kept in order.
+func SortMultiSigKeys(keys [][32]byte) {
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i][:], keys[j][:]) < 0 })
+}
+
+// NewMultiSigAddress derives the address of a key set, which is independent of the order of the keys.
+func NewMultiSigAddress(threshold uint8, keys [][32]byte) types.Address {
+	sorted := make([][32]byte, len(keys))
+	copy(sorted, keys)
+	SortMultiSigKeys(sorted)
+	data := [][]byte{{threshold}}
+	for i := range sorted {
+		data = append(data, sorted[i][:])
+	}
+	return types.CreateContractAddress(data...)
+}
+
+func GetMultiSigKey(addr types.Address) []byte {
+	return addr.Bytes()
+}
+
+// GetMultiSigInfo returns the key set registered for the address, nil if the address is not registered.
+func GetMultiSigInfo(db StorageDatabase, addr types.Address) *MultiSigInfo {
+	info := new(MultiSigInfo)
+	if err := ABIMultiSig.UnpackVariable(info, VariableNameMultiSigInfo, db.GetStorageBySnapshotHash(&types.AddressMultiSig, GetMultiSigKey(addr), nil)); err != nil {
+		return nil
+	}
+	return info
+}
+
+// IsMultiSigKey returns true if the key belongs to the key set.
+func (info *MultiSigInfo) IsMultiSigKey(key []byte) bool {
+	for _, k := range info.Keys {
+		if bytes.Equal(k[:], key) {
+			return true
+		}
+	}
+	return false
+}
@@ -12,7 +12,7 @@ import (
 )
 
 func TestContractsABIInit(t *testing.T) {
-	tests := []string{jsonRegister, jsonVote, jsonPledge, jsonConsensusGroup, jsonMintage}
+	tests := []string{jsonRegister, jsonVote, jsonPledge, jsonConsensusGroup, jsonMintage, jsonBatchSend, jsonEscrow, jsonMultiSig}
 	for _, data := range tests {
 		if _, err := abi.JSONToABIContract(strings.NewReader(jsonRegister)); err != nil {
 			t.Fatalf("json to abi failed, %v, %v", data, err)
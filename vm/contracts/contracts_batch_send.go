@@ -0,0 +1,79 @@
+package contracts
+
+import (
+	"errors"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
+	"math/big"
+)
+
+type MethodBatchSend struct{}
+
+func (p *MethodBatchSend) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (p *MethodBatchSend) GetRefundData() []byte {
+	return []byte{1}
+}
+
+func (p *MethodBatchSend) GetQuota() uint64 {
+	return BatchSendGas
+}
+
+// send the amount of the block to a list of accounts, the contract sends one block for each transfer at receive
+func (p *MethodBatchSend) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	if !fork.IsBatchSendFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	quotaLeft, err := util.UseQuota(quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	var transfers []cabi.BatchTransfer
+	if err = cabi.ABIBatchSend.UnpackMethod(&transfers, cabi.MethodNameBatchSend, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if len(transfers) == 0 || len(transfers) > batchSendCountMax {
+		return quotaLeft, errors.New("invalid transfer count")
+	}
+	quotaLeft, err = util.UseQuota(quotaLeft, BatchSendTransferGas*uint64(len(transfers)))
+	if err != nil {
+		return quotaLeft, err
+	}
+	total := big.NewInt(0)
+	for _, transfer := range transfers {
+		if transfer.TokenId != block.TokenId ||
+			transfer.Amount.Sign() <= 0 ||
+			types.IsPrecompiledContractAddress(transfer.To, db.CurrentSnapshotBlock().Height) {
+			return quotaLeft, errors.New("invalid transfer")
+		}
+		total.Add(total, transfer.Amount)
+	}
+	if total.Cmp(block.Amount) != 0 {
+		return quotaLeft, errors.New("transfer amount not match")
+	}
+	block.Data, _ = cabi.ABIBatchSend.PackMethod(cabi.MethodNameBatchSend, transfers)
+	return quotaLeft, nil
+}
+
+func (p *MethodBatchSend) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+	var transfers []cabi.BatchTransfer
+	cabi.ABIBatchSend.UnpackMethod(&transfers, cabi.MethodNameBatchSend, sendBlock.Data)
+	sendBlockList := make([]*SendBlock, 0, len(transfers))
+	for _, transfer := range transfers {
+		sendBlockList = append(sendBlockList, &SendBlock{
+			block,
+			transfer.To,
+			ledger.BlockTypeSendCall,
+			transfer.Amount,
+			transfer.TokenId,
+			[]byte{},
+		})
+	}
+	return sendBlockList, nil
+}
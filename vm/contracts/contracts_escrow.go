@@ -0,0 +1,161 @@
+package contracts
+
+import (
+	"errors"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
+	"math/big"
+)
+
+type MethodEscrowDeposit struct{}
+
+func (p *MethodEscrowDeposit) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (p *MethodEscrowDeposit) GetRefundData() []byte {
+	return []byte{1}
+}
+
+func (p *MethodEscrowDeposit) GetQuota() uint64 {
+	return EscrowDepositGas
+}
+
+// deposit tokens for a beneficial, which can be withdrawn by the beneficial after the unlock height
+func (p *MethodEscrowDeposit) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	if !fork.IsEscrowFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	quotaLeft, err := util.UseQuota(quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	if block.Amount.Sign() <= 0 {
+		return quotaLeft, errors.New("invalid block data")
+	}
+	param := new(cabi.ParamEscrowDeposit)
+	if err = cabi.ABIEscrow.UnpackMethod(param, cabi.MethodNameEscrowDeposit, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if param.UnlockHeight <= db.CurrentSnapshotBlock().Height ||
+		types.IsPrecompiledContractAddress(param.Beneficial, db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, errors.New("invalid unlock height or beneficial address")
+	}
+	block.Data, _ = cabi.ABIEscrow.PackMethod(cabi.MethodNameEscrowDeposit, param.Beneficial, param.UnlockHeight)
+	return quotaLeft, nil
+}
+
+func (p *MethodEscrowDeposit) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+	param := new(cabi.ParamEscrowDeposit)
+	cabi.ABIEscrow.UnpackMethod(param, cabi.MethodNameEscrowDeposit, sendBlock.Data)
+	escrowInfo, _ := cabi.ABIEscrow.PackVariable(cabi.VariableNameEscrowInfo, sendBlock.AccountAddress, sendBlock.TokenId, sendBlock.Amount, param.UnlockHeight, uint64(0))
+	db.SetStorage(cabi.GetEscrowKey(param.Beneficial, sendBlock.Hash), escrowInfo)
+	return nil, nil
+}
+
+type MethodEscrowDepositByTime struct{}
+
+func (p *MethodEscrowDepositByTime) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (p *MethodEscrowDepositByTime) GetRefundData() []byte {
+	return []byte{3}
+}
+
+func (p *MethodEscrowDepositByTime) GetQuota() uint64 {
+	return EscrowDepositGas
+}
+
+// deposit tokens for a beneficial, which can be withdrawn by the beneficial after the timestamp of the snapshot block
+// reaches the unlock time
+func (p *MethodEscrowDepositByTime) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	if !fork.IsEscrowFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	quotaLeft, err := util.UseQuota(quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	if block.Amount.Sign() <= 0 {
+		return quotaLeft, errors.New("invalid block data")
+	}
+	param := new(cabi.ParamEscrowDepositByTime)
+	if err = cabi.ABIEscrow.UnpackMethod(param, cabi.MethodNameEscrowDepositByTime, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if param.UnlockTime <= uint64(db.CurrentSnapshotBlock().Timestamp.Unix()) ||
+		types.IsPrecompiledContractAddress(param.Beneficial, db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, errors.New("invalid unlock time or beneficial address")
+	}
+	block.Data, _ = cabi.ABIEscrow.PackMethod(cabi.MethodNameEscrowDepositByTime, param.Beneficial, param.UnlockTime)
+	return quotaLeft, nil
+}
+
+func (p *MethodEscrowDepositByTime) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+	param := new(cabi.ParamEscrowDepositByTime)
+	cabi.ABIEscrow.UnpackMethod(param, cabi.MethodNameEscrowDepositByTime, sendBlock.Data)
+	escrowInfo, _ := cabi.ABIEscrow.PackVariable(cabi.VariableNameEscrowInfo, sendBlock.AccountAddress, sendBlock.TokenId, sendBlock.Amount, uint64(0), param.UnlockTime)
+	db.SetStorage(cabi.GetEscrowKey(param.Beneficial, sendBlock.Hash), escrowInfo)
+	return nil, nil
+}
+
+type MethodEscrowWithdraw struct{}
+
+func (p *MethodEscrowWithdraw) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (p *MethodEscrowWithdraw) GetRefundData() []byte {
+	return []byte{2}
+}
+
+func (p *MethodEscrowWithdraw) GetQuota() uint64 {
+	return EscrowWithdrawGas
+}
+
+// withdraw a deposit by the beneficial after the unlock height or the unlock time
+func (p *MethodEscrowWithdraw) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	if !fork.IsEscrowFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	quotaLeft, err := util.UseQuota(quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	if block.Amount.Sign() > 0 {
+		return quotaLeft, errors.New("invalid block data")
+	}
+	id := new(types.Hash)
+	if err = cabi.ABIEscrow.UnpackMethod(id, cabi.MethodNameEscrowWithdraw, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	block.Data, _ = cabi.ABIEscrow.PackMethod(cabi.MethodNameEscrowWithdraw, *id)
+	return quotaLeft, nil
+}
+
+func (p *MethodEscrowWithdraw) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+	id := new(types.Hash)
+	cabi.ABIEscrow.UnpackMethod(id, cabi.MethodNameEscrowWithdraw, sendBlock.Data)
+	escrowKey := cabi.GetEscrowKey(sendBlock.AccountAddress, *id)
+	escrowInfo := new(cabi.EscrowInfo)
+	err := cabi.ABIEscrow.UnpackVariable(escrowInfo, cabi.VariableNameEscrowInfo, db.GetStorage(&block.AccountAddress, escrowKey))
+	if err != nil || !escrowInfo.IsDue(db.CurrentSnapshotBlock()) {
+		return nil, errors.New("escrow not yet due")
+	}
+	db.SetStorage(escrowKey, nil)
+	return []*SendBlock{
+		{
+			block,
+			sendBlock.AccountAddress,
+			ledger.BlockTypeSendCall,
+			escrowInfo.Amount,
+			escrowInfo.TokenId,
+			[]byte{},
+		},
+	}, nil
+}
@@ -0,0 +1,74 @@
+package contracts
+
+import (
+	"errors"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/ledger"
+	cabi "github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
+	"math/big"
+)
+
+type MethodMultiSigRegister struct{}
+
+func (p *MethodMultiSigRegister) GetFee(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+
+func (p *MethodMultiSigRegister) GetRefundData() []byte {
+	return []byte{1}
+}
+
+func (p *MethodMultiSigRegister) GetQuota() uint64 {
+	return MultiSigRegisterGas
+}
+
+// register the key set of a multisig address, anyone can register since the address is derived from the key set
+func (p *MethodMultiSigRegister) DoSend(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, quotaLeft uint64) (uint64, error) {
+	if !fork.IsMultiSigFork(db.CurrentSnapshotBlock().Height) {
+		return quotaLeft, util.ErrVersionNotSupport
+	}
+	quotaLeft, err := util.UseQuota(quotaLeft, p.GetQuota())
+	if err != nil {
+		return quotaLeft, err
+	}
+	if block.Amount.Sign() > 0 {
+		return quotaLeft, errors.New("invalid block data")
+	}
+	param := new(cabi.MultiSigInfo)
+	if err = cabi.ABIMultiSig.UnpackMethod(param, cabi.MethodNameMultiSigRegister, block.Data); err != nil {
+		return quotaLeft, util.ErrInvalidMethodParam
+	}
+	if err = checkMultiSigInfo(param); err != nil {
+		return quotaLeft, err
+	}
+	block.Data, _ = cabi.ABIMultiSig.PackMethod(cabi.MethodNameMultiSigRegister, param.Threshold, param.Keys)
+	return quotaLeft, nil
+}
+
+func checkMultiSigInfo(param *cabi.MultiSigInfo) error {
+	if len(param.Keys) == 0 || len(param.Keys) > multiSigKeyCountMax ||
+		param.Threshold == 0 || int(param.Threshold) > len(param.Keys) {
+		return errors.New("invalid threshold or key count")
+	}
+	cabi.SortMultiSigKeys(param.Keys)
+	for i := 1; i < len(param.Keys); i++ {
+		if param.Keys[i] == param.Keys[i-1] {
+			return errors.New("duplicated key")
+		}
+	}
+	return nil
+}
+
+func (p *MethodMultiSigRegister) DoReceive(db vmctxt_interface.VmDatabase, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) ([]*SendBlock, error) {
+	param := new(cabi.MultiSigInfo)
+	cabi.ABIMultiSig.UnpackMethod(param, cabi.MethodNameMultiSigRegister, sendBlock.Data)
+	key := cabi.GetMultiSigKey(cabi.NewMultiSigAddress(param.Threshold, param.Keys))
+	if len(db.GetStorage(&block.AccountAddress, key)) > 0 {
+		return nil, errors.New("multisig address registered")
+	}
+	multiSigInfo, _ := cabi.ABIMultiSig.PackVariable(cabi.VariableNameMultiSigInfo, param.Threshold, param.Keys)
+	db.SetStorage(key, multiSigInfo)
+	return nil, nil
+}
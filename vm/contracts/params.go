@@ -24,6 +24,11 @@ const (
 	BurnGas                   uint64 = 48837
 	TransferOwnerGas          uint64 = 58981
 	ChangeTokenTypeGas        uint64 = 63125
+	BatchSendGas              uint64 = 21000
+	BatchSendTransferGas      uint64 = 6600 // Per transfer of a batch send, covers the data of the transfer
+	EscrowDepositGas          uint64 = 42000
+	EscrowWithdrawGas         uint64 = 42000
+	MultiSigRegisterGas       uint64 = 62200
 
 	cgNodeCountMin   uint8 = 3       // Minimum node count of consensus group
 	cgNodeCountMax   uint8 = 101     // Maximum node count of consensus group
@@ -39,6 +44,9 @@ const (
 
 	registrationNameLengthMax int = 40
 
+	batchSendCountMax   int = 100 // Maximum transfer count of a batch send
+	multiSigKeyCountMax int = 16  // Maximum key count of a multisig address
+
 	tokenNameLengthMax   int = 40 // Maximum length of a token name(include)
 	tokenSymbolLengthMax int = 10 // Maximum length of a token symbol(include)
 )
@@ -1871,6 +1871,636 @@ func TestContractsMintageV2(t *testing.T) {
 	db.accountBlockMap[addr2][hash28] = receiveCancelPledgeBlockList[0].AccountBlock
 }
 
+// testSendBeforeFork checks that addr is a user account before the fork adding its contract, so a send of data to it
+// is a transfer
+func testSendBeforeFork(t *testing.T, addr2 types.Address, data []byte) {
+	// prepare db
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, addr1, _, hash12, snapshot2, _ := prepareDb(viteTotalSupply)
+	blockTime := time.Now()
+
+	if types.IsPrecompiledContractAddress(addr2, snapshot2.Height) || !util.IsUserAccount(db, addr2) {
+		t.Fatalf("address %v is a contract before fork", addr2)
+	}
+
+	amount := big.NewInt(1e18)
+	hash13 := types.DataHash([]byte{1, 3})
+	block13 := &ledger.AccountBlock{
+		Height:         3,
+		ToAddress:      addr2,
+		AccountAddress: addr1,
+		Amount:         amount,
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		PrevHash:       hash12,
+		Data:           data,
+		SnapshotHash:   snapshot2.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash13,
+	}
+
+	vm := NewVM()
+	db.addr = addr1
+	balance1 := new(big.Int).Sub(viteTotalSupply, amount)
+	sendBlockList, isRetry, err := vm.Run(db, block13, nil)
+	if len(sendBlockList) != 1 || isRetry || err != nil ||
+		sendBlockList[0].AccountBlock.Fee.Sign() != 0 ||
+		db.balanceMap[addr1][ledger.ViteTokenId].Cmp(balance1) != 0 {
+		t.Fatalf("send to %v before fork error, err %v", addr2, err)
+	}
+}
+
+func TestContractsBatchSendBeforeFork(t *testing.T) {
+	addr3, _, _ := types.CreateAddress()
+	data, _ := abi.ABIBatchSend.PackMethod(abi.MethodNameBatchSend, []abi.BatchTransfer{
+		{To: addr3, TokenId: ledger.ViteTokenId, Amount: big.NewInt(1e18)},
+	})
+	testSendBeforeFork(t, types.AddressBatchSend, data)
+}
+
+func TestContractsEscrowBeforeFork(t *testing.T) {
+	addr3, _, _ := types.CreateAddress()
+	data, _ := abi.ABIEscrow.PackMethod(abi.MethodNameEscrowDeposit, addr3, uint64(100))
+	testSendBeforeFork(t, types.AddressEscrow, data)
+}
+
+func TestContractsBatchSend(t *testing.T) {
+	// prepare db
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, addr1, _, hash12, snapshot2, _ := prepareDb(viteTotalSupply)
+	blockTime := time.Now()
+	addr2 := types.AddressBatchSend
+	addr3, _, _ := types.CreateAddress()
+	addr4, _, _ := types.CreateAddress()
+	transfers := []abi.BatchTransfer{
+		{To: addr3, TokenId: ledger.ViteTokenId, Amount: big.NewInt(1e18)},
+		{To: addr4, TokenId: ledger.ViteTokenId, Amount: big.NewInt(2e18)},
+	}
+	amount := big.NewInt(3e18)
+	block13Data, _ := abi.ABIBatchSend.PackMethod(abi.MethodNameBatchSend, transfers)
+	hash13 := types.DataHash([]byte{1, 3})
+	block13 := &ledger.AccountBlock{
+		Height:         3,
+		ToAddress:      addr2,
+		AccountAddress: addr1,
+		Amount:         amount,
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		PrevHash:       hash12,
+		Data:           block13Data,
+		SnapshotHash:   snapshot2.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash13,
+	}
+
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, BatchSend: &config.ForkPoint{Height: 2}})
+	defer initFork()
+
+	// the amount of the block must be the sum of the transfers
+	block13.Amount = big.NewInt(1e18)
+	vm := NewVM()
+	db.addr = addr1
+	if _, _, err := vm.Run(db, block13, nil); err == nil {
+		t.Fatalf("send batch send transaction with invalid amount")
+	}
+	block13.Amount = amount
+
+	vm = NewVM()
+	balance1 := new(big.Int).Sub(viteTotalSupply, amount)
+	sendBatchBlockList, isRetry, err := vm.Run(db, block13, nil)
+	if len(sendBatchBlockList) != 1 || isRetry || err != nil ||
+		db.balanceMap[addr1][ledger.ViteTokenId].Cmp(balance1) != 0 ||
+		!bytes.Equal(sendBatchBlockList[0].AccountBlock.Data, block13Data) ||
+		sendBatchBlockList[0].AccountBlock.Quota != contracts.BatchSendGas+2*contracts.BatchSendTransferGas {
+		t.Fatalf("send batch send transaction error")
+	}
+	db.accountBlockMap[addr1][hash13] = sendBatchBlockList[0].AccountBlock
+
+	hash21 := types.DataHash([]byte{2, 1})
+	block21 := &ledger.AccountBlock{
+		Height:         1,
+		AccountAddress: addr2,
+		BlockType:      ledger.BlockTypeReceive,
+		FromBlockHash:  hash13,
+		SnapshotHash:   snapshot2.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash21,
+	}
+	vm = NewVM()
+	db.addr = addr2
+	receiveBatchBlockList, isRetry, err := vm.Run(db, block21, sendBatchBlockList[0].AccountBlock)
+	if len(receiveBatchBlockList) != 3 || isRetry || err != nil ||
+		len(receiveBatchBlockList[0].AccountBlock.Data) != 33 ||
+		receiveBatchBlockList[0].AccountBlock.Data[32] != byte(0) ||
+		receiveBatchBlockList[0].AccountBlock.Quota != 0 ||
+		db.balanceMap[addr2][ledger.ViteTokenId].Sign() != 0 {
+		t.Fatalf("receive batch send transaction error")
+	}
+	for i, transfer := range transfers {
+		sendBlock := receiveBatchBlockList[i+1].AccountBlock
+		if sendBlock.BlockType != ledger.BlockTypeSendCall ||
+			sendBlock.ToAddress != transfer.To ||
+			sendBlock.TokenId != transfer.TokenId ||
+			sendBlock.Amount.Cmp(transfer.Amount) != 0 ||
+			sendBlock.Height != uint64(i+2) ||
+			sendBlock.Quota != 0 {
+			t.Fatalf("send block %v of batch send error", i)
+		}
+	}
+}
+
+func TestContractsEscrow(t *testing.T) {
+	// prepare db
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, addr1, _, hash12, snapshot2, _ := prepareDb(viteTotalSupply)
+	blockTime := time.Now()
+	// deposit for addr1 itself, like a vesting schedule
+	addr2 := types.AddressEscrow
+	amount := big.NewInt(1e18)
+	unlockHeight := snapshot2.Height + 1
+	block13Data, _ := abi.ABIEscrow.PackMethod(abi.MethodNameEscrowDeposit, addr1, unlockHeight)
+	hash13 := types.DataHash([]byte{1, 3})
+	block13 := &ledger.AccountBlock{
+		Height:         3,
+		ToAddress:      addr2,
+		AccountAddress: addr1,
+		Amount:         amount,
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		PrevHash:       hash12,
+		Data:           block13Data,
+		SnapshotHash:   snapshot2.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash13,
+	}
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, Escrow: &config.ForkPoint{Height: 2}})
+	defer initFork()
+
+	vm := NewVM()
+	db.addr = addr1
+	balance1 := new(big.Int).Sub(viteTotalSupply, amount)
+	sendDepositBlockList, isRetry, err := vm.Run(db, block13, nil)
+	if len(sendDepositBlockList) != 1 || isRetry || err != nil ||
+		db.balanceMap[addr1][ledger.ViteTokenId].Cmp(balance1) != 0 ||
+		!bytes.Equal(sendDepositBlockList[0].AccountBlock.Data, block13Data) ||
+		sendDepositBlockList[0].AccountBlock.Quota != contracts.EscrowDepositGas {
+		t.Fatalf("send deposit transaction error")
+	}
+	db.accountBlockMap[addr1][hash13] = sendDepositBlockList[0].AccountBlock
+
+	hash21 := types.DataHash([]byte{2, 1})
+	block21 := &ledger.AccountBlock{
+		Height:         1,
+		AccountAddress: addr2,
+		BlockType:      ledger.BlockTypeReceive,
+		FromBlockHash:  hash13,
+		SnapshotHash:   snapshot2.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash21,
+	}
+	vm = NewVM()
+	db.addr = addr2
+	receiveDepositBlockList, isRetry, err := vm.Run(db, block21, sendDepositBlockList[0].AccountBlock)
+	escrowKey := abi.GetEscrowKey(addr1, hash13)
+	escrowData, _ := abi.ABIEscrow.PackVariable(abi.VariableNameEscrowInfo, addr1, ledger.ViteTokenId, amount, unlockHeight, uint64(0))
+	if len(receiveDepositBlockList) != 1 || isRetry || err != nil ||
+		!bytes.Equal(db.storageMap[addr2][string(escrowKey)], escrowData) ||
+		db.balanceMap[addr2][ledger.ViteTokenId].Cmp(amount) != 0 ||
+		receiveDepositBlockList[0].AccountBlock.Data[32] != byte(0) {
+		t.Fatalf("receive deposit transaction error")
+	}
+	db.accountBlockMap[addr2] = make(map[types.Hash]*ledger.AccountBlock)
+	db.accountBlockMap[addr2][hash21] = receiveDepositBlockList[0].AccountBlock
+
+	// get contracts data
+	if list := abi.GetEscrowInfoList(db, addr1); len(list) != 1 || list[0].Id != hash13 || list[0].Depositor != addr1 || list[0].Amount.Cmp(amount) != 0 {
+		t.Fatalf("get escrow info list failed")
+	}
+	if list := abi.GetEscrowInfoListByDepositor(db, addr1); len(list) != 1 || list[0].Beneficial != addr1 || list[0].UnlockHeight != unlockHeight {
+		t.Fatalf("get escrow info list by depositor failed")
+	}
+
+	// withdraw before the unlock height
+	block14Data, _ := abi.ABIEscrow.PackMethod(abi.MethodNameEscrowWithdraw, hash13)
+	hash14 := types.DataHash([]byte{1, 4})
+	block14 := &ledger.AccountBlock{
+		Height:         4,
+		ToAddress:      addr2,
+		AccountAddress: addr1,
+		Amount:         big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		PrevHash:       hash13,
+		Data:           block14Data,
+		SnapshotHash:   snapshot2.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash14,
+	}
+	vm = NewVM()
+	db.addr = addr1
+	sendWithdrawBlockList, isRetry, err := vm.Run(db, block14, nil)
+	if len(sendWithdrawBlockList) != 1 || isRetry || err != nil ||
+		!bytes.Equal(sendWithdrawBlockList[0].AccountBlock.Data, block14Data) ||
+		sendWithdrawBlockList[0].AccountBlock.Quota != contracts.EscrowWithdrawGas {
+		t.Fatalf("send withdraw transaction error")
+	}
+	db.accountBlockMap[addr1][hash14] = sendWithdrawBlockList[0].AccountBlock
+
+	hash22 := types.DataHash([]byte{2, 2})
+	block22 := &ledger.AccountBlock{
+		Height:         2,
+		AccountAddress: addr2,
+		BlockType:      ledger.BlockTypeReceive,
+		PrevHash:       hash21,
+		FromBlockHash:  hash14,
+		SnapshotHash:   snapshot2.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash22,
+	}
+	vm = NewVM()
+	db.addr = addr2
+	receiveWithdrawBlockList, isRetry, err := vm.Run(db, block22, sendWithdrawBlockList[0].AccountBlock)
+	if len(receiveWithdrawBlockList) != 1 || isRetry || err == nil ||
+		receiveWithdrawBlockList[0].AccountBlock.Data[32] != byte(1) ||
+		!bytes.Equal(db.storageMap[addr2][string(escrowKey)], escrowData) {
+		t.Fatalf("receive withdraw transaction before unlock height error")
+	}
+	db.accountBlockMap[addr2][hash22] = receiveWithdrawBlockList[0].AccountBlock
+
+	// withdraw after the unlock height
+	t3 := time.Unix(snapshot2.Timestamp.Unix()+1, 0)
+	snapshot3 := &ledger.SnapshotBlock{Height: unlockHeight, Timestamp: &t3, Hash: types.DataHash([]byte{10, 3})}
+	db.snapshotBlockList = append(db.snapshotBlockList, snapshot3)
+	hash15 := types.DataHash([]byte{1, 5})
+	block15 := &ledger.AccountBlock{
+		Height:         5,
+		ToAddress:      addr2,
+		AccountAddress: addr1,
+		Amount:         big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		PrevHash:       hash14,
+		Data:           block14Data,
+		SnapshotHash:   snapshot3.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash15,
+	}
+	vm = NewVM()
+	db.addr = addr1
+	sendWithdrawBlockList2, isRetry, err := vm.Run(db, block15, nil)
+	if len(sendWithdrawBlockList2) != 1 || isRetry || err != nil {
+		t.Fatalf("send withdraw transaction 2 error")
+	}
+	db.accountBlockMap[addr1][hash15] = sendWithdrawBlockList2[0].AccountBlock
+
+	hash23 := types.DataHash([]byte{2, 3})
+	block23 := &ledger.AccountBlock{
+		Height:         3,
+		AccountAddress: addr2,
+		BlockType:      ledger.BlockTypeReceive,
+		PrevHash:       hash22,
+		FromBlockHash:  hash15,
+		SnapshotHash:   snapshot3.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash23,
+	}
+	vm = NewVM()
+	db.addr = addr2
+	receiveWithdrawBlockList2, isRetry, err := vm.Run(db, block23, sendWithdrawBlockList2[0].AccountBlock)
+	if len(receiveWithdrawBlockList2) != 2 || isRetry || err != nil ||
+		receiveWithdrawBlockList2[0].AccountBlock.Data[32] != byte(0) ||
+		len(db.storageMap[addr2][string(escrowKey)]) != 0 ||
+		db.balanceMap[addr2][ledger.ViteTokenId].Sign() != 0 ||
+		receiveWithdrawBlockList2[1].AccountBlock.ToAddress != addr1 ||
+		receiveWithdrawBlockList2[1].AccountBlock.Amount.Cmp(amount) != 0 ||
+		receiveWithdrawBlockList2[1].AccountBlock.TokenId != ledger.ViteTokenId {
+		t.Fatalf("receive withdraw transaction 2 error")
+	}
+	if list := abi.GetEscrowInfoList(db, addr1); len(list) != 0 {
+		t.Fatalf("get escrow info list after withdraw failed")
+	}
+
+	// deposit until a timestamp
+	block16Data, _ := abi.ABIEscrow.PackMethod(abi.MethodNameEscrowDepositByTime, addr1, uint64(t3.Unix()))
+	hash16 := types.DataHash([]byte{1, 6})
+	block16 := &ledger.AccountBlock{
+		Height:         6,
+		ToAddress:      addr2,
+		AccountAddress: addr1,
+		Amount:         amount,
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		PrevHash:       hash15,
+		Data:           block16Data,
+		SnapshotHash:   snapshot3.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash16,
+	}
+	vm = NewVM()
+	db.addr = addr1
+	if _, _, err := vm.Run(db, block16, nil); err == nil {
+		t.Fatalf("send deposit by time transaction with a past unlock time")
+	}
+	unlockTime := uint64(t3.Unix() + 10)
+	block16Data, _ = abi.ABIEscrow.PackMethod(abi.MethodNameEscrowDepositByTime, addr1, unlockTime)
+	block16.Data = block16Data
+	vm = NewVM()
+	sendDepositByTimeBlockList, isRetry, err := vm.Run(db, block16, nil)
+	if len(sendDepositByTimeBlockList) != 1 || isRetry || err != nil ||
+		!bytes.Equal(sendDepositByTimeBlockList[0].AccountBlock.Data, block16Data) ||
+		sendDepositByTimeBlockList[0].AccountBlock.Quota != contracts.EscrowDepositGas {
+		t.Fatalf("send deposit by time transaction error")
+	}
+	db.accountBlockMap[addr1][hash16] = sendDepositByTimeBlockList[0].AccountBlock
+
+	hash24 := types.DataHash([]byte{2, 4})
+	block24 := &ledger.AccountBlock{
+		Height:         4,
+		AccountAddress: addr2,
+		BlockType:      ledger.BlockTypeReceive,
+		PrevHash:       hash23,
+		FromBlockHash:  hash16,
+		SnapshotHash:   snapshot3.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash24,
+	}
+	vm = NewVM()
+	db.addr = addr2
+	receiveDepositByTimeBlockList, isRetry, err := vm.Run(db, block24, sendDepositByTimeBlockList[0].AccountBlock)
+	escrowKey = abi.GetEscrowKey(addr1, hash16)
+	escrowData, _ = abi.ABIEscrow.PackVariable(abi.VariableNameEscrowInfo, addr1, ledger.ViteTokenId, amount, uint64(0), unlockTime)
+	if len(receiveDepositByTimeBlockList) != 1 || isRetry || err != nil ||
+		!bytes.Equal(db.storageMap[addr2][string(escrowKey)], escrowData) ||
+		db.balanceMap[addr2][ledger.ViteTokenId].Cmp(amount) != 0 {
+		t.Fatalf("receive deposit by time transaction error")
+	}
+	db.accountBlockMap[addr2][hash24] = receiveDepositByTimeBlockList[0].AccountBlock
+	if list := abi.GetEscrowInfoList(db, addr1); len(list) != 1 || list[0].Id != hash16 || list[0].UnlockHeight != 0 || list[0].UnlockTime != unlockTime {
+		t.Fatalf("get escrow info list of deposit by time failed")
+	}
+
+	// withdraw after more snapshot blocks but before the unlock time
+	block17Data, _ := abi.ABIEscrow.PackMethod(abi.MethodNameEscrowWithdraw, hash16)
+	t4 := time.Unix(int64(unlockTime)-1, 0)
+	snapshot4 := &ledger.SnapshotBlock{Height: snapshot3.Height + 1, Timestamp: &t4, Hash: types.DataHash([]byte{10, 4})}
+	db.snapshotBlockList = append(db.snapshotBlockList, snapshot4)
+	hash17 := types.DataHash([]byte{1, 7})
+	block17 := &ledger.AccountBlock{
+		Height:         7,
+		ToAddress:      addr2,
+		AccountAddress: addr1,
+		Amount:         big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		PrevHash:       hash16,
+		Data:           block17Data,
+		SnapshotHash:   snapshot4.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash17,
+	}
+	vm = NewVM()
+	db.addr = addr1
+	sendWithdrawBlockList3, isRetry, err := vm.Run(db, block17, nil)
+	if len(sendWithdrawBlockList3) != 1 || isRetry || err != nil {
+		t.Fatalf("send withdraw transaction 3 error")
+	}
+	db.accountBlockMap[addr1][hash17] = sendWithdrawBlockList3[0].AccountBlock
+
+	hash25 := types.DataHash([]byte{2, 5})
+	block25 := &ledger.AccountBlock{
+		Height:         5,
+		AccountAddress: addr2,
+		BlockType:      ledger.BlockTypeReceive,
+		PrevHash:       hash24,
+		FromBlockHash:  hash17,
+		SnapshotHash:   snapshot4.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash25,
+	}
+	vm = NewVM()
+	db.addr = addr2
+	receiveWithdrawBlockList3, isRetry, err := vm.Run(db, block25, sendWithdrawBlockList3[0].AccountBlock)
+	if len(receiveWithdrawBlockList3) != 1 || isRetry || err == nil ||
+		receiveWithdrawBlockList3[0].AccountBlock.Data[32] != byte(1) ||
+		!bytes.Equal(db.storageMap[addr2][string(escrowKey)], escrowData) {
+		t.Fatalf("receive withdraw transaction before unlock time error")
+	}
+	db.accountBlockMap[addr2][hash25] = receiveWithdrawBlockList3[0].AccountBlock
+
+	// withdraw at the unlock time
+	t5 := time.Unix(int64(unlockTime), 0)
+	snapshot5 := &ledger.SnapshotBlock{Height: snapshot4.Height + 1, Timestamp: &t5, Hash: types.DataHash([]byte{10, 5})}
+	db.snapshotBlockList = append(db.snapshotBlockList, snapshot5)
+	hash18 := types.DataHash([]byte{1, 8})
+	block18 := &ledger.AccountBlock{
+		Height:         8,
+		ToAddress:      addr2,
+		AccountAddress: addr1,
+		Amount:         big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		PrevHash:       hash17,
+		Data:           block17Data,
+		SnapshotHash:   snapshot5.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash18,
+	}
+	vm = NewVM()
+	db.addr = addr1
+	sendWithdrawBlockList4, isRetry, err := vm.Run(db, block18, nil)
+	if len(sendWithdrawBlockList4) != 1 || isRetry || err != nil {
+		t.Fatalf("send withdraw transaction 4 error")
+	}
+	db.accountBlockMap[addr1][hash18] = sendWithdrawBlockList4[0].AccountBlock
+
+	hash26 := types.DataHash([]byte{2, 6})
+	block26 := &ledger.AccountBlock{
+		Height:         6,
+		AccountAddress: addr2,
+		BlockType:      ledger.BlockTypeReceive,
+		PrevHash:       hash25,
+		FromBlockHash:  hash18,
+		SnapshotHash:   snapshot5.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash26,
+	}
+	vm = NewVM()
+	db.addr = addr2
+	receiveWithdrawBlockList4, isRetry, err := vm.Run(db, block26, sendWithdrawBlockList4[0].AccountBlock)
+	if len(receiveWithdrawBlockList4) != 2 || isRetry || err != nil ||
+		receiveWithdrawBlockList4[0].AccountBlock.Data[32] != byte(0) ||
+		len(db.storageMap[addr2][string(escrowKey)]) != 0 ||
+		db.balanceMap[addr2][ledger.ViteTokenId].Sign() != 0 ||
+		receiveWithdrawBlockList4[1].AccountBlock.ToAddress != addr1 ||
+		receiveWithdrawBlockList4[1].AccountBlock.Amount.Cmp(amount) != 0 {
+		t.Fatalf("receive withdraw transaction 4 error")
+	}
+}
+
+func TestContractsMultiSigBeforeFork(t *testing.T) {
+	keys := make([][32]byte, 2)
+	for i := range keys {
+		pub, _, _ := ed25519.GenerateKey(nil)
+		copy(keys[i][:], pub)
+	}
+	data, _ := abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigRegister, uint8(2), keys)
+	testSendBeforeFork(t, types.AddressMultiSig, data)
+}
+
+func TestContractsMultiSig(t *testing.T) {
+	// prepare db
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
+	db, addr1, _, hash12, snapshot2, _ := prepareDb(viteTotalSupply)
+	blockTime := time.Now()
+	// register a 2 of 3 key set
+	addr2 := types.AddressMultiSig
+	keys := make([][32]byte, 3)
+	for i := range keys {
+		pub, _, _ := ed25519.GenerateKey(nil)
+		copy(keys[i][:], pub)
+	}
+	threshold := uint8(2)
+	multiSigAddr := abi.NewMultiSigAddress(threshold, keys)
+	if reversed := abi.NewMultiSigAddress(threshold, [][32]byte{keys[2], keys[1], keys[0]}); reversed != multiSigAddr {
+		t.Fatalf("multisig address depends on the order of keys")
+	}
+	block13Data, _ := abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigRegister, threshold, keys)
+	hash13 := types.DataHash([]byte{1, 3})
+	block13 := &ledger.AccountBlock{
+		Height:         3,
+		ToAddress:      addr2,
+		AccountAddress: addr1,
+		Amount:         big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		PrevHash:       hash12,
+		Data:           block13Data,
+		SnapshotHash:   snapshot2.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash13,
+	}
+	fork.SetForkPoints(&config.ForkPoints{Smart: &config.ForkPoint{Height: 2}, Mint: &config.ForkPoint{Height: 2}, MultiSig: &config.ForkPoint{Height: 2}})
+	defer initFork()
+
+	vm := NewVM()
+	db.addr = addr1
+	sortedKeys := [][32]byte{keys[0], keys[1], keys[2]}
+	abi.SortMultiSigKeys(sortedKeys)
+	sortedData, _ := abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigRegister, threshold, sortedKeys)
+	sendRegisterBlockList, isRetry, err := vm.Run(db, block13, nil)
+	if len(sendRegisterBlockList) != 1 || isRetry || err != nil ||
+		!bytes.Equal(sendRegisterBlockList[0].AccountBlock.Data, sortedData) ||
+		sendRegisterBlockList[0].AccountBlock.Quota != contracts.MultiSigRegisterGas {
+		t.Fatalf("send register transaction error")
+	}
+	db.accountBlockMap[addr1][hash13] = sendRegisterBlockList[0].AccountBlock
+
+	hash21 := types.DataHash([]byte{2, 1})
+	block21 := &ledger.AccountBlock{
+		Height:         1,
+		AccountAddress: addr2,
+		BlockType:      ledger.BlockTypeReceive,
+		FromBlockHash:  hash13,
+		SnapshotHash:   snapshot2.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash21,
+	}
+	vm = NewVM()
+	db.addr = addr2
+	receiveRegisterBlockList, isRetry, err := vm.Run(db, block21, sendRegisterBlockList[0].AccountBlock)
+	multiSigData, _ := abi.ABIMultiSig.PackVariable(abi.VariableNameMultiSigInfo, threshold, sortedKeys)
+	if len(receiveRegisterBlockList) != 1 || isRetry || err != nil ||
+		!bytes.Equal(db.storageMap[addr2][string(abi.GetMultiSigKey(multiSigAddr))], multiSigData) ||
+		receiveRegisterBlockList[0].AccountBlock.Data[32] != byte(0) {
+		t.Fatalf("receive register transaction error")
+	}
+	db.accountBlockMap[addr2] = make(map[types.Hash]*ledger.AccountBlock)
+	db.accountBlockMap[addr2][hash21] = receiveRegisterBlockList[0].AccountBlock
+
+	// get contracts data
+	if info := abi.GetMultiSigInfo(db, multiSigAddr); info == nil || info.Threshold != threshold || len(info.Keys) != 3 ||
+		!info.IsMultiSigKey(keys[1][:]) || info.IsMultiSigKey(addr1.Bytes()) {
+		t.Fatalf("get multisig info failed")
+	}
+	if info := abi.GetMultiSigInfo(db, addr1); info != nil {
+		t.Fatalf("get multisig info of an unregistered address failed")
+	}
+
+	// register the same key set again
+	hash14 := types.DataHash([]byte{1, 4})
+	block14 := &ledger.AccountBlock{
+		Height:         4,
+		ToAddress:      addr2,
+		AccountAddress: addr1,
+		Amount:         big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		PrevHash:       hash13,
+		Data:           block13Data,
+		SnapshotHash:   snapshot2.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash14,
+	}
+	vm = NewVM()
+	db.addr = addr1
+	sendRegisterBlockList2, isRetry, err := vm.Run(db, block14, nil)
+	if len(sendRegisterBlockList2) != 1 || isRetry || err != nil {
+		t.Fatalf("send register transaction 2 error")
+	}
+	db.accountBlockMap[addr1][hash14] = sendRegisterBlockList2[0].AccountBlock
+
+	hash22 := types.DataHash([]byte{2, 2})
+	block22 := &ledger.AccountBlock{
+		Height:         2,
+		AccountAddress: addr2,
+		BlockType:      ledger.BlockTypeReceive,
+		PrevHash:       hash21,
+		FromBlockHash:  hash14,
+		SnapshotHash:   snapshot2.Hash,
+		Timestamp:      &blockTime,
+		Hash:           hash22,
+	}
+	vm = NewVM()
+	db.addr = addr2
+	receiveRegisterBlockList2, isRetry, err := vm.Run(db, block22, sendRegisterBlockList2[0].AccountBlock)
+	if len(receiveRegisterBlockList2) != 1 || isRetry || err == nil ||
+		receiveRegisterBlockList2[0].AccountBlock.Data[32] != byte(1) {
+		t.Fatalf("receive register transaction 2 error")
+	}
+
+	// invalid threshold
+	invalidData, _ := abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigRegister, uint8(4), keys)
+	block15 := &ledger.AccountBlock{
+		Height:         5,
+		ToAddress:      addr2,
+		AccountAddress: addr1,
+		Amount:         big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		PrevHash:       hash14,
+		Data:           invalidData,
+		SnapshotHash:   snapshot2.Hash,
+		Timestamp:      &blockTime,
+		Hash:           types.DataHash([]byte{1, 5}),
+	}
+	vm = NewVM()
+	db.addr = addr1
+	if _, _, err := vm.Run(db, block15, nil); err == nil {
+		t.Fatalf("send register transaction with invalid threshold error")
+	}
+}
+
 func TestCheckCreateConsensusGroupData(t *testing.T) {
 	tests := []struct {
 		data string
@@ -52,10 +52,13 @@ func (i *Interpreter) Run(vm *VM, c *contract) (ret []byte, err error) {
 		operation := i.instructionSet[op]
 
 		if !operation.valid {
-			return nil, fmt.Errorf("invalid opcode 0x%x", int(op))
+			err := fmt.Errorf("invalid opcode 0x%x", int(op))
+			vm.captureFault(currentPc, op, c, st, mem, 0, err)
+			return nil, err
 		}
 
 		if err := operation.validateStack(st); err != nil {
+			vm.captureFault(currentPc, op, c, st, mem, 0, err)
 			return nil, err
 		}
 
@@ -63,21 +66,34 @@ func (i *Interpreter) Run(vm *VM, c *contract) (ret []byte, err error) {
 		if operation.memorySize != nil {
 			memSize, overflow := helper.BigUint64(operation.memorySize(st))
 			if overflow {
+				vm.captureFault(currentPc, op, c, st, mem, 0, util.ErrMemSizeOverflow)
 				return nil, util.ErrMemSizeOverflow
 			}
 			if memorySize, overflow = helper.SafeMul(helper.ToWordSize(memSize), helper.WordSize); overflow {
+				vm.captureFault(currentPc, op, c, st, mem, 0, util.ErrMemSizeOverflow)
 				return nil, util.ErrMemSizeOverflow
 			}
 		}
 
 		cost, err = operation.gasCost(vm, c, st, mem, memorySize)
 		if err != nil {
+			vm.captureFault(currentPc, op, c, st, mem, 0, err)
 			return nil, err
 		}
+		var step *TraceStep
+		if vm.Tracer != nil {
+			step = newTraceStep(currentPc, op, c, st, mem, cost)
+		}
 		c.quotaLeft, err = util.UseQuota(c.quotaLeft, cost)
 		if err != nil {
+			if step != nil {
+				vm.Tracer.CaptureFault(step, err)
+			}
 			return nil, err
 		}
+		if step != nil {
+			vm.Tracer.CaptureStep(step)
+		}
 
 		if memorySize > 0 {
 			mem.resize(memorySize)
@@ -110,6 +126,9 @@ func (i *Interpreter) Run(vm *VM, c *contract) (ret []byte, err error) {
 
 		switch {
 		case err != nil:
+			if step != nil {
+				vm.Tracer.CaptureFault(step, err)
+			}
 			return nil, err
 		case operation.halts:
 			return res, nil
@@ -121,3 +140,9 @@ func (i *Interpreter) Run(vm *VM, c *contract) (ret []byte, err error) {
 	}
 	return nil, nil
 }
+
+func (vm *VM) captureFault(pc uint64, op opCode, c *contract, st *stack, mem *memory, cost uint64, err error) {
+	if vm.Tracer != nil {
+		vm.Tracer.CaptureFault(newTraceStep(pc, op, c, st, mem, cost), err)
+	}
+}
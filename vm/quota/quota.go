@@ -47,9 +47,11 @@ func GetPledgeQuota(db quotaDb, beneficial types.Address, pledgeAmount *big.Int)
 }
 
 // quotaInit = quotaLimitForAccount * (1 - 2/(1 + e**(fDifficulty * difficulty + fPledge * snapshotHeightGap * pledgeAmount)))
-// 				- quota used by prevBlock referring to the same snapshot hash
+//   - quota used by prevBlock referring to the same snapshot hash
+//
 // quotaAddition = quotaLimitForAccount * (1 - 2/(1 + e**(fDifficulty * difficulty + fPledge * snapshotHeightGap * pledgeAmount)))
-//				- quotaLimitForAccount * (1 - 2/(1 + e**(fPledge * snapshotHeightGap * pledgeAmount)))
+//   - quotaLimitForAccount * (1 - 2/(1 + e**(fPledge * snapshotHeightGap * pledgeAmount)))
+//
 // snapshotHeightGap is limit to 1 day
 // e**(fDifficulty * difficulty + fPledge * snapshotHeightGap * pledgeAmount) is discrete to reduce computation complexity
 // quotaLimitForAccount is within a range decided by net congestion and net capacity
@@ -80,54 +82,119 @@ func IsPoW(nonce []byte) bool {
 
 func CalcQuotaV2(db quotaDb, addr types.Address, pledgeAmount *big.Int, difficulty *big.Int) (uint64, uint64, error) {
 	isPoW := difficulty.Sign() > 0
+	x, quotaUsed, err := calcPledgeParam(db, pledgeAmount, isPoW)
+	if err != nil {
+		return 0, 0, err
+	}
+	var quotaWithoutPoW uint64
+	if pledgeAmount.Sign() != 0 {
+		quotaWithoutPoW = calcQuotaInSection(x)
+	}
+	if quotaWithoutPoW < quotaUsed {
+		return 0, 0, nil
+	}
+	quotaTotal := quotaWithoutPoW
+	if isPoW {
+		addPoWParam(x, difficulty)
+		quotaTotal = calcQuotaInSection(x)
+	}
+	return quotaTotal - quotaUsed, quotaTotal - quotaWithoutPoW, nil
+}
+
+// calcPledgeParam returns fPledge * snapshotHeightGap * pledgeAmount and the quota used by the previous blocks
+// referring to the current snapshot block.
+func calcPledgeParam(db quotaDb, pledgeAmount *big.Int, isPoW bool) (*big.Float, uint64, error) {
 	currentSnapshotHash := db.CurrentSnapshotBlock().Hash
 	prevBlock := db.PrevAccountBlock()
 	quotaUsed := uint64(0)
+	for prevBlock != nil && currentSnapshotHash == prevBlock.SnapshotHash {
+		// quick fail on a receive error block referencing to the same snapshot block
+		if prevBlock.BlockType == ledger.BlockTypeReceiveError {
+			return nil, 0, util.ErrOutOfQuota
+		}
+		if isPoW && IsPoW(prevBlock.Nonce) {
+			// only one block gets extra quota when referencing to the same snapshot block
+			return nil, 0, util.ErrCalcPoWTwice
+		}
+		quotaUsed = quotaUsed + prevBlock.Quota
+		prevBlock = db.GetAccountBlockByHash(&prevBlock.PrevHash)
+	}
+
+	x := new(big.Float).SetPrec(precForFloat).SetUint64(0)
+	if pledgeAmount.Sign() == 0 {
+		return x, quotaUsed, nil
+	}
+	tmpFLoat := new(big.Float).SetPrec(precForFloat)
+	if prevBlock == nil {
+		tmpFLoat.SetUint64(helper.Min(maxQuotaHeightGap, db.CurrentSnapshotBlock().Height))
+	} else {
+		prevSnapshotBlock := db.GetSnapshotBlockByHash(&prevBlock.SnapshotHash)
+		if prevSnapshotBlock == nil {
+			return nil, 0, util.ErrForked
+		}
+		tmpFLoat.SetUint64(helper.Min(maxQuotaHeightGap, db.CurrentSnapshotBlock().Height-prevSnapshotBlock.Height))
+	}
+	x.Mul(tmpFLoat, nodeConfig.paramA)
+	tmpFLoat.SetInt(pledgeAmount)
+	x.Mul(tmpFLoat, x)
+	return x, quotaUsed, nil
+}
+
+func addPoWParam(x *big.Float, difficulty *big.Int) {
+	tmpFLoat := new(big.Float).SetPrec(precForFloat).SetInt(difficulty)
+	tmpFLoat.Mul(tmpFLoat, nodeConfig.paramB)
+	x.Add(x, tmpFLoat)
+}
+
+// CalcPledgeQuota returns the quota got by pledge and the quota used by the previous blocks referring to the current
+// snapshot block.
+func CalcPledgeQuota(db quotaDb, addr types.Address, pledgeAmount *big.Int) (pledgeQuota uint64, quotaUsed uint64, err error) {
+	x, quotaUsed, err := calcPledgeParam(db, pledgeAmount, false)
+	if err != nil {
+		return 0, 0, err
+	}
+	if pledgeAmount.Sign() != 0 {
+		pledgeQuota = calcQuotaInSection(x)
+	}
+	return pledgeQuota, quotaUsed, nil
+}
+
+// CalcPoWDifficultyWithPledge returns the min difficulty with which the account gets quotaRequired quota for the next
+// block, the pledge quota is counted in, so the difficulty is 0 if the pledge quota is enough.
+func CalcPoWDifficultyWithPledge(db quotaDb, addr types.Address, pledgeAmount *big.Int, quotaRequired uint64) (*big.Int, error) {
+	x, quotaUsed, err := calcPledgeParam(db, pledgeAmount, true)
+	if err != nil {
+		return nil, err
+	}
+	var quotaWithoutPoW uint64
+	if pledgeAmount.Sign() != 0 {
+		quotaWithoutPoW = calcQuotaInSection(x)
+	}
+	if quotaWithoutPoW < quotaUsed {
+		return nil, util.ErrOutOfQuota
+	}
+	if quotaWithoutPoW-quotaUsed >= quotaRequired {
+		return big.NewInt(0), nil
+	}
+	if quotaRequired > helper.MaxUint64-quotaUsed {
+		return nil, util.ErrOutOfQuota
+	}
+	index := calcSectionIndexByQuotaRequired(quotaUsed + quotaRequired)
+	if index >= uint64(len(nodeConfig.sectionList)) {
+		return nil, util.ErrOutOfQuota
+	}
+
+	// difficulty = (sectionList[index] - x) / paramB, rounded up by the same float computation as CalcQuotaV2
+	tmpFLoat := new(big.Float).SetPrec(precForFloat).Sub(nodeConfig.sectionList[index], x)
+	tmpFLoat.Quo(tmpFLoat, nodeConfig.paramB)
+	difficulty, _ := tmpFLoat.Int(nil)
 	for {
-		if prevBlock != nil && currentSnapshotHash == prevBlock.SnapshotHash {
-			// quick fail on a receive error block referencing to the same snapshot block
-			if prevBlock.BlockType == ledger.BlockTypeReceiveError {
-				return 0, 0, util.ErrOutOfQuota
-			}
-			if isPoW && IsPoW(prevBlock.Nonce) {
-				// only one block gets extra quota when referencing to the same snapshot block
-				return 0, 0, util.ErrCalcPoWTwice
-			}
-			quotaUsed = quotaUsed + prevBlock.Quota
-			prevBlock = db.GetAccountBlockByHash(&prevBlock.PrevHash)
-		} else {
-			x := new(big.Float).SetPrec(precForFloat).SetUint64(0)
-			tmpFLoat := new(big.Float).SetPrec(precForFloat)
-			var quotaWithoutPoW uint64
-			if pledgeAmount.Sign() == 0 {
-				quotaWithoutPoW = 0
-			} else {
-				if prevBlock == nil {
-					tmpFLoat.SetUint64(helper.Min(maxQuotaHeightGap, db.CurrentSnapshotBlock().Height))
-				} else {
-					prevSnapshotBlock := db.GetSnapshotBlockByHash(&prevBlock.SnapshotHash)
-					if prevSnapshotBlock == nil {
-						return 0, 0, util.ErrForked
-					}
-					tmpFLoat.SetUint64(helper.Min(maxQuotaHeightGap, db.CurrentSnapshotBlock().Height-prevSnapshotBlock.Height))
-				}
-				x.Mul(tmpFLoat, nodeConfig.paramA)
-				tmpFLoat.SetInt(pledgeAmount)
-				x.Mul(tmpFLoat, x)
-				quotaWithoutPoW = calcQuotaInSection(x)
-			}
-			if quotaWithoutPoW < quotaUsed {
-				return 0, 0, nil
-			}
-			quotaTotal := quotaWithoutPoW
-			if isPoW {
-				tmpFLoat.SetInt(difficulty)
-				tmpFLoat.Mul(tmpFLoat, nodeConfig.paramB)
-				x.Add(x, tmpFLoat)
-				quotaTotal = calcQuotaInSection(x)
-			}
-			return quotaTotal - quotaUsed, quotaTotal - quotaWithoutPoW, nil
+		xWithPoW := new(big.Float).SetPrec(precForFloat).Set(x)
+		addPoWParam(xWithPoW, difficulty)
+		if uint64(getIndexInSection(xWithPoW)) >= index {
+			return difficulty, nil
 		}
+		difficulty.Add(difficulty, helper.Big1)
 	}
 }
 
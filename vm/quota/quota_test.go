@@ -2,7 +2,10 @@ package quota
 
 import (
 	"fmt"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/vm/util"
+	"github.com/vitelabs/go-vite/vm_context/vmctxt_interface"
 	"math"
 	"math/big"
 	"testing"
@@ -235,3 +238,58 @@ func TestCalcPoWDifficultyTest(t *testing.T) {
 		}
 	}
 }
+
+type testQuotaDb struct {
+	currentSnapshotBlock *ledger.SnapshotBlock
+	prevAccountBlock     *ledger.AccountBlock
+}
+
+func (db *testQuotaDb) GetStorage(addr *types.Address, key []byte) []byte { return nil }
+func (db *testQuotaDb) NewStorageIterator(addr *types.Address, prefix []byte) vmctxt_interface.StorageIterator {
+	return nil
+}
+func (db *testQuotaDb) GetAccountBlockByHash(hash *types.Hash) *ledger.AccountBlock   { return nil }
+func (db *testQuotaDb) CurrentSnapshotBlock() *ledger.SnapshotBlock                   { return db.currentSnapshotBlock }
+func (db *testQuotaDb) PrevAccountBlock() *ledger.AccountBlock                        { return db.prevAccountBlock }
+func (db *testQuotaDb) GetSnapshotBlockByHash(hash *types.Hash) *ledger.SnapshotBlock { return nil }
+func (db *testQuotaDb) GetGenesisSnapshotBlock() *ledger.SnapshotBlock                { return nil }
+
+func TestCalcPoWDifficultyWithPledge(t *testing.T) {
+	InitQuotaConfig(false)
+	snapshotBlock := &ledger.SnapshotBlock{Hash: types.DataHash([]byte("snapshot")), Height: 1}
+	addr := types.Address{}
+	minPledgeAmount, _ := new(big.Int).SetString("1000000000000000000000", 10)
+
+	tests := []struct {
+		pledgeAmount  *big.Int
+		prevQuota     uint64
+		quotaRequired uint64
+	}{
+		{big.NewInt(0), 0, util.TxGas},
+		{big.NewInt(0), 0, util.TxGas * 3},
+		{minPledgeAmount, 0, util.TxGas},
+		{minPledgeAmount, 0, util.TxGas * 3},
+		{new(big.Int).Mul(minPledgeAmount, big.NewInt(100)), 0, util.TxGas},
+		{new(big.Int).Mul(minPledgeAmount, big.NewInt(100)), util.TxGas * 2, util.TxGas * 2},
+	}
+	for i, test := range tests {
+		db := &testQuotaDb{currentSnapshotBlock: snapshotBlock}
+		if test.prevQuota > 0 {
+			db.prevAccountBlock = &ledger.AccountBlock{SnapshotHash: snapshotBlock.Hash, Quota: test.prevQuota}
+		}
+		difficulty, err := CalcPoWDifficultyWithPledge(db, addr, test.pledgeAmount, test.quotaRequired)
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if quotaTotal, _, err := CalcQuotaV2(db, addr, test.pledgeAmount, difficulty); err != nil || quotaTotal < test.quotaRequired {
+			t.Fatalf("test %d: difficulty %v is not enough, quota %v, %v", i, difficulty, quotaTotal, err)
+		}
+		// the pledge quota is counted in, and the quota used is counted in the default difficulty as well
+		if difficulty.Cmp(CalcPoWDifficulty(test.prevQuota+test.quotaRequired)) > 0 {
+			t.Fatalf("test %d: difficulty %v is larger than the default one", i, difficulty)
+		}
+		if test.pledgeAmount.Sign() > 0 && difficulty.Cmp(CalcPoWDifficulty(test.quotaRequired)) >= 0 {
+			t.Fatalf("test %d: the pledge quota is not counted in, difficulty %v", i, difficulty)
+		}
+	}
+}
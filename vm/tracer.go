@@ -0,0 +1,173 @@
+package vm
+
+import (
+	"encoding/hex"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm_context"
+)
+
+const (
+	TraceEnterDelegateCall = "DELEGATECALL"
+	TraceEnterSend         = "SEND"
+)
+
+// TraceStep is the interpreter state before an opcode is executed, stack and memory are copies.
+type TraceStep struct {
+	Pc        uint64
+	Op        string
+	QuotaLeft uint64
+	Cost      uint64
+	Address   types.Address
+	Stack     []*big.Int
+	Memory    []byte
+}
+
+// Tracer is called by the vm while running a block, it is only used when VMConfig.Tracer is set.
+type Tracer interface {
+	CaptureStart(block *ledger.AccountBlock, sendBlock *ledger.AccountBlock)
+	CaptureStep(step *TraceStep)
+	// CaptureFault is called instead of CaptureStep if the opcode fails before running,
+	// or after CaptureStep with the same step if it fails while running.
+	CaptureFault(step *TraceStep, err error)
+	// CaptureEnter and CaptureExit wrap a delegate call or a send block generated by the receive.
+	CaptureEnter(typ string, from types.Address, to types.Address, data []byte, quotaLeft uint64)
+	CaptureExit(ret []byte, err error)
+	CaptureEnd(blockList []*vm_context.VmAccountBlock, err error)
+}
+
+func newTraceStep(pc uint64, op opCode, c *contract, st *stack, mem *memory, cost uint64) *TraceStep {
+	step := &TraceStep{
+		Pc:        pc,
+		Op:        op.String(),
+		QuotaLeft: c.quotaLeft,
+		Cost:      cost,
+		Address:   c.codeAddr,
+		Stack:     make([]*big.Int, len(st.data)),
+		Memory:    make([]byte, len(mem.store)),
+	}
+	for i, value := range st.data {
+		step.Stack[i] = new(big.Int).Set(value)
+	}
+	copy(step.Memory, mem.store)
+	return step
+}
+
+type StructLog struct {
+	Pc        uint64            `json:"pc"`
+	Op        string            `json:"op"`
+	QuotaLeft uint64            `json:"quotaLeft"`
+	Cost      uint64            `json:"cost"`
+	Depth     int               `json:"depth"`
+	Stack     []string          `json:"stack"`
+	Memory    string            `json:"memory"`
+	Storage   map[string]string `json:"storage,omitempty"`
+	Err       string            `json:"err,omitempty"`
+}
+
+type CallLog struct {
+	Type      string        `json:"type"`
+	Depth     int           `json:"depth"`
+	From      types.Address `json:"from"`
+	To        types.Address `json:"to"`
+	Data      string        `json:"data"`
+	QuotaLeft uint64        `json:"quotaLeft"`
+	Ret       string        `json:"ret"`
+	Err       string        `json:"err,omitempty"`
+}
+
+type TraceResult struct {
+	Logs  []*StructLog `json:"logs"`
+	Calls []*CallLog   `json:"calls"`
+	Err   string       `json:"err,omitempty"`
+}
+
+// StructLogger records every step of the interpreter, storage shows the slot written by each SSTORE.
+type StructLogger struct {
+	depth     int
+	lastStep  *TraceStep
+	callStack []*CallLog
+	result    TraceResult
+}
+
+func NewStructLogger() *StructLogger {
+	return &StructLogger{
+		result: TraceResult{
+			Logs:  make([]*StructLog, 0),
+			Calls: make([]*CallLog, 0),
+		},
+	}
+}
+
+func (l *StructLogger) CaptureStart(block *ledger.AccountBlock, sendBlock *ledger.AccountBlock) {}
+
+func (l *StructLogger) CaptureStep(step *TraceStep) {
+	log := &StructLog{
+		Pc:        step.Pc,
+		Op:        step.Op,
+		QuotaLeft: step.QuotaLeft,
+		Cost:      step.Cost,
+		Depth:     l.depth,
+		Stack:     make([]string, len(step.Stack)),
+		Memory:    hex.EncodeToString(step.Memory),
+	}
+	for i, value := range step.Stack {
+		log.Stack[i] = value.Text(16)
+	}
+
+	if step.Op == SSTORE.String() && len(step.Stack) >= 2 {
+		locHash, _ := types.BigToHash(step.Stack[len(step.Stack)-1])
+		log.Storage = map[string]string{
+			hex.EncodeToString(locHash.Bytes()): hex.EncodeToString(step.Stack[len(step.Stack)-2].Bytes()),
+		}
+	}
+	l.result.Logs = append(l.result.Logs, log)
+	l.lastStep = step
+}
+
+func (l *StructLogger) CaptureFault(step *TraceStep, err error) {
+	if step != l.lastStep {
+		l.CaptureStep(step)
+	}
+	l.result.Logs[len(l.result.Logs)-1].Err = err.Error()
+}
+
+func (l *StructLogger) CaptureEnter(typ string, from types.Address, to types.Address, data []byte, quotaLeft uint64) {
+	l.depth++
+	call := &CallLog{
+		Type:      typ,
+		Depth:     l.depth,
+		From:      from,
+		To:        to,
+		Data:      hex.EncodeToString(data),
+		QuotaLeft: quotaLeft,
+	}
+	l.callStack = append(l.callStack, call)
+	l.result.Calls = append(l.result.Calls, call)
+}
+
+func (l *StructLogger) CaptureExit(ret []byte, err error) {
+	if len(l.callStack) == 0 {
+		return
+	}
+	call := l.callStack[len(l.callStack)-1]
+	l.callStack = l.callStack[:len(l.callStack)-1]
+	l.depth--
+
+	call.Ret = hex.EncodeToString(ret)
+	if err != nil {
+		call.Err = err.Error()
+	}
+}
+
+func (l *StructLogger) CaptureEnd(blockList []*vm_context.VmAccountBlock, err error) {
+	if err != nil {
+		l.result.Err = err.Error()
+	}
+}
+
+func (l *StructLogger) Result() *TraceResult {
+	return &l.result
+}
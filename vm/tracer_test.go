@@ -0,0 +1,92 @@
+package vm
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/helper"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/util"
+)
+
+func runTracedCode(db *testDatabase, addr types.Address, code []byte) (*TraceResult, error) {
+	blockTime := time.Now()
+	tracer := NewStructLogger()
+	vm := NewVM()
+	vm.Tracer = tracer
+	vm.i = NewInterpreter(1, false)
+	sendCallBlock := ledger.AccountBlock{
+		AccountAddress: addr,
+		ToAddress:      addr,
+		BlockType:      ledger.BlockTypeSendCall,
+		Amount:         big.NewInt(10),
+		Fee:            big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+	}
+	receiveCallBlock := &ledger.AccountBlock{
+		AccountAddress: addr,
+		BlockType:      ledger.BlockTypeReceive,
+		Timestamp:      &blockTime,
+	}
+	c := newContract(receiveCallBlock, db, &sendCallBlock, nil, 1000000, 0)
+	c.setCallCode(addr, code)
+	_, err := c.run(vm)
+	return tracer.Result(), err
+}
+
+func TestStructLogger(t *testing.T) {
+	db, _, _, _, _, _ := prepareDb(new(big.Int).Mul(big.NewInt(1e9), util.AttovPerVite))
+
+	// code1 stores 1+2 at slot 5 and returns it
+	addr1, _, _ := types.CreateAddress()
+	code1 := []byte{1, byte(PUSH1), 1, byte(PUSH1), 2, byte(ADD), byte(DUP1), byte(PUSH1), 5, byte(SSTORE), byte(PUSH1), 0, byte(MSTORE), byte(PUSH1), 32, byte(PUSH1), 0, byte(RETURN)}
+	db.codeMap[addr1] = code1
+
+	addr2, _, _ := types.CreateAddress()
+	code2 := helper.JoinBytes([]byte{1, byte(PUSH1), 32, byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH1), 0, byte(PUSH20)}, addr1.Bytes(), []byte{byte(DELEGATECALL), byte(PUSH1), 32, byte(PUSH1), 0, byte(RETURN)})
+
+	result, err := runTracedCode(db, addr2, code2[1:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Calls) != 1 || result.Calls[0].Type != TraceEnterDelegateCall || result.Calls[0].To != addr1 || result.Calls[0].Err != "" {
+		t.Fatalf("unexpected calls %v", result.Calls)
+	}
+	if len(result.Logs) != 20 {
+		t.Fatalf("trace has %d steps, expected 20", len(result.Logs))
+	}
+
+	var sstore *StructLog
+	for _, log := range result.Logs {
+		if log.Op == SSTORE.String() {
+			sstore = log
+		}
+	}
+	if sstore == nil || sstore.Depth != 1 {
+		t.Fatalf("sstore is not traced in the delegate call")
+	}
+	locHash, _ := types.BigToHash(big.NewInt(5))
+	for key, value := range sstore.Storage {
+		if key != locHash.Hex() || value != "03" {
+			t.Fatalf("storage diff is %s=%s", key, value)
+		}
+	}
+	if last := result.Logs[len(result.Logs)-1]; last.Op != RETURN.String() || last.Depth != 0 {
+		t.Fatalf("last step is %s at depth %d", last.Op, last.Depth)
+	}
+}
+
+func TestStructLoggerFault(t *testing.T) {
+	db, _, _, _, _, _ := prepareDb(new(big.Int).Mul(big.NewInt(1e9), util.AttovPerVite))
+	addr, _, _ := types.CreateAddress()
+
+	result, err := runTracedCode(db, addr, []byte{byte(PUSH1), 1, byte(ADD)})
+	if err == nil {
+		t.Fatal("stack underflow expected")
+	}
+	if len(result.Logs) != 2 || result.Logs[0].Err != "" || result.Logs[1].Op != ADD.String() || result.Logs[1].Err != err.Error() {
+		t.Fatalf("unexpected fault trace %v", result.Logs)
+	}
+}
@@ -106,8 +106,13 @@ func PrintMap(m map[string][]byte) string {
 	return result
 }
 
-func IsUserAccount(db CommonDb, addr types.Address) bool {
-	if types.IsPrecompiledContractAddress(addr) {
+type UserAccountDb interface {
+	CommonDb
+	CurrentSnapshotBlock() *ledger.SnapshotBlock
+}
+
+func IsUserAccount(db UserAccountDb, addr types.Address) bool {
+	if types.IsPrecompiledContractAddress(addr, db.CurrentSnapshotBlock().Height) {
 		return false
 	}
 	_, code := GetContractCode(db, &addr)
@@ -30,7 +30,11 @@ import (
 )
 
 type VMConfig struct {
-	Debug bool
+	Debug  bool
+	Tracer Tracer
+	// QuotaLimit replaces the quota of the account when running a send block if it is not 0, so that the quota
+	// required by the block can be measured
+	QuotaLimit uint64
 }
 
 type NodeConfig struct {
@@ -169,6 +173,12 @@ func (vm *VM) Run(database vmctxt_interface.VmDatabase, block *ledger.AccountBlo
 			"height", block.Height, ""+
 				"fromHash", block.FromBlockHash.String())
 	}
+	if vm.Tracer != nil {
+		vm.Tracer.CaptureStart(block, sendBlock)
+		defer func() {
+			vm.Tracer.CaptureEnd(blockList, err)
+		}()
+	}
 	blockContext := &vm_context.VmAccountBlock{block.Copy(), database}
 	vm.i = NewInterpreter(database.CurrentSnapshotBlock().Height, false)
 	switch block.BlockType {
@@ -188,11 +198,7 @@ func (vm *VM) Run(database vmctxt_interface.VmDatabase, block *ledger.AccountBlo
 		if !fork.IsSmartFork(database.CurrentSnapshotBlock().Height) {
 			return nil, NoRetry, errors.New("snapshot height not supported")
 		}
-		quotaTotal, quotaAddition, err := nodeConfig.calcQuota(
-			database,
-			block.AccountAddress,
-			abi.GetPledgeBeneficialAmount(database, block.AccountAddress),
-			block.Difficulty)
+		quotaTotal, quotaAddition, err := vm.calcSendQuota(database, block)
 		if err != nil {
 			return nil, NoRetry, err
 		}
@@ -203,11 +209,7 @@ func (vm *VM) Run(database vmctxt_interface.VmDatabase, block *ledger.AccountBlo
 			return []*vm_context.VmAccountBlock{blockContext}, NoRetry, nil
 		}
 	case ledger.BlockTypeSendCall:
-		quotaTotal, quotaAddition, err := nodeConfig.calcQuota(
-			database,
-			block.AccountAddress,
-			abi.GetPledgeBeneficialAmount(database, block.AccountAddress),
-			block.Difficulty)
+		quotaTotal, quotaAddition, err := vm.calcSendQuota(database, block)
 		if err != nil {
 			return nil, NoRetry, err
 		}
@@ -347,7 +349,7 @@ func (vm *VM) sendCall(block *vm_context.VmAccountBlock, quotaTotal, quotaAdditi
 
 	// check can make transaction
 	quotaLeft := quotaTotal
-	if p, ok, err := GetPrecompiledContract(block.AccountBlock.ToAddress, block.AccountBlock.Data); ok {
+	if p, ok, err := GetPrecompiledContract(block.AccountBlock.ToAddress, block.AccountBlock.Data, block.VmContext.CurrentSnapshotBlock().Height); ok {
 		if err != nil {
 			return nil, err
 		}
@@ -414,7 +416,7 @@ func (vm *VM) receiveCall(block *vm_context.VmAccountBlock, sendBlock *ledger.Ac
 		vm.updateBlock(block, util.ErrDepth, 0)
 		return vm.blockList, NoRetry, util.ErrDepth
 	}
-	if p, ok, _ := GetPrecompiledContract(block.AccountBlock.AccountAddress, sendBlock.Data); ok {
+	if p, ok, _ := GetPrecompiledContract(block.AccountBlock.AccountAddress, sendBlock.Data, block.VmContext.CurrentSnapshotBlock().Height); ok {
 		vm.blockList = []*vm_context.VmAccountBlock{block}
 		block.VmContext.AddBalance(&sendBlock.TokenId, sendBlock.Amount)
 		blockListToSend, err := p.DoReceive(block.VmContext, block.AccountBlock, sendBlock)
@@ -636,6 +638,17 @@ func (vm *VM) sendRefund(block *vm_context.VmAccountBlock, quotaTotal, quotaAddi
 	return block, nil
 }
 
+func (vm *VM) calcSendQuota(database vmctxt_interface.VmDatabase, block *ledger.AccountBlock) (uint64, uint64, error) {
+	if vm.QuotaLimit > 0 {
+		return vm.QuotaLimit, 0, nil
+	}
+	return nodeConfig.calcQuota(
+		database,
+		block.AccountAddress,
+		abi.GetPledgeBeneficialAmount(database, block.AccountAddress),
+		block.Difficulty)
+}
+
 func (vm *VM) receiveRefund(block *vm_context.VmAccountBlock, sendBlock *ledger.AccountBlock) (blockList []*vm_context.VmAccountBlock, isRetry bool, err error) {
 	//defer monitor.LogTime("vm", "receiveRefund", time.Now())
 	var monitorTags []string
@@ -672,7 +685,13 @@ func (vm *VM) delegateCall(contractAddr types.Address, data []byte, c *contract)
 	if len(code) > 0 {
 		cNew := newContract(c.block, c.db, c.sendBlock, c.data, c.quotaLeft, c.quotaRefund)
 		cNew.setCallCode(contractAddr, code)
+		if vm.Tracer != nil {
+			vm.Tracer.CaptureEnter(TraceEnterDelegateCall, c.codeAddr, contractAddr, data, c.quotaLeft)
+		}
 		ret, err = cNew.run(vm)
+		if vm.Tracer != nil {
+			vm.Tracer.CaptureExit(ret, err)
+		}
 		c.quotaLeft, c.quotaRefund = cNew.quotaLeft, cNew.quotaRefund
 		return ret, err
 	}
@@ -698,22 +717,22 @@ func (vm *VM) doSendBlockList(quotaLeft uint64, quotaAdditionForOneTx uint64) (e
 		db = db.CopyAndFreeze()
 		block.VmContext = db
 		quotaTotal := quotaLeft + quotaAdditionForOneTx
+		if vm.Tracer != nil {
+			vm.Tracer.CaptureEnter(TraceEnterSend, block.AccountBlock.AccountAddress, block.AccountBlock.ToAddress, block.AccountBlock.Data, quotaTotal)
+		}
 		switch block.AccountBlock.BlockType {
 		case ledger.BlockTypeSendCall:
 			vm.blockList[i+1], err = vm.sendCall(block, quotaTotal, quotaAdditionForOneTx)
-			if err != nil {
-				return err
-			}
 		case ledger.BlockTypeSendReward:
 			vm.blockList[i+1], err = vm.sendReward(block, quotaTotal, quotaAdditionForOneTx)
-			if err != nil {
-				return err
-			}
 		case ledger.BlockTypeSendRefund:
 			vm.blockList[i+1], err = vm.sendRefund(block, quotaTotal, quotaAdditionForOneTx)
-			if err != nil {
-				return err
-			}
+		}
+		if vm.Tracer != nil {
+			vm.Tracer.CaptureExit(nil, err)
+		}
+		if err != nil {
+			return err
 		}
 		quotaLeft = quotaLeft - vm.blockList[i+1].AccountBlock.Quota
 	}
@@ -750,7 +769,7 @@ func checkDepth(db vmctxt_interface.VmDatabase, sendBlock *ledger.AccountBlock)
 		depth = depth + 1
 		prevReceiveBlock := findPrevReceiveBlock(db, prevBlock)
 		prevBlock = db.GetAccountBlockByHash(&prevReceiveBlock.FromBlockHash)
-		if prevBlock == nil && prevReceiveBlock.Height == 1 && types.IsPrecompiledContractAddress(prevReceiveBlock.AccountAddress) {
+		if prevBlock == nil && prevReceiveBlock.Height == 1 && types.IsPrecompiledContractAddress(prevReceiveBlock.AccountAddress, db.CurrentSnapshotBlock().Height) {
 			// some precompiled contracts' genesis block does not have prevblock
 			return false
 		}
@@ -0,0 +1,66 @@
+package offline
+
+import (
+	"encoding/base64"
+	"errors"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+// payloadVersion is the first byte of a payload, the rest is the protobuf of the block.
+const payloadVersion byte = 1
+
+var (
+	ErrInvalidPayload  = errors.New("invalid offline payload")
+	ErrHashNotMatch    = errors.New("hash of the block doesn't match")
+	ErrAddressNotMatch = errors.New("signer doesn't match with the account address")
+)
+
+// SignFunc signs the data with the key of the address, entropystore.Manager.SignDataWithPassphrase can be wrapped as
+// a SignFunc.
+type SignFunc func(addr types.Address, data []byte) (signedData, pubkey []byte, err error)
+
+// EncodeBlock encodes the block into a portable payload, which is the url safe base64 of a version byte and the
+// protobuf of the block, so that it can be copied or shown as a QR code.
+func EncodeBlock(block *ledger.AccountBlock) (string, error) {
+	data, err := block.Serialize()
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(append([]byte{payloadVersion}, data...)), nil
+}
+
+// DecodeBlock decodes a payload encoded by EncodeBlock, the hash of the block is checked.
+func DecodeBlock(payload string) (*ledger.AccountBlock, error) {
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil || len(data) <= 1 || data[0] != payloadVersion {
+		return nil, ErrInvalidPayload
+	}
+	block := &ledger.AccountBlock{}
+	if err := block.Deserialize(data[1:]); err != nil {
+		return nil, ErrInvalidPayload
+	}
+	if block.ComputeHash() != block.Hash {
+		return nil, ErrHashNotMatch
+	}
+	return block, nil
+}
+
+// SignBlock signs the hash of the block with the key of the account address, the block is expected to be decoded
+// from a payload and not signed yet.
+func SignBlock(block *ledger.AccountBlock, signFunc SignFunc) error {
+	if block.ComputeHash() != block.Hash {
+		return ErrHashNotMatch
+	}
+	signature, pubkey, err := signFunc(block.AccountAddress, block.Hash.Bytes())
+	if err != nil {
+		return err
+	}
+	if types.PubkeyToAddress(pubkey) != block.AccountAddress {
+		return ErrAddressNotMatch
+	}
+	block.Signature = signature
+	block.PublicKey = pubkey
+	return nil
+}
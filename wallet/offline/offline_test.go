@@ -0,0 +1,81 @@
+package offline
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+func TestSignBlock(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	addr := types.PubkeyToAddress(pub)
+	ts := time.Unix(1539604021, 0)
+	block := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		Height:         2,
+		PrevHash:       types.DataHash([]byte{1}),
+		AccountAddress: addr,
+		ToAddress:      addr,
+		Amount:         big.NewInt(100),
+		TokenId:        ledger.ViteTokenId,
+		Fee:            big.NewInt(0),
+		SnapshotHash:   types.DataHash([]byte{2}),
+		Data:           []byte{1, 2, 3},
+		Timestamp:      &ts,
+		Difficulty:     big.NewInt(65535),
+		Nonce:          []byte{1, 2, 3, 4, 5, 6, 7, 8},
+	}
+	block.Hash = block.ComputeHash()
+
+	payload, err := EncodeBlock(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := DecodeBlock(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Hash != block.Hash {
+		t.Fatalf("hash of the decoded block doesn't match")
+	}
+	if _, err := DecodeBlock(payload[:len(payload)-4]); err == nil {
+		t.Fatalf("decode a broken payload")
+	}
+
+	signFunc := func(priv ed25519.PrivateKey) SignFunc {
+		return func(addr types.Address, data []byte) ([]byte, []byte, error) {
+			return ed25519.Sign(priv, data), priv.PubByte(), nil
+		}
+	}
+	_, otherPriv, _ := ed25519.GenerateKey(nil)
+	if err := SignBlock(decoded, signFunc(otherPriv)); err != ErrAddressNotMatch {
+		t.Fatalf("sign with another key, err %v", err)
+	}
+	if err := SignBlock(decoded, signFunc(priv)); err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.VerifySignature() {
+		t.Fatalf("verify signature failed")
+	}
+
+	signedPayload, err := EncodeBlock(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := DecodeBlock(signedPayload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !signed.VerifySignature() {
+		t.Fatalf("verify signature of the decoded signed block failed")
+	}
+
+	decoded.Amount = big.NewInt(200)
+	if err := SignBlock(decoded, signFunc(priv)); err != ErrHashNotMatch {
+		t.Fatalf("sign a modified block, err %v", err)
+	}
+}